@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// addDataFiles adds the links in the data files of the site at siteRoot to
+// files: the link lists, resource catalogs, and sponsor tables templates
+// render on pages but that no content file holds. Only the fields selected
+// by keys are read.
+func addDataFiles(files []*scanner.File, siteRoot string, keys []string, ignorePatterns []*regexp.Regexp) ([]*scanner.File, error) {
+	paths, err := findFiles(filepath.Join(siteRoot, "data"), scanner.IsDataFile)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]*scanner.File, len(files))
+	for _, file := range files {
+		byPath[file.CanonicalPath] = file
+	}
+	for _, path := range paths {
+		canonicalPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get canonical path for %s: %v", path, err)
+		}
+		file, existing := byPath[canonicalPath]
+		if !existing {
+			file = &scanner.File{Path: path, CanonicalPath: canonicalPath}
+		}
+		if err := scanner.ParseDataFile(file, keys); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing links from %s: %v\n", path, err)
+			continue
+		}
+		applyIgnorePatterns(file, ignorePatterns)
+		if !existing && len(file.Links) > 0 {
+			files = append(files, file)
+			byPath[canonicalPath] = file
+		}
+	}
+	return files, nil
+}
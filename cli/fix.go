@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/fixer"
+	"github.com/infodancer/hugo-link-checker/internal/reporter"
+)
+
+// runFix implements the fix subcommand, which applies the suggested fixes of
+// the JSON report of an earlier run, such as relative forms of links to the
+// site's own host and the new URLs of moved pages, to the files the links
+// were found in. With -patch, the fixes are written as a unified diff
+// instead, to be reviewed in a PR or applied selectively with git apply.
+func runFix(args []string) {
+	flags := flag.NewFlagSet("fix", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hugo-link-checker fix -from report.json [flags]\n")
+		flags.PrintDefaults()
+	}
+	from := flags.String("from", "", "JSON report of an earlier run whose suggested fixes are applied")
+	patch := flags.String("patch", "", "Write the fixes as a unified diff to this file (- for stdout) instead of editing files in place")
+	if err := flags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if *from == "" || flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	files, _, err := reporter.ReadReportFiles(*from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	var diff strings.Builder
+	fixedLinks, fixedFiles := 0, 0
+	for _, file := range files {
+		fixes := make(map[string]string)
+		for _, link := range file.Links {
+			if link.Fix != "" && link.Fix != link.URL {
+				fixes[link.URL] = link.Fix
+			}
+		}
+		if len(fixes) == 0 {
+			continue
+		}
+
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		content, count := fixer.ReplaceLinks(string(data), fixes)
+		if count == 0 {
+			continue
+		}
+		fixedLinks += count
+		fixedFiles++
+
+		if *patch != "" {
+			diff.WriteString(fixer.UnifiedDiff(patchPath(file.Path), string(data), content))
+			continue
+		}
+		info, err := os.Stat(file.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fixing %s: %v\n", file.Path, err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(file.Path, []byte(content), info.Mode().Perm()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error fixing %s: %v\n", file.Path, err)
+			os.Exit(1)
+		}
+	}
+
+	switch *patch {
+	case "":
+		fmt.Fprintf(os.Stderr, "Fixed %d links in %d files\n", fixedLinks, fixedFiles)
+	case "-":
+		fmt.Print(diff.String())
+	default:
+		if err := os.WriteFile(*patch, []byte(diff.String()), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing patch: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote fixes for %d links in %d files to %s\n", fixedLinks, fixedFiles, *patch)
+	}
+}
+
+// patchPath returns the name of a file in a patch: relative to the working
+// directory where possible, with forward slashes, as git apply expects
+func patchPath(path string) string {
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
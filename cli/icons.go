@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/hugo"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// addIconFiles adds the favicon, touch icon, and web manifest references of
+// the site at siteRoot to files: the icon links of its templates (including
+// themes), the icon paths in its site params, and the icons listed in its web
+// manifests. These 404 silently in the browser but fill server error logs and
+// break PWA installs. Templates already in files get the icon links added to
+// their other links.
+func addIconFiles(files []*scanner.File, siteRoot string, ignorePatterns []*regexp.Regexp) ([]*scanner.File, error) {
+	byPath := make(map[string]*scanner.File, len(files))
+	for _, file := range files {
+		byPath[file.CanonicalPath] = file
+	}
+	fileFor := func(path string) (*scanner.File, bool, error) {
+		canonicalPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get canonical path for %s: %v", path, err)
+		}
+		if file, ok := byPath[canonicalPath]; ok {
+			return file, true, nil
+		}
+		return &scanner.File{Path: path, CanonicalPath: canonicalPath}, false, nil
+	}
+	add := func(file *scanner.File, existing bool) {
+		applyIgnorePatterns(file, ignorePatterns)
+		if !existing && len(file.Links) > 0 {
+			files = append(files, file)
+			byPath[file.CanonicalPath] = file
+		}
+	}
+
+	layoutDirs := []string{filepath.Join(siteRoot, "layouts")}
+	staticDirs := []string{filepath.Join(siteRoot, "static")}
+	themes, _ := filepath.Glob(filepath.Join(siteRoot, "themes", "*"))
+	sort.Strings(themes)
+	for _, theme := range themes {
+		layoutDirs = append(layoutDirs, filepath.Join(theme, "layouts"))
+		staticDirs = append(staticDirs, filepath.Join(theme, "static"))
+	}
+
+	for _, dir := range layoutDirs {
+		templates, err := findFiles(dir, func(name string) bool { return strings.HasSuffix(name, ".html") })
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range templates {
+			file, existing, err := fileFor(path)
+			if err != nil {
+				return nil, err
+			}
+			if err := scanner.ParseHeadIcons(file); err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing links from %s: %v\n", path, err)
+				continue
+			}
+			add(file, existing)
+		}
+	}
+
+	siteConfig, err := hugo.LoadConfig(siteRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else if params := siteConfig.IconParams(); len(params) > 0 && siteConfig.Path() != "" {
+		file, existing, err := fileFor(siteConfig.Path())
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			file.Links = append(file.Links, scanner.NewLink(scanner.SitePath(params[name])))
+		}
+		add(file, existing)
+	}
+
+	for _, dir := range staticDirs {
+		manifests, err := findFiles(dir, func(name string) bool {
+			return strings.HasSuffix(name, ".webmanifest") || name == "manifest.json"
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range manifests {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				continue
+			}
+			file, existing, err := fileFor(path)
+			if err != nil {
+				return nil, err
+			}
+			if err := scanner.ParseWebManifest(file, "/"+filepath.ToSlash(rel)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing links from %s: %v\n", path, err)
+				continue
+			}
+			add(file, existing)
+		}
+	}
+
+	return files, nil
+}
+
+// findFiles returns the files under dir whose lowercased name matches; a
+// missing dir has none
+func findFiles(dir string, match func(name string) bool) ([]string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, nil
+	}
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && match(strings.ToLower(info.Name())) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error scanning files in %s: %v", dir, err)
+	}
+	return paths, nil
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/checker"
+	"github.com/infodancer/hugo-link-checker/internal/config"
+	"github.com/infodancer/hugo-link-checker/internal/lsp"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// runLSP implements the lsp subcommand, a language server on stdin and
+// stdout that checks the links of the Markdown documents open in an editor
+// as they are written and reports broken ones as diagnostics
+func runLSP(args []string) {
+	flags := flag.NewFlagSet("lsp", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hugo-link-checker lsp [flags]\n")
+		flags.PrintDefaults()
+	}
+	rootDir := flags.String("root", "", "Hugo site root (default: the workspace root opened by the editor)")
+	checkExternal := flags.Bool("check-external", false, "Check external links once a document has been left unchanged for -debounce")
+	checkImages := flags.Bool("check-images", false, "Check image sources in page bundles and static/")
+	fmKeys := flags.String("front-matter-keys", strings.Join(scanner.DefaultFrontMatterURLKeys, ","), "Comma-separated front matter field names or paths whose URLs and file paths are checked")
+	wikiLinks := flags.Bool("wiki-links", false, "Check wiki links ([[Page Name]], [[page|text]]) against the content tree")
+	lazyAttrs := flags.String("lazy-load-attrs", strings.Join(scanner.DefaultLazyLoadAttrs, ","), "Comma-separated HTML attributes holding lazy-loaded image sources, checked with -check-images")
+	configFile := flags.String("config", "", "YAML config whose custom shortcodes, rewrite rules, and link policy rules apply")
+	resultCache := flags.String("cache", "", "Keep external check results in this file, or a shared http(s):// or s3:// location, across sessions")
+	cacheMaxAge := flags.Duration("cache-max-age", checker.DefaultCacheMaxAge, "Reuse cached external results younger than this")
+	cacheKeep := flags.Duration("cache-retention", 0, "Drop results older than this from the -cache file when saving it (0: keep results of any age)")
+	debounce := flags.Duration("debounce", lsp.DefaultDebounce, "How long a document must be left unchanged before its external links are checked")
+	if err := flags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *debounce <= 0 {
+		fmt.Fprintf(os.Stderr, "Flag -debounce must be positive\n")
+		os.Exit(1)
+	}
+
+	cfg := &config.Config{}
+	if *configFile != "" {
+		var err error
+		cfg, err = config.Load(*configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+	ignorePatterns, err := loadIgnorePatterns()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading ignore patterns: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := lsp.Options{
+		Check: checker.Options{
+			RootDir:       *rootDir,
+			CheckExternal: *checkExternal,
+		},
+		Parse:    scanner.ParseOptions{CheckImages: *checkImages, FrontMatterKeys: splitList(*fmKeys), Shortcodes: cfg.Shortcodes, WikiLinks: *wikiLinks, LazyLoadAttrs: splitList(*lazyAttrs)},
+		Ignore:   ignorePatterns,
+		Debounce: *debounce,
+	}
+	if *rootDir != "" {
+		opts.Check.HeadingIDType = siteHeadingIDType(scanner.SiteRoot(*rootDir))
+	}
+	for _, rewrite := range cfg.Rewrites {
+		rule, err := checker.NewRewriteRule(rewrite.Match, rewrite.Replace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		opts.Check.Rewrites = append(opts.Check.Rewrites, rule)
+	}
+	for _, rule := range cfg.Rules {
+		policyRule, err := checker.NewPolicyRule(rule.ID, rule.Severity, rule.Scheme, rule.Host, rule.Match, rule.Message)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		opts.Check.Rules = append(opts.Check.Rules, policyRule)
+	}
+	if *resultCache != "" {
+		opts.Check.Cache = checker.LoadResultCache(*resultCache, *cacheMaxAge)
+		opts.Check.Cache.Retention = *cacheKeep
+	} else {
+		opts.Check.Cache = checker.NewResultCache(*cacheMaxAge)
+	}
+
+	server := lsp.NewServer(opts, os.Stdout)
+	runErr := server.Run(os.Stdin)
+	if *resultCache != "" {
+		if err := opts.Check.Cache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	if runErr != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", runErr)
+		os.Exit(1)
+	}
+}
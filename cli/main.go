@@ -0,0 +1,1082 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/hugo"
+	"github.com/infodancer/hugo-link-checker/internal/checker"
+	"github.com/infodancer/hugo-link-checker/internal/config"
+	"github.com/infodancer/hugo-link-checker/internal/reporter"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+	"github.com/infodancer/hugo-link-checker/internal/version"
+	"github.com/infodancer/hugo-link-checker/sink"
+)
+
+// Main runs the hugo-link-checker command with the arguments in os.Args and
+// exits with its exit code. Programs that register their own report sinks
+// with sink.Register call it to run the checker with them.
+func Main() {
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		runMerge(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		runReport(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "fix" {
+		runFix(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lsp" {
+		runLSP(os.Args[2:])
+		return
+	}
+
+	startedAt := time.Now()
+
+	var (
+		showVersion   bool
+		outputFile    string
+		format        string
+		noReport      bool
+		rootDir       string
+		checkImages   bool
+		checkExternal bool
+		checkPublic   bool
+		baseURL       string
+		verbose       bool
+		ip4           bool
+		ip6           bool
+		sourceAddr    string
+		method        string
+		methodByHost  string
+		compareLocal  bool
+		secHeaders    bool
+		imageDims     bool
+		verifyContent bool
+		stripParams   string
+		unicodeForm   string
+		relScheme     string
+		schemes       string
+		failOn        string
+		failSection   string
+		configFile    string
+		siteURL       string
+		headingIDs    string
+		profileName   string
+		latestOnly    bool
+		allowlistFile string
+		typosquats    bool
+		lintLinkText  bool
+		language      string
+		reportTitle   string
+		reportLogo    string
+		reportCSS     string
+		splitSections bool
+		checkpointTo  string
+		resume        bool
+		shard         string
+		maxExternal   int
+		maxLinks      int
+		scanCache     string
+		checkOGImage  bool
+		ogImageMin    string
+		enclosures    bool
+		checkIcons    bool
+		warnNoindex   int
+		recheckFrom   string
+		fixedSince    string
+		mutesFile     string
+		trafficFile   string
+		checkParams   bool
+		paramKeys     string
+		checkData     bool
+		dataKeys      string
+		checkMenus    bool
+		resultCache   string
+		cacheMaxAge   time.Duration
+		cacheKeep     time.Duration
+		cacheOnly     bool
+		offline       bool
+		minCoverage   float64
+		fmKeys        string
+		wikiLinks     bool
+		skipDrafts    bool
+		draftLinks    bool
+		lazyAttrs     string
+	)
+
+	flag.BoolVar(&showVersion, "version", false, "Print version and exit")
+	flag.StringVar(&outputFile, "output", "", "Output file for report (default: stdout)")
+	flag.StringVar(&format, "format", "text", "Report format: text, json, html, domains, treemap-csv, treemap-json, logfmt, rdjson, badge")
+	flag.BoolVar(&noReport, "no-report", false, "Don't generate report, just return exit code based on broken links")
+	flag.StringVar(&rootDir, "root", ".", "Root directory to scan")
+	flag.BoolVar(&checkImages, "check-images", false, "Check image sources (Markdown images, img src and srcset, picture sources, figure shortcodes) in page bundles and static/")
+	flag.BoolVar(&checkExternal, "check-external", false, "Check external links (default: only check internal links)")
+	flag.BoolVar(&checkPublic, "check-public", false, "Check for link destinations in Hugo's public directory")
+	flag.StringVar(&baseURL, "base-url", "", "Base URL prefix to use when checking internal links online (e.g., https://example.com)")
+	flag.BoolVar(&verbose, "verbose", false, "Verbose output: show all candidate paths checked for broken internal links")
+	flag.BoolVar(&ip4, "ip4", false, "Only use IPv4 for outgoing requests")
+	flag.BoolVar(&ip6, "ip6", false, "Only use IPv6 for outgoing requests")
+	flag.StringVar(&sourceAddr, "source-addr", "", "Bind outgoing requests to this local IP address or network interface")
+	flag.StringVar(&method, "method", "head-then-get", "HTTP method strategy for external links: head-then-get, get, head")
+	flag.StringVar(&methodByHost, "method-override", "", "Per-domain method strategies, e.g. example.com=get,cdn.example.org=head")
+	flag.BoolVar(&compareLocal, "compare-local", false, "With -base-url, check internal links both locally and online and report discrepancies")
+	flag.BoolVar(&secHeaders, "security-headers", false, "With -base-url, warn about pages served without HSTS or CSP headers")
+	flag.BoolVar(&imageDims, "image-dimensions", false, "Record width and height of resolved PNG, JPEG, and GIF images in the report")
+	flag.BoolVar(&verifyContent, "verify-content", false, "Fetch the first bytes of PDF and other document links and verify they match the file extension")
+	flag.StringVar(&stripParams, "strip-params", "", "Comma-separated query parameter patterns to strip from external URLs before checking (e.g. utm_*,fbclid); use \"tracking\" for a built-in list")
+	flag.StringVar(&unicodeForm, "unicode-form", "nfc", "Unicode normalization applied to link paths and filenames: nfc, nfd, nfkc, nfkd, none")
+	flag.StringVar(&relScheme, "protocol-relative-scheme", "https", "Scheme used to check protocol-relative URLs such as //cdn.example.com/lib.js")
+	flag.StringVar(&schemes, "schemes", strings.Join(checker.DefaultAllowedSchemes, ","), "Comma-separated URL schemes to check; links with other schemes are reported as skipped")
+	flag.StringVar(&failOn, "fail-on", "", "Comma-separated error categories that count toward the exit code (default: all), e.g. not-found-local,http-4xx")
+	flag.StringVar(&failSection, "fail-section", "", "Comma-separated content sections whose broken links count toward the exit code (default: all)")
+	flag.StringVar(&configFile, "config", "", "YAML config declaring multiple Hugo sites and URL rewrite rules")
+	flag.StringVar(&siteURL, "site-url", "", "The site's own URL; absolute links to it are flagged in favor of relative links (default: -base-url)")
+	flag.StringVar(&headingIDs, "heading-ids", "", "Heading ID style for anchor checks: github, github-ascii, blackfriday (default: the site's markup.goldmark.parser.autoHeadingIDType)")
+	flag.StringVar(&profileName, "profile", "", "Theme conventions to apply: docsy (default: none)")
+	flag.BoolVar(&latestOnly, "latest-only", false, "With versioned docs in the config, only check pages of the latest version")
+	flag.StringVar(&allowlistFile, "domain-allowlist", "", "File of allowed external domains, one per line; links to other domains are reported broken")
+	flag.BoolVar(&typosquats, "typosquat", false, "Warn about external domains that look like typos or homoglyphs of popular or other linked domains")
+	flag.BoolVar(&lintLinkText, "lint-link-text", false, "Warn about generic link texts such as \"click here\" and bare URLs used as link text")
+	flag.StringVar(&language, "lang", reporter.DefaultLanguage, "Language of text and HTML report strings: "+strings.Join(reporter.Languages(), ", "))
+	flag.StringVar(&reportTitle, "report-title", "", "Title of the HTML report (default: Hugo Link Checker Report)")
+	flag.StringVar(&reportLogo, "report-logo", "", "Logo shown in the HTML report: an image URL or a local image file to embed")
+	flag.StringVar(&reportCSS, "report-css", "", "CSS file added to the HTML report after the built-in styles")
+	flag.BoolVar(&splitSections, "split-sections", false, "With -format json, write -output as a manifest plus one chunk file per content section")
+	flag.StringVar(&checkpointTo, "checkpoint", "", "Save progress to this file periodically so an interrupted run can be resumed")
+	flag.BoolVar(&resume, "resume", false, "With -checkpoint, continue the run recorded in the checkpoint file if it exists")
+	flag.StringVar(&shard, "shard", "", "Check only shard i of n (e.g. 2/4) of the unique links, for splitting a run across parallel jobs; combine the JSON reports with the merge subcommand")
+	flag.IntVar(&maxExternal, "max-external-links", 0, "Warn about pages with more than this many external links (default: no limit)")
+	flag.IntVar(&maxLinks, "max-links", 0, "Warn about pages with more than this many links (default: no limit)")
+	flag.StringVar(&scanCache, "scan-cache", "", "Cache parsed links in this file and skip re-parsing files whose size and modification time are unchanged")
+	flag.BoolVar(&checkOGImage, "check-og-image", false, "Warn about pages whose og:image doesn't resolve or is smaller than -og-image-min-size")
+	flag.StringVar(&ogImageMin, "og-image-min-size", fmt.Sprintf("%dx%d", checker.DefaultOGImageWidth, checker.DefaultOGImageHeight), "Minimum og:image size for -check-og-image, as WIDTHxHEIGHT")
+	flag.BoolVar(&enclosures, "check-enclosures", false, "With -check-external, check podcast enclosures in the RSS feeds in public/: audio Content-Type, range request support, and size")
+	flag.BoolVar(&checkIcons, "check-icons", false, "Check favicon, apple-touch-icon, and web manifest paths in layouts, site params, and web manifests")
+	flag.IntVar(&warnNoindex, "warn-noindex", 0, "Warn about pages marked robots noindex that at least this many pages link to (default: off)")
+	flag.StringVar(&recheckFrom, "recheck-broken", "", "Check only the links that were broken in this JSON report, plus links added since, to verify fixes quickly")
+	flag.StringVar(&fixedSince, "fixed-since", "", "List the links that were broken in this JSON report of an earlier run and work now (default: the -recheck-broken report)")
+	flag.StringVar(&trafficFile, "traffic", "", "CSV or JSON of page URLs and pageviews exported from analytics, ranking the report's findings and weighting its health score by page traffic")
+	flag.StringVar(&mutesFile, "mutes", "", "File of URL patterns and until dates (YYYY-MM-DD); matching links are checked but don't count as failures until then")
+	flag.BoolVar(&checkParams, "check-params", false, "Check the URLs in the site params under -param-keys, such as social profiles and CDN hosts, attributed to the site config")
+	flag.StringVar(&paramKeys, "param-keys", strings.Join(hugo.DefaultURLParams, ","), "Comma-separated site param names or paths whose URLs -check-params checks, including nested params")
+	flag.BoolVar(&checkData, "check-data", false, "Check the URLs and content paths in the YAML, TOML, and JSON files under data/, in the fields selected by -data-keys")
+	flag.StringVar(&dataKeys, "data-keys", strings.Join(scanner.DefaultDataKeys, ","), "Comma-separated data file field names, paths, or wildcard patterns (e.g. *url) whose values -check-data checks")
+	flag.BoolVar(&checkMenus, "check-menus", false, "Check the url and pageRef of the menu entries in the site config, attributed to the config files defining them")
+	flag.StringVar(&resultCache, "cache", "", "Keep external check results in this file (a SQLite database if it ends in .db, .sqlite, or .sqlite3), or a shared http(s):// or s3:// location, across runs and reuse results younger than -cache-max-age")
+	flag.DurationVar(&cacheMaxAge, "cache-max-age", checker.DefaultCacheMaxAge, "How long results in the -cache file are reused (0: any age)")
+	flag.DurationVar(&cacheKeep, "cache-retention", 0, "Drop results older than this from the -cache file when saving it (0: keep results of any age)")
+	flag.BoolVar(&cacheOnly, "cache-only", false, "With -cache, answer external links from the cache alone, whatever their age, without network requests; links not in it are reported as unknown")
+	flag.BoolVar(&offline, "offline", false, "Make no network requests: links that need the network (external, -base-url, mailto) are reported as not checked (offline)")
+	flag.Float64Var(&minCoverage, "min-coverage", 0, "Fail when less than this percentage of links was verified rather than skipped or ignored, e.g. 90 (default: off)")
+	flag.StringVar(&fmKeys, "front-matter-keys", strings.Join(scanner.DefaultFrontMatterURLKeys, ","), "Comma-separated front matter field names or paths whose URLs and file paths are checked, including nested fields (empty: none)")
+	flag.BoolVar(&wikiLinks, "wiki-links", false, "Check wiki links ([[Page Name]], [[page|text]]) in Markdown files against the content tree")
+	flag.BoolVar(&skipDrafts, "skip-drafts", true, "Leave out content pages marked draft: true, which Hugo doesn't publish (use -skip-drafts=false to check them)")
+	flag.BoolVar(&draftLinks, "check-draft-links", false, "Report internal links from published pages to draft pages as broken, since they 404 on the live site")
+	flag.StringVar(&lazyAttrs, "lazy-load-attrs", strings.Join(scanner.DefaultLazyLoadAttrs, ","), "Comma-separated HTML attributes holding lazy-loaded image sources, checked with -check-images (empty: none)")
+	flag.Parse()
+
+	if showVersion {
+		fmt.Println("hugo-link-checker", version.Version)
+		os.Exit(0)
+	}
+
+	// Validate format
+	reportFormat, err := parseFormat(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if splitSections && (reportFormat != reporter.FormatJSON || outputFile == "") {
+		fmt.Fprintf(os.Stderr, "Flag -split-sections requires -format json and -output\n")
+		os.Exit(1)
+	}
+
+	if minCoverage < 0 || minCoverage > 100 {
+		fmt.Fprintf(os.Stderr, "Flag -min-coverage must be a percentage from 0 to 100\n")
+		os.Exit(1)
+	}
+
+	if cacheOnly {
+		if resultCache == "" {
+			fmt.Fprintf(os.Stderr, "Flag -cache-only requires -cache\n")
+			os.Exit(1)
+		}
+		// External links are answered, from the cache
+		checkExternal = true
+	}
+
+	if enclosures && !checkExternal {
+		fmt.Fprintf(os.Stderr, "Flag -check-enclosures requires -check-external\n")
+		os.Exit(1)
+	}
+
+	if err := reporter.ValidateLanguage(language); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	reportTheme := &reporter.Theme{
+		Title:   reportTitle,
+		Logo:    reportLogo,
+		CSSFile: reportCSS,
+	}
+	if err := reportTheme.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if ip4 && ip6 {
+		fmt.Fprintf(os.Stderr, "Flags -ip4 and -ip6 are mutually exclusive\n")
+		os.Exit(1)
+	}
+	ipVersion := 0
+	if ip4 {
+		ipVersion = 4
+	} else if ip6 {
+		ipVersion = 6
+	}
+
+	methodStrategy, err := checker.ParseMethodStrategy(method)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	domainMethods, err := checker.ParseDomainMethods(methodByHost)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := checker.ParseUnicodeForm(unicodeForm); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := scanner.ParseHeadingIDType(headingIDs); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	profile, err := config.LookupProfile(profileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	failCategories, err := checker.ParseCategories(failOn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	// Load ignore patterns
+	ignorePatterns, err := loadIgnorePatterns()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading ignore patterns: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg := &config.Config{}
+	if configFile != "" {
+		cfg, err = config.Load(configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+	var sinks []sink.Sink
+	for _, declared := range cfg.Sinks {
+		created, err := sink.New(declared.Type, declared.Decode)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error in config %s: %v\n", configFile, err)
+			os.Exit(1)
+		}
+		sinks = append(sinks, created)
+	}
+
+	parseOptions := scanner.ParseOptions{CheckImages: checkImages, FrontMatterKeys: splitList(fmKeys), Shortcodes: cfg.Shortcodes, WikiLinks: wikiLinks, LazyLoadAttrs: splitList(lazyAttrs)}
+	var parseCache *scanner.ParseCache
+	if scanCache != "" {
+		parseCache = scanner.LoadParseCache(scanCache, parseOptions)
+	}
+
+	var recheck *recheckSet
+	if recheckFrom != "" {
+		recheck, err = loadRecheckSet(recheckFrom)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+	fixed := recheck
+	if fixedSince != "" {
+		fixed, err = loadRecheckSet(fixedSince)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	allowedDomains, err := loadDomainAllowlist(allowlistFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading domain allowlist: %v\n", err)
+		os.Exit(1)
+	}
+
+	shardIndex, shardTotal := 1, 1
+	if shard != "" {
+		shardIndex, shardTotal, err = scanner.ParseShard(shard)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if resume && checkpointTo == "" {
+		fmt.Fprintf(os.Stderr, "Flag -resume requires -checkpoint\n")
+		os.Exit(1)
+	}
+	runID := checker.NewRunID()
+	var checkpoint *checker.Checkpoint
+	if checkpointTo != "" {
+		checkpoint = checker.NewCheckpoint(checkpointTo, runID)
+		if resume {
+			if _, err := os.Stat(checkpointTo); err == nil {
+				checkpoint, err = checker.LoadCheckpoint(checkpointTo)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+					os.Exit(1)
+				}
+				runID = checkpoint.RunID
+				fmt.Fprintf(os.Stderr, "Resuming run %s: %d external URLs already checked\n", runID, len(checkpoint.Results))
+			}
+		}
+		saveCheckpointOnSignal(checkpoint)
+	}
+
+	var externalCache *checker.ResultCache
+	if resultCache != "" {
+		externalCache = checker.LoadResultCache(resultCache, cacheMaxAge)
+		externalCache.Retention = cacheKeep
+	}
+
+	checkOptions := checker.Options{
+		RootDir:         rootDir,
+		CheckExternal:   checkExternal,
+		CheckPublic:     checkPublic,
+		BaseURL:         baseURL,
+		SiteURL:         siteURL,
+		Verbose:         verbose,
+		HeadingIDType:   headingIDs,
+		CompareLocal:    compareLocal,
+		SecurityHeaders: secHeaders,
+		ImageDimensions: imageDims,
+		VerifyContent:   verifyContent,
+		StripParams:     parseStripParams(stripParams),
+		UnicodeForm:     unicodeForm,
+		AllowedSchemes:  splitList(schemes),
+
+		ProtocolRelativeScheme: relScheme,
+		IPVersion:              ipVersion,
+		SourceAddr:             sourceAddr,
+		MethodStrategy:         methodStrategy,
+		DomainMethods:          domainMethods,
+		AllowedDomains:         allowedDomains,
+		Typosquats:             typosquats,
+		Checkpoint:             checkpoint,
+		Cache:                  externalCache,
+		CacheOnly:              cacheOnly,
+		Offline:                offline,
+		DraftLinks:             draftLinks,
+	}
+
+	for _, rewrite := range cfg.Rewrites {
+		rule, err := checker.NewRewriteRule(rewrite.Match, rewrite.Replace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		checkOptions.Rewrites = append(checkOptions.Rewrites, rule)
+	}
+	linkBudget := checker.LinkBudget{
+		MaxExternal: cfg.LinkBudget.MaxExternal,
+		MaxTotal:    cfg.LinkBudget.MaxTotal,
+	}
+	if maxExternal > 0 {
+		linkBudget.MaxExternal = maxExternal
+	}
+	if maxLinks > 0 {
+		linkBudget.MaxTotal = maxLinks
+	}
+	if checkOGImage {
+		checkOptions.OGImage = true
+		checkOptions.OGImageMinWidth, checkOptions.OGImageMinHeight, err = checker.ParseImageSize(ogImageMin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+	checkOptions.NoindexThreshold = warnNoindex
+	checkOptions.LinkTextLint = lintLinkText || cfg.LinkText.Enabled
+	checkOptions.GenericLinkTexts = cfg.LinkText.Generic
+	for _, rule := range cfg.Rules {
+		policyRule, err := checker.NewPolicyRule(rule.ID, rule.Severity, rule.Scheme, rule.Host, rule.Match, rule.Message)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		checkOptions.Rules = append(checkOptions.Rules, policyRule)
+	}
+	if mutesFile != "" {
+		checkOptions.Mutes, err = checker.LoadMutes(mutesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading mutes: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if trafficFile != "" {
+		checkOptions.Traffic, err = checker.LoadTraffic(trafficFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading traffic: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if len(cfg.Sites) == 0 {
+		if headingIDs == "" {
+			checkOptions.HeadingIDType = siteHeadingIDType(scanner.SiteRoot(rootDir))
+		}
+		checkOptions.ContentDirs = profileContentDirs(profile, scanner.SiteRoot(rootDir))
+		checkOptions.URLs = siteURLResolver(scanner.SiteRoot(rootDir))
+		checkOptions.Versions, err = docVersions(cfg.Versions, scanner.SiteRoot(rootDir))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	sources := siteSources{
+		icons:     checkIcons,
+		params:    checkParams,
+		paramKeys: splitList(paramKeys),
+		data:      checkData,
+		dataKeys:  splitList(dataKeys),
+		menus:     checkMenus,
+	}
+
+	var fileList []*scanner.File
+	if len(cfg.Sites) > 0 {
+		// Multi-site mode: check every declared site and combine the results.
+		// Links between declared sites are resolved against the target
+		// site's local tree, so breaks surface before either site deploys.
+		siteStates := make([]checker.LocalSite, len(cfg.Sites))
+		for i, site := range cfg.Sites {
+			siteVersions := site.Versions
+			if siteVersions == nil {
+				siteVersions = cfg.Versions
+			}
+			versions, err := docVersions(siteVersions, site.Root)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Site %s: %v\n", site.Name, err)
+				os.Exit(1)
+			}
+			siteStates[i] = checker.LocalSite{
+				Name:          site.Name,
+				BaseURL:       site.BaseURL,
+				RootDir:       site.Root,
+				ContentDirs:   profileContentDirs(profile, site.Root),
+				URLs:          siteURLResolver(site.Root),
+				Versions:      versions,
+				HeadingIDType: checkOptions.HeadingIDType,
+			}
+			if headingIDs == "" {
+				siteStates[i].HeadingIDType = siteHeadingIDType(site.Root)
+			}
+			if site.BaseURL != "" {
+				checkOptions.LocalSites = append(checkOptions.LocalSites, siteStates[i])
+			}
+		}
+
+		for i, site := range cfg.Sites {
+			state := siteStates[i]
+			siteFiles, err := collectFiles(site.ContentPaths(), site.Root, profile.ExcludeDirs, checkPublic, enclosures, verbose, parseOptions,
+				slices.Concat(ignorePatterns, site.IgnorePatterns()), parseCache)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Site %s: %v\n", site.Name, err)
+				os.Exit(1)
+			}
+			if skipDrafts {
+				siteFiles = scanner.WithoutDrafts(siteFiles, site.Root, state.ContentDirs)
+			}
+			siteFiles, err = sources.add(siteFiles, site.Root, slices.Concat(ignorePatterns, site.IgnorePatterns()))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Site %s: %v\n", site.Name, err)
+				os.Exit(1)
+			}
+			for _, file := range siteFiles {
+				file.Site = site.Name
+			}
+			checker.LintLinkBudget(siteFiles, linkBudget)
+			if recheck != nil {
+				recheck.filter(siteFiles)
+			}
+			scanner.ShardFiles(siteFiles, shardIndex, shardTotal)
+
+			siteVersions := site.Versions
+			if siteVersions == nil {
+				siteVersions = cfg.Versions
+			}
+			if state.Versions != nil && (latestOnly || siteVersions.OnlyLatest) {
+				siteFiles = latestVersionFiles(siteFiles, state.Versions)
+			}
+
+			siteOptions := checkOptions
+			siteOptions.Versions = state.Versions
+			siteOptions.RootDir = site.Root
+			siteOptions.HeadingIDType = state.HeadingIDType
+			siteOptions.ContentDirs = state.ContentDirs
+			siteOptions.URLs = state.URLs
+			if site.BaseURL != "" {
+				siteOptions.SiteURL = site.BaseURL
+			}
+			if site.CheckOnline {
+				siteOptions.BaseURL = site.BaseURL
+			}
+			if err := checker.CheckLinksWithOptions(siteFiles, siteOptions); err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking links in site %s: %v\n", site.Name, err)
+				os.Exit(1)
+			}
+			fileList = append(fileList, siteFiles...)
+		}
+	} else {
+		// Get paths to scan from command line arguments, or use root directory if none specified
+		pathsToScan := flag.Args()
+		if len(pathsToScan) == 0 {
+			pathsToScan = []string{rootDir}
+		}
+
+		fileList, err = collectFiles(pathsToScan, rootDir, profile.ExcludeDirs, checkPublic, enclosures, verbose, parseOptions, ignorePatterns, parseCache)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if skipDrafts {
+			fileList = scanner.WithoutDrafts(fileList, scanner.SiteRoot(rootDir), checkOptions.ContentDirs)
+		}
+		fileList, err = sources.add(fileList, scanner.SiteRoot(rootDir), ignorePatterns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if checkOptions.Versions != nil && (latestOnly || cfg.Versions.OnlyLatest) {
+			fileList = latestVersionFiles(fileList, checkOptions.Versions)
+		}
+		checker.LintLinkBudget(fileList, linkBudget)
+		scanner.ShardFiles(fileList, shardIndex, shardTotal)
+		if recheck != nil {
+			recheck.filter(fileList)
+		}
+
+		// Check all links
+		err = checker.CheckLinksWithOptions(fileList, checkOptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking links: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if parseCache != nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Scan cache: %d files unchanged, %d parsed\n", parseCache.Hits, parseCache.Misses)
+		}
+		if err := parseCache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if externalCache != nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Result cache: %d external links answered from the cache, %d not cached\n", externalCache.Hits, externalCache.Misses)
+		}
+		if !cacheOnly {
+			if err := externalCache.Save(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+
+	// The run is complete; a later -resume starts afresh
+	if checkpoint != nil {
+		if err := checkpoint.Remove(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if recheck != nil {
+		recheck.summarize(fileList)
+	}
+	if fixed != nil {
+		fixed.markFixed(fileList)
+	}
+
+	// Count broken links
+	gatedFiles := fileList
+	if sections := splitList(failSection); len(sections) > 0 {
+		gatedFiles = scanner.FilterSections(fileList, sections)
+	}
+	brokenCount := checker.CountBrokenLinksInCategories(gatedFiles, failCategories)
+
+	metadataDir := scanner.SiteRoot(rootDir)
+	if len(cfg.Sites) > 0 {
+		metadataDir = filepath.Dir(configFile)
+	}
+	metadata := reporter.CollectRunMetadata(metadataDir, startedAt, configSnapshot())
+	metadata.RunID = runID
+
+	// Sinks receive the results whether or not a report is written; one
+	// that fails doesn't fail the run
+	if err := sink.Send(fileList, &metadata, sinks); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if noReport {
+		// Just exit with the number of broken links as exit code
+		finish(fileList, brokenCount, minCoverage, startedAt, runID)
+	}
+
+	// Generate report
+	reportOptions := reporter.ReportOptions{
+		Format:        reportFormat,
+		OutputFile:    outputFile,
+		Metadata:      &metadata,
+		Language:      language,
+		Theme:         reportTheme,
+		SplitSections: splitSections,
+	}
+
+	err = reporter.GenerateReport(fileList, reportOptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating report: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Exit with error code if broken links found
+	finish(fileList, brokenCount, minCoverage, startedAt, runID)
+}
+
+// parseFormat validates a report format name
+func parseFormat(format string) (reporter.ReportFormat, error) {
+	switch format {
+	case "text":
+		return reporter.FormatText, nil
+	case "json":
+		return reporter.FormatJSON, nil
+	case "html":
+		return reporter.FormatHTML, nil
+	case "domains":
+		return reporter.FormatDomains, nil
+	case "treemap-csv":
+		return reporter.FormatTreemapCSV, nil
+	case "treemap-json":
+		return reporter.FormatTreemapJSON, nil
+	case "logfmt":
+		return reporter.FormatLogfmt, nil
+	case "rdjson":
+		return reporter.FormatRDJSON, nil
+	case "badge":
+		return reporter.FormatBadge, nil
+	default:
+		return "", fmt.Errorf("Invalid format: %s. Valid formats: text, json, html, domains, treemap-csv, treemap-json, logfmt, rdjson, badge", format)
+	}
+}
+
+// saveCheckpointOnSignal saves the checkpoint when the run is interrupted or
+// terminated, e.g. by a CI job timeout, before exiting
+func saveCheckpointOnSignal(checkpoint *checker.Checkpoint) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		if err := checkpoint.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Received %v; run %s saved, continue it with -resume\n", sig, checkpoint.RunID)
+		}
+		os.Exit(130)
+	}()
+}
+
+// finish prints the run summary line to stderr and exits with the number of
+// broken links, capped at 255 for valid exit codes, and at least 1 when the
+// verification coverage is below minCoverage. The line has the same shape
+// whatever the report format, so CI logs can be parsed uniformly.
+func finish(files []*scanner.File, brokenCount int, minCoverage float64, startedAt time.Time, runID string) {
+	links, warnings := 0, 0
+	for _, file := range files {
+		links += len(file.Links)
+		for _, link := range file.Links {
+			warnings += len(link.Warnings)
+		}
+	}
+	coverage := checker.VerificationCoverage(files)
+	fmt.Fprintf(os.Stderr, "hugo-link-checker: run=%s files=%d links=%d broken=%d warnings=%d coverage=%.1f%% duration=%s\n",
+		runID, len(files), links, brokenCount, warnings, coverage, time.Since(startedAt).Round(time.Millisecond))
+
+	exitCode := min(brokenCount, 255)
+	if coverage < minCoverage {
+		fmt.Fprintf(os.Stderr, "Verification coverage %.1f%% is below -min-coverage %g%%\n", coverage, minCoverage)
+		exitCode = max(exitCode, 1)
+	}
+	os.Exit(exitCode)
+}
+
+// collectFiles enumerates the files under the given paths, skipping
+// excludeDirs, adds the generated HTML in public/ when checkPublic is set and
+// the RSS feeds in public/ when checkFeeds is set, and parses their links
+// with parseOptions, through cache when one is given
+func collectFiles(paths []string, rootDir string, excludeDirs []string, checkPublic, checkFeeds, verbose bool, parseOptions scanner.ParseOptions, ignorePatterns []*regexp.Regexp, cache *scanner.ParseCache) ([]*scanner.File, error) {
+	// Scan for files in specified paths
+	files := make(map[string]*scanner.File)
+	for _, path := range paths {
+		pathFiles, err := scanner.EnumerateFilesExcluding(path, []string{".md", ".html", ".htm", ".css"}, excludeDirs)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning files in %s: %v", path, err)
+		}
+		// Merge files from this path into the main files map
+		for k, v := range pathFiles {
+			files[k] = v
+		}
+	}
+
+	// With -check-public, the generated site is authoritative: scan the HTML
+	// in public/ and map each page back to the content file that produced it
+	if checkPublic {
+		siteRoot := scanner.SiteRoot(rootDir)
+		publicDir := filepath.Join(siteRoot, "public")
+		if _, err := os.Stat(publicDir); err == nil {
+			publicFiles, err := scanner.EnumerateFiles(publicDir, []string{".html", ".htm"})
+			if err != nil {
+				return nil, fmt.Errorf("error scanning files in %s: %v", publicDir, err)
+			}
+			if err := scanner.MapPublicSources(scanner.GetFileList(publicFiles), siteRoot, siteURLResolver(siteRoot)); err != nil {
+				return nil, fmt.Errorf("error mapping public files to sources: %v", err)
+			}
+			for k, v := range publicFiles {
+				files[k] = v
+			}
+		}
+	}
+
+	// Podcast feeds are generated too; their enclosures are the only links
+	// taken from them
+	feeds := make(map[string]bool)
+	if checkFeeds {
+		publicDir := filepath.Join(scanner.SiteRoot(rootDir), "public")
+		if _, err := os.Stat(publicDir); err == nil {
+			feedFiles, err := scanner.EnumerateFiles(publicDir, []string{".xml"})
+			if err != nil {
+				return nil, fmt.Errorf("error scanning files in %s: %v", publicDir, err)
+			}
+			for k, v := range feedFiles {
+				files[k] = v
+				feeds[k] = true
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: no public directory in %s; build the site to check podcast enclosures\n", scanner.SiteRoot(rootDir))
+		}
+	}
+
+	fileList := scanner.GetFileList(files)
+
+	// Parse links from each file, reusing the links of unchanged files
+	for _, file := range fileList {
+		var err error
+		if feeds[file.CanonicalPath] {
+			err = scanner.ParseEnclosuresFromFeed(file)
+		} else if cache != nil {
+			err = cache.Parse(file)
+		} else {
+			err = scanner.ParseLinksFromFileWithOptions(file, parseOptions)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing links from %s: %v\n", file.Path, err)
+			continue
+		}
+
+		// Apply ignore patterns
+		applyIgnorePatterns(file, ignorePatterns)
+
+		// Debug: Print ignored links if verbose
+		if verbose {
+			for _, link := range file.Links {
+				if link.Ignored {
+					fmt.Fprintf(os.Stderr, "DEBUG: Ignored link: %s in file %s\n", link.URL, file.Path)
+				}
+			}
+		}
+	}
+
+	return fileList, nil
+}
+
+// siteSources selects the site files checked besides content: icons, site
+// params, data files, and menus
+type siteSources struct {
+	icons     bool
+	params    bool
+	paramKeys []string
+	data      bool
+	dataKeys  []string
+	menus     bool
+}
+
+// add appends the site's selected sources to files
+func (s siteSources) add(files []*scanner.File, siteRoot string, ignorePatterns []*regexp.Regexp) ([]*scanner.File, error) {
+	var err error
+	if s.icons {
+		if files, err = addIconFiles(files, siteRoot, ignorePatterns); err != nil {
+			return nil, err
+		}
+	}
+	if s.params {
+		if files, err = addParamFile(files, siteRoot, s.paramKeys, ignorePatterns); err != nil {
+			return nil, err
+		}
+	}
+	if s.data {
+		if files, err = addDataFiles(files, siteRoot, s.dataKeys, ignorePatterns); err != nil {
+			return nil, err
+		}
+	}
+	if s.menus {
+		if files, err = addMenuFiles(files, siteRoot, ignorePatterns); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// siteHeadingIDType returns the heading ID style configured in the Hugo site
+// config, falling back to Hugo's default
+func siteHeadingIDType(siteRoot string) string {
+	siteConfig, err := hugo.LoadConfig(siteRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return scanner.HeadingIDGitHub
+	}
+	idType, err := scanner.ParseHeadingIDType(siteConfig.String("markup", "goldmark", "parser", "autoHeadingIDType"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return scanner.HeadingIDGitHub
+	}
+	return idType
+}
+
+// siteURLResolver returns the resolver of the paths content pages are
+// served at, following the Hugo site config; a config that can't be read
+// leaves Hugo's defaults
+func siteURLResolver(siteRoot string) *hugo.URLResolver {
+	siteConfig, err := hugo.LoadConfig(siteRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	return hugo.NewURLResolver(siteConfig)
+}
+
+// profileContentDirs returns the extra content directories a profile resolves
+// internal links against, including per-language directories from the Hugo
+// config when the profile asks for them
+func profileContentDirs(profile config.Profile, siteRoot string) []string {
+	dirs := slices.Clone(profile.ContentDirs)
+	if !profile.LanguageContentDirs {
+		return dirs
+	}
+
+	siteConfig, err := hugo.LoadConfig(siteRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return dirs
+	}
+	for _, dir := range siteConfig.ContentDirs() {
+		if dir != "content" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// docVersions resolves the versioned docs config of a site, returning nil
+// when the site has no versioned docs
+func docVersions(versions *config.Versions, siteRoot string) (*checker.DocVersions, error) {
+	if versions == nil {
+		return nil, nil
+	}
+	resolved, err := versions.Resolve(siteRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &checker.DocVersions{
+		Section:  resolved.Section,
+		Versions: resolved.List,
+		Latest:   resolved.Latest,
+		Alias:    resolved.Alias,
+	}, nil
+}
+
+// latestVersionFiles drops the pages of all but the latest docs version
+func latestVersionFiles(files []*scanner.File, versions *checker.DocVersions) []*scanner.File {
+	var filtered []*scanner.File
+	for _, file := range files {
+		if version := versions.VersionOf(file); version == "" || version == versions.Latest {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// loadIgnorePatterns reads the .hugo-link-checker-ignore file and returns compiled regex patterns
+func loadIgnorePatterns() ([]*regexp.Regexp, error) {
+	file, err := os.Open(".hugo-link-checker-ignore")
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Ignore file doesn't exist, return empty patterns
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close ignore file: %v\n", closeErr)
+		}
+	}()
+
+	var patterns []*regexp.Regexp
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip empty lines and comments (lines starting with #)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Compile the regex pattern
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Invalid regex pattern '%s': %v\n", line, err)
+			continue
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return patterns, nil
+}
+
+// loadDomainAllowlist reads a domain allowlist: one domain or glob per line,
+// with # starting a comment. The output of -format domains can be used as is.
+func loadDomainAllowlist(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close allowlist file: %v\n", closeErr)
+		}
+	}()
+
+	var domains []string
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line, _, _ := strings.Cut(lines.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			domains = append(domains, strings.ToLower(line))
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("%s lists no domains", path)
+	}
+	return domains, nil
+}
+
+// applyIgnorePatterns marks links as ignored if they match any ignore pattern
+func applyIgnorePatterns(file *scanner.File, patterns []*regexp.Regexp) {
+	for i := range file.Links {
+		link := &file.Links[i]
+
+		// Check if this link matches any ignore pattern
+		for _, pattern := range patterns {
+			if pattern.MatchString(link.URL) {
+				link.Ignored = true
+				fmt.Fprintf(os.Stderr, "DEBUG: Ignoring link %s (matched pattern %s)\n", link.URL, pattern.String())
+				break
+			}
+		}
+	}
+}
+
+// parseStripParams turns the -strip-params flag into a list of parameter
+// patterns, expanding "tracking" to the built-in tracking parameter list
+func parseStripParams(spec string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(spec, ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch pattern {
+		case "":
+		case "tracking":
+			patterns = append(patterns, scanner.DefaultTrackingParams...)
+		default:
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// splitList splits a comma-separated flag value into trimmed, non-empty items
+func splitList(spec string) []string {
+	items := []string{}
+	for _, item := range strings.Split(spec, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// configSnapshot records the value of every flag for the report metadata
+func configSnapshot() map[string]string {
+	config := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		config[f.Name] = f.Value.String()
+	})
+	if args := flag.Args(); len(args) > 0 {
+		config["paths"] = strings.Join(args, " ")
+	}
+	return config
+}
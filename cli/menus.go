@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/hugo"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// addMenuFiles adds the links of the menus defined in the configuration of
+// the site at siteRoot to files, attributed to the config files defining
+// them: the site's navigation, which every page renders but no content file
+// holds. An entry's pageRef is resolved against the content tree; Hugo only
+// falls back to its url when the page doesn't exist, so url is checked for
+// entries without one.
+func addMenuFiles(files []*scanner.File, siteRoot string, ignorePatterns []*regexp.Regexp) ([]*scanner.File, error) {
+	siteConfig, err := hugo.LoadConfig(siteRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return files, nil
+	}
+
+	byPath := make(map[string]*scanner.File, len(files))
+	for _, file := range files {
+		byPath[file.CanonicalPath] = file
+	}
+	// The files the menus are in, and the number of links each had before
+	var menuFiles []*scanner.File
+	existingLinks := make(map[*scanner.File]int)
+	contents := make(map[string]string)
+	for _, entry := range siteConfig.MenuEntries() {
+		if entry.Source == "" {
+			continue
+		}
+		canonicalPath, err := filepath.Abs(entry.Source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get canonical path for %s: %v", entry.Source, err)
+		}
+		file, ok := byPath[canonicalPath]
+		if !ok {
+			file = &scanner.File{Path: entry.Source, CanonicalPath: canonicalPath}
+			byPath[canonicalPath] = file
+			existingLinks[file] = -1
+			menuFiles = append(menuFiles, file)
+		} else if _, seen := existingLinks[file]; !seen {
+			existingLinks[file] = len(file.Links)
+			menuFiles = append(menuFiles, file)
+		}
+
+		link := scanner.NewLink(scanner.SitePath(entry.URL))
+		value := entry.URL
+		if entry.PageRef != "" {
+			link = scanner.NewLink(entry.PageRef)
+			link.Kind = scanner.KindPageRef
+			value = entry.PageRef
+		}
+		link.Text = entry.Name
+		if _, ok := contents[entry.Source]; !ok {
+			data, _ := os.ReadFile(entry.Source)
+			contents[entry.Source] = string(data)
+		}
+		if offset := strings.Index(contents[entry.Source], value); offset != -1 {
+			link.Line = strings.Count(contents[entry.Source][:offset], "\n") + 1
+		}
+		file.Links = append(file.Links, link)
+	}
+
+	for _, file := range menuFiles {
+		existing := existingLinks[file]
+		menuLinks := &scanner.File{Links: file.Links[max(existing, 0):]}
+		applyIgnorePatterns(menuLinks, ignorePatterns)
+		if existing == -1 {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
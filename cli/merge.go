@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/checker"
+	"github.com/infodancer/hugo-link-checker/internal/reporter"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// runMerge implements the merge subcommand, which combines the JSON reports
+// of several runs into one report: the shards of a job matrix, or runs over
+// different sections or on different machines. Results reported by more
+// than one run are deduplicated, keeping the most recent.
+func runMerge(args []string) {
+	startedAt := time.Now()
+
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hugo-link-checker merge [flags] report.json...\n")
+		flags.PrintDefaults()
+	}
+	var outputFile string
+	flags.StringVar(&outputFile, "output", "", "Output file for the merged report (default: stdout)")
+	flags.StringVar(&outputFile, "o", "", "Shorthand for -output")
+	format := flags.String("format", "json", "Report format: text, json, html, domains, treemap-csv, treemap-json, logfmt, rdjson, badge")
+	language := flags.String("lang", reporter.DefaultLanguage, "Language of text and HTML report strings")
+	if err := flags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	reportFormat, err := parseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if err := reporter.ValidateLanguage(*language); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	var sets [][]*scanner.File
+	var metadata *reporter.RunMetadata
+	for _, path := range flags.Args() {
+		files, reportMetadata, err := reporter.ReadReportFiles(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if metadata == nil {
+			metadata = reportMetadata
+		}
+		sets = append(sets, files)
+	}
+	files := reporter.MergeFiles(sets...)
+
+	runID := checker.NewRunID()
+	if metadata != nil && metadata.RunID != "" {
+		runID = metadata.RunID
+	}
+
+	err = reporter.GenerateReport(files, reporter.ReportOptions{
+		Format:     reportFormat,
+		OutputFile: outputFile,
+		Metadata:   metadata,
+		Language:   *language,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating report: %v\n", err)
+		os.Exit(1)
+	}
+
+	finish(files, checker.CountBrokenLinks(files), 0, startedAt, runID)
+}
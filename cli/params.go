@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/infodancer/hugo-link-checker/hugo"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// addParamFile adds the URLs in the site params under keys of the site at
+// siteRoot to files, attributed to its config file: social profiles,
+// analytics endpoints, and CDN hosts that no content page links to but the
+// theme does on every page.
+func addParamFile(files []*scanner.File, siteRoot string, keys []string, ignorePatterns []*regexp.Regexp) ([]*scanner.File, error) {
+	siteConfig, err := hugo.LoadConfig(siteRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return files, nil
+	}
+	params := siteConfig.URLParams(keys)
+	if len(params) == 0 || siteConfig.Path() == "" {
+		return files, nil
+	}
+
+	canonicalPath, err := filepath.Abs(siteConfig.Path())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get canonical path for %s: %v", siteConfig.Path(), err)
+	}
+	var file *scanner.File
+	for _, existing := range files {
+		if existing.CanonicalPath == canonicalPath {
+			file = existing
+			break
+		}
+	}
+	existing := file != nil
+	if !existing {
+		file = &scanner.File{Path: siteConfig.Path(), CanonicalPath: canonicalPath}
+	}
+
+	paths := make([]string, 0, len(params))
+	for path := range params {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		file.Links = append(file.Links, scanner.NewLink(params[path]))
+	}
+	applyIgnorePatterns(file, ignorePatterns)
+	if !existing && len(file.Links) > 0 {
+		files = append(files, file)
+	}
+	return files, nil
+}
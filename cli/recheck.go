@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/infodancer/hugo-link-checker/internal/reporter"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// recheckSet records the links of a previous JSON report, per file, for a
+// -recheck-broken or -fixed-since run
+type recheckSet struct {
+	// broken and reported map a file to the URLs that were broken in it and
+	// to all URLs the report had for it
+	broken   map[string]map[string]bool
+	reported map[string]map[string]bool
+}
+
+// loadRecheckSet reads the links of the JSON report at path
+func loadRecheckSet(path string) (*recheckSet, error) {
+	files, _, err := reporter.ReadReportFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &recheckSet{
+		broken:   make(map[string]map[string]bool),
+		reported: make(map[string]map[string]bool),
+	}
+	for _, file := range files {
+		key := recheckKey(file)
+		set.reported[key] = make(map[string]bool)
+		for _, link := range file.Links {
+			set.reported[key][link.URL] = true
+			if !link.Ignored && (link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "")) {
+				if set.broken[key] == nil {
+					set.broken[key] = make(map[string]bool)
+				}
+				set.broken[key][link.URL] = true
+			}
+		}
+	}
+	return set, nil
+}
+
+// recheckKey identifies a file the way reports do: by its site and its
+// content source
+func recheckKey(file *scanner.File) string {
+	path := file.Path
+	if file.SourcePath != "" {
+		path = file.SourcePath
+	}
+	return file.Site + "\x00" + path
+}
+
+// filter keeps only the links of files that were broken in the previous
+// report, plus links the report didn't have, such as the replacements
+// written for broken ones, so only those are checked
+func (r *recheckSet) filter(files []*scanner.File) {
+	for _, file := range files {
+		key := recheckKey(file)
+		kept := file.Links[:0]
+		for _, link := range file.Links {
+			if r.broken[key][link.URL] || !r.reported[key][link.URL] {
+				kept = append(kept, link)
+			}
+		}
+		file.Links = kept
+	}
+}
+
+// markFixed marks the links that were broken in the previous report and
+// work now
+func (r *recheckSet) markFixed(files []*scanner.File) {
+	for _, file := range files {
+		key := recheckKey(file)
+		for i := range file.Links {
+			link := &file.Links[i]
+			if r.broken[key][link.URL] && !link.Ignored && link.Skipped == "" && link.StatusCode > 0 && link.StatusCode < 400 {
+				link.Fixed = true
+			}
+		}
+	}
+}
+
+// summarize prints how many of the previously broken links are fixed,
+// either because they now resolve or because they were edited away
+func (r *recheckSet) summarize(files []*scanner.File) {
+	total := 0
+	for _, urls := range r.broken {
+		total += len(urls)
+	}
+
+	stillBroken := 0
+	for _, file := range files {
+		key := recheckKey(file)
+		for _, link := range file.Links {
+			if r.broken[key][link.URL] && !link.Ignored && (link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "")) {
+				stillBroken++
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Rechecked %d previously broken links: %d fixed, %d still broken\n", total, total-stillBroken, stillBroken)
+}
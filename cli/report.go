@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/checker"
+	"github.com/infodancer/hugo-link-checker/internal/reporter"
+)
+
+// runReport implements the report subcommand, which renders a report in any
+// format from the JSON report of an earlier run without checking anything
+// again, e.g. to produce an HTML report from an expensive CI run later on,
+// or after the report templates change
+func runReport(args []string) {
+	startedAt := time.Now()
+
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hugo-link-checker report -from report.json [flags]\n")
+		flags.PrintDefaults()
+	}
+	var outputFile string
+	from := flags.String("from", "", "JSON report of an earlier run to render (plain, compressed, or a split report manifest)")
+	flags.StringVar(&outputFile, "output", "", "Output file for the report (default: stdout)")
+	flags.StringVar(&outputFile, "o", "", "Shorthand for -output")
+	format := flags.String("format", "html", "Report format: text, json, html, domains, treemap-csv, treemap-json, logfmt, rdjson, badge")
+	language := flags.String("lang", reporter.DefaultLanguage, "Language of text and HTML report strings")
+	reportTitle := flags.String("report-title", "", "Title of the HTML report (default: Hugo Link Checker Report)")
+	reportLogo := flags.String("report-logo", "", "Logo shown in the HTML report: an image URL or a local image file to embed")
+	reportCSS := flags.String("report-css", "", "CSS file added to the HTML report after the built-in styles")
+	if err := flags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if *from == "" || flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	reportFormat, err := parseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if err := reporter.ValidateLanguage(*language); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	theme := &reporter.Theme{
+		Title:   *reportTitle,
+		Logo:    *reportLogo,
+		CSSFile: *reportCSS,
+	}
+	if err := theme.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	files, metadata, err := reporter.ReadReportFiles(*from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	runID := checker.NewRunID()
+	if metadata != nil && metadata.RunID != "" {
+		runID = metadata.RunID
+	}
+
+	err = reporter.GenerateReport(files, reporter.ReportOptions{
+		Format:     reportFormat,
+		OutputFile: outputFile,
+		Metadata:   metadata,
+		Language:   *language,
+		Theme:      theme,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating report: %v\n", err)
+		os.Exit(1)
+	}
+
+	finish(files, checker.CountBrokenLinks(files), 0, startedAt, runID)
+}
@@ -0,0 +1,261 @@
+// Package hugo reads the configuration of a Hugo site and computes the URLs
+// its content pages are served at. It depends on nothing else in the link
+// checker, so other tools can use it to map content files to URLs.
+package hugo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// configNames are the site config files Hugo reads from the site root, in
+// order of precedence
+var configNames = []string{"hugo.toml", "hugo.yaml", "hugo.yml", "hugo.json", "config.toml", "config.yaml", "config.yml", "config.json"}
+
+// SiteConfig is a Hugo site configuration. Keys are matched case-insensitively,
+// as Hugo does.
+type SiteConfig struct {
+	values map[string]any
+	path   string
+	// sources maps the lowercased top-level keys of a split configuration
+	// to the file under config/_default that sets them
+	sources map[string]string
+}
+
+// LoadConfig reads the Hugo configuration of the site at siteRoot: the root
+// config file, or the files under config/_default. A site without any config
+// yields an empty SiteConfig.
+func LoadConfig(siteRoot string) (*SiteConfig, error) {
+	cfg := &SiteConfig{values: make(map[string]any), sources: make(map[string]string)}
+
+	for _, name := range configNames {
+		path := filepath.Join(siteRoot, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		values, err := decodeFile(path)
+		if err != nil {
+			return nil, err
+		}
+		cfg.values = values
+		cfg.path = path
+		return cfg, nil
+	}
+
+	// Split configuration: config/_default/hugo.toml plus one file per
+	// top-level key, e.g. config/_default/markup.toml
+	defaultDir := filepath.Join(siteRoot, "config", "_default")
+	entries, err := os.ReadDir(defaultDir)
+	if err != nil {
+		return cfg, nil
+	}
+	cfg.path = defaultDir
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".toml" && ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		path := filepath.Join(defaultDir, entry.Name())
+		values, err := decodeFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(strings.TrimSuffix(entry.Name(), ext))
+		if key == "hugo" || key == "config" {
+			for k, v := range values {
+				cfg.values[k] = v
+				cfg.sources[strings.ToLower(k)] = path
+			}
+		} else {
+			cfg.values[key] = values
+			cfg.sources[key] = path
+		}
+	}
+
+	return cfg, nil
+}
+
+// decodeFile decodes a TOML, YAML, or JSON config file
+func decodeFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Hugo config %s: %v", path, err)
+	}
+
+	values := make(map[string]any)
+	switch filepath.Ext(path) {
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	default:
+		err = json.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Hugo config %s: %v", path, err)
+	}
+	return values, nil
+}
+
+// Path returns the config file the configuration was read from, or the
+// config/_default directory of a split configuration. It is empty for a site
+// without any config.
+func (c *SiteConfig) Path() string {
+	return c.path
+}
+
+// Source returns the config file that sets the top-level key: the file of a
+// split configuration holding it, or else Path
+func (c *SiteConfig) Source(key string) string {
+	if source, ok := c.sources[strings.ToLower(key)]; ok {
+		return source
+	}
+	return c.path
+}
+
+// Get returns the value at the given key path, e.g. Get("markup", "goldmark")
+func (c *SiteConfig) Get(keys ...string) (any, bool) {
+	var current any = c.values
+	for _, key := range keys {
+		table, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = lookup(table, key)
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// String returns the string value at the given key path, or "" if unset
+func (c *SiteConfig) String(keys ...string) string {
+	value, ok := c.Get(keys...)
+	if !ok {
+		return ""
+	}
+	s, _ := value.(string)
+	return s
+}
+
+// lookup finds a key in a table case-insensitively
+func lookup(table map[string]any, key string) (any, bool) {
+	if value, ok := table[key]; ok {
+		return value, true
+	}
+	for k, value := range table {
+		if strings.EqualFold(k, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// ContentDirs returns the content directories of the site, relative to the
+// site root: contentDir (default "content") and the contentDir of every
+// configured language
+func (c *SiteConfig) ContentDirs() []string {
+	dirs := []string{"content"}
+	if dir := c.String("contentDir"); dir != "" {
+		dirs[0] = dir
+	}
+
+	if languages, ok := c.Get("languages"); ok {
+		if table, ok := languages.(map[string]any); ok {
+			var langDirs []string
+			for lang := range table {
+				if dir := c.String("languages", lang, "contentDir"); dir != "" && !slices.Contains(dirs, dir) && !slices.Contains(langDirs, dir) {
+					langDirs = append(langDirs, dir)
+				}
+			}
+			sort.Strings(langDirs)
+			dirs = append(dirs, langDirs...)
+		}
+	}
+
+	return dirs
+}
+
+// iconParams are the site params themes commonly use for favicon, touch icon,
+// and web manifest paths
+var iconParams = []string{"favicon", "favicon_ico", "faviconFile", "appleTouchIcon", "apple_touch_icon", "touchIcon", "maskIcon", "manifest", "webmanifest"}
+
+// IconParams returns the icon and web manifest paths set in the site params,
+// keyed by param name
+func (c *SiteConfig) IconParams() map[string]string {
+	params := make(map[string]string)
+	for _, name := range iconParams {
+		if value := strings.TrimSpace(c.String("params", name)); value != "" {
+			params[name] = value
+		}
+	}
+	return params
+}
+
+// DefaultURLParams are the site params commonly holding URLs to other
+// sites: social profiles, analytics endpoints, and CDN hosts
+var DefaultURLParams = []string{"social", "links", "github", "gitlab", "twitter", "mastodon", "linkedin", "facebook", "instagram", "youtube", "analytics", "cdn", "cdnURL", "github_repo", "repo", "editURL"}
+
+// URLParams returns the absolute http(s) and protocol-relative URLs in the
+// site params under the given keys, keyed by param path, e.g.
+// "social.twitter" or "links[2]". A key selects the params of that name at
+// any depth, or the param at that path such as "author.github", and
+// everything nested under them; keys match case-insensitively.
+func (c *SiteConfig) URLParams(keys []string) map[string]string {
+	params := make(map[string]string)
+	values, ok := c.Get("params")
+	if !ok {
+		return params
+	}
+	collectURLParams(values, "", "", keys, false, params)
+	return params
+}
+
+// collectURLParams walks value, the param named name at path, adding its URLs
+// to params once it or one of its parents is selected by keys
+func collectURLParams(value any, path, name string, keys []string, selected bool, params map[string]string) {
+	if !selected && path != "" {
+		for _, key := range keys {
+			if strings.EqualFold(key, path) || strings.EqualFold(key, name) {
+				selected = true
+				break
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for name, child := range v {
+			childPath := name
+			if path != "" {
+				childPath = path + "." + name
+			}
+			collectURLParams(child, childPath, name, keys, selected, params)
+		}
+	case []any:
+		for i, child := range v {
+			collectURLParams(child, fmt.Sprintf("%s[%d]", path, i), "", keys, selected, params)
+		}
+	case string:
+		url := strings.TrimSpace(v)
+		if selected && isAbsoluteURL(url) {
+			params[path] = url
+		}
+	}
+}
+
+// isAbsoluteURL reports whether s is an http(s) or protocol-relative URL
+func isAbsoluteURL(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+		(strings.HasPrefix(s, "//") && len(s) > 2 && s[2] != '/')
+}
@@ -0,0 +1,156 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	testCases := map[string]string{
+		"hugo.toml":   "baseURL = 'https://example.com/'\n[markup.goldmark.parser]\nautoHeadingIDType = 'github-ascii'\n",
+		"config.yaml": "baseURL: https://example.com/\nmarkup:\n  goldmark:\n    parser:\n      autoHeadingIDType: github-ascii\n",
+		"hugo.json":   `{"baseURL": "https://example.com/", "markup": {"goldmark": {"parser": {"autoHeadingIDType": "github-ascii"}}}}`,
+	}
+
+	for name, data := range testCases {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadConfig(dir)
+		if err != nil {
+			t.Fatalf("%s: LoadConfig failed: %v", name, err)
+		}
+		if got := cfg.String("baseurl"); got != "https://example.com/" {
+			t.Errorf("%s: expected baseURL, got %q", name, got)
+		}
+		if got := cfg.String("markup", "goldmark", "parser", "autoHeadingIDType"); got != "github-ascii" {
+			t.Errorf("%s: expected autoHeadingIDType, got %q", name, got)
+		}
+	}
+}
+
+func TestLoadConfig_Split(t *testing.T) {
+	dir := t.TempDir()
+	defaultDir := filepath.Join(dir, "config", "_default")
+	if err := os.MkdirAll(defaultDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(defaultDir, "hugo.toml"), []byte("title = 'Docs'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(defaultDir, "markup.toml"), []byte("[goldmark.parser]\nautoHeadingIDType = 'blackfriday'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if got := cfg.String("title"); got != "Docs" {
+		t.Errorf("Expected title, got %q", got)
+	}
+	if got := cfg.String("markup", "goldmark", "parser", "autoHeadingIDType"); got != "blackfriday" {
+		t.Errorf("Expected autoHeadingIDType from markup.toml, got %q", got)
+	}
+
+	empty, err := LoadConfig(t.TempDir())
+	if err != nil || empty.String("title") != "" {
+		t.Errorf("Expected empty config for site without config, got %v", err)
+	}
+}
+
+func TestContentDirs(t *testing.T) {
+	dir := t.TempDir()
+	data := "[languages.en]\ncontentDir = 'content/en'\n[languages.fr]\ncontentDir = 'content/fr'\n[languages.de]\nweight = 3\n"
+	if err := os.WriteFile(filepath.Join(dir, "hugo.toml"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	dirs := cfg.ContentDirs()
+	expected := []string{"content", "content/en", "content/fr"}
+	if len(dirs) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, dirs)
+	}
+	for i := range expected {
+		if dirs[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, dirs)
+		}
+	}
+}
+
+func TestIconParams(t *testing.T) {
+	dir := t.TempDir()
+	data := "[params]\nfavicon = '/favicon.ico'\nappleTouchIcon = 'images/apple-touch-icon.png'\ndescription = 'Docs'\n"
+	path := filepath.Join(dir, "hugo.toml")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Path() != path {
+		t.Errorf("Expected config path %s, got %s", path, cfg.Path())
+	}
+	params := cfg.IconParams()
+	if len(params) != 2 || params["favicon"] != "/favicon.ico" || params["appleTouchIcon"] != "images/apple-touch-icon.png" {
+		t.Errorf("Unexpected icon params: %v", params)
+	}
+}
+
+func TestURLParams(t *testing.T) {
+	dir := t.TempDir()
+	data := `params:
+  description: Docs at https://example.com/docs
+  analytics: https://stats.example.com/script.js
+  CDN: //cdn.example.com
+  author:
+    name: Jane
+    github: https://github.com/jane
+  social:
+    twitter: https://twitter.com/jane
+    email: mailto:jane@example.com
+  links:
+    - https://example.com/one
+    - name: Two
+      url: http://example.com/two
+  logo: https://example.com/logo.png
+`
+	if err := os.WriteFile(filepath.Join(dir, "hugo.yaml"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	params := cfg.URLParams([]string{"analytics", "cdn", "github", "social", "links", "description"})
+	expected := map[string]string{
+		"analytics":      "https://stats.example.com/script.js",
+		"CDN":            "//cdn.example.com",
+		"author.github":  "https://github.com/jane",
+		"social.twitter": "https://twitter.com/jane",
+		"links[0]":       "https://example.com/one",
+		"links[1].url":   "http://example.com/two",
+	}
+	if len(params) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, params)
+	}
+	for path, url := range expected {
+		if params[path] != url {
+			t.Errorf("Expected %s = %s, got %q", path, url, params[path])
+		}
+	}
+
+	if params := cfg.URLParams([]string{"author.github"}); len(params) != 1 || params["author.github"] != "https://github.com/jane" {
+		t.Errorf("Unexpected params for a key path: %v", params)
+	}
+}
@@ -0,0 +1,117 @@
+package hugo
+
+import (
+	"sort"
+	"strings"
+)
+
+// MenuEntry is one entry of a menu defined in the site configuration
+type MenuEntry struct {
+	// Menu is the name of the menu, e.g. main
+	Menu string
+	// Lang is the language whose menus define the entry, or "" for the
+	// site's own menus
+	Lang string
+	// Name is the entry's text, or its identifier when it has none
+	Name string
+	// URL is the entry's url, relative to the site root or absolute
+	URL string
+	// PageRef is the path of the page the entry links to, relative to the
+	// content directory
+	PageRef string
+	// Source is the config file defining the entry
+	Source string
+}
+
+// MenuEntries returns the entries of the menus defined in the site
+// configuration, under menus or menu, for the site and for each of its
+// languages, including split configurations' menus.toml and menus.<lang>.toml
+// files. Entries are ordered by language, menu, and position.
+func (c *SiteConfig) MenuEntries() []MenuEntry {
+	var entries []MenuEntry
+
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name, lang, _ := strings.Cut(strings.ToLower(key), ".")
+		if name != "menus" && name != "menu" {
+			continue
+		}
+		entries = append(entries, menuEntries(c.values[key], lang, c.Source(key))...)
+	}
+
+	if languages, ok := c.Get("languages"); ok {
+		if table, ok := languages.(map[string]any); ok {
+			langs := make([]string, 0, len(table))
+			for lang := range table {
+				langs = append(langs, lang)
+			}
+			sort.Strings(langs)
+			for _, lang := range langs {
+				for _, name := range []string{"menus", "menu"} {
+					if menus, ok := c.Get("languages", lang, name); ok {
+						entries = append(entries, menuEntries(menus, lang, c.Source("languages"))...)
+					}
+				}
+			}
+		}
+	}
+
+	return entries
+}
+
+// menuEntries reads the menus of a menus table: menu names mapped to lists
+// of entries, or a single entry
+func menuEntries(menus any, lang, source string) []MenuEntry {
+	table, ok := menus.(map[string]any)
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []MenuEntry
+	for _, name := range names {
+		var items []any
+		switch v := table[name].(type) {
+		case []any:
+			items = v
+		case map[string]any:
+			items = []any{v}
+		}
+		for _, item := range items {
+			values, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			entry := MenuEntry{
+				Menu:    name,
+				Lang:    lang,
+				Name:    menuString(values, "name"),
+				URL:     menuString(values, "url"),
+				PageRef: menuString(values, "pageRef"),
+				Source:  source,
+			}
+			if entry.Name == "" {
+				entry.Name = menuString(values, "identifier")
+			}
+			if entry.URL != "" || entry.PageRef != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// menuString returns the string value of a menu entry key, trimmed
+func menuString(values map[string]any, key string) string {
+	value, _ := lookup(values, key)
+	s, _ := value.(string)
+	return strings.TrimSpace(s)
+}
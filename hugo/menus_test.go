@@ -0,0 +1,84 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMenuEntries(t *testing.T) {
+	dir := t.TempDir()
+	config := `
+[[menus.main]]
+name = "Docs"
+pageRef = "/docs"
+
+[[menus.main]]
+identifier = "source"
+url = "https://github.com/example/site"
+
+[[menus.main]]
+name = "Heading only"
+
+[languages.fr.menus]
+[[languages.fr.menus.main]]
+name = "Accueil"
+url = "/fr/"
+`
+	if err := os.WriteFile(filepath.Join(dir, "hugo.toml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	source := filepath.Join(dir, "hugo.toml")
+	expected := []MenuEntry{
+		{Menu: "main", Name: "Docs", PageRef: "/docs", Source: source},
+		{Menu: "main", Name: "source", URL: "https://github.com/example/site", Source: source},
+		{Menu: "main", Lang: "fr", Name: "Accueil", URL: "/fr/", Source: source},
+	}
+	entries := cfg.MenuEntries()
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %+v", len(expected), entries)
+	}
+	for i, entry := range entries {
+		if entry != expected[i] {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, expected[i], entry)
+		}
+	}
+}
+
+func TestMenuEntries_Split(t *testing.T) {
+	dir := t.TempDir()
+	defaultDir := filepath.Join(dir, "config", "_default")
+	if err := os.MkdirAll(defaultDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"hugo.toml":     "title = 'Docs'\n",
+		"menus.toml":    "[[main]]\nname = 'Blog'\npageRef = '/blog'\n",
+		"menus.de.yaml": "main:\n  - name: Über\n    url: /de/ueber/\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(defaultDir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	entries := cfg.MenuEntries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %+v", entries)
+	}
+	if entries[0].Lang != "" || entries[0].PageRef != "/blog" || entries[0].Source != filepath.Join(defaultDir, "menus.toml") {
+		t.Errorf("Unexpected entry from menus.toml: %+v", entries[0])
+	}
+	if entries[1].Lang != "de" || entries[1].URL != "/de/ueber/" || entries[1].Source != filepath.Join(defaultDir, "menus.de.yaml") {
+		t.Errorf("Unexpected entry from menus.de.yaml: %+v", entries[1])
+	}
+}
@@ -0,0 +1,359 @@
+package hugo
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// URLResolver computes the URL paths Hugo serves content pages at, from the
+// site configuration: permalinks patterns, url and slug front matter,
+// uglyURLs, the language prefixes of multilingual sites, and page bundles.
+// A nil resolver applies Hugo's defaults.
+type URLResolver struct {
+	// pagePatterns and sectionPatterns are the permalinks patterns of
+	// regular pages and section pages, keyed by lowercased top-level section
+	pagePatterns    map[string]string
+	sectionPatterns map[string]string
+
+	// uglyURLs serves regular pages as /section/page.html; uglySections
+	// does so for the listed sections only
+	uglyURLs     bool
+	uglySections map[string]bool
+
+	// preserveCase keeps the case of paths (disablePathToLower)
+	preserveCase bool
+
+	// languages are the configured language codes, lowercased; langDirs
+	// maps the content directory of each language that has its own to the
+	// language
+	languages           []string
+	langDirs            map[string]string
+	defaultLang         string
+	defaultLangInSubdir bool
+}
+
+// Page is a content file whose URL is computed
+type Page struct {
+	// Path is the file's path relative to its content directory
+	Path string
+	// ContentDir is the content directory, relative to the site root. On
+	// sites with a content directory per language it selects the page's
+	// language. Empty means "content".
+	ContentDir string
+	// FrontMatter is the page's front matter, after cascades
+	FrontMatter map[string]any
+}
+
+// NewURLResolver returns the resolver for the site configured by cfg
+func NewURLResolver(cfg *SiteConfig) *URLResolver {
+	r := &URLResolver{
+		pagePatterns:    make(map[string]string),
+		sectionPatterns: make(map[string]string),
+		uglySections:    make(map[string]bool),
+		langDirs:        make(map[string]string),
+		defaultLang:     "en",
+	}
+	if cfg == nil {
+		return r
+	}
+
+	// Patterns are keyed by section (posts = "/:year/:title/"), or by page
+	// kind and then section in the newer form
+	if permalinks, ok := cfg.Get("permalinks"); ok {
+		table, _ := permalinks.(map[string]any)
+		for key, value := range table {
+			switch value := value.(type) {
+			case string:
+				r.pagePatterns[strings.ToLower(key)] = value
+			case map[string]any:
+				var patterns map[string]string
+				switch strings.ToLower(key) {
+				case "page":
+					patterns = r.pagePatterns
+				case "section":
+					patterns = r.sectionPatterns
+				default:
+					continue
+				}
+				for section, pattern := range value {
+					if pattern, ok := pattern.(string); ok {
+						patterns[strings.ToLower(section)] = pattern
+					}
+				}
+			}
+		}
+	}
+
+	if ugly, ok := cfg.Get("uglyURLs"); ok {
+		switch ugly := ugly.(type) {
+		case bool:
+			r.uglyURLs = ugly
+		case map[string]any:
+			for section, value := range ugly {
+				if enabled, ok := value.(bool); ok && enabled {
+					r.uglySections[strings.ToLower(section)] = true
+				}
+			}
+		}
+	}
+	r.preserveCase, _ = configBool(cfg, "disablePathToLower")
+
+	if lang := cfg.String("defaultContentLanguage"); lang != "" {
+		r.defaultLang = strings.ToLower(lang)
+	}
+	r.defaultLangInSubdir, _ = configBool(cfg, "defaultContentLanguageInSubdir")
+	if languages, ok := cfg.Get("languages"); ok {
+		table, _ := languages.(map[string]any)
+		for lang := range table {
+			r.languages = append(r.languages, strings.ToLower(lang))
+			if dir := cfg.String("languages", lang, "contentDir"); dir != "" {
+				r.langDirs[path.Clean(filepath.ToSlash(dir))] = strings.ToLower(lang)
+			}
+		}
+		slices.Sort(r.languages)
+	}
+	return r
+}
+
+// configBool returns the boolean at the given key path
+func configBool(cfg *SiteConfig, keys ...string) (bool, bool) {
+	value, ok := cfg.Get(keys...)
+	if !ok {
+		return false, false
+	}
+	b, ok := value.(bool)
+	return b, ok
+}
+
+// PageURL returns the URL path Hugo serves a content page at. A url in the
+// front matter is used as it is. Otherwise the path is given by the
+// permalinks pattern of the page's section, or by its location, with the
+// last segment replaced by its slug; leaf bundles (index.md) and branch
+// bundles (_index.md) take the path of their directory. Pages in another
+// language than the default one, by file name (about.fr.md) or content
+// directory, are served under the language's prefix.
+func (r *URLResolver) PageURL(page Page) string {
+	return r.pageURL(page, true)
+}
+
+// FileURL returns the URL path given by a content file's location alone, as
+// PageURL does for a page without front matter in a section without a
+// permalinks pattern: the path a page moved by its url, slug, or pattern
+// would otherwise have
+func (r *URLResolver) FileURL(page Page) string {
+	page.FrontMatter = nil
+	return r.pageURL(page, false)
+}
+
+// pageURL implements PageURL, ignoring permalinks patterns unless
+// usePatterns is set
+func (r *URLResolver) pageURL(page Page, usePatterns bool) string {
+	if r == nil {
+		r = NewURLResolver(nil)
+	}
+	if pageURL := frontMatterString(page.FrontMatter, "url"); pageURL != "" {
+		pageURL = "/" + strings.TrimLeft(pageURL, "/")
+		if path.Ext(pageURL) == "" && !strings.HasSuffix(pageURL, "/") {
+			pageURL += "/"
+		}
+		return pageURL
+	}
+
+	relPath := filepath.ToSlash(page.Path)
+	dir, name := path.Split(relPath)
+	base, lang := r.splitLanguage(strings.TrimSuffix(name, path.Ext(name)))
+	if dirLang, ok := r.langDirs[path.Clean(filepath.ToSlash(page.ContentDir))]; ok && page.ContentDir != "" {
+		lang = dirLang
+	}
+	dir = strings.Trim(dir, "/")
+	section, _, _ := strings.Cut(dir, "/")
+	isSection := base == "_index"
+	isBundle := base == "index"
+
+	// The path of the page without its language prefix; regular pages
+	// are named after their file, or their directory for bundles
+	var pagePath string
+	name = base
+	if isBundle {
+		dir, name = path.Split(dir)
+		dir = strings.Trim(dir, "/")
+	}
+	var pattern string
+	switch {
+	case !usePatterns:
+	case isSection:
+		pattern = r.sectionPatterns[strings.ToLower(section)]
+	default:
+		pattern = r.pagePatterns[strings.ToLower(section)]
+	}
+	switch {
+	case pattern != "":
+		pagePath = expandPattern(pattern, page, dir, name)
+	case isSection:
+		pagePath = "/" + dir
+	default:
+		if slug := strings.Trim(frontMatterString(page.FrontMatter, "slug"), "/"); slug != "" && (dir != "" || name != "") {
+			name = slug
+		}
+		pagePath = strings.TrimSuffix("/"+dir, "/") + "/" + name
+	}
+
+	pagePath = "/" + strings.Trim(pagePath, "/")
+	if !r.preserveCase {
+		pagePath = strings.ToLower(pagePath)
+	}
+	switch {
+	case pagePath == "/":
+	case !isSection && (r.uglyURLs || r.uglySections[strings.ToLower(section)]):
+		pagePath += ".html"
+	case pattern == "" || path.Ext(pattern) == "":
+		pagePath += "/"
+	}
+
+	if prefix := r.languagePrefix(lang); prefix != "" {
+		pagePath = "/" + prefix + pagePath
+	}
+	return pagePath
+}
+
+// splitLanguage splits the language suffix of a configured language off a
+// file name without extension, e.g. about.fr
+func (r *URLResolver) splitLanguage(base string) (string, string) {
+	ext := path.Ext(base)
+	if ext == "" || !slices.Contains(r.languages, strings.ToLower(ext[1:])) {
+		return base, ""
+	}
+	return strings.TrimSuffix(base, ext), strings.ToLower(ext[1:])
+}
+
+// languagePrefix returns the path prefix of a language, empty for the
+// default language unless it is served in a subdirectory too
+func (r *URLResolver) languagePrefix(lang string) string {
+	if lang == "" {
+		lang = r.defaultLang
+	}
+	if len(r.languages) == 0 || (lang == r.defaultLang && !r.defaultLangInSubdir) {
+		return ""
+	}
+	return lang
+}
+
+// expandPattern fills in the tokens of a permalinks pattern, such as
+// /:year/:month/:slug/, for a page in dir named name
+func expandPattern(pattern string, page Page, dir, name string) string {
+	date := frontMatterDate(page.FrontMatter)
+	title := frontMatterString(page.FrontMatter, "title")
+	slug := frontMatterString(page.FrontMatter, "slug")
+	section, _, _ := strings.Cut(dir, "/")
+
+	var out strings.Builder
+	for i := 0; i < len(pattern); {
+		if pattern[i] != ':' {
+			out.WriteByte(pattern[i])
+			i++
+			continue
+		}
+		end := i + 1
+		for end < len(pattern) && isTokenByte(pattern[end]) {
+			end++
+		}
+		switch token := pattern[i+1 : end]; token {
+		case "year":
+			out.WriteString(date.Format("2006"))
+		case "month":
+			out.WriteString(date.Format("01"))
+		case "monthname":
+			out.WriteString(strings.ToLower(date.Format("January")))
+		case "day":
+			out.WriteString(date.Format("02"))
+		case "weekday":
+			out.WriteString(fmt.Sprint(int(date.Weekday())))
+		case "weekdayname":
+			out.WriteString(strings.ToLower(date.Format("Monday")))
+		case "yearday":
+			out.WriteString(fmt.Sprint(date.YearDay()))
+		case "section":
+			out.WriteString(section)
+		case "sections":
+			out.WriteString(dir)
+		case "title":
+			out.WriteString(urlize(title))
+		case "slug":
+			out.WriteString(urlize(firstNonEmpty(slug, title)))
+		case "slugorfilename", "slugorcontentbasename":
+			out.WriteString(urlize(firstNonEmpty(slug, name)))
+		case "filename", "contentbasename":
+			out.WriteString(urlize(name))
+		default:
+			// Tokens Hugo doesn't know are kept as written
+			out.WriteString(pattern[i:end])
+		}
+		i = end
+	}
+	return out.String()
+}
+
+func isTokenByte(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// urlize turns a title into a URL path segment as Hugo's urlize does:
+// spaces become hyphens, and characters other than letters, digits, and
+// . _ - ~ + # are dropped. Case is kept; PageURL lowercases whole paths.
+func urlize(s string) string {
+	var out strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case unicode.IsSpace(r):
+			out.WriteRune('-')
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.Is(unicode.M, r), strings.ContainsRune("._-~+#", r):
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// frontMatterString returns the string value of a front matter key,
+// matched case-insensitively
+func frontMatterString(fm map[string]any, key string) string {
+	value, _ := lookup(fm, key)
+	s, _ := value.(string)
+	return s
+}
+
+// frontMatterDate returns the date of a page, written as a TOML or YAML
+// date or a string
+func frontMatterDate(fm map[string]any) time.Time {
+	value, _ := lookup(fm, "date")
+	switch value := value.(type) {
+	case time.Time:
+		return value
+	case toml.LocalDate:
+		return value.AsTime(time.UTC)
+	case toml.LocalDateTime:
+		return value.AsTime(time.UTC)
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+			if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+				return date
+			}
+		}
+	}
+	return time.Time{}
+}
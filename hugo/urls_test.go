@@ -0,0 +1,153 @@
+package hugo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// loadTestConfig writes a hugo.toml with data and loads it
+func loadTestConfig(t *testing.T, data string) *SiteConfig {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hugo.toml"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	return cfg
+}
+
+func TestPageURL_Defaults(t *testing.T) {
+	testCases := []struct {
+		path     string
+		fm       map[string]any
+		expected string
+	}{
+		{"_index.md", nil, "/"},
+		{"about.md", nil, "/about/"},
+		{"posts/_index.md", nil, "/posts/"},
+		{"posts/hello/index.md", nil, "/posts/hello/"},
+		{"posts/Second-Post.md", nil, "/posts/second-post/"},
+		{"docs/guide/install.md", nil, "/docs/guide/install/"},
+		{"docs/guide/_index.html", nil, "/docs/guide/"},
+		{"about.fr.md", nil, "/about.fr/"},
+		{"posts/hello.md", map[string]any{"slug": "Hi-There"}, "/posts/hi-there/"},
+		{"posts/hello/index.md", map[string]any{"slug": "hi"}, "/posts/hi/"},
+		{"about.md", map[string]any{"slug": "team"}, "/team/"},
+		{"posts/_index.md", map[string]any{"slug": "blog"}, "/posts/"},
+		{"docs/start.md", map[string]any{"url": "start"}, "/start/"},
+		{"docs/start.md", map[string]any{"URL": "/getting-started/", "slug": "ignored"}, "/getting-started/"},
+		{"feeds/podcast.md", map[string]any{"url": "/podcast.xml"}, "/podcast.xml"},
+	}
+
+	// A nil resolver and one for a site without config apply the defaults
+	for _, resolver := range []*URLResolver{nil, NewURLResolver(loadTestConfig(t, ""))} {
+		for _, tc := range testCases {
+			if got := resolver.PageURL(Page{Path: tc.path, FrontMatter: tc.fm}); got != tc.expected {
+				t.Errorf("%s %v: expected %s, got %s", tc.path, tc.fm, tc.expected, got)
+			}
+		}
+	}
+}
+
+func TestPageURL_Permalinks(t *testing.T) {
+	resolver := NewURLResolver(loadTestConfig(t, `
+[permalinks]
+posts = "/:year/:month/:slug/"
+docs = "/manual/:sections/:filename"
+[permalinks.section]
+tags = "/topics/"
+`))
+	date := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		path     string
+		fm       map[string]any
+		expected string
+	}{
+		{"posts/hello.md", map[string]any{"date": date, "title": "Hello, World!"}, "/2024/03/hello-world/"},
+		{"posts/hello.md", map[string]any{"date": "2024-03-09", "title": "Hello", "slug": "Greeting"}, "/2024/03/greeting/"},
+		{"posts/trip/index.md", map[string]any{"date": "2023-12-01T10:00:00Z", "title": "Our Trip"}, "/2023/12/our-trip/"},
+		{"posts/_index.md", nil, "/posts/"},
+		{"docs/guide/Install.md", nil, "/manual/docs/guide/install/"},
+		{"tags/_index.md", nil, "/topics/"},
+		{"posts/hello.md", map[string]any{"url": "/hi/"}, "/hi/"},
+		{"about.md", nil, "/about/"},
+	}
+	for _, tc := range testCases {
+		if got := resolver.PageURL(Page{Path: tc.path, FrontMatter: tc.fm}); got != tc.expected {
+			t.Errorf("%s %v: expected %s, got %s", tc.path, tc.fm, tc.expected, got)
+		}
+	}
+
+	resolver = NewURLResolver(loadTestConfig(t, "[permalinks.page]\nposts = \"/blog/:slugorfilename.html\"\n"))
+	if got := resolver.PageURL(Page{Path: "posts/first.md"}); got != "/blog/first.html" {
+		t.Errorf("Expected the pattern's extension to be kept, got %s", got)
+	}
+
+	// FileURL ignores patterns and front matter
+	resolver = NewURLResolver(loadTestConfig(t, "uglyURLs = true\n[permalinks]\nposts = \"/:year/:slug/\"\n"))
+	if got := resolver.FileURL(Page{Path: "posts/hello.md", FrontMatter: map[string]any{"date": date, "url": "/hi/"}}); got != "/posts/hello.html" {
+		t.Errorf("Expected the path given by the file name, got %s", got)
+	}
+}
+
+func TestPageURL_UglyURLs(t *testing.T) {
+	resolver := NewURLResolver(loadTestConfig(t, "uglyURLs = true\n"))
+	testCases := map[string]string{
+		"_index.md":            "/",
+		"about.md":             "/about.html",
+		"posts/_index.md":      "/posts/",
+		"posts/hello/index.md": "/posts/hello.html",
+	}
+	for path, expected := range testCases {
+		if got := resolver.PageURL(Page{Path: path}); got != expected {
+			t.Errorf("%s: expected %s, got %s", path, expected, got)
+		}
+	}
+
+	resolver = NewURLResolver(loadTestConfig(t, "disablePathToLower = true\n[uglyURLs]\nposts = true\n"))
+	if got := resolver.PageURL(Page{Path: "posts/Hello.md"}); got != "/posts/Hello.html" {
+		t.Errorf("Expected an ugly URL keeping its case, got %s", got)
+	}
+	if got := resolver.PageURL(Page{Path: "docs/Hello.md"}); got != "/docs/Hello/" {
+		t.Errorf("Expected a pretty URL outside the ugly section, got %s", got)
+	}
+}
+
+func TestPageURL_Multilingual(t *testing.T) {
+	resolver := NewURLResolver(loadTestConfig(t, `
+defaultContentLanguage = "en"
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+[languages.de]
+contentDir = "content/de"
+`))
+	testCases := []struct {
+		contentDir string
+		path       string
+		expected   string
+	}{
+		{"", "about.md", "/about/"},
+		{"", "about.fr.md", "/fr/about/"},
+		{"content", "posts/hello/index.fr.md", "/fr/posts/hello/"},
+		{"", "_index.fr.md", "/fr/"},
+		{"content/de", "about.md", "/de/about/"},
+		{"", "notes.txt.md", "/notes.txt/"},
+	}
+	for _, tc := range testCases {
+		if got := resolver.PageURL(Page{Path: tc.path, ContentDir: tc.contentDir}); got != tc.expected {
+			t.Errorf("%s in %q: expected %s, got %s", tc.path, tc.contentDir, tc.expected, got)
+		}
+	}
+
+	resolver = NewURLResolver(loadTestConfig(t, "defaultContentLanguageInSubdir = true\n[languages.en]\n[languages.fr]\n"))
+	if got := resolver.PageURL(Page{Path: "about.md"}); got != "/en/about/" {
+		t.Errorf("Expected the default language in a subdirectory, got %s", got)
+	}
+}
@@ -0,0 +1,115 @@
+package checker
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// anchorIndex caches the anchors defined by each page, keyed by path
+type anchorIndex map[string]map[string]bool
+
+// anchors returns the anchors defined by the page at path, or nil if the
+// page can't be read. A nil index reads the page every time.
+func (idx anchorIndex) anchors(path string, idType string) map[string]bool {
+	if anchors, ok := idx[path]; ok {
+		return anchors
+	}
+	anchors, err := scanner.ParseAnchors(path, idType)
+	if err != nil {
+		anchors = nil
+	}
+	if idx != nil {
+		idx[path] = anchors
+	}
+	return anchors
+}
+
+// checkFragmentLink validates an anchor-only link such as #installation
+// against the headings and element IDs of the page containing it. Links on
+// pages that can't be read are passed, as they were before anchors were checked.
+// With CheckPublic, a content file's links are checked against the page Hugo
+// generated from it.
+func checkFragmentLink(link *scanner.Link, file *scanner.File, index anchorIndex, opts Options) {
+	fragment := strings.TrimPrefix(link.URL, "#")
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+
+	anchors := index.anchors(renderedPage(file, opts), opts.HeadingIDType)
+
+	// An empty fragment and #top scroll to the top of any page
+	if fragment == "" || strings.EqualFold(fragment, "top") || anchors == nil || anchors[fragment] {
+		link.StatusCode = 200
+		link.ErrorMessage = ""
+	} else {
+		link.StatusCode = 404
+		link.ErrorMessage = "Anchor not found"
+		link.ErrorCategory = scanner.CategoryMissingAnchor
+	}
+}
+
+// renderedPage returns the page whose anchors the fragment-only links of
+// file are checked against. With CheckPublic that is the HTML page Hugo
+// generated from a content file, whose IDs include those added by
+// shortcodes, render hooks, and the table of contents rather than the ones
+// inferred from Markdown headings. Otherwise, or when the page hasn't been
+// generated, it is the file itself.
+func renderedPage(file *scanner.File, opts Options) string {
+	if !opts.CheckPublic {
+		return file.Path
+	}
+	// Pages scanned in public/ are the generated pages already
+	publicDir := absPath(filepath.Join(scanner.SiteRoot(opts.RootDir), "public"))
+	if _, ok := relativeTo(publicDir, absPath(file.Path)); ok {
+		return file.Path
+	}
+	pagePath := filePagePath(file, opts)
+	if pagePath == "" {
+		return file.Path
+	}
+	found, _ := findPublicFile(pagePath, opts.RootDir, false, "nfc")
+	if page := publicPage(found); page != "" {
+		return page
+	}
+	return file.Path
+}
+
+// checkPublicFragment validates the fragment of a link resolved in public/
+// against the IDs of the generated page found
+func checkPublicFragment(link *scanner.Link, found string, opts Options) {
+	_, fragment, ok := strings.Cut(link.URL, "#")
+	page := publicPage(found)
+	if !ok || page == "" {
+		return
+	}
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	if fragment == "" || strings.EqualFold(fragment, "top") {
+		return
+	}
+	if anchors := opts.anchors.anchors(page, opts.HeadingIDType); anchors != nil && !anchors[fragment] {
+		link.StatusCode = 404
+		link.ErrorMessage = "Anchor not found"
+		link.ErrorCategory = scanner.CategoryMissingAnchor
+	}
+}
+
+// publicPage returns the HTML page of a path found in public/: the file
+// itself, or the index.html of a directory. Other files have none.
+func publicPage(found string) string {
+	if info, err := os.Stat(found); err == nil && info.IsDir() {
+		found = filepath.Join(found, "index.html")
+		if _, err := os.Stat(found); err != nil {
+			return ""
+		}
+	}
+	if ext := strings.ToLower(filepath.Ext(found)); ext != ".html" && ext != ".htm" {
+		return ""
+	}
+	return found
+}
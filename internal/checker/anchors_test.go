@@ -0,0 +1,81 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckLinks_FragmentOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "# Guide\n\n## Installation\n\nSee [install](#installation), [top](#top), [missing](#configuration).\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &scanner.File{
+		Path: path,
+		Links: []scanner.Link{
+			scanner.NewLink("#installation"),
+			scanner.NewLink("#top"),
+			scanner.NewLink("#configuration"),
+		},
+	}
+
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	for _, link := range file.Links[:2] {
+		if link.StatusCode != 200 {
+			t.Errorf("Expected %s to resolve, got %d", link.URL, link.StatusCode)
+		}
+	}
+	if link := file.Links[2]; link.StatusCode != 404 || link.ErrorCategory != scanner.CategoryMissingAnchor {
+		t.Errorf("Expected missing anchor, got status %d category %q", link.StatusCode, link.ErrorCategory)
+	}
+}
+
+func TestCheckLinks_FragmentPublic(t *testing.T) {
+	dir := t.TempDir()
+	pages := map[string]string{
+		// A shortcode adds the tabs anchor, which the Markdown doesn't show
+		"content/guide.md":        "# Guide\n\n{{< tabs >}}\n\nSee [tabs](#tabs), [guide](#guide).\n",
+		"public/guide/index.html": "<h1 id=guide-page>Guide</h1><div id=tabs></div>",
+		"public/faq/index.html":   `<h2 id="setup">Setup<a class="anchor" href="#setup">#</a></h2>`,
+	}
+	for name, content := range pages {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := &scanner.File{
+		Path: filepath.Join(dir, "content", "guide.md"),
+		Links: []scanner.Link{
+			scanner.NewLink("#tabs"),
+			scanner.NewLink("#guide"),
+			scanner.NewLink("/faq/#setup"),
+			scanner.NewLink("/faq/#install"),
+		},
+	}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: dir, CheckPublic: true}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	expected := []int{200, 404, 200, 404}
+	for i, link := range file.Links {
+		if link.StatusCode != expected[i] {
+			t.Errorf("%s: expected status %d, got %d (%s)", link.URL, expected[i], link.StatusCode, link.ErrorMessage)
+		}
+		if link.StatusCode == 404 && link.ErrorCategory != scanner.CategoryMissingAnchor {
+			t.Errorf("%s: expected missing anchor, got category %q", link.URL, link.ErrorCategory)
+		}
+	}
+}
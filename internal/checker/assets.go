@@ -0,0 +1,80 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// assetDirs returns the assets directories of the site and its themes.
+// Hugo only publishes files from them that templates process with
+// resources.Get, so links to them don't resolve by themselves.
+func assetDirs(rootDir string) []string {
+	siteRoot := scanner.SiteRoot(rootDir)
+	dirs := []string{filepath.Join(siteRoot, "assets")}
+	themes, _ := filepath.Glob(filepath.Join(siteRoot, "themes", "*", "assets"))
+	return append(dirs, themes...)
+}
+
+// findAssetFile returns the file in an assets directory a link path names,
+// either directly (/assets/css/main.css) or as it would be named in static/
+// (/css/main.css), or "" if there is none
+func findAssetFile(linkPath string, rootDir string) string {
+	siteRoot := scanner.SiteRoot(rootDir)
+	linkPath = strings.TrimPrefix(linkPath, "/")
+	if linkPath == "" || strings.HasSuffix(linkPath, "/") {
+		return ""
+	}
+
+	candidates := []string{filepath.Join(siteRoot, linkPath)}
+	for _, dir := range assetDirs(rootDir) {
+		candidates = append(candidates, filepath.Join(dir, linkPath))
+	}
+	for _, candidate := range candidates {
+		if !inAssetDir(candidate, rootDir) {
+			continue
+		}
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// inAssetDir reports whether a path lies in an assets directory
+func inAssetDir(path string, rootDir string) bool {
+	for _, dir := range assetDirs(rootDir) {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// staticFileExists reports whether a link path names a file in the static
+// directory of the site or one of its themes
+func staticFileExists(linkPath string, rootDir string) bool {
+	siteRoot := scanner.SiteRoot(rootDir)
+	dirs := []string{filepath.Join(siteRoot, "static")}
+	themes, _ := filepath.Glob(filepath.Join(siteRoot, "themes", "*", "static"))
+	for _, dir := range append(dirs, themes...) {
+		if info, err := os.Stat(filepath.Join(dir, strings.TrimPrefix(linkPath, "/"))); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// markUnpublishedAsset reports a link that only resolves to a file in
+// assets/, explaining what to do instead
+func markUnpublishedAsset(link *scanner.Link, assetPath string, rootDir string) {
+	if rel, err := filepath.Rel(scanner.SiteRoot(rootDir), assetPath); err == nil {
+		assetPath = filepath.ToSlash(rel)
+	}
+	link.StatusCode = 404
+	link.ErrorCategory = scanner.CategoryNotFoundLocal
+	link.ErrorMessage = fmt.Sprintf("Only found in %s, but assets/ isn't published: process it with resources.Get in a template or move it to static/", assetPath)
+}
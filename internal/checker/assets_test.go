@@ -0,0 +1,59 @@
+package checker
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestUnpublishedAssets(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := []string{
+		"assets/css/main.css",
+		"assets/shared/logo.png",
+		"static/shared/logo.png",
+		"themes/docs/assets/js/app.js",
+		"static/css/print.css",
+		"assets/img/logo.png",
+		"static/assets/img/logo.png",
+	}
+	for _, name := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	testCases := []struct {
+		url    string
+		broken bool
+	}{
+		{"/css/main.css", true},
+		{"/assets/css/main.css", true},
+		{"/js/app.js", true},
+		{"/css/print.css", false},
+		{"/assets/img/logo.png", false},
+		{"/shared/logo.png", false},
+	}
+	for _, tc := range testCases {
+		link := &scanner.Link{URL: tc.url, Type: scanner.LinkTypeInternal}
+		if err := checkInternalLink(link, client, Options{RootDir: tmpDir}); err != nil {
+			t.Fatalf("checkInternalLink failed: %v", err)
+		}
+		if broken := link.StatusCode >= 400; broken != tc.broken {
+			t.Errorf("%s: expected broken=%v, got %+v", tc.url, tc.broken, link)
+		}
+		if tc.broken && !strings.Contains(link.ErrorMessage, "assets/ isn't published") {
+			t.Errorf("%s: expected an assets explanation, got %q", tc.url, link.ErrorMessage)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// LinkBudget caps the links on a page, e.g. to keep posts from turning into
+// link farms. Zero disables a limit.
+type LinkBudget struct {
+	MaxExternal int
+	MaxTotal    int
+}
+
+// LintLinkBudget warns about pages with more links than the budget allows.
+// Links are counted once per distinct URL on the page, so call it before the
+// links are sharded or filtered for a recheck, while each page has them all.
+func LintLinkBudget(files []*scanner.File, budget LinkBudget) {
+	if budget.MaxExternal <= 0 && budget.MaxTotal <= 0 {
+		return
+	}
+
+	for _, file := range files {
+		external := 0
+		for _, link := range file.Links {
+			if link.Type == scanner.LinkTypeExternal {
+				external++
+			}
+		}
+
+		if budget.MaxExternal > 0 && external > budget.MaxExternal {
+			file.Warnings = append(file.Warnings, fmt.Sprintf("Page has %d external links, over the budget of %d", external, budget.MaxExternal))
+		}
+		if budget.MaxTotal > 0 && len(file.Links) > budget.MaxTotal {
+			file.Warnings = append(file.Warnings, fmt.Sprintf("Page has %d links, over the budget of %d", len(file.Links), budget.MaxTotal))
+		}
+	}
+}
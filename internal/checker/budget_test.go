@@ -0,0 +1,38 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestLintLinkBudget(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "farm.md", Links: []scanner.Link{
+			scanner.NewLink("https://a.example.com/"),
+			scanner.NewLink("https://b.example.com/"),
+			scanner.NewLink("https://c.example.com/"),
+			scanner.NewLink("/about/"),
+		}},
+		{Path: "post.md", Links: []scanner.Link{
+			scanner.NewLink("https://a.example.com/"),
+			scanner.NewLink("/about/"),
+		}},
+	}
+
+	LintLinkBudget(files, LinkBudget{MaxExternal: 2, MaxTotal: 3})
+
+	if len(files[0].Warnings) != 2 {
+		t.Errorf("expected external and total budget warnings, got %v", files[0].Warnings)
+	}
+	if len(files[1].Warnings) != 0 {
+		t.Errorf("expected no warnings within budget, got %v", files[1].Warnings)
+	}
+
+	// An empty budget disables the lint
+	files[1].Links = append(files[1].Links, files[0].Links...)
+	LintLinkBudget(files[1:], LinkBudget{})
+	if len(files[1].Warnings) != 0 {
+		t.Errorf("expected no warnings without a budget, got %v", files[1].Warnings)
+	}
+}
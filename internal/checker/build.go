@@ -0,0 +1,97 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// unrenderedPages maps the absolute paths of content files Hugo doesn't
+// render, because their front matter sets build.render to never or link or
+// marks them as drafts, to the reason, such as "build.render is never"
+type unrenderedPages map[string]string
+
+// draftReason is the reason drafts aren't rendered
+const draftReason = "draft is true"
+
+// findUnrenderedPages reads the front matter of the site's content files,
+// including the build options cascaded from their sections, and returns the
+// pages that are never rendered, and with DraftLinks the drafts. Such a page
+// has no URL, so links to it lead nowhere on the built site.
+func findUnrenderedPages(opts Options) unrenderedPages {
+	unrendered := make(unrenderedPages)
+	for _, contentDir := range refContentDirs(opts) {
+		err := filepath.Walk(contentDir, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if info.IsDir() || !scanner.IsContentFile(filePath) {
+				return nil
+			}
+			rel, err := filepath.Rel(contentDir, filePath)
+			if err != nil {
+				return err
+			}
+			// Unreadable front matter is reported by findMovedPages
+			fm, _ := opts.pageFrontMatter(contentDir, rel, filePath)
+			if render := fm.Build().Render; render == "never" || render == "link" {
+				unrendered[absPath(filePath)] = "build.render is " + render
+			} else if opts.DraftLinks && fm.Draft() {
+				unrendered[absPath(filePath)] = draftReason
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read front matter in %s: %v\n", contentDir, err)
+		}
+	}
+	return unrendered
+}
+
+// lintUnrenderedLink marks a link broken when the content file it resolves
+// to is a page Hugo doesn't render, and reports whether it did. Links
+// between drafts are left alone; they are published together.
+func lintUnrenderedLink(link *scanner.Link, found string, opts Options) bool {
+	page, reason, ok := opts.unrendered.lookup(found)
+	if !ok || (reason == draftReason && opts.fromDraft) {
+		return false
+	}
+	if rel, err := filepath.Rel(scanner.SiteRoot(opts.RootDir), page); err == nil {
+		page = filepath.ToSlash(rel)
+	}
+	link.StatusCode = 404
+	link.ErrorMessage = fmt.Sprintf("Page is not rendered: %s in %s", reason, page)
+	link.ErrorCategory = scanner.CategoryNotFoundLocal
+	return true
+}
+
+// lookup returns the content file and reason of the unrendered page
+// found is: the file itself, or the index of a page bundle or section
+// directory, which links to the bundle or section resolve to
+func (unrendered unrenderedPages) lookup(found string) (string, string, bool) {
+	page := absPath(found)
+	if reason, ok := unrendered[page]; ok {
+		return found, reason, true
+	}
+	for _, name := range []string{"index", "_index"} {
+		for _, ext := range refPageExtensions {
+			if reason, ok := unrendered[filepath.Join(page, name+ext)]; ok {
+				return filepath.Join(found, name+ext), reason, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// absPath returns the absolute form of path, or path itself if it has none
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
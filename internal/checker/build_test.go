@@ -0,0 +1,124 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestUnrenderedPages(t *testing.T) {
+	tmpDir := t.TempDir()
+	pages := map[string]string{
+		"content/posts/hidden.md":        "---\nbuild:\n  render: never\n---\n",
+		"content/posts/bundle/index.md":  "+++\n[build]\nrender = 'link'\n+++\n",
+		"content/posts/bundle/photo.jpg": "",
+		"content/posts/unlisted.md":      "---\nbuild:\n  list: never\n---\n",
+		"content/posts/legacy.md":        "---\n_build:\n  render: false\n---\n",
+		"content/internal/_index.md":     "---\ncascade:\n  build:\n    render: never\n---\n",
+		"content/internal/notes.md":      "---\ntitle: Notes\n---\n",
+	}
+	for name, content := range pages {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := &scanner.File{
+		Path: filepath.Join(tmpDir, "content", "index.md"),
+		Links: []scanner.Link{
+			{URL: "/posts/hidden/", Type: scanner.LinkTypeInternal},
+			{URL: "/posts/bundle/", Type: scanner.LinkTypeInternal},
+			{URL: "/posts/legacy/", Type: scanner.LinkTypeInternal},
+			{URL: `{{< ref "posts/hidden" >}}`, Type: scanner.LinkTypeInternal},
+			{URL: "/internal/notes/", Type: scanner.LinkTypeInternal},
+			{URL: "/posts/unlisted/", Type: scanner.LinkTypeInternal},
+			{URL: "/internal/", Type: scanner.LinkTypeInternal},
+		},
+	}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: tmpDir}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	for _, link := range file.Links[:5] {
+		if link.StatusCode != 404 || link.ErrorCategory != scanner.CategoryNotFoundLocal || !strings.HasPrefix(link.ErrorMessage, "Page is not rendered") {
+			t.Errorf("Expected %s reported as not rendered, got %d %q", link.URL, link.StatusCode, link.ErrorMessage)
+		}
+	}
+	if !strings.Contains(file.Links[0].ErrorMessage, "content/posts/hidden.md") {
+		t.Errorf("Expected the message to name the page, got %q", file.Links[0].ErrorMessage)
+	}
+	// Unlisted pages are still rendered, and so is a section cascading
+	// build options to its pages
+	for _, link := range file.Links[5:] {
+		if link.StatusCode != 200 {
+			t.Errorf("Expected %s to pass, got %d %q", link.URL, link.StatusCode, link.ErrorMessage)
+		}
+	}
+}
+
+func TestDraftLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	pages := map[string]string{
+		"content/posts/wip.md":          "---\ndraft: true\n---\n",
+		"content/posts/bundle/index.md": "+++\ndraft = true\n+++\n",
+		"content/upcoming/_index.md":    "---\ncascade:\n  draft: true\n---\n",
+		"content/upcoming/launch.md":    "---\ntitle: Launch\n---\n",
+		"content/posts/published.md":    "---\ndraft: false\n---\n",
+	}
+	for name, content := range pages {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := func() []scanner.Link {
+		return []scanner.Link{
+			{URL: "/posts/wip/", Type: scanner.LinkTypeInternal},
+			{URL: "/posts/bundle/", Type: scanner.LinkTypeInternal},
+			{URL: `{{< ref "upcoming/launch" >}}`, Type: scanner.LinkTypeInternal},
+			{URL: "/posts/published/", Type: scanner.LinkTypeInternal},
+		}
+	}
+	published := &scanner.File{Path: filepath.Join(tmpDir, "content", "_index.md"), Links: links()}
+	draft := &scanner.File{Path: filepath.Join(tmpDir, "content", "posts", "wip.md"), Links: links()}
+	if err := CheckLinksWithOptions([]*scanner.File{published, draft}, Options{RootDir: tmpDir, DraftLinks: true}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	for _, link := range published.Links[:3] {
+		if link.StatusCode != 404 || link.ErrorCategory != scanner.CategoryNotFoundLocal || !strings.HasPrefix(link.ErrorMessage, "Page is not rendered: draft is true in content/") {
+			t.Errorf("Expected %s reported as a draft, got %d %q", link.URL, link.StatusCode, link.ErrorMessage)
+		}
+	}
+	if link := published.Links[3]; link.StatusCode != 200 {
+		t.Errorf("Expected %s to pass, got %d %q", link.URL, link.StatusCode, link.ErrorMessage)
+	}
+	// Drafts may link to each other
+	for _, link := range draft.Links {
+		if link.StatusCode != 200 {
+			t.Errorf("Expected %s from a draft to pass, got %d %q", link.URL, link.StatusCode, link.ErrorMessage)
+		}
+	}
+
+	// Without DraftLinks, drafts are linked like any page
+	published.Links = links()
+	if err := CheckLinksWithOptions([]*scanner.File{published}, Options{RootDir: tmpDir}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	for _, link := range published.Links {
+		if link.StatusCode != 200 {
+			t.Errorf("Expected %s to pass without DraftLinks, got %d %q", link.URL, link.StatusCode, link.ErrorMessage)
+		}
+	}
+}
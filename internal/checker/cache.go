@@ -0,0 +1,149 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// DefaultCacheMaxAge is how long a cached external result is reused
+const DefaultCacheMaxAge = 24 * time.Hour
+
+// CacheStore stores the results of external checks, keyed by destination.
+// It only keeps them: the ResultCache in front of it decides which are
+// fresh enough to reuse and how long they are kept. Implementations must be
+// safe for concurrent use. FileStore and SQLStore are built in; library
+// users may supply others, such as one backed by Redis.
+type CacheStore interface {
+	// Get returns the stored result for key, and false if there is none
+	Get(key string) (scanner.Link, bool, error)
+	// Set stores the result for key, replacing any earlier one
+	Set(key string, link scanner.Link) error
+	// Sweep removes the results last checked before cutoff
+	Sweep(cutoff time.Time) error
+}
+
+// cacheSaver is implemented by stores that hold their results in memory
+// until they are saved, such as FileStore
+type cacheSaver interface {
+	Save() error
+}
+
+// ResultCache keeps the results of external checks across runs in a
+// CacheStore, so destinations checked recently aren't requested again
+type ResultCache struct {
+	// Store holds the results
+	Store CacheStore
+
+	// MaxAge is how long a result is reused; zero reuses results of any age
+	MaxAge time.Duration
+
+	// Retention is how long a result is kept: older results are swept from
+	// the store when the cache is saved. Zero keeps results of any age.
+	Retention time.Duration
+
+	// Hits and Misses count the destinations answered from the cache and
+	// not found in it (or found too old)
+	Hits   int
+	Misses int
+
+	// err is the first error the store returned
+	err error
+	mu  sync.Mutex
+}
+
+// NewResultCache returns an empty result cache kept in memory only, for
+// long-running processes that check the same links repeatedly
+func NewResultCache(maxAge time.Duration) *ResultCache {
+	return NewResultCacheWithStore(NewFileStore(""), maxAge)
+}
+
+// NewResultCacheWithStore returns a result cache keeping its results in
+// store
+func NewResultCacheWithStore(store CacheStore, maxAge time.Duration) *ResultCache {
+	return &ResultCache{Store: store, MaxAge: maxAge}
+}
+
+// LoadResultCache reads the result cache at path, a file, a SQLite database
+// (see IsSQLiteCache), or the URL of a shared backend (see IsSharedCache). A
+// missing, unreadable, or outdated cache yields an empty one rather than an
+// error, since the cache only saves time; a database or shared cache that
+// can't be read is reported as a warning.
+func LoadResultCache(path string, maxAge time.Duration) *ResultCache {
+	if IsSQLiteCache(path) {
+		store, err := OpenSQLiteStore(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return NewResultCache(maxAge)
+		}
+		return NewResultCacheWithStore(store, maxAge)
+	}
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return NewResultCacheWithStore(store, maxAge)
+}
+
+// lookup returns the cached result for a destination. With anyAge set, a
+// result is returned however old it is.
+func (c *ResultCache) lookup(key string, anyAge bool) (scanner.Link, bool) {
+	if c == nil {
+		return scanner.Link{}, false
+	}
+	link, ok, err := c.Store.Get(key)
+	if ok && !anyAge && c.MaxAge > 0 && time.Since(link.LastChecked) > c.MaxAge {
+		ok = false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+	if ok {
+		c.Hits++
+	} else {
+		c.Misses++
+	}
+	return link, ok
+}
+
+// record stores the result of an external check
+func (c *ResultCache) record(key string, link scanner.Link) {
+	if c == nil {
+		return
+	}
+	if err := c.Store.Set(key, link); err != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.err == nil {
+			c.err = err
+		}
+	}
+}
+
+// Save sweeps the results older than Retention from the store and saves
+// it, for stores that hold results in memory. It reports the first error
+// the store returned while the cache was used.
+func (c *ResultCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return fmt.Errorf("result cache store failed: %v", c.err)
+	}
+	if c.Retention > 0 {
+		if err := c.Store.Sweep(time.Now().Add(-c.Retention)); err != nil {
+			return fmt.Errorf("failed to sweep result cache: %v", err)
+		}
+	}
+	if saver, ok := c.Store.(cacheSaver); ok {
+		return saver.Save()
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestResultCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	newFiles := func(paths ...string) []*scanner.File {
+		file := &scanner.File{Path: "a.md"}
+		for _, p := range paths {
+			file.Links = append(file.Links, scanner.NewLink(server.URL+p))
+		}
+		return []*scanner.File{file}
+	}
+
+	// A first run fills the cache
+	cache := LoadResultCache(path, time.Hour)
+	if err := CheckLinksWithOptions(newFiles("/gone", "/ok"), Options{CheckExternal: true, Cache: cache}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	firstRequests := requests
+
+	// A second run answers from it without requesting the URLs again
+	cache = LoadResultCache(path, time.Hour)
+	files := newFiles("/gone", "/ok")
+	if err := CheckLinksWithOptions(files, Options{CheckExternal: true, Cache: cache}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	if requests != firstRequests {
+		t.Errorf("expected no new requests, got %d", requests-firstRequests)
+	}
+	if files[0].Links[0].StatusCode != 404 || files[0].Links[1].StatusCode != 200 {
+		t.Errorf("unexpected cached results %+v", files[0].Links)
+	}
+	if files[0].Links[0].LastChecked.IsZero() {
+		t.Error("expected the cached check time to be kept")
+	}
+	if cache.Hits != 2 || cache.Misses != 0 {
+		t.Errorf("expected 2 hits and no misses, got %d and %d", cache.Hits, cache.Misses)
+	}
+
+	// Results older than the maximum age are checked again
+	store := cache.Store.(*FileStore)
+	for key, link := range store.Results {
+		link.LastChecked = time.Now().Add(-2 * time.Hour)
+		store.Results[key] = link
+	}
+	if err := CheckLinksWithOptions(newFiles("/ok"), Options{CheckExternal: true, Cache: cache}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	if requests == firstRequests {
+		t.Error("expected an expired result to be checked again")
+	}
+
+	// A cache-only run uses results of any age and reports the others as
+	// unknown, without any request
+	for key, link := range store.Results {
+		link.LastChecked = time.Now().Add(-48 * time.Hour)
+		store.Results[key] = link
+	}
+	before := requests
+	files = newFiles("/gone", "/new")
+	if err := CheckLinksWithOptions(files, Options{CheckExternal: true, Cache: cache, CacheOnly: true}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	if requests != before {
+		t.Errorf("expected no requests in a cache-only run, got %d", requests-before)
+	}
+	if files[0].Links[0].StatusCode != 404 {
+		t.Errorf("expected the cached 404, got %+v", files[0].Links[0])
+	}
+	unknown := files[0].Links[1]
+	if unknown.Skipped != scanner.SkipUnknown || unknown.StatusCode != 0 || unknown.ErrorMessage != "" {
+		t.Errorf("expected an unknown result for an uncached link, got %+v", unknown)
+	}
+}
+
+// mapStore is a CacheStore a library user might supply
+type mapStore struct {
+	results map[string]scanner.Link
+	sets    int
+}
+
+func (s *mapStore) Get(key string) (scanner.Link, bool, error) {
+	link, ok := s.results[key]
+	return link, ok, nil
+}
+
+func (s *mapStore) Set(key string, link scanner.Link) error {
+	s.results[key] = link
+	s.sets++
+	return nil
+}
+
+func (s *mapStore) Sweep(cutoff time.Time) error {
+	for key, link := range s.results {
+		if link.LastChecked.Before(cutoff) {
+			delete(s.results, key)
+		}
+	}
+	return nil
+}
+
+func TestResultCache_Store(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store := &mapStore{results: map[string]scanner.Link{
+		"https://old.example.com/": {URL: "https://old.example.com/", StatusCode: 200, LastChecked: time.Now().Add(-30 * 24 * time.Hour)},
+	}}
+	cache := NewResultCacheWithStore(store, time.Hour)
+	cache.Retention = 7 * 24 * time.Hour
+
+	file := &scanner.File{Path: "a.md", Links: []scanner.Link{scanner.NewLink(server.URL + "/page")}}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{CheckExternal: true, Cache: cache}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	if store.sets != 1 || cache.Misses != 1 {
+		t.Errorf("expected the result to be stored after a miss, got %d sets and %d misses", store.sets, cache.Misses)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, ok := store.results["https://old.example.com/"]; ok {
+		t.Error("expected the result older than the retention to be swept")
+	}
+	if len(store.results) != 1 {
+		t.Errorf("expected the new result to be kept, got %v", store.results)
+	}
+}
@@ -0,0 +1,21 @@
+package checker
+
+import (
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// pageFrontMatter reads the front matter of the content file at filePath,
+// rel relative to contentDir, with the values the sections above it cascade
+// to it added, as Hugo sees it
+func (opts Options) pageFrontMatter(contentDir, rel, filePath string) (scanner.FrontMatter, error) {
+	fm, err := scanner.ParseFrontMatter(filePath)
+	key := absPath(contentDir)
+	cascade := opts.cascades[key]
+	if cascade == nil {
+		cascade = scanner.NewCascade(contentDir)
+		if opts.cascades != nil {
+			opts.cascades[key] = cascade
+		}
+	}
+	return cascade.Apply(rel, fm), err
+}
@@ -2,148 +2,677 @@ package checker
 
 import (
 	"fmt"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/infodancer/hugo-link-checker/hugo"
 	"github.com/infodancer/hugo-link-checker/internal/scanner"
 )
 
+// Options configures how links are checked
+type Options struct {
+	RootDir       string
+	CheckExternal bool
+	CheckPublic   bool
+	BaseURL       string
+	Verbose       bool
+
+	// CompareLocal checks internal links both against the local site tree
+	// and against BaseURL, recording any disagreement on the link
+	CompareLocal bool
+
+	// SecurityHeaders records the security headers (HSTS, CSP) missing
+	// from the responses of internal links checked online against BaseURL,
+	// and warns about the pages served without them
+	SecurityHeaders bool
+
+	// ImageDimensions decodes the headers of resolved images to record
+	// their width and height
+	ImageDimensions bool
+
+	// VerifyContent reads the first bytes of document and binary targets
+	// and checks that their signature matches the file extension
+	VerifyContent bool
+
+	// StripParams lists glob patterns of query parameters (e.g. "utm_*")
+	// removed from external URLs before they are checked and deduplicated
+	StripParams []string
+
+	// AllowedSchemes lists the URL schemes that are checked. Links with any
+	// other scheme are skipped and counted as unsupported. Nil selects
+	// DefaultAllowedSchemes.
+	AllowedSchemes []string
+
+	// ProtocolRelativeScheme is the scheme used to check protocol-relative
+	// URLs such as //cdn.example.com/lib.js. The empty string selects https.
+	ProtocolRelativeScheme string
+
+	// UnicodeForm is the normalization form (nfc, nfd, nfkc, nfkd, none)
+	// applied to both link paths and filenames when resolving internal
+	// links. The empty string selects nfc.
+	UnicodeForm string
+
+	// IPVersion restricts outgoing connections to IPv4 (4) or IPv6 (6).
+	// Zero allows both protocol families.
+	IPVersion int
+	// SourceAddr binds outgoing connections to a local IP address or to
+	// the first matching address of a named network interface.
+	SourceAddr string
+
+	// MethodStrategy selects the HTTP method(s) used for external checks.
+	// The zero value behaves like MethodHeadThenGet.
+	MethodStrategy MethodStrategy
+	// DomainMethods overrides MethodStrategy for specific domains and their
+	// subdomains.
+	DomainMethods map[string]MethodStrategy
+
+	// URLs computes the paths content pages are served at, following the
+	// site's permalinks, uglyURLs, and language settings. Nil applies
+	// Hugo's defaults.
+	URLs *hugo.URLResolver
+
+	// ContentDirs are additional content directories, relative to the site
+	// root, that internal links are resolved against when they aren't found
+	// in the standard layout (e.g. content/en for multilingual sites)
+	ContentDirs []string
+
+	// Typosquats warns about external domains that are one edit or a
+	// homoglyph away from a popular domain or another domain on the site
+	Typosquats bool
+
+	// LinkTextLint warns about generic link texts ("click here") and bare
+	// URLs used as link text. GenericLinkTexts adds phrases to
+	// DefaultGenericLinkTexts.
+	LinkTextLint     bool
+	GenericLinkTexts []string
+
+	// AllowedDomains, when set, restricts external links to these domains
+	// and their subdomains; links elsewhere are reported broken
+	AllowedDomains []string
+
+	// Rules are link policy rules evaluated against every link as written
+	Rules []PolicyRule
+
+	// Versions describes versioned docs content; absolute links into it are
+	// checked against the matching version tree
+	Versions *DocVersions
+
+	// HeadingIDType is the autoHeadingIDType (github, github-ascii,
+	// blackfriday) used to derive heading anchors. Empty selects github.
+	HeadingIDType string
+
+	// SiteURL is the site's own URL. Absolute links to it are flagged with
+	// a warning suggesting the relative form. Empty falls back to BaseURL.
+	SiteURL string
+
+	// Rewrites are applied in order to each link before it is checked,
+	// e.g. to map a production domain onto a staging host or local path
+	Rewrites []RewriteRule
+
+	// LocalSites are related sites from a multi-site config. Absolute links
+	// into them are checked against their local content tree, even when
+	// external checking is disabled.
+	LocalSites []LocalSite
+
+	// Checkpoint, when set, supplies external results from an interrupted
+	// run and periodically records progress to disk
+	Checkpoint *Checkpoint
+
+	// OGImage checks that the og:image of each page resolves and is at
+	// least OGImageMinWidth x OGImageMinHeight. Zero minimums select
+	// DefaultOGImageWidth x DefaultOGImageHeight.
+	OGImage          bool
+	OGImageMinWidth  int
+	OGImageMinHeight int
+
+	// NoindexThreshold warns about pages marked robots noindex that at
+	// least this many pages link to. Zero disables the check.
+	NoindexThreshold int
+
+	// Mutes silence the failures of matching links until their date
+	Mutes []Mute
+
+	// Traffic holds the pageviews of the site's pages, which weight the
+	// health score of the report and rank its findings
+	Traffic Traffic
+
+	// Cache keeps external results across runs; fresh results in it are
+	// reused instead of requesting the destination again. With CacheOnly,
+	// external links are answered from the cache alone, whatever the age
+	// of their result, and links not in it are skipped as unknown.
+	Cache     *ResultCache
+	CacheOnly bool
+
+	// Offline makes no network requests: external links, internal links
+	// checked online against BaseURL, and mailto links are skipped as
+	// offline, and remote og:images aren't checked. Fresh results in Cache
+	// are still used.
+	Offline bool
+
+	// DraftLinks reports internal links from published pages to drafts as
+	// broken: Hugo doesn't publish drafts, so the links 404 on the live site
+	DraftLinks bool
+
+	// moved holds the pages moved by front matter url or slug, so links to
+	// their old paths can be reported as stale
+	moved movedPages
+
+	// unrendered holds the pages whose build options, or with DraftLinks
+	// their draft status, keep Hugo from rendering them
+	unrendered unrenderedPages
+
+	// fromDraft is set while the links of a draft are checked, which may
+	// link to other drafts
+	fromDraft bool
+
+	// cascades holds the cascade blocks of each content directory's
+	// sections, keyed by the directory's absolute path
+	cascades map[string]*scanner.Cascade
+
+	// mailDomains looks up the domains of mailto links once per run
+	mailDomains *mailDomainResolver
+
+	// hostRobots reads the robots.txt of external hosts once per run
+	hostRobots *hostRobots
+
+	// pageNames finds the pages that refs name by file name alone
+	pageNames *pageNameIndex
+
+	// anchors caches the anchors of the generated pages in public/ that
+	// fragments are checked against
+	anchors anchorIndex
+
+	// localSites holds the options checking links into each of LocalSites,
+	// keyed by site name
+	localSites map[string]Options
+}
+
+// DefaultAllowedSchemes are the URL schemes checked when no allowlist is configured
+var DefaultAllowedSchemes = []string{"http", "https", "mailto"}
+
 // CheckLinks validates all links in the provided files
 func CheckLinks(files []*scanner.File, rootDir string, checkExternal bool, checkPublic bool, baseURL string, verbose bool) error {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	return CheckLinksWithOptions(files, Options{
+		RootDir:       rootDir,
+		CheckExternal: checkExternal,
+		CheckPublic:   checkPublic,
+		BaseURL:       baseURL,
+		Verbose:       verbose,
+	})
+}
+
+// CheckLinksWithOptions validates all links in the provided files using the given options
+func CheckLinksWithOptions(files []*scanner.File, opts Options) error {
+	session, err := NewSession(opts)
+	if err != nil {
+		return err
+	}
+	return session.Check(files)
+}
+
+// Session checks links against one site repeatedly, as an editor
+// integration does after every change. The HTTP client and the index of the
+// site's content pages are set up once; Refresh rebuilds the index when
+// pages are added, moved, or removed. A Session is not safe for concurrent
+// use.
+type Session struct {
+	opts   Options
+	client *http.Client
+}
+
+// NewSession prepares a session checking links with opts
+func NewSession(opts Options) (*Session, error) {
+	client, err := NewHTTPClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{opts: opts, client: client}
+	session.Refresh()
+	return session, nil
+}
+
+// Refresh rebuilds the index of the site's content pages and those of its
+// local sites
+func (s *Session) Refresh() {
+	s.opts.pageNames = &pageNameIndex{}
+	s.opts.cascades = make(map[string]*scanner.Cascade)
+	// Generated HTML in public/ already reflects url and slug
+	if !s.opts.CheckPublic {
+		s.opts.moved = findMovedPages(s.opts)
+		s.opts.unrendered = findUnrenderedPages(s.opts)
+	}
+	s.opts.localSites = make(map[string]Options, len(s.opts.LocalSites))
+	for _, site := range s.opts.LocalSites {
+		s.opts.localSites[site.Name] = s.opts.forLocalSite(site)
+	}
+}
+
+// Check validates all links in the provided files
+func (s *Session) Check(files []*scanner.File) error {
+	opts, client := s.opts, s.client
+
+	// Results of external checks keyed by normalized URL, so equivalent
+	// spellings of the same destination are only requested once
+	checked := make(map[string]scanner.Link)
+	for key, link := range opts.Checkpoint.results() {
+		checked[key] = link
+	}
+	anchors := make(anchorIndex)
+	opts.anchors = anchors
+	opts.hostRobots = newHostRobots()
+	// Mail domains are looked up in the background while links are checked
+	if opts.CheckExternal && !opts.Offline && !opts.CacheOnly && opts.schemeAllowed("mailto:") {
+		opts.mailDomains = newMailDomainResolver()
+		opts.mailDomains.prefetch(files)
+	}
+
+	var errorRules, warningRules []PolicyRule
+	for _, rule := range opts.Rules {
+		if rule.Severity == SeverityWarning {
+			warningRules = append(warningRules, rule)
+		} else {
+			errorRules = append(errorRules, rule)
+		}
 	}
 
 	for _, file := range files {
+		opts.fromDraft = opts.unrendered[absPath(file.Path)] == draftReason
 		for i := range file.Links {
 			link := &file.Links[i]
 
-			// Skip ignored links
-			if link.Ignored {
-				link.StatusCode = 200
+			// Rewrite rules change what is checked, not what is reported
+			original, originalType := link.URL, link.Type
+			rewritten := opts.rewrite(original)
+			if opts.Versions != nil && strings.HasPrefix(rewritten, "/") {
+				rewritten = opts.Versions.resolve(rewritten, opts.Versions.VersionOf(file), opts.localPageExists)
+			}
+			if rewritten != original {
+				link.URL = rewritten
+				link.Type = scanner.NewLink(rewritten).Type
+			}
+
+			// Ignored and skipped links are left alone entirely. Links
+			// leaking secrets or forbidden by policy are not fetched; policy
+			// warnings are added once the link has been checked.
+			if !skipUnchecked(link) && !applySecretScan(link, original) && !applyPolicy(link, original, errorRules) &&
+				!applyDomainAllowlist(link, opts.AllowedDomains) {
+				if err := checkLink(link, file, client, opts, checked, anchors); err != nil {
+					return err
+				}
+				applyPolicy(link, original, warningRules)
+			}
+
+			if rewritten != original {
+				if link.CheckedURL == "" {
+					link.CheckedURL = rewritten
+				}
+				link.URL, link.Type = original, originalType
+			}
+		}
+
+		if err := opts.Checkpoint.fileDone(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if opts.Typosquats {
+		lintTyposquats(files)
+	}
+	if opts.LinkTextLint {
+		lintLinkText(files, opts.GenericLinkTexts)
+	}
+	lintReferences(files)
+	if opts.OGImage {
+		lintOGImages(files, client, opts)
+	}
+	lintNoindex(files, opts.NoindexThreshold, opts)
+	lintRobots(files, opts)
+	applyMutes(files, opts.Mutes)
+	applyTraffic(files, opts.Traffic, opts)
+
+	return nil
+}
+
+// skipUnchecked records ignored links and links their author asked not to
+// check, returning true if link is one of them
+func skipUnchecked(link *scanner.Link) bool {
+	// Skip ignored links
+	if link.Ignored {
+		link.StatusCode = 200
+		link.ErrorMessage = ""
+		link.LastChecked = time.Now()
+		return true
+	}
+
+	// Skip links their author asked not to check
+	if link.Expect != nil && link.Expect.Skip {
+		link.StatusCode = 0
+		link.ErrorMessage = ""
+		link.Skipped = scanner.SkipAnnotated
+		link.LastChecked = time.Now()
+		return true
+	}
+
+	return false
+}
+
+// checkLink checks a single link found in file, reusing earlier external
+// results from checked
+func checkLink(link *scanner.Link, file *scanner.File, client *http.Client, opts Options, checked map[string]scanner.Link, anchors anchorIndex) error {
+	// References to undefined Markdown labels were found by the parser
+	if link.Kind == scanner.KindReference {
+		checkReference(link)
+		link.LastChecked = time.Now()
+		return nil
+	}
+
+	// Resolve wiki links against the content tree
+	if link.Kind == scanner.KindWiki {
+		checkWikiLink(link, file, anchors, opts)
+		applyExpectation(link)
+		link.LastChecked = time.Now()
+		return nil
+	}
+
+	// Resolve menu page references from the content directory
+	if link.Kind == scanner.KindPageRef {
+		checkRefLink(link, file, "/"+strings.TrimPrefix(link.URL, "/"), anchors, opts)
+		applyExpectation(link)
+		link.LastChecked = time.Now()
+		return nil
+	}
+
+	// Resolve ref and relref shortcodes against the content tree
+	if target, ok := parseRefShortcode(link.URL); ok {
+		checkRefLink(link, file, target, anchors, opts)
+		applyExpectation(link)
+		link.LastChecked = time.Now()
+		return nil
+	}
+
+	// Skip links with other Hugo template syntax
+	if strings.Contains(link.URL, "{{") || strings.Contains(link.URL, "}}") {
+		link.StatusCode = 200
+		link.ErrorMessage = ""
+		link.LastChecked = time.Now()
+		return nil
+	}
+
+	if link.Type == scanner.LinkTypeExternal && !opts.schemeAllowed(link.URL) {
+		link.StatusCode = 0
+		link.ErrorMessage = ""
+		link.Skipped = scanner.SkipUnsupportedScheme
+		link.LastChecked = time.Now()
+		return nil
+	}
+
+	if link.Type == scanner.LinkTypeExternal {
+		checkURL := link.URL
+		if scanner.IsProtocolRelative(checkURL) {
+			scheme := opts.ProtocolRelativeScheme
+			if scheme == "" {
+				scheme = "https"
+			}
+			checkURL = scheme + ":" + checkURL
+		}
+		// <...> destinations may hold spaces; Go escapes them in the path
+		// but would send them as they are in the query
+		checkURL = strings.ReplaceAll(checkURL, " ", "%20")
+		if checkURL = scanner.StripQueryParams(checkURL, opts.StripParams); checkURL != link.URL {
+			link.CheckedURL = checkURL
+		}
+
+		lintOwnHost(link, checkURL, opts)
+
+		if site, path, ok := opts.localSiteFor(checkURL); ok {
+			checkLocalSiteLink(link, site, path, opts)
+		} else if opts.CheckExternal || opts.Offline {
+			key := scanner.DestinationKey(*link)
+			// Enclosures need the extra checks of checkEnclosure, so an
+			// earlier result for a plain link to the same file isn't reused
+			if previous, ok := checked[key]; ok && (!link.Enclosure || previous.Enclosure) {
+				copyCheckResult(link, previous)
+				applyExpectation(link)
+				link.LastChecked = time.Now()
+				return nil
+			}
+			// Results of earlier runs keep the time they were checked
+			if cached, ok := opts.Cache.lookup(key, opts.CacheOnly); ok && (!link.Enclosure || cached.Enclosure) {
+				copyCheckResult(link, cached)
+				link.LastChecked = cached.LastChecked
+				checked[key] = *link
+				applyExpectation(link)
+				return nil
+			}
+			if opts.CacheOnly {
+				link.StatusCode = 0
 				link.ErrorMessage = ""
+				link.Skipped = scanner.SkipUnknown
 				link.LastChecked = time.Now()
-				continue
+				return nil
 			}
-
-			// Skip links with Hugo template syntax
-			if strings.Contains(link.URL, "{{") || strings.Contains(link.URL, "}}") {
-				link.StatusCode = 200
+			if opts.Offline {
+				link.StatusCode = 0
 				link.ErrorMessage = ""
+				link.Skipped = scanner.SkipOffline
 				link.LastChecked = time.Now()
-				continue
+				return nil
 			}
 
-			if link.Type == scanner.LinkTypeExternal {
-				if checkExternal {
-					if strings.HasPrefix(link.URL, "mailto:") {
-						err := checkMailtoLink(link)
-						if err != nil {
-							return fmt.Errorf("error checking mailto link %s: %v", link.URL, err)
-						}
-					} else {
-						err := checkExternalLink(client, link)
-						if err != nil {
-							return fmt.Errorf("error checking external link %s: %v", link.URL, err)
-						}
-					}
-				} else {
-					// Skip external link checking, mark as OK
-					link.StatusCode = 200
-					link.ErrorMessage = ""
+			// Check the cleaned URL, keeping the link as written
+			target := *link
+			if link.CheckedURL != "" {
+				target.URL = link.CheckedURL
+			}
+
+			if strings.HasPrefix(link.URL, "mailto:") {
+				err := checkMailtoLink(&target, opts.mailDomains)
+				if err != nil {
+					return fmt.Errorf("error checking mailto link %s: %v", link.URL, err)
 				}
 			} else {
-				err := checkInternalLink(link, rootDir, checkPublic, baseURL, client, verbose)
+				err := checkExternalLink(client, &target, opts)
 				if err != nil {
-					return fmt.Errorf("error checking internal link %s: %v", link.URL, err)
+					return fmt.Errorf("error checking external link %s: %v", link.URL, err)
 				}
 			}
-
+			copyCheckResult(link, target)
 			link.LastChecked = time.Now()
+			checked[key] = *link
+			opts.Checkpoint.record(key, *link)
+			opts.Cache.record(key, *link)
+		} else {
+			link.StatusCode = 0
+			link.ErrorMessage = ""
+			link.Skipped = scanner.SkipExternalDisabled
+			link.LastChecked = time.Now()
+			return nil
+		}
+	} else if strings.HasPrefix(link.URL, "#") {
+		checkFragmentLink(link, file, anchors, opts)
+	} else if !checkResourceFile(link, file, opts) {
+		err := checkInternalLink(link, client, opts)
+		if err != nil {
+			return fmt.Errorf("error checking internal link %s: %v", link.URL, err)
 		}
 	}
 
+	applyExpectation(link)
+	link.LastChecked = time.Now()
 	return nil
 }
 
-func checkMailtoLink(link *scanner.Link) error {
-	// Parse the mailto URL
-	u, err := url.Parse(link.URL)
+// schemeAllowed reports whether the scheme of an external URL is in the
+// allowlist. Protocol-relative URLs inherit an allowed web scheme.
+func (opts Options) schemeAllowed(linkURL string) bool {
+	if scanner.IsProtocolRelative(linkURL) {
+		return true
+	}
+
+	scheme, _, found := strings.Cut(linkURL, ":")
+	if !found {
+		return true
+	}
+
+	allowed := opts.AllowedSchemes
+	if allowed == nil {
+		allowed = DefaultAllowedSchemes
+	}
+	for _, s := range allowed {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyCheckResult copies the outcome of a check from src to dst, leaving
+// dst's identity (URL, type, ignore state) untouched
+func copyCheckResult(dst *scanner.Link, src scanner.Link) {
+	dst.StatusCode = src.StatusCode
+	dst.ErrorMessage = src.ErrorMessage
+	dst.ErrorCategory = src.ErrorCategory
+	dst.Method = src.Method
+	dst.FinalURL = src.FinalURL
+	dst.RedirectStatus = src.RedirectStatus
+	dst.Canonical = src.Canonical
+	dst.ContentLocation = src.ContentLocation
+	dst.Warnings = append([]string(nil), src.Warnings...)
+	dst.Discrepancy = src.Discrepancy
+	dst.Width = src.Width
+	dst.Height = src.Height
+	dst.ContentType = src.ContentType
+	dst.Size = src.Size
+}
+
+// checkMailtoLink checks that the domain of each recipient of a mailto link
+// can receive mail, looking them up through domains
+func checkMailtoLink(link *scanner.Link, domains *mailDomainResolver) error {
+	recipients, err := mailtoRecipients(link.URL)
 	if err != nil {
 		link.StatusCode = 0
-		link.ErrorMessage = "Invalid mailto URL"
+		link.ErrorMessage = fmt.Sprintf("Invalid mailto URL: %v", err)
+		link.ErrorCategory = scanner.CategoryInvalidURL
 		return nil
 	}
-
-	// Extract email address
-	email := u.Opaque
-	if email == "" {
+	if len(recipients) == 0 {
 		link.StatusCode = 0
 		link.ErrorMessage = "No email address in mailto URL"
+		link.ErrorCategory = scanner.CategoryInvalidURL
 		return nil
 	}
 
-	// Extract domain from email
-	parts := strings.Split(email, "@")
-	if len(parts) != 2 {
+	// Look up MX records for each domain, or an address as a fallback
+	var missing []string
+	for _, domain := range mailtoDomains(recipients) {
+		if err := domains.resolve(domain); err != nil {
+			missing = append(missing, domain)
+		}
+	}
+	if len(missing) > 0 {
 		link.StatusCode = 0
-		link.ErrorMessage = "Invalid email format"
+		link.ErrorMessage = fmt.Sprintf("Domain not found: %s", strings.Join(missing, ", "))
+		link.ErrorCategory = scanner.CategoryDNS
 		return nil
 	}
 
-	domain := parts[1]
+	link.StatusCode = 200
+	link.ErrorMessage = ""
+	return nil
+}
 
-	// Look up MX records for the domain
-	_, err = net.LookupMX(domain)
-	if err != nil {
-		// If MX lookup fails, try A record lookup as fallback
-		_, err = net.LookupHost(domain)
-		if err != nil {
-			link.StatusCode = 0
-			link.ErrorMessage = fmt.Sprintf("Domain not found: %s", domain)
+func checkExternalLink(client *http.Client, link *scanner.Link, opts Options) error {
+	// Video platforms answer 200 for removed videos, so ask oEmbed first
+	if endpoint := oEmbedEndpoint(link.URL); endpoint != "" {
+		if checkVideoLink(client, link, endpoint) {
+			link.Method = http.MethodGet
 			return nil
 		}
 	}
 
-	link.StatusCode = 200
-	link.ErrorMessage = ""
-	return nil
-}
+	var resp *http.Response
+	var err error
 
-func checkExternalLink(client *http.Client, link *scanner.Link) error {
-	resp, err := client.Head(link.URL)
-	if err != nil {
-		// Try GET if HEAD fails
+	switch opts.methodFor(link.URL) {
+	case MethodGetOnly:
+		link.Method = http.MethodGet
 		resp, err = client.Get(link.URL)
+	case MethodHeadOnly:
+		link.Method = http.MethodHead
+		resp, err = client.Head(link.URL)
+	default:
+		link.Method = http.MethodHead
+		resp, err = client.Head(link.URL)
+		if err == nil && headNotSupported(resp.StatusCode) {
+			closeBody(resp)
+			err = fmt.Errorf("HEAD not supported: HTTP %d", resp.StatusCode)
+		}
 		if err != nil {
-			link.StatusCode = 0
-			link.ErrorMessage = err.Error()
-			return nil
+			// Try GET if HEAD fails
+			link.Method = http.MethodGet
+			resp, err = client.Get(link.URL)
 		}
 	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			// Log the error but don't override the main function's return value
-			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", closeErr)
-		}
-	}()
+	if err != nil {
+		link.StatusCode = 0
+		link.ErrorMessage = err.Error()
+		link.ErrorCategory = classifyError(err)
+		return nil
+	}
+	defer closeBody(resp)
+
+	recordCanonicalHeaders(link, resp)
+	if final := resp.Request.URL.String(); final != link.URL {
+		link.FinalURL = final
+	}
+	// The first response of a redirect chain is the one the link got
+	for req := resp.Request; req.Response != nil; req = req.Response.Request {
+		link.RedirectStatus = req.Response.StatusCode
+	}
 
 	link.StatusCode = resp.StatusCode
 	if resp.StatusCode >= 400 {
 		link.ErrorMessage = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		link.ErrorCategory = categoryForStatus(resp.StatusCode)
+		// A site refusing a path its robots.txt disallows is turning the
+		// checker away, which says nothing about the page
+		if slices.Contains(robotsBlockStatuses, resp.StatusCode) && opts.hostRobots.disallows(client, resp.Request.URL) {
+			link.ErrorMessage = fmt.Sprintf("HTTP %d; robots.txt disallows crawling it", resp.StatusCode)
+			link.ErrorCategory = scanner.CategoryRobotsBlocked
+		}
 	} else {
 		link.ErrorMessage = ""
+		link.ErrorCategory = ""
+		if opts.ImageDimensions && isImagePath(resp.Request.URL.Path) {
+			recordRemoteImageDimensions(client, link)
+		}
+		if opts.VerifyContent {
+			verifyRemoteMagic(client, link)
+		}
+		if link.Enclosure {
+			checkEnclosure(client, link)
+		}
+		if opts.SecurityHeaders && link.Type == scanner.LinkTypeInternal {
+			recordSecurityHeaders(link, resp)
+		}
 	}
 
 	return nil
 }
 
-func checkInternalLink(link *scanner.Link, rootDir string, checkPublic bool, baseURL string, client *http.Client, verbose bool) error {
+// closeBody closes a response body, logging rather than returning any error
+func closeBody(resp *http.Response) {
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", closeErr)
+	}
+}
+
+func checkInternalLink(link *scanner.Link, client *http.Client, opts Options) error {
 	// Clean and resolve the path
 	linkPath := link.URL
 
@@ -165,64 +694,192 @@ func checkInternalLink(link *scanner.Link, rootDir string, checkPublic bool, bas
 	}
 
 	// If base URL is provided, check the link online instead of locally
-	if baseURL != "" {
-		// Construct the full URL
-		fullURL := strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(linkPath, "/")
+	if opts.BaseURL == "" {
+		checkInternalLinkLocally(link, linkPath, opts)
+		return nil
+	}
 
-		// Create a temporary link to check online
-		tempLink := &scanner.Link{URL: fullURL}
-		err := checkExternalLink(client, tempLink)
-		if err != nil {
-			return err
+	if opts.Offline {
+		// Compared links are still checked locally; the others need the
+		// network
+		if opts.CompareLocal {
+			checkInternalLinkLocally(link, linkPath, opts)
+		} else {
+			link.StatusCode = 0
+			link.ErrorMessage = ""
+			link.Skipped = scanner.SkipOffline
 		}
+		return nil
+	}
 
-		// Copy the results back to the original link
-		link.StatusCode = tempLink.StatusCode
-		link.ErrorMessage = tempLink.ErrorMessage
-	} else {
-		// Check if file exists locally using Hugo conventions
-		var found bool
-		var checkedPaths []string
+	if !opts.CompareLocal {
+		return checkInternalLinkOnline(link, linkPath, client, opts)
+	}
+
+	// Check both ways and report disagreements between the content tree
+	// and the deployed site
+	checkInternalLinkLocally(link, linkPath, opts)
+	localStatus, localError := link.StatusCode, link.ErrorMessage
 
-		if checkPublic {
-			// Check in Hugo's public directory for built site files
-			found, checkedPaths = checkPublicFileVerbose(linkPath, rootDir, verbose)
+	if err := checkInternalLinkOnline(link, linkPath, client, opts); err != nil {
+		return err
+	}
+
+	localOK := localStatus < 400
+	onlineOK := link.StatusCode != 0 && link.StatusCode < 400
+	switch {
+	case localOK && !onlineOK:
+		link.Discrepancy = fmt.Sprintf("Exists locally but not online (%s)", describeStatus(link.StatusCode, link.ErrorMessage))
+	case !localOK && onlineOK:
+		link.Discrepancy = "Reachable online but missing locally"
+		link.StatusCode = localStatus
+		link.ErrorMessage = localError
+		link.ErrorCategory = scanner.CategoryNotFoundLocal
+	}
+
+	return nil
+}
+
+// checkInternalLinkOnline checks an internal link path against the base URL
+func checkInternalLinkOnline(link *scanner.Link, linkPath string, client *http.Client, opts Options) error {
+	// Construct the full URL
+	fullURL := strings.TrimRight(opts.BaseURL, "/") + "/" + strings.TrimLeft(linkPath, "/")
+
+	// Create a temporary link to check online
+	tempLink := &scanner.Link{URL: fullURL, Type: scanner.LinkTypeInternal}
+	err := checkExternalLink(client, tempLink, opts)
+	if err != nil {
+		return err
+	}
+
+	// Copy the results back to the original link
+	link.StatusCode = tempLink.StatusCode
+	link.ErrorMessage = tempLink.ErrorMessage
+	link.ErrorCategory = tempLink.ErrorCategory
+	link.Method = tempLink.Method
+	link.MissingHeaders = tempLink.MissingHeaders
+	if len(link.MissingHeaders) > 0 {
+		link.Warnings = append(link.Warnings, fmt.Sprintf("Page served without %s", strings.Join(link.MissingHeaders, ", ")))
+	}
+	return nil
+}
+
+// checkInternalLinkLocally checks an internal link path against the local site tree
+func checkInternalLinkLocally(link *scanner.Link, linkPath string, opts Options) {
+	// Check if file exists locally using Hugo conventions
+	var found string
+	var checkedPaths []string
+
+	// Links to non-ASCII filenames are usually percent-encoded
+	if decoded, err := url.PathUnescape(linkPath); err == nil {
+		linkPath = decoded
+	}
+	unicodeForm, err := ParseUnicodeForm(opts.UnicodeForm)
+	if err != nil {
+		unicodeForm = "nfc"
+	}
+	linkPath = normalizeString(linkPath, unicodeForm)
+	found, checkedPaths = opts.findLocalFile(linkPath, unicodeForm)
+
+	if found != "" {
+		link.StatusCode = 200
+		link.ErrorMessage = ""
+		if opts.ImageDimensions && isImagePath(found) {
+			recordLocalImageDimensions(link, found)
+		}
+		if opts.VerifyContent {
+			verifyLocalMagic(link, found)
+		}
+		lintStaleLink(link, linkPath, opts.moved)
+		lintUnrenderedLink(link, found, opts)
+		if opts.CheckPublic {
+			checkPublicFragment(link, found, opts)
+		}
+		if inAssetDir(found, opts.RootDir) && !staticFileExists(linkPath, opts.RootDir) {
+			markUnpublishedAsset(link, found, opts.RootDir)
+		}
+	} else if asset := findAssetFile(linkPath, opts.RootDir); asset != "" {
+		markUnpublishedAsset(link, asset, opts.RootDir)
+	} else {
+		link.StatusCode = 404
+		link.ErrorCategory = scanner.CategoryNotFoundLocal
+		if opts.Verbose && len(checkedPaths) > 0 {
+			link.ErrorMessage = fmt.Sprintf("File not found. Checked paths: %s", strings.Join(checkedPaths, ", "))
 		} else {
-			// Check using standard Hugo source conventions
-			found, checkedPaths = checkHugoFile(linkPath, rootDir, verbose)
+			link.ErrorMessage = "File not found"
 		}
+	}
+}
 
-		if found {
-			link.StatusCode = 200
-			link.ErrorMessage = ""
-		} else {
-			link.StatusCode = 404
-			if verbose && len(checkedPaths) > 0 {
-				link.ErrorMessage = fmt.Sprintf("File not found. Checked paths: %s", strings.Join(checkedPaths, ", "))
-			} else {
-				link.ErrorMessage = "File not found"
-			}
+// findLocalFile returns the file serving a decoded link path in the local
+// tree ("" if none) and optionally the checked paths
+func (opts Options) findLocalFile(linkPath string, unicodeForm string) (string, []string) {
+	if opts.CheckPublic {
+		// Check in Hugo's public directory for built site files
+		return findPublicFile(linkPath, opts.RootDir, opts.Verbose, unicodeForm)
+	}
+
+	// Check using standard Hugo source conventions
+	found, checkedPaths := findHugoFile(linkPath, opts.RootDir, opts.Verbose, unicodeForm)
+
+	// Then any additional content directories, e.g. per-language trees
+	siteRoot := scanner.SiteRoot(opts.RootDir)
+	for _, dir := range opts.ContentDirs {
+		if found != "" {
+			break
 		}
+		var paths []string
+		found, paths = findHugoFile(linkPath, filepath.Join(siteRoot, dir), opts.Verbose, unicodeForm)
+		checkedPaths = append(checkedPaths, paths...)
 	}
 
-	return nil
+	// Pages moved by their front matter url or slug are served there
+	if found == "" {
+		found = opts.moved.servedPage(linkPath)
+	}
+	return found, checkedPaths
+}
+
+// localPageExists reports whether a site-relative link, with any query or
+// fragment, resolves to a file in the local tree
+func (opts Options) localPageExists(linkPath string) bool {
+	if idx := strings.IndexAny(linkPath, "?#"); idx != -1 {
+		linkPath = linkPath[:idx]
+	}
+	if decoded, err := url.PathUnescape(linkPath); err == nil {
+		linkPath = decoded
+	}
+	unicodeForm, err := ParseUnicodeForm(opts.UnicodeForm)
+	if err != nil {
+		unicodeForm = "nfc"
+	}
+	found, _ := opts.findLocalFile(normalizeString(linkPath, unicodeForm), unicodeForm)
+	return found != ""
+}
+
+// describeStatus renders a status code and error message for messages
+func describeStatus(statusCode int, errorMessage string) string {
+	if errorMessage != "" {
+		return errorMessage
+	}
+	return fmt.Sprintf("HTTP %d", statusCode)
 }
 
 // checkHugoFile checks if a file exists using Hugo's conventions and optionally returns checked paths
 func checkHugoFile(linkPath string, rootDir string, verbose bool) (bool, []string) {
+	found, checkedPaths := findHugoFile(linkPath, rootDir, verbose, "")
+	return found != "", checkedPaths
+}
+
+// findHugoFile resolves a link path using Hugo's conventions, returning the
+// matching file path ("" if none) and optionally the checked paths
+func findHugoFile(linkPath string, rootDir string, verbose bool, unicodeForm string) (string, []string) {
 	// Clean the path
 	linkPath = strings.TrimPrefix(linkPath, "/")
 
 	// Detect if we're scanning from within a Hugo content directory
 	// and adjust the root to be the Hugo site root
-	hugoSiteRoot := rootDir
-	if strings.Contains(rootDir, "/content/") {
-		// Find the Hugo site root by going up to the directory containing "content"
-		parts := strings.Split(rootDir, "/content/")
-		if len(parts) > 0 {
-			hugoSiteRoot = parts[0]
-		}
-	}
+	hugoSiteRoot := scanner.SiteRoot(rootDir)
 
 	// List of possible file locations to check
 	var candidatePaths []string
@@ -318,7 +975,7 @@ func checkHugoFile(linkPath string, rootDir string, verbose bool) (bool, []strin
 
 		// First try exact match
 		if _, err := os.Stat(path); err == nil {
-			return true, checkedPaths
+			return path, checkedPaths
 		}
 
 		// If exact match fails and this looks like a source file path, try case-insensitive matching
@@ -327,28 +984,36 @@ func checkHugoFile(linkPath string, rootDir string, verbose bool) (bool, []strin
 				if verbose {
 					checkedPaths = append(checkedPaths, found)
 				}
-				return true, checkedPaths
+				return found, checkedPaths
+			}
+		}
+
+		// Filenames may use a different Unicode normalization than the link
+		if found := findNormalizedFile(path, unicodeForm); found != "" {
+			if verbose {
+				checkedPaths = append(checkedPaths, found)
 			}
+			return found, checkedPaths
 		}
 	}
 
-	return false, checkedPaths
+	return "", checkedPaths
 }
 
 // checkPublicFileVerbose checks if a file exists in Hugo's public directory and optionally returns checked paths
 func checkPublicFileVerbose(linkPath string, rootDir string, verbose bool) (bool, []string) {
+	found, checkedPaths := findPublicFile(linkPath, rootDir, verbose, "")
+	return found != "", checkedPaths
+}
+
+// findPublicFile resolves a link path in Hugo's public directory, returning
+// the matching file path ("" if none) and optionally the checked paths
+func findPublicFile(linkPath string, rootDir string, verbose bool, unicodeForm string) (string, []string) {
 	// Clean the path
 	linkPath = strings.TrimPrefix(linkPath, "/")
 
 	// Detect Hugo site root
-	hugoSiteRoot := rootDir
-	if strings.Contains(rootDir, "/content/") {
-		// Find the Hugo site root by going up to the directory containing "content"
-		parts := strings.Split(rootDir, "/content/")
-		if len(parts) > 0 {
-			hugoSiteRoot = parts[0]
-		}
-	}
+	hugoSiteRoot := scanner.SiteRoot(rootDir)
 
 	// List of possible file locations to check in public directory
 	var candidatePaths []string
@@ -390,11 +1055,14 @@ func checkPublicFileVerbose(linkPath string, rootDir string, verbose bool) (bool
 			checkedPaths = append(checkedPaths, path)
 		}
 		if _, err := os.Stat(path); err == nil {
-			return true, checkedPaths
+			return path, checkedPaths
+		}
+		if found := findNormalizedFile(path, unicodeForm); found != "" {
+			return found, checkedPaths
 		}
 	}
 
-	return false, checkedPaths
+	return "", checkedPaths
 }
 
 // isSourceFilePath checks if a path looks like it's for a Hugo source file
@@ -424,13 +1092,14 @@ func findCaseInsensitiveFile(targetPath string) string {
 	return ""
 }
 
-// CountBrokenLinks returns the number of broken links across all files
+// CountBrokenLinks returns the number of broken links across all files,
+// leaving out ignored and muted links
 func CountBrokenLinks(files []*scanner.File) int {
 	count := 0
 	for _, file := range files {
 		for _, link := range file.Links {
-			// Skip ignored links when counting broken links
-			if link.Ignored {
+			// Skip ignored and muted links when counting broken links
+			if link.Ignored || link.MutedUntil != "" {
 				continue
 			}
 			if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
@@ -440,3 +1109,22 @@ func CountBrokenLinks(files []*scanner.File) int {
 	}
 	return count
 }
+
+// VerificationCoverage returns the percentage of links that were verified,
+// whether working or broken, rather than skipped or ignored; it is 100 when
+// there are no links
+func VerificationCoverage(files []*scanner.File) float64 {
+	verified, total := 0, 0
+	for _, file := range files {
+		for _, link := range file.Links {
+			total++
+			if !link.Ignored && link.Skipped == "" {
+				verified++
+			}
+		}
+	}
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(verified) / float64(total)
+}
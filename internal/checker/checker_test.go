@@ -21,7 +21,7 @@ func TestCheckLinks_HugoTemplateSyntax(t *testing.T) {
 				{URL: "{{.Site.BaseURL}}/about", Type: scanner.LinkTypeInternal},
 				{URL: "https://example.com/{{.Params.slug}}", Type: scanner.LinkTypeExternal},
 				{URL: "/normal-link", Type: scanner.LinkTypeInternal},
-				{URL: "{{< ref \"other-page\" >}}", Type: scanner.LinkTypeInternal},
+				{URL: "{{< param \"homepage\" >}}", Type: scanner.LinkTypeInternal},
 			},
 		},
 	}
@@ -88,7 +88,7 @@ func TestCheckExternalLink(t *testing.T) {
 
 	for _, tc := range testCases {
 		link := &scanner.Link{URL: tc.url}
-		err := checkExternalLink(client, link)
+		err := checkExternalLink(client, link, Options{})
 
 		if tc.expectError && err == nil {
 			t.Errorf("Expected error for URL %s, but got none", tc.url)
@@ -102,6 +102,69 @@ func TestCheckExternalLink(t *testing.T) {
 	}
 }
 
+func TestCheckExternalLink_FinalURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	redirected := &scanner.Link{URL: server.URL + "/old"}
+	if err := checkExternalLink(client, redirected, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if redirected.FinalURL != server.URL+"/new" {
+		t.Errorf("expected final URL %s/new, got %q", server.URL, redirected.FinalURL)
+	}
+
+	direct := &scanner.Link{URL: server.URL + "/new"}
+	if err := checkExternalLink(client, direct, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if direct.FinalURL != "" {
+		t.Errorf("expected no final URL without a redirect, got %q", direct.FinalURL)
+	}
+}
+
+func TestCheckLinks_SpacesInURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/my file.pdf" || r.URL.Query().Get("q") != "a b" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	page := filepath.Join(tmpDir, "page.md")
+	content := "[angle](<" + server.URL + "/my file.pdf?q=a b>) and [encoded](" + server.URL + "/my%20file.pdf?q=a%20b \"Title\")\n"
+	if err := os.WriteFile(page, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := &scanner.File{Path: page}
+	if err := scanner.ParseLinksFromFile(file, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Links) != 2 {
+		t.Fatalf("Expected 2 links, got %+v", file.Links)
+	}
+
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: tmpDir, CheckExternal: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range file.Links {
+		if link.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d %s", link.URL, link.StatusCode, link.ErrorMessage)
+		}
+	}
+	if file.Links[0].URL != server.URL+"/my file.pdf?q=a b" {
+		t.Errorf("Expected the link as written, got %q", file.Links[0].URL)
+	}
+}
+
 func TestCheckMailtoLink(t *testing.T) {
 	testCases := []struct {
 		url            string
@@ -115,7 +178,7 @@ func TestCheckMailtoLink(t *testing.T) {
 
 	for _, tc := range testCases {
 		link := &scanner.Link{URL: tc.url}
-		err := checkMailtoLink(link)
+		err := checkMailtoLink(link, nil)
 
 		if tc.expectError && err == nil {
 			t.Errorf("Expected error for URL %s, but got none", tc.url)
@@ -185,7 +248,7 @@ func TestCheckInternalLink_LocalFiles(t *testing.T) {
 
 	for _, tc := range testCases {
 		link := &scanner.Link{URL: tc.url, Type: scanner.LinkTypeInternal}
-		err := checkInternalLink(link, tmpDir, false, "", client, false)
+		err := checkInternalLink(link, client, Options{RootDir: tmpDir})
 		if err != nil {
 			t.Errorf("Unexpected error checking %s: %v", tc.url, err)
 			continue
@@ -225,7 +288,7 @@ func TestCheckInternalLink_WithBaseURL(t *testing.T) {
 
 	for _, tc := range testCases {
 		link := &scanner.Link{URL: tc.url, Type: scanner.LinkTypeInternal}
-		err := checkInternalLink(link, "", false, server.URL, client, false)
+		err := checkInternalLink(link, client, Options{BaseURL: server.URL})
 		if err != nil {
 			t.Errorf("Unexpected error checking %s: %v", tc.url, err)
 			continue
@@ -364,6 +427,26 @@ func TestCountBrokenLinks(t *testing.T) {
 	}
 }
 
+func TestVerificationCoverage(t *testing.T) {
+	files := []*scanner.File{
+		{
+			Path: "test.md",
+			Links: []scanner.Link{
+				{URL: "http://example.com", StatusCode: 200},
+				{URL: "http://broken.com", StatusCode: 404},
+				{URL: "http://ignored.com", StatusCode: 200, Ignored: true},
+				{URL: "ftp://example.com", Skipped: scanner.SkipUnsupportedScheme},
+			},
+		},
+	}
+	if coverage := VerificationCoverage(files); coverage != 50 {
+		t.Errorf("Expected 50%% coverage, got %v", coverage)
+	}
+	if coverage := VerificationCoverage(nil); coverage != 100 {
+		t.Errorf("Expected 100%% coverage without links, got %v", coverage)
+	}
+}
+
 func TestCheckLinks_Integration(t *testing.T) {
 	// Create a temporary directory structure
 	tmpDir, err := os.MkdirTemp("", "test_check_links_integration")
@@ -432,3 +515,242 @@ func TestCheckLinks_Integration(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckInternalLink_CompareLocal(t *testing.T) {
+	tmpDir := t.TempDir()
+	contentDir := filepath.Join(tmpDir, "content")
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		t.Fatalf("Failed to create content directory: %v", err)
+	}
+	for _, name := range []string{"about.md", "both.md"} {
+		if err := os.WriteFile(filepath.Join(contentDir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	// The deployed site is missing /about/ but serves /legacy/
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/both/", "/legacy/":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	opts := Options{RootDir: tmpDir, BaseURL: server.URL, CompareLocal: true}
+
+	testCases := []struct {
+		url                 string
+		expectedStatus      int
+		expectedDiscrepancy bool
+	}{
+		{"/both/", 200, false},
+		{"/about/", 404, true},
+		{"/legacy/", 404, true},
+		{"/missing/", 404, false},
+	}
+
+	for _, tc := range testCases {
+		link := &scanner.Link{URL: tc.url, Type: scanner.LinkTypeInternal}
+		if err := checkInternalLink(link, client, opts); err != nil {
+			t.Fatalf("checkInternalLink failed: %v", err)
+		}
+		if link.StatusCode != tc.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", tc.url, tc.expectedStatus, link.StatusCode)
+		}
+		if (link.Discrepancy != "") != tc.expectedDiscrepancy {
+			t.Errorf("%s: expected discrepancy=%v, got %q", tc.url, tc.expectedDiscrepancy, link.Discrepancy)
+		}
+	}
+}
+
+func TestCheckLinks_NormalizedDeduplication(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	upper := strings.Replace(server.URL, "http://", "HTTP://", 1)
+	files := []*scanner.File{
+		{Path: "a.md", Links: []scanner.Link{{URL: server.URL, Type: scanner.LinkTypeExternal}}},
+		{Path: "b.md", Links: []scanner.Link{
+			{URL: server.URL + "/", Type: scanner.LinkTypeExternal},
+			{URL: upper, Type: scanner.LinkTypeExternal},
+		}},
+	}
+
+	if err := CheckLinksWithOptions(files, Options{CheckExternal: true}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected 1 request for equivalent URLs, got %d", requests)
+	}
+	for _, link := range files[1].Links {
+		if link.StatusCode != 200 {
+			t.Errorf("%s: expected status 200, got %d", link.URL, link.StatusCode)
+		}
+	}
+	if files[1].Links[1].URL != upper {
+		t.Errorf("Raw URL should be preserved, got %s", files[1].Links[1].URL)
+	}
+}
+
+func TestCheckLinks_ProtocolRelative(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	relative := strings.TrimPrefix(server.URL, "http:")
+	files := []*scanner.File{
+		{Path: "a.md", Links: []scanner.Link{scanner.NewLink(relative + "/lib.js")}},
+	}
+
+	opts := Options{CheckExternal: true, ProtocolRelativeScheme: "http"}
+	if err := CheckLinksWithOptions(files, opts); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	link := files[0].Links[0]
+	if link.Type != scanner.LinkTypeExternal {
+		t.Errorf("Protocol-relative URL should be external")
+	}
+	if link.CheckedURL != server.URL+"/lib.js" {
+		t.Errorf("Expected checked URL %s, got %s", server.URL+"/lib.js", link.CheckedURL)
+	}
+	if link.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d (%s)", link.StatusCode, link.ErrorMessage)
+	}
+}
+
+func TestCheckLinks_SchemeAllowlist(t *testing.T) {
+	files := []*scanner.File{
+		{
+			Path: "test.md",
+			Links: []scanner.Link{
+				scanner.NewLink("ftp://ftp.example.com/file.txt"),
+				scanner.NewLink("magnet:?xt=urn:btih:abc"),
+				scanner.NewLink("gemini://example.org/"),
+				scanner.NewLink("https://example.com/"),
+			},
+		},
+	}
+
+	if err := CheckLinksWithOptions(files, Options{}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	for _, link := range files[0].Links[:3] {
+		if link.Skipped != scanner.SkipUnsupportedScheme {
+			t.Errorf("%s: expected unsupported-scheme skip, got %q", link.URL, link.Skipped)
+		}
+	}
+	if skipped := files[0].Links[3].Skipped; skipped != scanner.SkipExternalDisabled {
+		t.Errorf("https link should only be skipped as external, got %q", skipped)
+	}
+	if count := CountBrokenLinks(files); count != 0 {
+		t.Errorf("Skipped links should not count as broken, got %d", count)
+	}
+
+	opts := Options{AllowedSchemes: []string{"http", "https", "ftp"}}
+	if !opts.schemeAllowed("FTP://ftp.example.com/") {
+		t.Errorf("ftp should be allowed when listed")
+	}
+}
+
+func TestCheckInternalLink_ContentDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	docsDir := filepath.Join(tmpDir, "content", "en", "docs", "getting-started")
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		t.Fatalf("Failed to create docs directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, "_index.md"), []byte("# Getting started"), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+
+	link := scanner.Link{URL: "/docs/getting-started/", Type: scanner.LinkTypeInternal}
+	if err := checkInternalLink(&link, nil, Options{RootDir: tmpDir}); err != nil {
+		t.Fatalf("checkInternalLink failed: %v", err)
+	}
+	if link.StatusCode != 404 {
+		t.Errorf("Expected 404 without language content dirs, got %d", link.StatusCode)
+	}
+
+	link = scanner.Link{URL: "/docs/getting-started/", Type: scanner.LinkTypeInternal}
+	if err := checkInternalLink(&link, nil, Options{RootDir: tmpDir, ContentDirs: []string{"content/en"}}); err != nil {
+		t.Fatalf("checkInternalLink failed: %v", err)
+	}
+	if link.StatusCode != 200 {
+		t.Errorf("Expected link resolved in content/en, got %d", link.StatusCode)
+	}
+}
+
+func TestCheckLinks_Offline(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "content"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "content", "about.md"), []byte("# About"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []*scanner.File{{
+		Path: "test.md",
+		Links: []scanner.Link{
+			scanner.NewLink(server.URL + "/page"),
+			scanner.NewLink("mailto:someone@example.invalid"),
+			scanner.NewLink("/about/"),
+		},
+	}}
+	opts := Options{RootDir: tmpDir, CheckExternal: true, Offline: true}
+	if err := CheckLinksWithOptions(files, opts); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	for _, link := range files[0].Links[:2] {
+		if link.Skipped != scanner.SkipOffline || link.StatusCode != 0 || link.ErrorMessage != "" {
+			t.Errorf("%s: expected an offline skip, got %+v", link.URL, link)
+		}
+	}
+	if link := files[0].Links[2]; link.Skipped != "" || link.StatusCode != 200 {
+		t.Errorf("local links should still be checked offline, got %+v", link)
+	}
+
+	// Internal links checked online are skipped too, unless they are also
+	// compared locally
+	online := []*scanner.File{{Path: "test.md", Links: []scanner.Link{scanner.NewLink("/about/")}}}
+	opts.BaseURL = server.URL
+	if err := CheckLinksWithOptions(online, opts); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	if online[0].Links[0].Skipped != scanner.SkipOffline {
+		t.Errorf("expected an offline skip with -base-url, got %+v", online[0].Links[0])
+	}
+	opts.CompareLocal = true
+	online[0].Links[0] = scanner.NewLink("/about/")
+	if err := CheckLinksWithOptions(online, opts); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	if online[0].Links[0].StatusCode != 200 || online[0].Links[0].Skipped != "" {
+		t.Errorf("expected a local check with -compare-local, got %+v", online[0].Links[0])
+	}
+
+	if requests != 0 {
+		t.Errorf("expected no requests offline, got %d", requests)
+	}
+	if count := CountBrokenLinks(files); count != 0 {
+		t.Errorf("offline links should not count as broken, got %d", count)
+	}
+}
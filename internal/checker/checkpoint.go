@@ -0,0 +1,165 @@
+package checker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// DefaultCheckpointInterval is how often progress is saved during a run
+const DefaultCheckpointInterval = 30 * time.Second
+
+// Checkpoint records the progress of a run on disk, so a run that is
+// killed can be resumed without repeating its external checks
+type Checkpoint struct {
+	RunID     string    `json:"run_id"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// FilesScanned counts the files whose links have all been checked
+	FilesScanned int `json:"files_scanned"`
+	// URLsChecked counts the external destinations with a recorded result
+	URLsChecked int `json:"urls_checked"`
+
+	// Results holds external check results keyed by destination
+	Results map[string]scanner.Link `json:"results"`
+
+	// Interval is the minimum time between saves during checking
+	Interval time.Duration `json:"-"`
+
+	path      string
+	lastSaved time.Time
+	mu        sync.Mutex
+}
+
+// NewRunID returns an identifier for a run: its start time and a random suffix
+func NewRunID() string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return time.Now().UTC().Format("20060102T150405Z")
+	}
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// NewCheckpoint starts a checkpoint for a new run, saved to path
+func NewCheckpoint(path, runID string) *Checkpoint {
+	return &Checkpoint{
+		RunID:     runID,
+		StartedAt: time.Now(),
+		Results:   make(map[string]scanner.Link),
+		Interval:  DefaultCheckpointInterval,
+		path:      path,
+	}
+}
+
+// LoadCheckpoint reads the checkpoint of an earlier run from path
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %v", path, err)
+	}
+	if checkpoint.Results == nil {
+		checkpoint.Results = make(map[string]scanner.Link)
+	}
+	checkpoint.Interval = DefaultCheckpointInterval
+	checkpoint.path = path
+	// Files are re-walked on resume; only the external results carry over
+	checkpoint.FilesScanned = 0
+	return checkpoint, nil
+}
+
+// Save writes the checkpoint to disk. The file is replaced atomically, so a
+// run killed mid-save leaves the previous checkpoint intact.
+func (c *Checkpoint) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.save()
+}
+
+func (c *Checkpoint) save() error {
+	c.UpdatedAt = time.Now()
+	c.URLsChecked = len(c.Results)
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	c.lastSaved = c.UpdatedAt
+	return nil
+}
+
+// Remove deletes the checkpoint file once the run it records has completed
+func (c *Checkpoint) Remove() error {
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint: %v", err)
+	}
+	return nil
+}
+
+// results returns a copy of the recorded external results
+func (c *Checkpoint) results() map[string]scanner.Link {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results := make(map[string]scanner.Link, len(c.Results))
+	for key, link := range c.Results {
+		results[key] = link
+	}
+	return results
+}
+
+// record stores the result of an external check
+func (c *Checkpoint) record(key string, link scanner.Link) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Results[key] = link
+}
+
+// fileDone counts a checked file and saves the checkpoint if the interval
+// has passed since the last save
+func (c *Checkpoint) fileDone() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.FilesScanned++
+	if time.Since(c.lastSaved) < c.Interval {
+		return nil
+	}
+	return c.save()
+}
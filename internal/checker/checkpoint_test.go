@@ -0,0 +1,74 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckpoint_Resume(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	newFiles := func() []*scanner.File {
+		return []*scanner.File{{
+			Path: "a.md",
+			Links: []scanner.Link{
+				scanner.NewLink(server.URL + "/gone"),
+				scanner.NewLink(server.URL + "/ok"),
+			},
+		}}
+	}
+
+	// A first run records its external results
+	checkpoint := NewCheckpoint(path, "run-1")
+	if err := CheckLinksWithOptions(newFiles(), Options{CheckExternal: true, Checkpoint: checkpoint}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	if err := checkpoint.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	firstRequests := requests
+
+	resumed, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	if resumed.RunID != "run-1" || resumed.URLsChecked != 2 || len(resumed.Results) != 2 {
+		t.Fatalf("unexpected checkpoint %+v", resumed)
+	}
+
+	// The resumed run reuses them without requesting the URLs again
+	files := newFiles()
+	if err := CheckLinksWithOptions(files, Options{CheckExternal: true, Checkpoint: resumed}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+	if requests != firstRequests {
+		t.Errorf("expected no new requests on resume, got %d", requests-firstRequests)
+	}
+	if files[0].Links[0].StatusCode != http.StatusNotFound || files[0].Links[1].StatusCode != http.StatusOK {
+		t.Errorf("unexpected resumed results %+v", files[0].Links)
+	}
+	if resumed.FilesScanned != 1 {
+		t.Errorf("expected 1 file scanned, got %d", resumed.FilesScanned)
+	}
+
+	if err := resumed.Remove(); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := LoadCheckpoint(path); err == nil {
+		t.Error("expected the checkpoint to be removed")
+	}
+}
@@ -0,0 +1,113 @@
+package checker
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/hugo"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// LocalSite is a related site whose absolute URLs are resolved against its
+// local content tree instead of being fetched from production
+type LocalSite struct {
+	Name    string
+	BaseURL string
+	RootDir string
+
+	// ContentDirs, URLs, Versions, and HeadingIDType describe the site like
+	// the Options fields of the same names. Links into the site are
+	// resolved with them rather than with those of the linking site.
+	ContentDirs   []string
+	URLs          *hugo.URLResolver
+	Versions      *DocVersions
+	HeadingIDType string
+}
+
+// localSiteFor returns the local site serving the given URL and the path of
+// the URL relative to that site's base URL
+func (opts Options) localSiteFor(linkURL string) (LocalSite, string, bool) {
+	u, err := url.Parse(linkURL)
+	if err != nil || u.Host == "" {
+		return LocalSite{}, "", false
+	}
+
+	for _, site := range opts.LocalSites {
+		base, err := url.Parse(site.BaseURL)
+		if err != nil || base.Host == "" {
+			continue
+		}
+		if !strings.EqualFold(u.Hostname(), base.Hostname()) || u.Port() != base.Port() {
+			continue
+		}
+
+		basePath := strings.TrimSuffix(base.Path, "/")
+		if u.Path != basePath && !strings.HasPrefix(u.Path, basePath+"/") {
+			continue
+		}
+
+		path := strings.TrimPrefix(u.Path, basePath)
+		if path == "" {
+			path = "/"
+		}
+		if u.RawQuery != "" {
+			path += "?" + u.RawQuery
+		}
+		return site, path, true
+	}
+
+	return LocalSite{}, "", false
+}
+
+// forLocalSite returns the options checking links into site against its
+// local content tree, with the moved and unrendered pages found from the
+// site's own front matter and permalink rules
+func (opts Options) forLocalSite(site LocalSite) Options {
+	siteOpts := opts
+	siteOpts.RootDir = site.RootDir
+	siteOpts.ContentDirs = site.ContentDirs
+	siteOpts.URLs = site.URLs
+	siteOpts.Versions = site.Versions
+	siteOpts.HeadingIDType = site.HeadingIDType
+	// Without a base URL the checks never touch the network
+	siteOpts.BaseURL = ""
+	siteOpts.CompareLocal = false
+	siteOpts.LocalSites = nil
+	siteOpts.localSites = nil
+
+	siteOpts.pageNames = &pageNameIndex{}
+	siteOpts.cascades = make(map[string]*scanner.Cascade)
+	siteOpts.moved, siteOpts.unrendered = movedPages{}, nil
+	// Generated HTML in public/ already reflects url and slug
+	if !siteOpts.CheckPublic {
+		siteOpts.moved = findMovedPages(siteOpts)
+		siteOpts.unrendered = findUnrenderedPages(siteOpts)
+	}
+	return siteOpts
+}
+
+// checkLocalSiteLink checks a link into a related site against that site's
+// local content tree
+func checkLocalSiteLink(link *scanner.Link, site LocalSite, path string, opts Options) {
+	siteOpts, ok := opts.localSites[site.Name]
+	if !ok {
+		siteOpts = opts.forLocalSite(site)
+	}
+	siteOpts.fromDraft = opts.fromDraft
+	siteOpts.anchors = opts.anchors
+
+	target := *link
+	target.URL = path
+	if siteOpts.Versions != nil {
+		// Another site's pages belong to none of this site's versions
+		target.URL = siteOpts.Versions.resolve(path, "", siteOpts.localPageExists)
+	}
+	_ = checkInternalLink(&target, nil, siteOpts)
+
+	copyCheckResult(link, target)
+	link.ResolvedSite = site.Name
+	if target.MovedPage != "" {
+		link.MovedPage = target.MovedPage
+		link.Fix = strings.TrimSuffix(site.BaseURL, "/") + target.Fix
+	}
+}
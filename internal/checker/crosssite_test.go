@@ -0,0 +1,119 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestLocalSiteFor(t *testing.T) {
+	opts := Options{LocalSites: []LocalSite{
+		{Name: "docs", BaseURL: "https://docs.example.com/", RootDir: "docs"},
+		{Name: "blog", BaseURL: "https://example.com/blog", RootDir: "blog"},
+	}}
+
+	testCases := []struct {
+		url      string
+		site     string
+		path     string
+		resolved bool
+	}{
+		{"https://docs.example.com/guide/", "docs", "/guide/", true},
+		{"http://DOCS.example.com", "docs", "/", true},
+		{"https://example.com/blog/post/?p=1", "blog", "/post/?p=1", true},
+		{"https://example.com/blogroll/", "", "", false},
+		{"https://other.example.com/guide/", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		site, path, ok := opts.localSiteFor(tc.url)
+		if ok != tc.resolved || site.Name != tc.site || path != tc.path {
+			t.Errorf("%s: expected (%s, %s, %v), got (%s, %s, %v)", tc.url, tc.site, tc.path, tc.resolved, site.Name, path, ok)
+		}
+	}
+}
+
+func TestCheckLinks_CrossSite(t *testing.T) {
+	tempDir := t.TempDir()
+	docsRoot := filepath.Join(tempDir, "docs")
+	if err := os.MkdirAll(filepath.Join(docsRoot, "content", "guide"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(docsRoot, "content", "guide", "_index.md"), []byte("# Guide"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &scanner.File{
+		Path: "blog/content/post.md",
+		Links: []scanner.Link{
+			{URL: "https://docs.example.com/guide/", Type: scanner.LinkTypeExternal},
+			{URL: "https://docs.example.com/missing/", Type: scanner.LinkTypeExternal},
+		},
+	}
+
+	opts := Options{
+		RootDir:    filepath.Join(tempDir, "blog"),
+		LocalSites: []LocalSite{{Name: "docs", BaseURL: "https://docs.example.com", RootDir: docsRoot}},
+	}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, opts); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	if link := file.Links[0]; link.StatusCode != 200 || link.ResolvedSite != "docs" {
+		t.Errorf("Expected existing page resolved in docs, got status %d site %q", link.StatusCode, link.ResolvedSite)
+	}
+	if link := file.Links[1]; link.StatusCode != 404 || link.ErrorCategory != scanner.CategoryNotFoundLocal {
+		t.Errorf("Expected missing page reported not-found-local, got status %d category %q", link.StatusCode, link.ErrorCategory)
+	}
+}
+
+func TestCheckLinks_CrossSitePageState(t *testing.T) {
+	tempDir := t.TempDir()
+	pages := map[string]string{
+		// The docs site renamed one page and stopped rendering another
+		"docs/content/guide/old.md":    "---\nslug: renamed\n---\n",
+		"docs/content/guide/hidden.md": "---\nbuild:\n  render: never\n---\n",
+		"docs/content/guide/kept.md":   "---\ntitle: Kept\n---\n",
+		// The blog moving its own page of the same name doesn't move the
+		// docs one
+		"blog/content/guide/kept.md": "---\nurl: /elsewhere/\n---\n",
+	}
+	for name, content := range pages {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := &scanner.File{
+		Path: filepath.Join(tempDir, "blog", "content", "post.md"),
+		Links: []scanner.Link{
+			{URL: "https://docs.example.com/guide/old/", Type: scanner.LinkTypeExternal},
+			{URL: "https://docs.example.com/guide/hidden/", Type: scanner.LinkTypeExternal},
+			{URL: "https://docs.example.com/guide/kept/", Type: scanner.LinkTypeExternal},
+		},
+	}
+	opts := Options{
+		RootDir:    filepath.Join(tempDir, "blog"),
+		LocalSites: []LocalSite{{Name: "docs", BaseURL: "https://docs.example.com/", RootDir: filepath.Join(tempDir, "docs")}},
+	}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, opts); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	if link := file.Links[0]; link.ErrorCategory != scanner.CategoryStaleURL || link.Fix != "https://docs.example.com/guide/renamed/" {
+		t.Errorf("Expected a stale link to the renamed docs page, got %+v", link)
+	}
+	if link := file.Links[1]; link.StatusCode != 404 || !strings.HasPrefix(link.ErrorMessage, "Page is not rendered") {
+		t.Errorf("Expected the unrendered docs page reported, got %d %q", link.StatusCode, link.ErrorMessage)
+	}
+	if link := file.Links[2]; link.StatusCode != 200 || link.MovedPage != "" {
+		t.Errorf("Expected the docs page to pass, got %+v", link)
+	}
+}
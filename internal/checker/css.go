@@ -0,0 +1,51 @@
+package checker
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// checkResourceFile resolves the images, front matter files, and stylesheet
+// references that Hugo finds somewhere other than an ordinary internal link, and reports whether
+// the file was found. Links it doesn't find are checked as internal links,
+// e.g. in static/.
+func checkResourceFile(link *scanner.Link, file *scanner.File, opts Options) bool {
+	switch link.Kind {
+	case scanner.KindImage, scanner.KindFrontMatter:
+		return checkBundleImage(link, file, opts)
+	case scanner.KindCSS:
+		// Relative references are relative to the stylesheet, or to the
+		// page for style attributes
+		return checkBundleImage(link, file, opts) || checkAssetFile(link, opts)
+	default:
+		return false
+	}
+}
+
+// checkAssetFile resolves an absolute path in the assets/ directory of the
+// site, where Hugo Pipes finds the resources stylesheets are built from, and
+// reports whether the file was found there
+func checkAssetFile(link *scanner.Link, opts Options) bool {
+	assetPath := link.URL
+	if idx := strings.IndexAny(assetPath, "?#"); idx != -1 {
+		assetPath = assetPath[:idx]
+	}
+	if !strings.HasPrefix(assetPath, "/") || strings.HasPrefix(assetPath, "//") {
+		return false
+	}
+	if decoded, err := url.PathUnescape(assetPath); err == nil {
+		assetPath = decoded
+	}
+
+	found := filepath.Join(scanner.SiteRoot(opts.RootDir), "assets", filepath.FromSlash(assetPath))
+	if info, err := os.Stat(found); err != nil || info.IsDir() {
+		return false
+	}
+	link.StatusCode = 200
+	link.ErrorMessage = ""
+	return true
+}
@@ -0,0 +1,60 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckLinks_CSS(t *testing.T) {
+	tmpDir := t.TempDir()
+	cssDir := filepath.Join(tmpDir, "static", "css")
+	for _, dir := range []string{cssDir, filepath.Join(tmpDir, "static", "fonts"), filepath.Join(tmpDir, "assets", "images")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(cssDir, "site.css"),
+		filepath.Join(tmpDir, "static", "fonts", "inter.woff2"),
+		filepath.Join(tmpDir, "assets", "images", "hero.jpg"),
+	} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	css := func(url string) scanner.Link {
+		link := scanner.NewLink(url)
+		link.Kind = scanner.KindCSS
+		return link
+	}
+	file := &scanner.File{
+		Path: filepath.Join(cssDir, "site.css"),
+		Links: []scanner.Link{
+			css("../fonts/inter.woff2"),
+			css("/fonts/inter.woff2?v=3"),
+			css("/images/hero.jpg"),
+			css("../fonts/missing.woff2"),
+			css("/images/missing.jpg"),
+		},
+	}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: tmpDir}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	expected := map[string]int{
+		"../fonts/inter.woff2":   200,
+		"/fonts/inter.woff2?v=3": 200,
+		"/images/hero.jpg":       200,
+		"../fonts/missing.woff2": 404,
+		"/images/missing.jpg":    404,
+	}
+	for _, link := range file.Links {
+		if link.StatusCode != expected[link.URL] {
+			t.Errorf("%s: expected status %d, got %d (%s)", link.URL, expected[link.URL], link.StatusCode, link.ErrorMessage)
+		}
+	}
+}
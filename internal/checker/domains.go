@@ -0,0 +1,32 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// applyDomainAllowlist marks external links to domains outside the
+// allowlist as broken without fetching them, reporting true if it did
+func applyDomainAllowlist(link *scanner.Link, allowlist []string) bool {
+	if len(allowlist) == 0 || link.Type != scanner.LinkTypeExternal {
+		return false
+	}
+	domain := scanner.LinkDomain(*link)
+	if domain == "" {
+		return false
+	}
+	for _, allowed := range allowlist {
+		if hostMatches(strings.ToLower(allowed), domain) {
+			return false
+		}
+	}
+
+	link.StatusCode = 0
+	link.ErrorMessage = fmt.Sprintf("Domain %s is not in the allowlist", domain)
+	link.ErrorCategory = scanner.CategoryDomainNotAllowed
+	link.LastChecked = time.Now()
+	return true
+}
@@ -0,0 +1,33 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckLinks_DomainAllowlist(t *testing.T) {
+	file := &scanner.File{
+		Path: "content/index.md",
+		Links: []scanner.Link{
+			scanner.NewLink("https://docs.example.com/"),
+			scanner.NewLink("https://tracker.example.net/"),
+			scanner.NewLink("/about/"),
+		},
+	}
+
+	opts := Options{RootDir: t.TempDir(), AllowedDomains: []string{"example.com"}}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, opts); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	if link := file.Links[0]; link.ErrorCategory != "" {
+		t.Errorf("Expected subdomain of allowed domain to pass, got %s", link.ErrorCategory)
+	}
+	if link := file.Links[1]; link.ErrorCategory != scanner.CategoryDomainNotAllowed {
+		t.Errorf("Expected domain-not-allowed, got %q", link.ErrorCategory)
+	}
+	if link := file.Links[2]; link.ErrorCategory == scanner.CategoryDomainNotAllowed {
+		t.Error("Internal links should not be subject to the allowlist")
+	}
+}
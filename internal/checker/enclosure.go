@@ -0,0 +1,69 @@
+package checker
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// checkEnclosure requests the first byte of a podcast enclosure to record
+// its Content-Type and size, warning when it isn't served as audio, when the
+// server ignores range requests (podcast apps need them to seek and resume),
+// or when the size disagrees with the length declared in the feed
+func checkEnclosure(client *http.Client, link *scanner.Link) {
+	req, err := http.NewRequest(http.MethodGet, link.URL, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode >= 400 {
+		return
+	}
+
+	link.ContentType = resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(link.ContentType); err != nil || !strings.HasPrefix(mediaType, "audio/") {
+		contentType := link.ContentType
+		if contentType == "" {
+			contentType = "no Content-Type"
+		}
+		link.Warnings = append(link.Warnings, fmt.Sprintf("Enclosure served as %s, expected audio/*", contentType))
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		link.Size = contentRangeSize(resp.Header.Get("Content-Range"))
+	} else {
+		if resp.ContentLength > 0 {
+			link.Size = resp.ContentLength
+		}
+		link.Warnings = append(link.Warnings, "Enclosure server doesn't support range requests")
+	}
+
+	if link.EnclosureLength > 0 && link.Size > 0 && link.EnclosureLength != link.Size {
+		link.Warnings = append(link.Warnings, fmt.Sprintf("Enclosure length %d in the feed differs from the file size %d", link.EnclosureLength, link.Size))
+	}
+}
+
+// contentRangeSize returns the complete length from a Content-Range header
+// such as "bytes 0-0/48213", or zero if it is unknown
+func contentRangeSize(contentRange string) int64 {
+	_, total, found := strings.Cut(contentRange, "/")
+	if !found {
+		return 0
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,56 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckEnclosure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok.mp3":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Header().Set("Content-Range", "bytes 0-0/48213")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte{0})
+		case "/page.mp3":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html>not found</html>"))
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	ok := &scanner.Link{URL: server.URL + "/ok.mp3", Type: scanner.LinkTypeExternal, Enclosure: true, EnclosureLength: 48213}
+	if err := checkExternalLink(client, ok, Options{}); err != nil {
+		t.Fatalf("checkExternalLink failed: %v", err)
+	}
+	if ok.ContentType != "audio/mpeg" || ok.Size != 48213 || len(ok.Warnings) != 0 {
+		t.Errorf("Expected a clean audio enclosure of 48213 bytes, got %+v", ok)
+	}
+
+	bad := &scanner.Link{URL: server.URL + "/page.mp3", Type: scanner.LinkTypeExternal, Enclosure: true, EnclosureLength: 1000}
+	if err := checkExternalLink(client, bad, Options{}); err != nil {
+		t.Fatalf("checkExternalLink failed: %v", err)
+	}
+	warnings := strings.Join(bad.Warnings, "\n")
+	for _, want := range []string{"served as text/html", "doesn't support range requests", "length 1000 in the feed differs from the file size 22"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("Expected warning %q, got %v", want, bad.Warnings)
+		}
+	}
+
+	plain := &scanner.Link{URL: server.URL + "/page.mp3", Type: scanner.LinkTypeExternal}
+	if err := checkExternalLink(client, plain, Options{}); err != nil {
+		t.Fatalf("checkExternalLink failed: %v", err)
+	}
+	if plain.ContentType != "" || len(plain.Warnings) != 0 {
+		t.Errorf("Expected no enclosure checks for a plain link, got %+v", plain)
+	}
+}
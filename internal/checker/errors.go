@@ -0,0 +1,118 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// classifyError maps a request error to an error category
+func classifyError(err error) scanner.ErrorCategory {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return scanner.CategoryTimeout
+		}
+		return scanner.CategoryDNS
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return scanner.CategoryTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return scanner.CategoryTimeout
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return scanner.CategoryConnectionRefused
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &recordErr) || errors.As(err, &unknownAuthority) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidCert) || strings.Contains(err.Error(), "tls:") {
+		return scanner.CategoryTLS
+	}
+
+	if strings.Contains(err.Error(), "unsupported protocol scheme") {
+		return scanner.CategoryInvalidURL
+	}
+
+	return scanner.CategoryNetwork
+}
+
+// categoryForStatus maps an HTTP error status to an error category
+func categoryForStatus(statusCode int) scanner.ErrorCategory {
+	if statusCode >= 500 {
+		return scanner.CategoryHTTP5xx
+	}
+	return scanner.CategoryHTTP4xx
+}
+
+// ParseCategories parses a comma-separated list of error categories
+func ParseCategories(spec string) (map[scanner.ErrorCategory]bool, error) {
+	known := map[scanner.ErrorCategory]bool{
+		scanner.CategoryDNS:                true,
+		scanner.CategoryTimeout:            true,
+		scanner.CategoryConnectionRefused:  true,
+		scanner.CategoryTLS:                true,
+		scanner.CategoryNetwork:            true,
+		scanner.CategoryHTTP4xx:            true,
+		scanner.CategoryHTTP5xx:            true,
+		scanner.CategoryNotFoundLocal:      true,
+		scanner.CategoryRobotsBlocked:      true,
+		scanner.CategoryInvalidURL:         true,
+		scanner.CategoryContentMismatch:    true,
+		scanner.CategoryMissingAnchor:      true,
+		scanner.CategoryPolicy:             true,
+		scanner.CategoryDomainNotAllowed:   true,
+		scanner.CategoryCredentialLeak:     true,
+		scanner.CategoryStaleURL:           true,
+		scanner.CategoryExpectation:        true,
+		scanner.CategoryUndefinedReference: true,
+	}
+
+	categories := make(map[scanner.ErrorCategory]bool)
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !known[scanner.ErrorCategory(name)] {
+			return nil, errors.New("unknown error category: " + name)
+		}
+		categories[scanner.ErrorCategory(name)] = true
+	}
+	return categories, nil
+}
+
+// CountBrokenLinksInCategories returns the number of broken links whose
+// error category is in categories. An empty set counts every broken link.
+func CountBrokenLinksInCategories(files []*scanner.File, categories map[scanner.ErrorCategory]bool) int {
+	if len(categories) == 0 {
+		return CountBrokenLinks(files)
+	}
+
+	count := 0
+	for _, file := range files {
+		for _, link := range file.Links {
+			if link.Ignored || link.MutedUntil != "" {
+				continue
+			}
+			if (link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "")) && categories[link.ErrorCategory] {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,91 @@
+package checker
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestErrorCategories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/robots.txt":
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+		case "/private/page", "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	// Find a port with nothing listening on it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closedURL := "http://" + listener.Addr().String()
+	if err := listener.Close(); err != nil {
+		t.Fatalf("Failed to close listener: %v", err)
+	}
+
+	client := &http.Client{Timeout: 100 * time.Millisecond}
+
+	testCases := []struct {
+		url      string
+		expected scanner.ErrorCategory
+	}{
+		{server.URL + "/gone", scanner.CategoryHTTP4xx},
+		{server.URL + "/forbidden", scanner.CategoryHTTP4xx},
+		{server.URL + "/private/page", scanner.CategoryRobotsBlocked},
+		{server.URL + "/bad", scanner.CategoryHTTP5xx},
+		{server.URL + "/slow", scanner.CategoryTimeout},
+		{closedURL, scanner.CategoryConnectionRefused},
+		{"http://nonexistent.invalid/", scanner.CategoryDNS},
+	}
+
+	for _, tc := range testCases {
+		link := &scanner.Link{URL: tc.url}
+		if err := checkExternalLink(client, link, Options{}); err != nil {
+			t.Fatalf("checkExternalLink failed: %v", err)
+		}
+		if link.ErrorCategory != tc.expected {
+			t.Errorf("%s: expected category %s, got %s (%s)", tc.url, tc.expected, link.ErrorCategory, link.ErrorMessage)
+		}
+	}
+}
+
+func TestCountBrokenLinksInCategories(t *testing.T) {
+	files := []*scanner.File{
+		{
+			Path: "test.md",
+			Links: []scanner.Link{
+				{URL: "/missing/", StatusCode: 404, ErrorMessage: "File not found", ErrorCategory: scanner.CategoryNotFoundLocal},
+				{URL: "https://flaky.example.com", StatusCode: 0, ErrorMessage: "timeout", ErrorCategory: scanner.CategoryTimeout},
+				{URL: "https://example.com", StatusCode: 200},
+			},
+		},
+	}
+
+	categories, err := ParseCategories("not-found-local")
+	if err != nil {
+		t.Fatalf("ParseCategories failed: %v", err)
+	}
+	if count := CountBrokenLinksInCategories(files, categories); count != 1 {
+		t.Errorf("Expected 1 broken link in category, got %d", count)
+	}
+	if count := CountBrokenLinksInCategories(files, nil); count != 2 {
+		t.Errorf("Expected 2 broken links without filter, got %d", count)
+	}
+	if _, err := ParseCategories("bogus"); err == nil {
+		t.Errorf("Expected error for unknown category")
+	}
+}
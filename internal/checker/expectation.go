@@ -0,0 +1,56 @@
+package checker
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// applyExpectation compares the outcome of a check with the expectations
+// the link's author declared. A link meeting an expected error status, such
+// as a deliberate example of a dead link, is reported working, like an
+// ignored link; a link missing its expectation is reported broken.
+func applyExpectation(link *scanner.Link) {
+	expect := link.Expect
+	if expect == nil {
+		return
+	}
+
+	if expect.Status != 0 {
+		got := link.StatusCode
+		if expect.Status >= 300 && expect.Status < 400 && link.RedirectStatus != 0 {
+			got = link.RedirectStatus
+		}
+		if got != expect.Status {
+			link.ErrorMessage = fmt.Sprintf("Expected HTTP %d, got %s", expect.Status, describeStatus(got, link.ErrorMessage))
+			link.ErrorCategory = scanner.CategoryExpectation
+			link.StatusCode = 0
+			return
+		}
+		if link.StatusCode >= 400 {
+			link.StatusCode = 200
+			link.ErrorMessage = ""
+			link.ErrorCategory = ""
+		}
+	}
+
+	if expect.RedirectTo != "" && link.StatusCode != 0 {
+		final := link.FinalURL
+		if final == "" {
+			final = link.URL
+		}
+		want := expect.RedirectTo
+		if base, err := url.Parse(link.URL); err == nil {
+			if ref, err := url.Parse(want); err == nil {
+				want = base.ResolveReference(ref).String()
+			}
+		}
+		if strings.TrimSuffix(final, "/") != strings.TrimSuffix(want, "/") {
+			link.ErrorMessage = fmt.Sprintf("Expected a redirect to %s, ends at %s", want, final)
+			link.ErrorCategory = scanner.CategoryExpectation
+			link.StatusCode = 0
+		}
+	}
+}
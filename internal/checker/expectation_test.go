@@ -0,0 +1,59 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckLinks_Expectations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/temporary":
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	files := []*scanner.File{{
+		Path: "content/page.md",
+		Links: []scanner.Link{
+			{URL: server.URL + "/old", Type: scanner.LinkTypeExternal, Expect: &scanner.Expectation{Status: 301, RedirectTo: "/new"}},
+			{URL: server.URL + "/temporary", Type: scanner.LinkTypeExternal, Expect: &scanner.Expectation{Status: 301}},
+			{URL: server.URL + "/gone", Type: scanner.LinkTypeExternal, Expect: &scanner.Expectation{Status: 410}},
+			{URL: server.URL + "/plain", Type: scanner.LinkTypeExternal, Expect: &scanner.Expectation{RedirectTo: "/new"}},
+			{URL: server.URL + "/skipped", Type: scanner.LinkTypeExternal, Expect: &scanner.Expectation{Skip: true}},
+		},
+	}}
+	if err := CheckLinksWithOptions(files, Options{RootDir: t.TempDir(), CheckExternal: true}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	links := files[0].Links
+	if links[0].ErrorMessage != "" || links[0].RedirectStatus != 301 {
+		t.Errorf("expected /old to meet its expectations, got %+v", links[0])
+	}
+	if links[1].ErrorCategory != scanner.CategoryExpectation || links[1].ErrorMessage != "Expected HTTP 301, got HTTP 302" {
+		t.Errorf("expected /temporary to miss its status, got %+v", links[1])
+	}
+	if links[2].StatusCode != 200 || links[2].ErrorMessage != "" {
+		t.Errorf("expected the deliberately dead /gone to count as working, got %+v", links[2])
+	}
+	if links[3].ErrorCategory != scanner.CategoryExpectation {
+		t.Errorf("expected /plain to miss its redirect, got %+v", links[3])
+	}
+	if links[4].Skipped != scanner.SkipAnnotated || links[4].StatusCode != 0 {
+		t.Errorf("expected /skipped to be skipped, got %+v", links[4])
+	}
+	if count := CountBrokenLinks(files); count != 2 {
+		t.Errorf("expected 2 broken links, got %d", count)
+	}
+}
@@ -0,0 +1,158 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// resultCacheVersion identifies the format of a result cache file; caches of
+// other versions are discarded
+const resultCacheVersion = 1
+
+// FileStore is a CacheStore holding results in memory and saving them as one
+// JSON document: a local file, or a shared http(s):// or s3:// object (see
+// IsSharedCache). Before saving to a shared object it reads it again and
+// keeps the most recent result of each destination, so runs finishing at the
+// same time don't drop each other's results.
+type FileStore struct {
+	Version int                     `json:"version"`
+	Results map[string]scanner.Link `json:"results"`
+
+	// backend is where the document is saved, nil for a store kept in
+	// memory only
+	backend cacheBackend
+	// shared is set for backends other runs write too
+	shared bool
+	// swept is the latest sweep cutoff; older results read back from a
+	// shared backend aren't kept
+	swept time.Time
+	mu    sync.Mutex
+}
+
+// NewFileStore returns an empty store saved to the file at path, or kept in
+// memory only if path is empty
+func NewFileStore(path string) *FileStore {
+	store := &FileStore{Version: resultCacheVersion, Results: make(map[string]scanner.Link)}
+	if path != "" {
+		store.backend = fileCacheBackend(path)
+	}
+	return store
+}
+
+// OpenFileStore returns the store at location, a file or the URL of a shared
+// backend, with the results saved there. A missing or outdated document
+// yields an empty store. If location can't be used or read, the store is
+// returned empty, with the error.
+func OpenFileStore(location string) (*FileStore, error) {
+	store := NewFileStore(location)
+	if IsSharedCache(location) {
+		backend, err := newCacheBackend(location)
+		if err != nil {
+			store.backend = nil
+			return store, err
+		}
+		store.backend, store.shared = backend, true
+	}
+	data, err := store.backend.read()
+	if err != nil {
+		return store, err
+	}
+	if results := decodeResultCache(data); results != nil {
+		store.Results = results
+	}
+	return store, nil
+}
+
+// decodeResultCache returns the results of an encoded store, or nil if it
+// can't be read or is of another version
+func decodeResultCache(data []byte) map[string]scanner.Link {
+	var stored FileStore
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != resultCacheVersion {
+		return nil
+	}
+	return stored.Results
+}
+
+// Get returns the stored result for key
+func (s *FileStore) Get(key string) (scanner.Link, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link, ok := s.Results[key]
+	return link, ok, nil
+}
+
+// Set stores the result for key
+func (s *FileStore) Set(key string, link scanner.Link) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Results[key] = link
+	return nil
+}
+
+// Sweep removes the results last checked before cutoff
+func (s *FileStore) Sweep(cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, link := range s.Results {
+		if link.LastChecked.Before(cutoff) {
+			delete(s.Results, key)
+		}
+	}
+	if cutoff.After(s.swept) {
+		s.swept = cutoff
+	}
+	return nil
+}
+
+// Save writes the results to the store's file or shared object
+func (s *FileStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.backend == nil {
+		return nil
+	}
+
+	if s.shared {
+		// A cache that can't be read now is overwritten; writing it
+		// reports any lasting problem
+		data, _ := s.backend.read()
+		for key, link := range decodeResultCache(data) {
+			if link.LastChecked.Before(s.swept) {
+				continue
+			}
+			if current, ok := s.Results[key]; !ok || link.LastChecked.After(current.LastChecked) {
+				s.Results[key] = link
+			}
+		}
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode result cache: %v", err)
+	}
+	return s.backend.write(data)
+}
+
+// fileCacheBackend keeps an encoded cache in a local file
+type fileCacheBackend string
+
+func (path fileCacheBackend) read() ([]byte, error) {
+	data, err := os.ReadFile(string(path))
+	if err != nil {
+		// A missing or unreadable file is started afresh
+		return nil, nil
+	}
+	return data, nil
+}
+
+func (path fileCacheBackend) write(data []byte) error {
+	if err := os.WriteFile(string(path), data, 0644); err != nil {
+		return fmt.Errorf("failed to write result cache: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package checker
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// recordCanonicalHeaders stores the canonical URL and Content-Location
+// advertised by a response and warns when the canonical target differs
+// substantially from the linked URL
+func recordCanonicalHeaders(link *scanner.Link, resp *http.Response) {
+	base := resp.Request.URL
+
+	link.Canonical = ""
+	link.ContentLocation = ""
+
+	for _, header := range resp.Header.Values("Link") {
+		if canonical := parseCanonicalLinkHeader(header); canonical != "" {
+			link.Canonical = resolveReference(base, canonical)
+			break
+		}
+	}
+
+	if location := resp.Header.Get("Content-Location"); location != "" {
+		link.ContentLocation = resolveReference(base, location)
+	}
+
+	if link.Canonical != "" && differsSubstantially(link.URL, link.Canonical) {
+		link.Warnings = append(link.Warnings, fmt.Sprintf("Non-canonical URL: canonical is %s", link.Canonical))
+	}
+}
+
+// parseCanonicalLinkHeader extracts the rel=canonical target from an HTTP
+// Link header value, which may contain several comma-separated links
+func parseCanonicalLinkHeader(header string) string {
+	for _, entry := range strings.Split(header, ",") {
+		parts := strings.Split(entry, ";")
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+				if strings.EqualFold(rel, "canonical") {
+					return strings.Trim(target, "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// resolveReference resolves a possibly relative header URL against the URL
+// of the request that produced it
+func resolveReference(base *url.URL, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil || base == nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+// differsSubstantially reports whether two URLs point at different resources,
+// ignoring scheme, a leading "www.", trailing slashes, and query strings
+func differsSubstantially(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a != b
+	}
+
+	hostA := strings.TrimPrefix(strings.ToLower(ua.Hostname()), "www.")
+	hostB := strings.TrimPrefix(strings.ToLower(ub.Hostname()), "www.")
+	if hostA != hostB {
+		return true
+	}
+
+	return strings.TrimSuffix(ua.Path, "/") != strings.TrimSuffix(ub.Path, "/")
+}
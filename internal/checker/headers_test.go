@@ -0,0 +1,51 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestRecordCanonicalHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mirror":
+			w.Header().Add("Link", `<https://origin.example.com/article>; rel="canonical"`)
+		case "/same":
+			w.Header().Add("Link", `</style.css>; rel=preload, </same/>; rel="canonical"`)
+			w.Header().Set("Content-Location", "/same.en.html")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	mirror := &scanner.Link{URL: server.URL + "/mirror"}
+	if err := checkExternalLink(client, mirror, Options{}); err != nil {
+		t.Fatalf("checkExternalLink failed: %v", err)
+	}
+	if mirror.Canonical != "https://origin.example.com/article" {
+		t.Errorf("Unexpected canonical: %q", mirror.Canonical)
+	}
+	if len(mirror.Warnings) != 1 {
+		t.Errorf("Expected one non-canonical warning, got %v", mirror.Warnings)
+	}
+
+	same := &scanner.Link{URL: server.URL + "/same"}
+	if err := checkExternalLink(client, same, Options{}); err != nil {
+		t.Fatalf("checkExternalLink failed: %v", err)
+	}
+	if same.Canonical != server.URL+"/same/" {
+		t.Errorf("Unexpected canonical: %q", same.Canonical)
+	}
+	if same.ContentLocation != server.URL+"/same.en.html" {
+		t.Errorf("Unexpected content location: %q", same.ContentLocation)
+	}
+	if len(same.Warnings) != 0 {
+		t.Errorf("Expected no warnings for trailing-slash canonical, got %v", same.Warnings)
+	}
+}
@@ -0,0 +1,138 @@
+package checker
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder for image.DecodeConfig
+	_ "image/jpeg" // register JPEG decoder for image.DecodeConfig
+	_ "image/png"  // register PNG decoder for image.DecodeConfig
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// maxImageHeaderBytes bounds how much of a remote image is read when
+// decoding its dimensions
+const maxImageHeaderBytes = 64 * 1024
+
+// isImagePath reports whether a path has an extension whose dimensions can be decoded
+func isImagePath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		return true
+	default:
+		return false
+	}
+}
+
+// recordLocalImageDimensions decodes the header of a local image file and
+// stores its dimensions on the link
+func recordLocalImageDimensions(link *scanner.Link, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", path, closeErr)
+		}
+	}()
+
+	decodeImageDimensions(link, f)
+}
+
+// recordRemoteImageDimensions fetches the start of a remote image and stores
+// its dimensions on the link
+func recordRemoteImageDimensions(client *http.Client, link *scanner.Link) {
+	req, err := http.NewRequest(http.MethodGet, link.URL, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", maxImageHeaderBytes-1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode >= 400 {
+		return
+	}
+	decodeImageDimensions(link, io.LimitReader(resp.Body, maxImageHeaderBytes))
+}
+
+// decodeImageDimensions reads an image header and stores its dimensions
+func decodeImageDimensions(link *scanner.Link, r io.Reader) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return
+	}
+	link.Width = config.Width
+	link.Height = config.Height
+}
+
+// derivativePattern matches the file name Hugo gives an image derived from a
+// resource by image processing, such as
+// photo_hu3d03a01dcc18bc5be0e67db3d8d209a6_92785_640x0_resize_q75_box.jpg or
+// photo_hu_e45c84f3bf1b6a8b.webp, capturing the resource's name (photo)
+var derivativePattern = regexp.MustCompile(`^(.+?)_hu_?[0-9a-f]{8,}(?:_[^.]*)?\.[A-Za-z0-9]+$`)
+
+// checkBundleImage resolves a relative image source against the directory of
+// the page that uses it, where Hugo keeps the resources of a page bundle, and
+// reports whether the image was found there. A processed derivative, e.g.
+// copied from the built site, is found as the resource it is made from,
+// whatever its format. Other images are resolved like any internal link, e.g.
+// in static/.
+func checkBundleImage(link *scanner.Link, file *scanner.File, opts Options) bool {
+	imagePath := link.URL
+	if idx := strings.IndexAny(imagePath, "?#"); idx != -1 {
+		imagePath = imagePath[:idx]
+	}
+	if imagePath == "" || strings.HasPrefix(imagePath, "/") {
+		return false
+	}
+	if decoded, err := url.PathUnescape(imagePath); err == nil {
+		imagePath = decoded
+	}
+
+	found := filepath.Join(filepath.Dir(file.Path), filepath.FromSlash(imagePath))
+	if info, err := os.Stat(found); err != nil || info.IsDir() {
+		if found = findDerivativeSource(found); found == "" {
+			return false
+		}
+	}
+	link.StatusCode = 200
+	link.ErrorMessage = ""
+	if opts.ImageDimensions && isImagePath(found) {
+		recordLocalImageDimensions(link, found)
+	}
+	return true
+}
+
+// findDerivativeSource returns the resource an image processing derivative
+// at path is made from, or "" if path isn't a derivative or its resource
+// isn't next to it
+func findDerivativeSource(path string) string {
+	match := derivativePattern.FindStringSubmatch(filepath.Base(path))
+	if match == nil {
+		return ""
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.TrimSuffix(name, filepath.Ext(name)) == match[1] {
+			return filepath.Join(filepath.Dir(path), name)
+		}
+	}
+	return ""
+}
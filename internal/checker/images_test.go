@@ -0,0 +1,126 @@
+package checker
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageDimensions(t *testing.T) {
+	tmpDir := t.TempDir()
+	staticDir := filepath.Join(tmpDir, "static", "images")
+	if err := os.MkdirAll(staticDir, 0755); err != nil {
+		t.Fatalf("Failed to create static directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(staticDir, "logo.png"), encodeTestPNG(t, 32, 16), 0644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	remote := encodeTestPNG(t, 1200, 630)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		_, _ = w.Write(remote)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	opts := Options{RootDir: tmpDir, ImageDimensions: true}
+
+	local := &scanner.Link{URL: "/images/logo.png", Type: scanner.LinkTypeInternal}
+	if err := checkInternalLink(local, client, opts); err != nil {
+		t.Fatalf("checkInternalLink failed: %v", err)
+	}
+	if local.Width != 32 || local.Height != 16 {
+		t.Errorf("Expected local dimensions 32x16, got %dx%d", local.Width, local.Height)
+	}
+
+	external := &scanner.Link{URL: server.URL + "/og.png", Type: scanner.LinkTypeExternal}
+	if err := checkExternalLink(client, external, opts); err != nil {
+		t.Fatalf("checkExternalLink failed: %v", err)
+	}
+	if external.Width != 1200 || external.Height != 630 {
+		t.Errorf("Expected remote dimensions 1200x630, got %dx%d", external.Width, external.Height)
+	}
+}
+
+func TestCheckLinks_BundleImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	bundleDir := filepath.Join(tmpDir, "content", "posts", "trip")
+	staticDir := filepath.Join(tmpDir, "static", "images")
+	for _, dir := range []string{bundleDir, staticDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(bundleDir, "index.md"),
+		filepath.Join(bundleDir, "map.png"),
+		filepath.Join(bundleDir, "cover.png"),
+		filepath.Join(bundleDir, "sunset.jpg"),
+		filepath.Join(staticDir, "logo.png"),
+	} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	image := func(url string) scanner.Link {
+		link := scanner.NewLink(url)
+		link.Kind = scanner.KindImage
+		return link
+	}
+	file := &scanner.File{
+		Path: filepath.Join(bundleDir, "index.md"),
+		Links: []scanner.Link{
+			image("map.png"),
+			image("map.png?v=2"),
+			image("/images/logo.png"),
+			image("missing.png"),
+			image("/images/missing.png"),
+			{URL: "cover.png", Kind: scanner.KindFrontMatter},
+			image("sunset_hu3d03a01dcc18bc5be0e67db3d8d209a6_92785_640x0_resize_q75_box.jpg"),
+			image("sunset_hu_e45c84f3bf1b6a8b.webp"),
+			image("missing_hu_e45c84f3bf1b6a8b.webp"),
+		},
+	}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: tmpDir}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	expected := map[string]int{
+		"map.png":             200,
+		"map.png?v=2":         200,
+		"/images/logo.png":    200,
+		"missing.png":         404,
+		"/images/missing.png": 404,
+		"cover.png":           200,
+		"sunset_hu3d03a01dcc18bc5be0e67db3d8d209a6_92785_640x0_resize_q75_box.jpg": 200,
+		"sunset_hu_e45c84f3bf1b6a8b.webp":                                          200,
+		"missing_hu_e45c84f3bf1b6a8b.webp":                                         404,
+	}
+	for _, link := range file.Links {
+		if link.StatusCode != expected[link.URL] {
+			t.Errorf("%s: expected status %d, got %d (%s)", link.URL, expected[link.URL], link.StatusCode, link.ErrorMessage)
+		}
+	}
+}
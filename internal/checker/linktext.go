@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// DefaultGenericLinkTexts are link texts that say nothing about the target,
+// which hurts screen reader users and search ranking
+var DefaultGenericLinkTexts = []string{
+	"click here", "here", "click", "link", "this link", "this", "this page",
+	"read more", "more", "learn more", "more info", "more information",
+	"details", "go", "see here", "page",
+}
+
+var (
+	tagRegex     = regexp.MustCompile(`<[^>]+>`)
+	bareURLRegex = regexp.MustCompile(`^(?i)(https?://|www\.)\S+$`)
+)
+
+// lintLinkText warns about links whose text is generic or a bare URL
+func lintLinkText(files []*scanner.File, extraGeneric []string) {
+	generic := slices.Clone(DefaultGenericLinkTexts)
+	for _, phrase := range extraGeneric {
+		generic = append(generic, strings.ToLower(strings.TrimSpace(phrase)))
+	}
+
+	for _, file := range files {
+		for i := range file.Links {
+			link := &file.Links[i]
+			if warning := linkTextWarning(link.Text, generic); warning != "" {
+				link.Warnings = append(link.Warnings, warning)
+			}
+		}
+	}
+}
+
+// linkTextWarning returns the lint warning for a link text, if any. Links
+// without text, such as <link> elements and image links, are not linted.
+func linkTextWarning(linkText string, generic []string) string {
+	text := strings.TrimSpace(tagRegex.ReplaceAllString(linkText, ""))
+	if text == "" {
+		return ""
+	}
+
+	if slices.Contains(generic, strings.ToLower(strings.Trim(text, " .!:…»›→"))) {
+		return fmt.Sprintf("Generic link text %q", text)
+	}
+	if bareURLRegex.MatchString(text) {
+		return "Bare URL as link text"
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestLinkTextWarning(t *testing.T) {
+	generic := append(DefaultGenericLinkTexts, "hier klicken")
+
+	testCases := map[string]bool{
+		"Click here":                     true,
+		"<strong>read more</strong> »":   true,
+		"hier klicken":                   true,
+		"https://example.com/some/page":  true,
+		"www.example.com":                true,
+		"Installation guide":             false,
+		"Read more about the release":    false,
+		"":                               false,
+		`<img src="/logo.png" alt="Us">`: false,
+	}
+
+	for text, expected := range testCases {
+		if got := linkTextWarning(text, generic) != ""; got != expected {
+			t.Errorf("%q: expected warning %v, got %v", text, expected, got)
+		}
+	}
+}
+
+func TestCheckLinks_LinkTextLint(t *testing.T) {
+	file := &scanner.File{
+		Path: "content/index.md",
+		Links: []scanner.Link{
+			{URL: "https://example.com/", Type: scanner.LinkTypeExternal, Text: "here"},
+			{URL: "https://example.com/docs", Type: scanner.LinkTypeExternal, Text: "the docs"},
+		},
+	}
+
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{LinkTextLint: true}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	if len(file.Links[0].Warnings) != 1 || len(file.Links[1].Warnings) != 0 {
+		t.Errorf("Expected a warning on the generic link only, got %v and %v", file.Links[0].Warnings, file.Links[1].Warnings)
+	}
+}
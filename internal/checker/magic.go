@@ -0,0 +1,122 @@
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// magicHeaderBytes is how much of a target is read to verify its signature
+const magicHeaderBytes = 512
+
+// magicNumbers maps document and binary extensions to the signatures their
+// content must start with. A link to a ".pdf" that serves an HTML error
+// page is broken even if the server answers 200.
+var magicNumbers = map[string][][]byte{
+	".pdf":  {[]byte("%PDF-")},
+	".zip":  {[]byte("PK\x03\x04"), []byte("PK\x05\x06")},
+	".docx": {[]byte("PK\x03\x04")},
+	".xlsx": {[]byte("PK\x03\x04")},
+	".pptx": {[]byte("PK\x03\x04")},
+	".epub": {[]byte("PK\x03\x04")},
+	".gz":   {[]byte("\x1f\x8b")},
+	".tgz":  {[]byte("\x1f\x8b")},
+	".png":  {[]byte("\x89PNG\r\n\x1a\n")},
+	".jpg":  {[]byte("\xff\xd8\xff")},
+	".jpeg": {[]byte("\xff\xd8\xff")},
+	".gif":  {[]byte("GIF87a"), []byte("GIF89a")},
+	".doc":  {[]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")},
+	".xls":  {[]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")},
+	".ppt":  {[]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")},
+}
+
+// magicExtension returns the lower-cased extension of a link target if its
+// content signature can be verified, or "" otherwise
+func magicExtension(target string) string {
+	if u, err := url.Parse(target); err == nil {
+		target = u.Path
+	}
+	ext := strings.ToLower(filepath.Ext(target))
+	if _, ok := magicNumbers[ext]; ok {
+		return ext
+	}
+	return ""
+}
+
+// matchesMagic reports whether header starts with one of the signatures for ext
+func matchesMagic(ext string, header []byte) bool {
+	for _, magic := range magicNumbers[ext] {
+		if bytes.HasPrefix(header, magic) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordMagicMismatch marks the link broken when the header doesn't match
+// the signature expected for ext
+func recordMagicMismatch(link *scanner.Link, ext string, header []byte) {
+	if len(header) == 0 || matchesMagic(ext, header) {
+		return
+	}
+	link.StatusCode = 0
+	link.ErrorMessage = fmt.Sprintf("Content does not match %s file type (detected %s)", ext, http.DetectContentType(header))
+	link.ErrorCategory = scanner.CategoryContentMismatch
+}
+
+// verifyLocalMagic checks the signature of a resolved local file
+func verifyLocalMagic(link *scanner.Link, path string) {
+	ext := magicExtension(path)
+	if ext == "" {
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", path, closeErr)
+		}
+	}()
+
+	header := make([]byte, magicHeaderBytes)
+	n, _ := io.ReadFull(f, header)
+	recordMagicMismatch(link, ext, header[:n])
+}
+
+// verifyRemoteMagic fetches the first bytes of an external target and checks its signature
+func verifyRemoteMagic(client *http.Client, link *scanner.Link) {
+	ext := magicExtension(link.URL)
+	if ext == "" {
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodGet, link.URL, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", magicHeaderBytes-1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode >= 400 {
+		return
+	}
+
+	header := make([]byte, magicHeaderBytes)
+	n, _ := io.ReadFull(resp.Body, header)
+	recordMagicMismatch(link, ext, header[:n])
+}
@@ -0,0 +1,65 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestVerifyContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/real.pdf":
+			_, _ = w.Write([]byte("%PDF-1.7\n..."))
+		default:
+			// Soft error page served with 200
+			_, _ = w.Write([]byte("<!DOCTYPE html><html><body>Not here</body></html>"))
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	staticDir := filepath.Join(tmpDir, "static")
+	if err := os.MkdirAll(staticDir, 0755); err != nil {
+		t.Fatalf("Failed to create static directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(staticDir, "fake.pdf"), []byte("<html>oops</html>"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	opts := Options{RootDir: tmpDir, VerifyContent: true}
+
+	testCases := []struct {
+		link   scanner.Link
+		broken bool
+	}{
+		{scanner.Link{URL: server.URL + "/real.pdf", Type: scanner.LinkTypeExternal}, false},
+		{scanner.Link{URL: server.URL + "/fake.pdf", Type: scanner.LinkTypeExternal}, true},
+		{scanner.Link{URL: server.URL + "/page.html", Type: scanner.LinkTypeExternal}, false},
+		{scanner.Link{URL: "/fake.pdf", Type: scanner.LinkTypeInternal}, true},
+	}
+
+	for _, tc := range testCases {
+		link := tc.link
+		var err error
+		if link.Type == scanner.LinkTypeExternal {
+			err = checkExternalLink(client, &link, opts)
+		} else {
+			err = checkInternalLink(&link, client, opts)
+		}
+		if err != nil {
+			t.Fatalf("check failed for %s: %v", link.URL, err)
+		}
+
+		broken := CountBrokenLinks([]*scanner.File{{Links: []scanner.Link{link}}}) > 0
+		if broken != tc.broken {
+			t.Errorf("%s: expected broken=%v, got %v (%s)", link.URL, tc.broken, broken, link.ErrorMessage)
+		}
+	}
+}
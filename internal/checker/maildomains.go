@@ -0,0 +1,152 @@
+package checker
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// mailDomainConcurrency bounds the mail domain lookups running at once
+const mailDomainConcurrency = 8
+
+// mailDomainResolver looks up the domains of mailto links, each domain once
+// per run. Lookups started by prefetch run in the background while other
+// links are checked, so mailto links don't wait on DNS one after another.
+type mailDomainResolver struct {
+	// lookup resolves a domain, returning an error if it has no MX or
+	// address records
+	lookup func(domain string) error
+
+	mu      sync.Mutex
+	results map[string]*mailDomainResult
+	slots   chan struct{}
+}
+
+// mailDomainResult is the outcome of a domain lookup; done is closed once
+// err is set
+type mailDomainResult struct {
+	done chan struct{}
+	err  error
+}
+
+func newMailDomainResolver() *mailDomainResolver {
+	return &mailDomainResolver{
+		lookup:  lookupMailDomain,
+		results: make(map[string]*mailDomainResult),
+		slots:   make(chan struct{}, mailDomainConcurrency),
+	}
+}
+
+// lookupMailDomain checks that a domain can receive mail: it has MX
+// records, or failing that an address
+func lookupMailDomain(domain string) error {
+	if _, err := net.LookupMX(domain); err != nil {
+		_, err = net.LookupHost(domain)
+		return err
+	}
+	return nil
+}
+
+// start begins the lookup of a domain unless it has been started already
+func (r *mailDomainResolver) start(domain string) *mailDomainResult {
+	domain = strings.ToLower(domain)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if result, ok := r.results[domain]; ok {
+		return result
+	}
+	result := &mailDomainResult{done: make(chan struct{})}
+	r.results[domain] = result
+	go func() {
+		r.slots <- struct{}{}
+		result.err = r.lookup(domain)
+		<-r.slots
+		close(result.done)
+	}()
+	return result
+}
+
+// resolve returns the result of looking up a domain, waiting for a lookup
+// in progress. A nil resolver looks the domain up directly.
+func (r *mailDomainResolver) resolve(domain string) error {
+	if r == nil {
+		return lookupMailDomain(domain)
+	}
+	result := r.start(domain)
+	<-result.done
+	return result.err
+}
+
+// prefetch starts looking up the domains of the mailto links in files
+func (r *mailDomainResolver) prefetch(files []*scanner.File) {
+	for _, file := range files {
+		for _, link := range file.Links {
+			if link.Ignored || !strings.HasPrefix(link.URL, "mailto:") {
+				continue
+			}
+			recipients, _ := mailtoRecipients(link.URL)
+			for _, domain := range mailtoDomains(recipients) {
+				r.start(domain)
+			}
+		}
+	}
+}
+
+// mailtoRecipients returns the addresses of a mailto URL (RFC 6068): the
+// comma-separated addresses before the "?" and those of its to, cc, and bcc
+// headers, percent-decoded. An address with an empty local part or domain,
+// or an "@" outside a quoted local part, is an error.
+func mailtoRecipients(linkURL string) ([]string, error) {
+	if len(linkURL) < len("mailto:") || !strings.EqualFold(linkURL[:len("mailto:")], "mailto:") {
+		return nil, fmt.Errorf("not a mailto URL")
+	}
+	to, query, _ := strings.Cut(linkURL[len("mailto:"):], "?")
+	lists := []string{to}
+	for _, header := range strings.Split(query, "&") {
+		name, value, _ := strings.Cut(header, "=")
+		switch strings.ToLower(name) {
+		case "to", "cc", "bcc":
+			lists = append(lists, value)
+		}
+	}
+
+	var recipients []string
+	for _, list := range lists {
+		for _, encoded := range strings.Split(list, ",") {
+			address, err := url.PathUnescape(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("invalid percent-encoding in %q", encoded)
+			}
+			if address = strings.TrimSpace(address); address == "" {
+				continue
+			}
+			// Only a quoted local part may contain "@"
+			at := strings.LastIndex(address, "@")
+			local, domain := address[:max(at, 0)], address[at+1:]
+			quoted := len(local) > 1 && local[0] == '"' && local[len(local)-1] == '"'
+			if at <= 0 || domain == "" || (strings.Contains(local, "@") && !quoted) {
+				return nil, fmt.Errorf("invalid email address %q", address)
+			}
+			recipients = append(recipients, address)
+		}
+	}
+	return recipients, nil
+}
+
+// mailtoDomains returns the distinct domains of the recipients of a mailto
+// URL, lowercased, in order of appearance
+func mailtoDomains(recipients []string) []string {
+	var domains []string
+	for _, address := range recipients {
+		domain := strings.ToLower(address[strings.LastIndex(address, "@")+1:])
+		if !slices.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
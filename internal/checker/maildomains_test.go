@@ -0,0 +1,115 @@
+package checker
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestMailDomainResolver(t *testing.T) {
+	var mu sync.Mutex
+	lookups := make(map[string]int)
+	// Every lookup waits until three are running, so they must run
+	// concurrently to finish
+	var running sync.WaitGroup
+	running.Add(3)
+	resolver := newMailDomainResolver()
+	resolver.lookup = func(domain string) error {
+		mu.Lock()
+		lookups[domain]++
+		mu.Unlock()
+		running.Done()
+		running.Wait()
+		if domain == "nowhere.invalid" {
+			return errors.New("no such host")
+		}
+		return nil
+	}
+
+	files := []*scanner.File{{
+		Path: "contact.md",
+		Links: []scanner.Link{
+			scanner.NewLink("mailto:alice@example.com"),
+			scanner.NewLink("mailto:bob@Example.com"),
+			scanner.NewLink("mailto:carol@example.org"),
+			scanner.NewLink("mailto:dave@nowhere.invalid"),
+			scanner.NewLink("mailto:invalid-email"),
+			scanner.NewLink("https://example.com/"),
+		},
+	}}
+
+	done := make(chan struct{})
+	go func() {
+		resolver.prefetch(files)
+		for i := range files[0].Links[:5] {
+			if err := checkMailtoLink(&files[0].Links[i], resolver); err != nil {
+				t.Errorf("checkMailtoLink failed: %v", err)
+			}
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lookups did not run concurrently")
+	}
+
+	for domain, count := range lookups {
+		if count != 1 {
+			t.Errorf("%s: expected one lookup, got %d", domain, count)
+		}
+	}
+	if len(lookups) != 3 {
+		t.Errorf("expected 3 domains looked up, got %v", lookups)
+	}
+	for i, want := range []int{200, 200, 200, 0, 0} {
+		if files[0].Links[i].StatusCode != want {
+			t.Errorf("%s: expected status %d, got %d", files[0].Links[i].URL, want, files[0].Links[i].StatusCode)
+		}
+	}
+	if files[0].Links[3].ErrorCategory != scanner.CategoryDNS {
+		t.Errorf("expected a DNS failure for an unknown domain, got %+v", files[0].Links[3])
+	}
+}
+
+func TestMailtoRecipients(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected []string
+		domains  []string
+		invalid  bool
+	}{
+		{"mailto:alice@x.com", []string{"alice@x.com"}, []string{"x.com"}, false},
+		{"mailto:alice@x.com,bob@y.com?subject=Hi", []string{"alice@x.com", "bob@y.com"}, []string{"x.com", "y.com"}, false},
+		{"MAILTO:alice@X.com,%20carol@x.com", []string{"alice@X.com", "carol@x.com"}, []string{"x.com"}, false},
+		{"mailto:?to=alice@x.com&cc=bob@y.com&BCC=dave@z.com&body=a%2Cb", []string{"alice@x.com", "bob@y.com", "dave@z.com"}, []string{"x.com", "y.com", "z.com"}, false},
+		{"mailto:%22not%40me%22@example.org", []string{`"not@me"@example.org`}, []string{"example.org"}, false},
+		{"mailto:?subject=Hi", nil, nil, false},
+		{"mailto:invalid-email", nil, nil, true},
+		{"mailto:alice@x.com,@y.com", nil, nil, true},
+		{"mailto:alice%zz@x.com", nil, nil, true},
+	}
+	for _, tc := range testCases {
+		recipients, err := mailtoRecipients(tc.url)
+		if tc.invalid {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q", tc.url, recipients)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.url, err)
+			continue
+		}
+		if !slices.Equal(recipients, tc.expected) {
+			t.Errorf("%s: expected recipients %q, got %q", tc.url, tc.expected, recipients)
+		}
+		if domains := mailtoDomains(recipients); !slices.Equal(domains, tc.domains) {
+			t.Errorf("%s: expected domains %q, got %q", tc.url, tc.domains, domains)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package checker
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MethodStrategy selects which HTTP method(s) are used to check external links
+type MethodStrategy string
+
+const (
+	// MethodHeadThenGet sends HEAD and falls back to GET if HEAD fails or is
+	// rejected by the server
+	MethodHeadThenGet MethodStrategy = "head-then-get"
+	// MethodGetOnly always sends GET
+	MethodGetOnly MethodStrategy = "get"
+	// MethodHeadOnly always sends HEAD and never falls back
+	MethodHeadOnly MethodStrategy = "head"
+)
+
+// ParseMethodStrategy converts a strategy name into a MethodStrategy
+func ParseMethodStrategy(name string) (MethodStrategy, error) {
+	switch strategy := MethodStrategy(strings.ToLower(strings.TrimSpace(name))); strategy {
+	case MethodHeadThenGet, MethodGetOnly, MethodHeadOnly:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid method strategy %q: must be head-then-get, get, or head", name)
+	}
+}
+
+// ParseDomainMethods parses a comma-separated list of domain=strategy pairs
+func ParseDomainMethods(spec string) (map[string]MethodStrategy, error) {
+	overrides := make(map[string]MethodStrategy)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		domain, name, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid method override %q: expected domain=strategy", entry)
+		}
+
+		strategy, err := ParseMethodStrategy(name)
+		if err != nil {
+			return nil, err
+		}
+		overrides[strings.ToLower(strings.TrimSpace(domain))] = strategy
+	}
+	return overrides, nil
+}
+
+// methodFor returns the method strategy to use for the given URL, applying
+// any per-domain override that matches the host or one of its parent domains
+func (opts Options) methodFor(linkURL string) MethodStrategy {
+	if len(opts.DomainMethods) > 0 {
+		if u, err := url.Parse(linkURL); err == nil {
+			host := strings.ToLower(u.Hostname())
+			for host != "" {
+				if strategy, ok := opts.DomainMethods[host]; ok {
+					return strategy
+				}
+				_, parent, found := strings.Cut(host, ".")
+				if !found {
+					break
+				}
+				host = parent
+			}
+		}
+	}
+
+	if opts.MethodStrategy == "" {
+		return MethodHeadThenGet
+	}
+	return opts.MethodStrategy
+}
+
+// headNotSupported reports whether a HEAD response status indicates the
+// server doesn't handle HEAD and a GET should be attempted instead
+func headNotSupported(statusCode int) bool {
+	return statusCode == http.StatusMethodNotAllowed || statusCode == http.StatusNotImplemented
+}
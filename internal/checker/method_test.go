@@ -0,0 +1,75 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckExternalLink_MethodStrategy(t *testing.T) {
+	// Server that rejects HEAD requests
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	testCases := []struct {
+		opts           Options
+		expectedStatus int
+		expectedMethod string
+	}{
+		{Options{}, 200, http.MethodGet},
+		{Options{MethodStrategy: MethodHeadOnly}, 405, http.MethodHead},
+		{Options{MethodStrategy: MethodGetOnly}, 200, http.MethodGet},
+		{Options{MethodStrategy: MethodHeadOnly, DomainMethods: map[string]MethodStrategy{"127.0.0.1": MethodGetOnly}}, 200, http.MethodGet},
+	}
+
+	for _, tc := range testCases {
+		link := &scanner.Link{URL: server.URL}
+		if err := checkExternalLink(client, link, tc.opts); err != nil {
+			t.Fatalf("checkExternalLink failed: %v", err)
+		}
+		if link.StatusCode != tc.expectedStatus {
+			t.Errorf("%+v: expected status %d, got %d", tc.opts, tc.expectedStatus, link.StatusCode)
+		}
+		if link.Method != tc.expectedMethod {
+			t.Errorf("%+v: expected method %s, got %s", tc.opts, tc.expectedMethod, link.Method)
+		}
+	}
+}
+
+func TestParseDomainMethods(t *testing.T) {
+	overrides, err := ParseDomainMethods("example.com=get, cdn.example.org=head")
+	if err != nil {
+		t.Fatalf("ParseDomainMethods failed: %v", err)
+	}
+
+	opts := Options{MethodStrategy: MethodHeadThenGet, DomainMethods: overrides}
+	testCases := map[string]MethodStrategy{
+		"https://example.com/page":         MethodGetOnly,
+		"https://www.example.com/page":     MethodGetOnly,
+		"https://cdn.example.org/file.js":  MethodHeadOnly,
+		"https://other.example.org/page":   MethodHeadThenGet,
+		"https://unrelated.test/something": MethodHeadThenGet,
+	}
+	for linkURL, expected := range testCases {
+		if got := opts.methodFor(linkURL); got != expected {
+			t.Errorf("%s: expected %s, got %s", linkURL, expected, got)
+		}
+	}
+
+	for _, spec := range []string{"example.com", "example.com=post"} {
+		if _, err := ParseDomainMethods(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/hugo"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// movedPage is a content page whose front matter url or slug, or its
+// section's permalinks pattern, moves it away from the path Hugo derives
+// from its file name
+type movedPage struct {
+	// source is the content file, relative to the site root
+	source string
+	// file is the content file as found while walking the content tree
+	file string
+	// permalink is the path the page is served at
+	permalink string
+}
+
+// movedPages indexes the moved pages of a site
+type movedPages struct {
+	// byOldPath indexes them by the path their file name gives
+	byOldPath map[string]movedPage
+	// byPermalink indexes them by the path they are served at, which no
+	// content file name gives
+	byPermalink map[string]movedPage
+}
+
+// findMovedPages reads the front matter of the site's content files,
+// including the values cascaded from their sections, and returns the pages
+// moved by url, slug, or permalinks pattern. Old paths that are still served,
+// as another page or as one of the page's aliases, are left out.
+func findMovedPages(opts Options) movedPages {
+	siteRoot := scanner.SiteRoot(opts.RootDir)
+	dirs := []string{"content"}
+	for _, dir := range opts.ContentDirs {
+		if dir != "content" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	moved := movedPages{byOldPath: make(map[string]movedPage), byPermalink: make(map[string]movedPage)}
+	served := make(map[string]bool)
+	for _, dir := range dirs {
+		contentDir := filepath.Join(siteRoot, dir)
+		err := filepath.Walk(contentDir, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if info.IsDir() || !scanner.IsContentFile(filePath) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(contentDir, filePath)
+			if err != nil {
+				return err
+			}
+			fm, err := opts.pageFrontMatter(contentDir, rel, filePath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			implicit := opts.URLs.FileURL(hugo.Page{Path: rel, ContentDir: dir})
+			permalink := opts.URLs.PageURL(hugo.Page{Path: rel, ContentDir: dir, FrontMatter: fm})
+			served[normalizePagePath(permalink)] = true
+			for _, alias := range fm.Strings("aliases") {
+				if !strings.HasPrefix(alias, "/") {
+					alias = path.Join(path.Dir(strings.TrimSuffix(implicit, "/")), alias)
+				}
+				served[normalizePagePath(alias)] = true
+			}
+			if permalink == implicit {
+				return nil
+			}
+
+			source, err := filepath.Rel(siteRoot, filePath)
+			if err != nil {
+				source = filePath
+			}
+			page := movedPage{source: filepath.ToSlash(source), file: filePath, permalink: permalink}
+			moved.byOldPath[normalizePagePath(implicit)] = page
+			moved.byPermalink[normalizePagePath(permalink)] = page
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read front matter in %s: %v\n", contentDir, err)
+		}
+	}
+
+	for oldPath := range moved.byOldPath {
+		if served[oldPath] {
+			delete(moved.byOldPath, oldPath)
+		}
+	}
+	return moved
+}
+
+// servedPage returns the content file of the moved page served at linkPath,
+// which resolves a link to the url or slug a page sets in its front matter
+func (moved movedPages) servedPage(linkPath string) string {
+	if !strings.HasPrefix(linkPath, "/") {
+		return ""
+	}
+	return moved.byPermalink[normalizePagePath(linkPath)].file
+}
+
+// normalizePagePath lowercases a site path and gives page paths (those
+// without a file extension) a trailing slash. The .html paths of uglyURLs
+// are taken as the same page as the directory form.
+func normalizePagePath(pagePath string) string {
+	pagePath = "/" + strings.TrimLeft(strings.ToLower(pagePath), "/")
+	if strings.HasSuffix(pagePath, "/index.html") {
+		pagePath = strings.TrimSuffix(pagePath, "index.html")
+	} else if strings.HasSuffix(pagePath, ".html") {
+		pagePath = strings.TrimSuffix(pagePath, ".html") + "/"
+	}
+	if path.Ext(pagePath) == "" && !strings.HasSuffix(pagePath, "/") {
+		pagePath += "/"
+	}
+	return pagePath
+}
+
+// lintStaleLink marks a link broken when it uses the old path of a moved
+// page: the content file resolves locally, but Hugo serves the page
+// elsewhere. The new path is recorded as the fix.
+func lintStaleLink(link *scanner.Link, linkPath string, moved movedPages) {
+	if !strings.HasPrefix(linkPath, "/") {
+		return
+	}
+	page, ok := moved.byOldPath[normalizePagePath(linkPath)]
+	if !ok {
+		return
+	}
+
+	link.StatusCode = 404
+	link.ErrorMessage = fmt.Sprintf("Page moved to %s by the url or slug in %s", page.permalink, page.source)
+	link.ErrorCategory = scanner.CategoryStaleURL
+	link.MovedPage = page.source
+	link.Fix = page.permalink
+	if _, fragment, found := strings.Cut(link.URL, "#"); found {
+		link.Fix += "#" + fragment
+	}
+}
@@ -0,0 +1,103 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/hugo"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestStaleLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	pages := map[string]string{
+		"content/posts/old-name.md":   "---\ntitle: Renamed\nslug: new-name\n---\n",
+		"content/posts/aliased.md":    "---\nslug: elsewhere\naliases: [/posts/aliased/]\n---\n",
+		"content/docs/install.md":     "+++\nurl = '/start/'\n+++\n",
+		"content/docs/unchanged.md":   "---\ntitle: Same\n---\n",
+		"content/docs/replacement.md": "---\nurl: /docs/taken/\n---\n",
+		"content/docs/taken.md":       "---\nslug: moved-away\n---\n",
+	}
+	for name, content := range pages {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := &scanner.File{
+		Path: filepath.Join(tmpDir, "content", "index.md"),
+		Links: []scanner.Link{
+			{URL: "/posts/old-name/#usage", Type: scanner.LinkTypeInternal},
+			{URL: "/docs/install", Type: scanner.LinkTypeInternal},
+			{URL: "/posts/aliased/", Type: scanner.LinkTypeInternal},
+			{URL: "/docs/unchanged/", Type: scanner.LinkTypeInternal},
+			{URL: "/docs/taken/", Type: scanner.LinkTypeInternal},
+			{URL: "/posts/new-name/#usage", Type: scanner.LinkTypeInternal},
+			{URL: "/start", Type: scanner.LinkTypeInternal},
+			{URL: "/docs/moved-away/", Type: scanner.LinkTypeInternal},
+		},
+	}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: tmpDir}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	stale := file.Links[0]
+	if stale.ErrorCategory != scanner.CategoryStaleURL || stale.MovedPage != "content/posts/old-name.md" || stale.Fix != "/posts/new-name/#usage" {
+		t.Errorf("Expected a stale link to the renamed post, got %+v", stale)
+	}
+	if install := file.Links[1]; install.ErrorCategory != scanner.CategoryStaleURL || install.Fix != "/start/" {
+		t.Errorf("Expected a stale link to the page moved by url, got %+v", install)
+	}
+	// Links to the old paths still served, and to the paths pages moved
+	// to, pass
+	for _, link := range file.Links[2:] {
+		if link.StatusCode != 200 || link.MovedPage != "" {
+			t.Errorf("Expected %s to pass, got %+v", link.URL, link)
+		}
+	}
+}
+
+func TestStaleLinks_SiteURLs(t *testing.T) {
+	tmpDir := t.TempDir()
+	pages := map[string]string{
+		"hugo.toml":                 "[permalinks]\nposts = \"/:year/:slug/\"\n[languages.en]\n[languages.fr]\n",
+		"content/posts/launch.md":   "---\ntitle: Launch Day\ndate: 2024-05-01\n---\n",
+		"content/posts/renamed.md":  "---\ntitle: Renamed\ndate: 2023-01-02\nslug: better-name\n---\n",
+		"content/about.fr.md":       "---\nslug: a-propos\n---\n",
+		"content/docs/unchanged.md": "",
+	}
+	for name, content := range pages {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	siteConfig, err := hugo.LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{RootDir: tmpDir, URLs: hugo.NewURLResolver(siteConfig)}
+
+	moved := findMovedPages(opts)
+	for _, want := range []string{"/2024/launch-day/", "/2023/better-name/", "/fr/a-propos/"} {
+		if moved.servedPage(want) == "" {
+			t.Errorf("Expected a page served at %s, got %v", want, moved.byPermalink)
+		}
+	}
+	for oldPath, permalink := range map[string]string{"/posts/launch/": "/2024/launch-day/", "/posts/renamed/": "/2023/better-name/", "/fr/about/": "/fr/a-propos/"} {
+		if page, ok := moved.byOldPath[oldPath]; !ok || page.permalink != permalink {
+			t.Errorf("Expected %s to have moved to %s, got %v", oldPath, permalink, moved.byOldPath)
+		}
+	}
+	if moved.servedPage("/docs/unchanged/") != "" {
+		t.Errorf("Expected pages at their file's path not to be moved, got %v", moved.byPermalink)
+	}
+}
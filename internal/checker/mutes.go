@@ -0,0 +1,99 @@
+package checker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// muteDateLayout is the date format of the until column of a mutes file
+const muteDateLayout = "2006-01-02"
+
+// Mute silences failures of the links matching Pattern through the end of
+// Until, e.g. while a vendor site is under maintenance. Muted links are still
+// checked and reported, but don't count toward the exit code.
+type Mute struct {
+	Pattern *regexp.Regexp
+	Until   string
+}
+
+// LoadMutes reads a mutes file: one regular expression and date
+// (YYYY-MM-DD) per line, with # starting a comment line. Mutes whose date
+// has passed are dropped with a warning, so their links count again.
+func LoadMutes(path string) ([]Mute, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close mutes file: %v\n", closeErr)
+		}
+	}()
+	return parseMutes(file, path, time.Now())
+}
+
+// parseMutes reads the mutes file at path from r, keeping the mutes still
+// active at now
+func parseMutes(r io.Reader, path string, now time.Time) ([]Mute, error) {
+	var mutes []Mute
+	lines := bufio.NewScanner(r)
+	lineNumber := 0
+	for lines.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// The date is the last field; the pattern may contain spaces
+		split := strings.LastIndexAny(line, " \t")
+		if split == -1 {
+			return nil, fmt.Errorf("%s:%d: expected a pattern and an until date", path, lineNumber)
+		}
+		pattern, until := strings.TrimSpace(line[:split]), line[split+1:]
+		date, err := time.ParseInLocation(muteDateLayout, until, now.Location())
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid until date %q, expected YYYY-MM-DD", path, lineNumber, until)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern %q: %v", path, lineNumber, pattern, err)
+		}
+
+		// A mute lasts through its until date
+		if !now.Before(date.AddDate(0, 0, 1)) {
+			fmt.Fprintf(os.Stderr, "Warning: mute of %s expired on %s\n", pattern, until)
+			continue
+		}
+		mutes = append(mutes, Mute{Pattern: re, Until: until})
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mutes file %s: %v", path, err)
+	}
+	return mutes, nil
+}
+
+// applyMutes records the until date of the first matching mute on each link
+func applyMutes(files []*scanner.File, mutes []Mute) {
+	if len(mutes) == 0 {
+		return
+	}
+	for _, file := range files {
+		for i := range file.Links {
+			link := &file.Links[i]
+			for _, mute := range mutes {
+				if mute.Pattern.MatchString(link.URL) {
+					link.MutedUntil = mute.Until
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestParseMutes(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	input := `# Vendor maintenance
+^https://docs\.vendor\.example/   2026-10-23
+^https://today\.example/ 2026-10-16
+^https://expired\.example/ 2026-10-15
+`
+	mutes, err := parseMutes(strings.NewReader(input), "mutes", now)
+	if err != nil {
+		t.Fatalf("parseMutes failed: %v", err)
+	}
+	if len(mutes) != 2 || mutes[0].Until != "2026-10-23" || mutes[1].Until != "2026-10-16" {
+		t.Fatalf("expected the two unexpired mutes, got %+v", mutes)
+	}
+
+	for _, bad := range []string{"^https://no-date/", "( 2026-10-23", "^https://x/ 23.10.2026"} {
+		if _, err := parseMutes(strings.NewReader(bad), "mutes", now); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestApplyMutes(t *testing.T) {
+	mutes, err := parseMutes(strings.NewReader(`^https://docs\.vendor\.example/ 2099-01-01`), "mutes", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []*scanner.File{{
+		Path: "content/page.md",
+		Links: []scanner.Link{
+			{URL: "https://docs.vendor.example/api", StatusCode: 503, ErrorMessage: "HTTP 503"},
+			{URL: "https://other.example/", StatusCode: 404, ErrorMessage: "HTTP 404"},
+		},
+	}}
+	applyMutes(files, mutes)
+
+	if files[0].Links[0].MutedUntil != "2099-01-01" || files[0].Links[1].MutedUntil != "" {
+		t.Errorf("expected only the vendor link muted, got %+v", files[0].Links)
+	}
+	if count := CountBrokenLinks(files); count != 1 {
+		t.Errorf("expected muted links left out of the broken count, got %d", count)
+	}
+	categories := map[scanner.ErrorCategory]bool{scanner.CategoryHTTP5xx: true}
+	files[0].Links[0].ErrorCategory = scanner.CategoryHTTP5xx
+	if count := CountBrokenLinksInCategories(files, categories); count != 0 {
+		t.Errorf("expected muted links left out of the category count, got %d", count)
+	}
+}
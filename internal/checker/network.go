@@ -0,0 +1,93 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewHTTPClient builds the HTTP client used for external and base-URL checks,
+// honoring the IP version and source address options
+func NewHTTPClient(opts Options) (*http.Client, error) {
+	network := "tcp"
+	switch opts.IPVersion {
+	case 0:
+	case 4:
+		network = "tcp4"
+	case 6:
+		network = "tcp6"
+	default:
+		return nil, fmt.Errorf("invalid IP version %d: must be 4 or 6", opts.IPVersion)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	if opts.SourceAddr != "" {
+		ip, err := resolveSourceAddr(opts.SourceAddr, opts.IPVersion)
+		if err != nil {
+			return nil, err
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, _ string, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+	}, nil
+}
+
+// resolveSourceAddr turns a source address option into a local IP. The value
+// may be a literal IP address or the name of a network interface, in which
+// case the first address matching the requested IP version is used.
+func resolveSourceAddr(source string, ipVersion int) (net.IP, error) {
+	if ip := net.ParseIP(source); ip != nil {
+		if !ipMatchesVersion(ip, ipVersion) {
+			return nil, fmt.Errorf("source address %s does not match IPv%d", source, ipVersion)
+		}
+		return ip, nil
+	}
+
+	iface, err := net.InterfaceByName(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source address or interface %q: %w", source, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses of interface %s: %w", source, err)
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipMatchesVersion(ipNet.IP, ipVersion) {
+			return ipNet.IP, nil
+		}
+	}
+
+	return nil, fmt.Errorf("interface %s has no usable address", source)
+}
+
+// ipMatchesVersion reports whether ip belongs to the requested protocol family
+func ipMatchesVersion(ip net.IP, ipVersion int) bool {
+	switch ipVersion {
+	case 4:
+		return ip.To4() != nil
+	case 6:
+		return ip.To4() == nil
+	default:
+		return true
+	}
+}
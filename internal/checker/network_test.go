@@ -0,0 +1,41 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// httptest listens on 127.0.0.1, so an IPv4 client bound to loopback must work
+	client, err := NewHTTPClient(Options{IPVersion: 4, SourceAddr: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+
+	link := &scanner.Link{URL: server.URL}
+	if err := checkExternalLink(client, link, Options{}); err != nil {
+		t.Fatalf("checkExternalLink failed: %v", err)
+	}
+	if link.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d (%s)", link.StatusCode, link.ErrorMessage)
+	}
+
+	invalid := []Options{
+		{IPVersion: 5},
+		{IPVersion: 6, SourceAddr: "127.0.0.1"},
+		{SourceAddr: "no-such-interface0"},
+	}
+	for _, opts := range invalid {
+		if _, err := NewHTTPClient(opts); err == nil {
+			t.Errorf("Expected error for options %+v", opts)
+		}
+	}
+}
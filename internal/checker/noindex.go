@@ -0,0 +1,132 @@
+package checker
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/hugo"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// lintNoindex warns about pages that are linked from at least threshold
+// other pages but are excluded from search engines by a robots noindex, in
+// their front matter (robots: noindex, or noindex: true) or a robots meta
+// tag. Site owners usually want to know they are funneling links into them.
+func lintNoindex(files []*scanner.File, threshold int, opts Options) {
+	if threshold <= 0 {
+		return
+	}
+
+	paths := make(map[*scanner.File]string, len(files))
+	for _, file := range files {
+		if pagePath := filePagePath(file, opts); pagePath != "" {
+			paths[file] = pagePath
+		}
+	}
+
+	// Count the distinct pages linking to each page path. With
+	// -check-public, a page is scanned both as content and as generated HTML.
+	linkers := make(map[string]map[string]bool)
+	for _, file := range files {
+		source, ok := paths[file]
+		if !ok {
+			source = file.Path
+		}
+		base, err := url.Parse(paths[file])
+		if err != nil {
+			continue
+		}
+		for _, link := range file.Links {
+			if link.Type != scanner.LinkTypeInternal || link.StatusCode >= 400 || strings.HasPrefix(link.URL, "#") {
+				continue
+			}
+			ref, err := url.Parse(link.URL)
+			if err != nil {
+				continue
+			}
+			target := normalizePagePath(base.ResolveReference(ref).Path)
+			if target == source {
+				continue
+			}
+			if linkers[target] == nil {
+				linkers[target] = make(map[string]bool)
+			}
+			linkers[target][source] = true
+		}
+	}
+
+	warned := make(map[string]bool)
+	for _, file := range files {
+		pagePath, ok := paths[file]
+		if !ok || warned[pagePath] || len(linkers[pagePath]) < threshold || !isNoindex(file) {
+			continue
+		}
+		warned[pagePath] = true
+		file.Warnings = append(file.Warnings, fmt.Sprintf("Page is marked noindex but %d pages link to it", len(linkers[pagePath])))
+	}
+}
+
+// filePagePath returns the URL path of the page a scanned file produces: for
+// content files, the path given by their location and front matter; for
+// generated files in public/, the path they are served at. Other files yield
+// the empty string.
+func filePagePath(file *scanner.File, opts Options) string {
+	filePath, err := filepath.Abs(file.Path)
+	if err != nil {
+		return ""
+	}
+	siteRoot, err := filepath.Abs(scanner.SiteRoot(opts.RootDir))
+	if err != nil {
+		return ""
+	}
+
+	if rel, ok := relativeTo(filepath.Join(siteRoot, "public"), filePath); ok {
+		return normalizePagePath(scanner.PublicPermalink(rel))
+	}
+	// More specific content directories, e.g. content/en, come first
+	dirs := append(append([]string(nil), opts.ContentDirs...), "content")
+	for _, dir := range dirs {
+		rel, ok := relativeTo(filepath.Join(siteRoot, dir), filePath)
+		if !ok || !scanner.IsContentFile(filePath) {
+			continue
+		}
+		fm, _ := opts.pageFrontMatter(filepath.Join(siteRoot, dir), rel, filePath)
+		return normalizePagePath(opts.URLs.PageURL(hugo.Page{Path: rel, ContentDir: dir, FrontMatter: fm}))
+	}
+	return ""
+}
+
+// relativeTo returns path relative to dir, reporting false if it lies
+// outside dir
+func relativeTo(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return rel, true
+}
+
+// isNoindex reports whether a page asks search engines not to index it
+func isNoindex(file *scanner.File) bool {
+	if fm, err := scanner.ParseFrontMatter(file.Path); err == nil && fm != nil {
+		if strings.Contains(strings.ToLower(fm.String("robots")), "noindex") {
+			return true
+		}
+		if value, ok := fm.Get("noindex"); ok && value == true {
+			return true
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Path))
+	if ext != ".html" && ext != ".htm" {
+		return false
+	}
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(metaContent(string(content), "robots")), "noindex")
+}
@@ -0,0 +1,73 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestLintNoindex(t *testing.T) {
+	tmpDir := t.TempDir()
+	pages := map[string]string{
+		"content/hidden.md":        "---\nrobots: noindex, follow\n---\n",
+		"content/private/index.md": "+++\nnoindex = true\n+++\n",
+		"content/public.md":        "---\ntitle: Public\n---\n",
+		"content/posts/a.md":       "",
+		"content/posts/b.md":       "",
+		"content/posts/c.md":       "",
+		"public/legacy/index.html": `<html><head><meta name="robots" content="NOINDEX"></head></html>`,
+	}
+	files := make(map[string]*scanner.File)
+	var all []*scanner.File
+	for name, content := range pages {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files[name] = &scanner.File{Path: path}
+		all = append(all, files[name])
+	}
+
+	ok := func(urls ...string) []scanner.Link {
+		var links []scanner.Link
+		for _, u := range urls {
+			links = append(links, scanner.Link{URL: u, Type: scanner.LinkTypeInternal, StatusCode: 200})
+		}
+		return links
+	}
+	files["content/posts/a.md"].Links = ok("/hidden/", "/private/", "/public/", "/legacy/")
+	files["content/posts/b.md"].Links = ok("/hidden/#top", "../../private/", "/public/", "/legacy/")
+	files["content/posts/c.md"].Links = ok("/hidden", "/public/")
+
+	lintNoindex(all, 2, Options{RootDir: tmpDir})
+
+	expected := map[string]string{
+		"content/hidden.md":        "3 pages link to it",
+		"content/private/index.md": "2 pages link to it",
+		"public/legacy/index.html": "2 pages link to it",
+	}
+	for name, file := range files {
+		want, warn := expected[name]
+		if !warn {
+			if len(file.Warnings) != 0 {
+				t.Errorf("%s: expected no warnings, got %v", name, file.Warnings)
+			}
+			continue
+		}
+		if len(file.Warnings) != 1 || !strings.Contains(file.Warnings[0], want) {
+			t.Errorf("%s: expected a noindex warning with %q, got %v", name, want, file.Warnings)
+		}
+	}
+
+	files["content/hidden.md"].Warnings = nil
+	lintNoindex(all, 4, Options{RootDir: tmpDir})
+	if len(files["content/hidden.md"].Warnings) != 0 {
+		t.Errorf("Expected no warning below the threshold, got %v", files["content/hidden.md"].Warnings)
+	}
+}
@@ -0,0 +1,186 @@
+package checker
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// Minimum og:image dimensions used when none are configured: the size
+// Facebook and LinkedIn recommend for large link previews
+const (
+	DefaultOGImageWidth  = 1200
+	DefaultOGImageHeight = 630
+)
+
+var (
+	// metaTagPattern matches a <meta> tag; attrPattern its attributes
+	metaTagPattern = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrPattern    = regexp.MustCompile(`(?s)([\w:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// ParseImageSize parses a WIDTHxHEIGHT size such as 1200x630
+func ParseImageSize(size string) (int, int, error) {
+	widthText, heightText, ok := strings.Cut(strings.ToLower(strings.TrimSpace(size)), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid image size %q: expected WIDTHxHEIGHT", size)
+	}
+	width, err := strconv.Atoi(widthText)
+	if err != nil || width <= 0 {
+		return 0, 0, fmt.Errorf("invalid image width in %q", size)
+	}
+	height, err := strconv.Atoi(heightText)
+	if err != nil || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid image height in %q", size)
+	}
+	return width, height, nil
+}
+
+// findOGImage returns the content of the first og:image meta tag in an HTML
+// document, or the empty string if there is none
+func findOGImage(content string) string {
+	return metaContent(content, "og:image", "og:image:url")
+}
+
+// metaContent returns the content of the first meta tag in an HTML document
+// whose property or name is one of names, or the empty string if there is
+// none. Names are matched case-insensitively.
+func metaContent(content string, names ...string) string {
+	for _, tag := range metaTagPattern.FindAllString(content, -1) {
+		var property, value string
+		for _, attr := range attrPattern.FindAllStringSubmatch(tag, -1) {
+			switch strings.ToLower(attr[1]) {
+			case "property", "name":
+				property = strings.ToLower(attr[2] + attr[3])
+			case "content":
+				value = strings.TrimSpace(attr[2] + attr[3])
+			}
+		}
+		if value != "" && slices.Contains(names, property) {
+			return value
+		}
+	}
+	return ""
+}
+
+// ogImageResult is the outcome of checking one og:image URL
+type ogImageResult struct {
+	width, height int
+	err           error
+	// offline marks a remote image left unchecked in offline mode
+	offline bool
+}
+
+// lintOGImages checks the og:image declared by each page: the image must
+// resolve and be at least the minimum size, or social previews degrade to a
+// small thumbnail or none at all. Problems are reported as page warnings.
+func lintOGImages(files []*scanner.File, client *http.Client, opts Options) {
+	minWidth, minHeight := opts.OGImageMinWidth, opts.OGImageMinHeight
+	if minWidth <= 0 || minHeight <= 0 {
+		minWidth, minHeight = DefaultOGImageWidth, DefaultOGImageHeight
+	}
+
+	results := make(map[string]ogImageResult)
+	for _, file := range files {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			continue
+		}
+		imageURL := findOGImage(string(content))
+		if imageURL == "" {
+			continue
+		}
+
+		result, ok := results[imageURL]
+		if !ok {
+			result = checkOGImage(client, imageURL, opts)
+			results[imageURL] = result
+		}
+
+		switch {
+		case result.offline:
+		case result.err != nil:
+			file.Warnings = append(file.Warnings, fmt.Sprintf("og:image %s does not resolve: %v", imageURL, result.err))
+		case result.width == 0:
+			file.Warnings = append(file.Warnings, fmt.Sprintf("og:image %s dimensions could not be read", imageURL))
+		case result.width < minWidth || result.height < minHeight:
+			file.Warnings = append(file.Warnings, fmt.Sprintf("og:image %s is %dx%d, below the minimum of %dx%d",
+				imageURL, result.width, result.height, minWidth, minHeight))
+		}
+	}
+}
+
+// checkOGImage resolves an og:image URL and decodes its dimensions. Images
+// on the site's own domain and site-relative images are read from the local
+// tree; other images are fetched.
+func checkOGImage(client *http.Client, imageURL string, opts Options) ogImageResult {
+	localPath := imageURL
+	if relative, ok := opts.relativeToSite(imageURL); ok {
+		localPath = relative
+	}
+	if strings.HasPrefix(localPath, "/") && !scanner.IsProtocolRelative(localPath) {
+		localPath = strings.SplitN(strings.SplitN(localPath, "#", 2)[0], "?", 2)[0]
+		var found string
+		if opts.CheckPublic {
+			found, _ = findPublicFile(localPath, opts.RootDir, false, opts.UnicodeForm)
+		} else {
+			found, _ = findHugoFile(localPath, opts.RootDir, false, opts.UnicodeForm)
+		}
+		if found == "" {
+			return ogImageResult{err: fmt.Errorf("file not found")}
+		}
+		f, err := os.Open(found)
+		if err != nil {
+			return ogImageResult{err: err}
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", found, closeErr)
+			}
+		}()
+		return decodeOGImage(f)
+	}
+
+	if opts.Offline {
+		return ogImageResult{offline: true}
+	}
+	if scanner.IsProtocolRelative(imageURL) {
+		scheme := opts.ProtocolRelativeScheme
+		if scheme == "" {
+			scheme = "https"
+		}
+		imageURL = scheme + ":" + imageURL
+	}
+	req, err := http.NewRequest(http.MethodGet, imageURL, nil)
+	if err != nil {
+		return ogImageResult{err: err}
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", maxImageHeaderBytes-1))
+	resp, err := client.Do(req)
+	if err != nil {
+		return ogImageResult{err: err}
+	}
+	defer closeBody(resp)
+	if resp.StatusCode >= 400 {
+		return ogImageResult{err: fmt.Errorf("HTTP %d", resp.StatusCode)}
+	}
+	return decodeOGImage(io.LimitReader(resp.Body, maxImageHeaderBytes))
+}
+
+// decodeOGImage reads the dimensions of an image; formats that can't be
+// decoded leave them zero
+func decodeOGImage(r io.Reader) ogImageResult {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return ogImageResult{}
+	}
+	return ogImageResult{width: config.Width, height: config.Height}
+}
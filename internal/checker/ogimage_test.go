@@ -0,0 +1,110 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestFindOGImage(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`<meta property="og:image" content="https://example.com/og.png">`, "https://example.com/og.png"},
+		{`<meta content='/images/og.png' property='og:image' />`, "/images/og.png"},
+		{`<META PROPERTY="og:image:url" CONTENT="/og.png">`, "/og.png"},
+		{`<meta property="og:title" content="Title">`, ""},
+		{`<meta name="og:image" content="">`, ""},
+		{"no head tags", ""},
+	}
+
+	for _, tt := range tests {
+		if got := findOGImage(tt.content); got != tt.want {
+			t.Errorf("findOGImage(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestParseImageSize(t *testing.T) {
+	width, height, err := ParseImageSize("1200x630")
+	if err != nil || width != 1200 || height != 630 {
+		t.Errorf("ParseImageSize(1200x630) = %d, %d, %v", width, height, err)
+	}
+	for _, size := range []string{"", "1200", "x630", "1200x", "0x630", "axb"} {
+		if _, _, err := ParseImageSize(size); err == nil {
+			t.Errorf("ParseImageSize(%q) succeeded, want error", size)
+		}
+	}
+}
+
+func TestLintOGImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	staticDir := filepath.Join(tmpDir, "static", "images")
+	if err := os.MkdirAll(staticDir, 0755); err != nil {
+		t.Fatalf("Failed to create static directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(staticDir, "small.png"), encodeTestPNG(t, 600, 315), 0644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	large := encodeTestPNG(t, 1200, 630)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/og.png" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(large)
+	}))
+	defer server.Close()
+
+	pages := map[string]string{
+		"large.html":   `<meta property="og:image" content="` + server.URL + `/og.png">`,
+		"again.html":   `<meta property="og:image" content="` + server.URL + `/og.png">`,
+		"small.html":   `<meta property="og:image" content="https://example.com/images/small.png">`,
+		"missing.html": `<meta property="og:image" content="` + server.URL + `/missing.png">`,
+		"none.html":    `<title>No preview</title>`,
+	}
+	files := make(map[string]*scanner.File)
+	var all []*scanner.File
+	for name, content := range pages {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write page: %v", err)
+		}
+		files[name] = &scanner.File{Path: path}
+		all = append(all, files[name])
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	lintOGImages(all, client, Options{RootDir: tmpDir, SiteURL: "https://example.com"})
+
+	for _, name := range []string{"large.html", "again.html", "none.html"} {
+		if len(files[name].Warnings) != 0 {
+			t.Errorf("Expected no warnings for %s, got %v", name, files[name].Warnings)
+		}
+	}
+	if warnings := files["small.html"].Warnings; len(warnings) != 1 || !strings.Contains(warnings[0], "600x315, below the minimum of 1200x630") {
+		t.Errorf("Expected an undersized warning for small.html, got %v", warnings)
+	}
+	if warnings := files["missing.html"].Warnings; len(warnings) != 1 || !strings.Contains(warnings[0], "does not resolve: HTTP 404") {
+		t.Errorf("Expected an unresolved warning for missing.html, got %v", warnings)
+	}
+	if requests != 2 {
+		t.Errorf("Expected each remote image to be fetched once, got %d requests", requests)
+	}
+
+	files["small.html"].Warnings = nil
+	lintOGImages([]*scanner.File{files["small.html"]}, client, Options{RootDir: tmpDir, SiteURL: "https://example.com", OGImageMinWidth: 600, OGImageMinHeight: 300})
+	if len(files["small.html"].Warnings) != 0 {
+		t.Errorf("Expected no warnings with a 600x300 minimum, got %v", files["small.html"].Warnings)
+	}
+}
@@ -0,0 +1,73 @@
+package checker
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// siteURL returns the site's own URL, falling back to BaseURL
+func (opts Options) siteURL() string {
+	if opts.SiteURL != "" {
+		return opts.SiteURL
+	}
+	return opts.BaseURL
+}
+
+// relativeToSite returns the site-relative form of an absolute URL on the
+// site's own domain. It reports false for URLs on any other host.
+func (opts Options) relativeToSite(linkURL string) (string, bool) {
+	site, err := url.Parse(opts.siteURL())
+	if err != nil || site.Host == "" {
+		return "", false
+	}
+	u, err := url.Parse(linkURL)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	if !strings.EqualFold(u.Hostname(), site.Hostname()) {
+		return "", false
+	}
+
+	basePath := strings.TrimSuffix(site.Path, "/")
+	if u.Path != basePath && !strings.HasPrefix(u.Path, basePath+"/") {
+		return "", false
+	}
+
+	relative := &url.URL{
+		Path:     strings.TrimPrefix(u.Path, basePath),
+		RawQuery: u.RawQuery,
+		Fragment: u.Fragment,
+	}
+	if relative.Path == "" {
+		relative.Path = "/"
+	}
+	return relative.String(), true
+}
+
+// lintOwnHost warns about absolute links to the site's own domain, which
+// break on staging hosts and local previews, and records the relative fix.
+// The fix keeps the base URL's path, as Hugo's relURL does: on a site served
+// at https://example.com/blog/, https://example.com/blog/post/ becomes
+// /blog/post/. Metadata URLs such as rel="canonical" must be absolute and are
+// left alone, as are front matter values, which templates may need absolute.
+func lintOwnHost(link *scanner.Link, checkURL string, opts Options) {
+	if link.Kind == scanner.KindMetadata || link.Kind == scanner.KindFrontMatter {
+		return
+	}
+	if _, ok := opts.relativeToSite(checkURL); !ok {
+		return
+	}
+	u, err := url.Parse(checkURL)
+	if err != nil {
+		return
+	}
+	relative := &url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: u.RawQuery, Fragment: u.Fragment}
+	if relative.Path == "" {
+		relative.Path = "/"
+	}
+	link.Fix = relative.String()
+	link.Warnings = append(link.Warnings, fmt.Sprintf("Absolute link to own site: use %s", link.Fix))
+}
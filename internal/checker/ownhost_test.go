@@ -0,0 +1,90 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestRelativeToSite(t *testing.T) {
+	opts := Options{SiteURL: "https://mysite.com/"}
+
+	testCases := []struct {
+		url      string
+		expected string
+		own      bool
+	}{
+		{"https://mysite.com/about/", "/about/", true},
+		{"http://MySite.com", "/", true},
+		{"https://mysite.com/docs/?v=2#install", "/docs/?v=2#install", true},
+		{"https://other.com/about/", "", false},
+		{"https://www.mysite.com/about/", "", false},
+	}
+
+	for _, tc := range testCases {
+		got, ok := opts.relativeToSite(tc.url)
+		if ok != tc.own || got != tc.expected {
+			t.Errorf("%s: expected (%s, %v), got (%s, %v)", tc.url, tc.expected, tc.own, got, ok)
+		}
+	}
+
+	// Sites served below a path only claim URLs under that path
+	opts = Options{BaseURL: "https://example.com/blog"}
+	if got, ok := opts.relativeToSite("https://example.com/blog/post/"); !ok || got != "/post/" {
+		t.Errorf("Expected /post/, got (%s, %v)", got, ok)
+	}
+	if _, ok := opts.relativeToSite("https://example.com/shop/"); ok {
+		t.Error("Expected URL outside the base path not to be claimed")
+	}
+}
+
+func TestCheckLinks_OwnHostWarning(t *testing.T) {
+	file := &scanner.File{
+		Path: "content/index.md",
+		Links: []scanner.Link{
+			scanner.NewLink("https://mysite.com/about/"),
+			scanner.NewLink("https://other.com/"),
+			{URL: "https://mysite.com/", Type: scanner.LinkTypeExternal, Kind: scanner.KindMetadata},
+		},
+	}
+
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{SiteURL: "https://mysite.com"}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	if link := file.Links[0]; link.Fix != "/about/" || len(link.Warnings) != 1 {
+		t.Errorf("Expected own-host warning with fix, got fix %q warnings %v", link.Fix, link.Warnings)
+	}
+	if link := file.Links[1]; link.Fix != "" || len(link.Warnings) != 0 {
+		t.Errorf("Expected no warning for other host, got %v", link.Warnings)
+	}
+	if link := file.Links[2]; link.Fix != "" || len(link.Warnings) != 0 {
+		t.Errorf("Expected no warning for a metadata URL, got %v", link.Warnings)
+	}
+}
+
+func TestCheckLinks_OwnHostSubpath(t *testing.T) {
+	file := &scanner.File{
+		Path: "content/index.md",
+		Links: []scanner.Link{
+			scanner.NewLink("https://example.com/blog/post/?p=1#intro"),
+			scanner.NewLink("https://example.com/blog"),
+			scanner.NewLink("https://example.com/shop/"),
+		},
+	}
+
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{SiteURL: "https://example.com/blog/"}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	// The fix keeps the path the site is served below
+	if link := file.Links[0]; link.Fix != "/blog/post/?p=1#intro" || len(link.Warnings) != 1 {
+		t.Errorf("Expected fix /blog/post/?p=1#intro, got fix %q warnings %v", link.Fix, link.Warnings)
+	}
+	if link := file.Links[1]; link.Fix != "/blog" {
+		t.Errorf("Expected fix /blog, got %q", link.Fix)
+	}
+	if link := file.Links[2]; link.Fix != "" || len(link.Warnings) != 0 {
+		t.Errorf("Expected no warning outside the base path, got %v", link.Warnings)
+	}
+}
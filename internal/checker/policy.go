@@ -0,0 +1,129 @@
+package checker
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// Policy rule severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// PolicyRule flags links that violate a site's link policy, e.g. links to
+// internal hosts or plain http://. A rule matches when all of its
+// non-empty matchers match.
+type PolicyRule struct {
+	ID       string
+	Severity string
+	Message  string
+
+	// Scheme matches the URL scheme, e.g. "http"
+	Scheme string
+	// Host matches the host and its subdomains; it may be a glob such as
+	// "*.internal.corp"
+	Host string
+	// Pattern matches the whole URL
+	Pattern *regexp.Regexp
+}
+
+// NewPolicyRule builds a policy rule, validating its severity and pattern
+func NewPolicyRule(id, severity, scheme, host, pattern, message string) (PolicyRule, error) {
+	rule := PolicyRule{
+		ID:       id,
+		Severity: strings.ToLower(severity),
+		Message:  message,
+		Scheme:   strings.ToLower(strings.TrimSuffix(scheme, ":")),
+		Host:     strings.ToLower(host),
+	}
+
+	if id == "" {
+		return rule, fmt.Errorf("policy rule has no id")
+	}
+	switch rule.Severity {
+	case "":
+		rule.Severity = SeverityError
+	case SeverityError, SeverityWarning:
+	default:
+		return rule, fmt.Errorf("policy rule %s: invalid severity %s (valid: error, warning)", id, severity)
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return rule, fmt.Errorf("policy rule %s: invalid pattern %q: %v", id, pattern, err)
+		}
+		rule.Pattern = re
+	}
+	if rule.Scheme == "" && rule.Host == "" && rule.Pattern == nil {
+		return rule, fmt.Errorf("policy rule %s: needs a scheme, host, or pattern", id)
+	}
+	if rule.Message == "" {
+		rule.Message = "Link violates policy"
+	}
+	return rule, nil
+}
+
+// matches reports whether the URL violates the rule
+func (rule PolicyRule) matches(linkURL string) bool {
+	if rule.Pattern != nil && !rule.Pattern.MatchString(linkURL) {
+		return false
+	}
+	if rule.Scheme == "" && rule.Host == "" {
+		return true
+	}
+
+	u, err := url.Parse(linkURL)
+	if err != nil {
+		return false
+	}
+	if rule.Scheme != "" && strings.ToLower(u.Scheme) != rule.Scheme {
+		return false
+	}
+	if rule.Host != "" && !hostMatches(rule.Host, u.Hostname()) {
+		return false
+	}
+	return true
+}
+
+// hostMatches reports whether host is pattern or one of its subdomains.
+// Pattern may be a glob such as "*.internal.corp".
+func hostMatches(pattern, host string) bool {
+	host = strings.ToLower(host)
+	if matched, _ := path.Match(pattern, host); matched {
+		return true
+	}
+	return strings.HasSuffix(host, "."+pattern)
+}
+
+// applyPolicy evaluates the policy rules against a link. Error rules mark
+// the link broken without fetching it and report true; warning rules add
+// a warning.
+func applyPolicy(link *scanner.Link, linkURL string, rules []PolicyRule) bool {
+	violated := false
+	for _, rule := range rules {
+		if !rule.matches(linkURL) {
+			continue
+		}
+		link.PolicyRules = append(link.PolicyRules, rule.ID)
+		message := fmt.Sprintf("[%s] %s", rule.ID, rule.Message)
+		if rule.Severity == SeverityWarning {
+			link.Warnings = append(link.Warnings, message)
+			continue
+		}
+		if !violated {
+			link.StatusCode = 0
+			link.ErrorMessage = message
+			link.ErrorCategory = scanner.CategoryPolicy
+			link.LastChecked = time.Now()
+			violated = true
+		}
+	}
+	return violated
+}
@@ -0,0 +1,43 @@
+package checker
+
+import (
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// checkReference reports a Markdown reference to an undefined label as
+// broken: Hugo renders it as literal text. Unreferenced footnotes have
+// nothing to check; lintReferences warns about them.
+func checkReference(link *scanner.Link) {
+	if link.Unreferenced {
+		link.StatusCode = 200
+		link.ErrorMessage = ""
+		return
+	}
+	link.StatusCode = 404
+	link.ErrorCategory = scanner.CategoryUndefinedReference
+	if strings.HasPrefix(link.URL, "[^") {
+		link.ErrorMessage = "Undefined footnote"
+	} else {
+		link.ErrorMessage = "Undefined link reference"
+	}
+}
+
+// lintReferences warns about link reference definitions and footnotes that
+// nothing in their page refers to
+func lintReferences(files []*scanner.File) {
+	for _, file := range files {
+		for i := range file.Links {
+			link := &file.Links[i]
+			if !link.Unreferenced {
+				continue
+			}
+			if link.Kind == scanner.KindReference {
+				link.Warnings = append(link.Warnings, "Footnote is defined but never referenced")
+			} else {
+				link.Warnings = append(link.Warnings, "Link reference definition is never used")
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package checker
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckLinks_References(t *testing.T) {
+	file := &scanner.File{
+		Path: "content/index.md",
+		Links: []scanner.Link{
+			{URL: "[gone]", Kind: scanner.KindReference},
+			{URL: "[^nope]", Kind: scanner.KindReference},
+			{URL: "[^orphan]", Kind: scanner.KindReference, Unreferenced: true},
+			{URL: "https://example.com/unused", Type: scanner.LinkTypeExternal, Unreferenced: true},
+		},
+	}
+
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: t.TempDir()}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	for i, message := range []string{"Undefined link reference", "Undefined footnote"} {
+		link := file.Links[i]
+		if link.StatusCode != 404 || link.ErrorMessage != message || link.ErrorCategory != scanner.CategoryUndefinedReference {
+			t.Errorf("%s: expected %q, got %d %q (%s)", link.URL, message, link.StatusCode, link.ErrorMessage, link.ErrorCategory)
+		}
+	}
+	if orphan := file.Links[2]; orphan.StatusCode != 200 || !slices.Contains(orphan.Warnings, "Footnote is defined but never referenced") {
+		t.Errorf("expected the unreferenced footnote to be a warning, got %d %v", orphan.StatusCode, orphan.Warnings)
+	}
+	if unused := file.Links[3]; !slices.Contains(unused.Warnings, "Link reference definition is never used") {
+		t.Errorf("expected the unused definition to be a warning, got %v", unused.Warnings)
+	}
+}
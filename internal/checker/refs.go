@@ -0,0 +1,235 @@
+package checker
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+var (
+	// refPattern matches a link destination made of a ref or relref
+	// shortcode call, {{< ref ... >}} or {{% relref ... %}}, optionally
+	// followed by a fragment
+	refPattern = regexp.MustCompile(`^\{\{[<%]-?\s*(?:ref|relref)\s((?:[^}]|\}[^}])*?)\s*-?[>%]\}\}(#.*)?$`)
+	// refArgPattern matches one shortcode argument, named or positional,
+	// quoted with " or ` or unquoted
+	refArgPattern = regexp.MustCompile("(?:(\\w+)\\s*=\\s*)?(?:\"((?:[^\"\\\\]|\\\\.)*)\"|`([^`]*)`|([^\\s\"`]+))")
+)
+
+// refPageExtensions are the content file extensions a ref target without
+// one may have
+var refPageExtensions = []string{".md", ".markdown", ".html", ".htm"}
+
+// parseRefShortcode returns the page path and fragment referenced by a ref
+// or relref shortcode destination, e.g. "blog/post.md#setup" for
+// {{< ref "blog/post.md#setup" >}} or {{< relref path="blog/post.md" >}}#setup.
+// It reports false for other destinations and shortcodes without a path.
+func parseRefShortcode(linkURL string) (string, bool) {
+	match := refPattern.FindStringSubmatch(linkURL)
+	if match == nil {
+		return "", false
+	}
+
+	target := ""
+	for _, arg := range refArgPattern.FindAllStringSubmatch(match[1], -1) {
+		value := arg[2] + arg[3] + arg[4]
+		if arg[2] != "" {
+			value = strings.ReplaceAll(value, `\"`, `"`)
+		}
+		if arg[1] == "path" || (arg[1] == "" && target == "") {
+			target = value
+			if arg[1] == "path" {
+				break
+			}
+		}
+	}
+	if target == "" {
+		return "", false
+	}
+	if match[2] != "" && !strings.Contains(target, "#") {
+		target += match[2]
+	}
+	return target, true
+}
+
+// checkRefLink resolves the target of a ref or relref shortcode against the
+// content tree the way Hugo does: paths starting with / from the content
+// directory, others from the page's directory first and then from the
+// content directory, and a bare file name, wherever it is, when it names a
+// single page. The extension of the target page is optional, and a section
+// or page bundle may be referenced by its directory. A fragment is checked
+// against the anchors of the target page.
+func checkRefLink(link *scanner.Link, file *scanner.File, target string, index anchorIndex, opts Options) {
+	pagePath, fragment, _ := strings.Cut(target, "#")
+
+	found := file.Path
+	if pagePath != "" {
+		var matches []string
+		found, matches = findRefPage(pagePath, file.Path, opts)
+		if found == "" {
+			link.StatusCode = 404
+			link.ErrorCategory = scanner.CategoryNotFoundLocal
+			if len(matches) > 1 {
+				link.ErrorMessage = ambiguousMessage(pagePath, matches, opts)
+			} else {
+				link.ErrorMessage = fmt.Sprintf("Referenced page not found: %s", pagePath)
+			}
+			return
+		}
+		// A ref to a page that isn't rendered yields an empty URL
+		if lintUnrenderedLink(link, found, opts) {
+			return
+		}
+	}
+
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	if fragment != "" && !strings.EqualFold(fragment, "top") {
+		if anchors := index.anchors(found, opts.HeadingIDType); anchors != nil && !anchors[fragment] {
+			link.StatusCode = 404
+			link.ErrorMessage = "Anchor not found"
+			link.ErrorCategory = scanner.CategoryMissingAnchor
+			return
+		}
+	}
+	link.StatusCode = 200
+	link.ErrorMessage = ""
+}
+
+// ambiguousMessage describes a reference to a bare file name that matches
+// several pages
+func ambiguousMessage(pagePath string, matches []string, opts Options) string {
+	siteRoot := scanner.SiteRoot(opts.RootDir)
+	names := make([]string, len(matches))
+	for i, match := range matches {
+		names[i] = match
+		if rel, err := filepath.Rel(siteRoot, match); err == nil {
+			names[i] = filepath.ToSlash(rel)
+		}
+	}
+	return fmt.Sprintf("Ambiguous reference %s: matches %s", pagePath, strings.Join(names, ", "))
+}
+
+// findRefPage returns the content file a ref path from the page at
+// pagePath resolves to, or "" and the pages a bare file name matches when
+// there isn't exactly one
+func findRefPage(refPath, pagePath string, opts Options) (string, []string) {
+	contentDirs := refContentDirs(opts)
+
+	var bases []string
+	if !strings.HasPrefix(refPath, "/") {
+		bases = append(bases, filepath.Join(refBaseDir(refPath, pagePath), filepath.FromSlash(refPath)))
+	}
+	for _, dir := range contentDirs {
+		bases = append(bases, filepath.Join(dir, filepath.FromSlash(refPath)))
+	}
+	for _, base := range bases {
+		if found := findRefFile(base); found != "" {
+			return found, nil
+		}
+	}
+
+	if strings.Contains(refPath, "/") {
+		return "", nil
+	}
+	matches := opts.pageNames.lookup(refPath, contentDirs)
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return "", matches
+}
+
+// refContentDirs returns the content directories of the site
+func refContentDirs(opts Options) []string {
+	siteRoot := scanner.SiteRoot(opts.RootDir)
+	dirs := []string{filepath.Join(siteRoot, "content")}
+	for _, dir := range opts.ContentDirs {
+		if dir != "content" {
+			dirs = append(dirs, filepath.Join(siteRoot, dir))
+		}
+	}
+	if filepath.Base(opts.RootDir) == "content" && filepath.Dir(opts.RootDir) != siteRoot {
+		dirs = append(dirs, opts.RootDir)
+	}
+	return dirs
+}
+
+// refBaseDir returns the directory relative refs from the page at pagePath
+// start from. As in Hugo, that's the directory containing a page or leaf
+// bundle, and a section's own directory; ../ refs with an extension start
+// from a leaf bundle's own directory.
+func refBaseDir(refPath, pagePath string) string {
+	dir := filepath.Dir(pagePath)
+	base := strings.TrimSuffix(filepath.Base(pagePath), filepath.Ext(pagePath))
+	if base != "index" || (path.Ext(refPath) != "" && strings.HasPrefix(refPath, "../")) {
+		return dir
+	}
+	return filepath.Dir(dir)
+}
+
+// findRefFile returns the content file at base, with or without one of
+// refPageExtensions, or the index page of the bundle or section directory
+// at base; "" if there is none
+func findRefFile(base string) string {
+	candidates := []string{base}
+	for _, ext := range refPageExtensions {
+		candidates = append(candidates, base+ext)
+	}
+	for _, name := range []string{"index", "_index"} {
+		for _, ext := range refPageExtensions {
+			candidates = append(candidates, filepath.Join(base, name+ext))
+		}
+	}
+
+	for _, candidate := range candidates {
+		found := candidate
+		if _, err := os.Stat(candidate); err != nil {
+			found = findCaseInsensitiveFile(candidate)
+		}
+		if info, err := os.Stat(found); found != "" && err == nil && !info.IsDir() {
+			return found
+		}
+	}
+	return ""
+}
+
+// pageNameIndex indexes the site's content files by file name, with and
+// without extension, and bundles by directory name, for refs that name a
+// page anywhere in the content tree. It is built on first use.
+type pageNameIndex struct {
+	pages map[string][]string
+}
+
+// lookup returns the content files named name, lowercased
+func (idx *pageNameIndex) lookup(name string, contentDirs []string) []string {
+	if idx == nil {
+		return nil
+	}
+	if idx.pages == nil {
+		idx.pages = make(map[string][]string)
+		for _, dir := range contentDirs {
+			_ = filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() || !scanner.IsContentFile(filePath) {
+					return nil
+				}
+				fileName := strings.ToLower(info.Name())
+				names := []string{fileName, strings.TrimSuffix(fileName, filepath.Ext(fileName))}
+				if base := names[1]; base == "index" || base == "_index" {
+					names = append(names, strings.ToLower(filepath.Base(filepath.Dir(filePath))))
+				}
+				for _, n := range names {
+					idx.pages[n] = append(idx.pages[n], filePath)
+				}
+				return nil
+			})
+		}
+	}
+	return idx.pages[strings.ToLower(name)]
+}
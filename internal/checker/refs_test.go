@@ -0,0 +1,142 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestParseRefShortcode(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected string
+		ok       bool
+	}{
+		{`{{< ref "blog/post.md" >}}`, "blog/post.md", true},
+		{`{{< relref "/docs/install" >}}`, "/docs/install", true},
+		{`{{% ref "post#setup" %}}`, "post#setup", true},
+		{`{{< ref "post" >}}#setup`, "post#setup", true},
+		{`{{<ref "post">}}`, "post", true},
+		{`{{< relref path="post.md" lang="fr" >}}`, "post.md", true},
+		{`{{< relref lang="fr" path="post.md" >}}`, "post.md", true},
+		{"{{< ref `post.md` >}}", "post.md", true},
+		{`{{< ref post.md >}}`, "post.md", true},
+		{`{{< ref "#anchor" >}}`, "#anchor", true},
+		{`{{< param "homepage" >}}`, "", false},
+		{`{{< ref >}}`, "", false},
+		{`{{ .Site.BaseURL }}/about`, "", false},
+		{`/blog/post/`, "", false},
+	}
+	for _, tc := range testCases {
+		got, ok := parseRefShortcode(tc.url)
+		if got != tc.expected || ok != tc.ok {
+			t.Errorf("%s: expected %q (%v), got %q (%v)", tc.url, tc.expected, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestCheckLinks_RefShortcodes(t *testing.T) {
+	root := t.TempDir()
+	pages := map[string]string{
+		"content/blog/_index.md":          "# Blog\n",
+		"content/blog/first.md":           "# First\n\n## Setup\n",
+		"content/blog/bundle/index.md":    "# Bundle\n",
+		"content/docs/install.md":         "# Install\n",
+		"content/blog/second/index.md":    "# Second\n",
+		"content/blog/second/appendix.md": "# Appendix\n",
+		"content/docs/intro.md":           "# Intro\n",
+		"content/news/intro.md":           "# Intro\n",
+	}
+	for name, content := range pages {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := &scanner.File{
+		Path: filepath.Join(root, "content/blog/second/index.md"),
+		Links: []scanner.Link{
+			scanner.NewLink(`{{< ref "first" >}}`),
+			scanner.NewLink(`{{< relref "/docs/install.md" >}}`),
+			scanner.NewLink(`{{< ref "docs/install" >}}`),
+			scanner.NewLink(`{{< ref "appendix.md" >}}`),
+			scanner.NewLink(`{{< ref "../second/appendix.md" >}}`),
+			scanner.NewLink(`{{< ref "/blog" >}}`),
+			scanner.NewLink(`{{< ref "bundle" >}}`),
+			scanner.NewLink(`{{< ref "first#setup" >}}`),
+			scanner.NewLink(`{{< ref "#second" >}}`),
+			scanner.NewLink(`{{< ref "missing" >}}`),
+			scanner.NewLink(`{{< relref "/docs/uninstall.md" >}}`),
+			scanner.NewLink(`{{< ref "first" >}}#teardown`),
+			scanner.NewLink(`{{< ref "intro" >}}`),
+			scanner.NewLink(`{{< param "homepage" >}}`),
+		},
+	}
+
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: root}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	expected := []struct {
+		status   int
+		category scanner.ErrorCategory
+	}{
+		{200, ""},
+		{200, ""},
+		{200, ""},
+		{200, ""},
+		{200, ""},
+		{200, ""},
+		{200, ""},
+		{200, ""},
+		{200, ""},
+		{404, scanner.CategoryNotFoundLocal},
+		{404, scanner.CategoryNotFoundLocal},
+		{404, scanner.CategoryMissingAnchor},
+		{404, scanner.CategoryNotFoundLocal},
+		{200, ""},
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.StatusCode != want.status || link.ErrorCategory != want.category {
+			t.Errorf("%s: expected status %d category %q, got %d %q (%s)", link.URL, want.status, want.category, link.StatusCode, link.ErrorCategory, link.ErrorMessage)
+		}
+	}
+}
+
+func TestCheckLinks_MenuPageRefs(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"content/docs/_index.md", "content/about.md"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# Page\n\n## Team\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var links []scanner.Link
+	for _, pageRef := range []string{"/docs", "about", "/about.md#team", "/blog", "about#history"} {
+		link := scanner.NewLink(pageRef)
+		link.Kind = scanner.KindPageRef
+		links = append(links, link)
+	}
+	file := &scanner.File{Path: filepath.Join(root, "hugo.toml"), Links: links}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: root}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	expected := []int{200, 200, 200, 404, 404}
+	for i, want := range expected {
+		if link := file.Links[i]; link.StatusCode != want {
+			t.Errorf("%s: expected status %d, got %d (%s)", link.URL, want, link.StatusCode, link.ErrorMessage)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package checker
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RewriteRule rewrites link URLs matching Pattern before they are checked.
+// Replacement may reference capture groups as $1 or ${name}.
+type RewriteRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// NewRewriteRule compiles a rewrite rule from a regular expression and its replacement
+func NewRewriteRule(pattern, replacement string) (RewriteRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return RewriteRule{}, fmt.Errorf("invalid rewrite pattern %q: %v", pattern, err)
+	}
+	return RewriteRule{Pattern: re, Replacement: replacement}, nil
+}
+
+// rewrite applies every matching rewrite rule to the URL in order
+func (opts Options) rewrite(linkURL string) string {
+	for _, rule := range opts.Rewrites {
+		if rule.Pattern.MatchString(linkURL) {
+			linkURL = rule.Pattern.ReplaceAllString(linkURL, rule.Replacement)
+		}
+	}
+	return linkURL
+}
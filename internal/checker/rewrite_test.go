@@ -0,0 +1,71 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestRewrite(t *testing.T) {
+	vanity, err := NewRewriteRule(`^https://go\.example\.com/`, "https://origin.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, err := NewRewriteRule(`^https://docs\.example\.com/(.*)$`, "/docs/$1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Rewrites: []RewriteRule{vanity, docs}}
+
+	testCases := map[string]string{
+		"https://go.example.com/pkg":          "https://origin.example.com/pkg",
+		"https://docs.example.com/guide/":     "/docs/guide/",
+		"https://unrelated.example.com/page/": "https://unrelated.example.com/page/",
+	}
+	for input, expected := range testCases {
+		if got := opts.rewrite(input); got != expected {
+			t.Errorf("rewrite(%s): expected %s, got %s", input, expected, got)
+		}
+	}
+
+	if _, err := NewRewriteRule("(", ""); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestCheckLinks_RewriteToLocal(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, "content", "docs", "guide"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "content", "docs", "guide", "_index.md"), []byte("# Guide"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := NewRewriteRule(`^https://docs\.example\.com/(.*)$`, "/docs/$1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := &scanner.File{
+		Path: "content/index.md",
+		Links: []scanner.Link{
+			scanner.NewLink("https://docs.example.com/guide/"),
+			scanner.NewLink("https://docs.example.com/missing/"),
+		},
+	}
+
+	err = CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: tempDir, Rewrites: []RewriteRule{rule}})
+	if err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	found := file.Links[0]
+	if found.StatusCode != 200 || found.URL != "https://docs.example.com/guide/" || found.CheckedURL != "/docs/guide/" {
+		t.Errorf("Unexpected result for rewritten link: %+v", found)
+	}
+	if missing := file.Links[1]; missing.StatusCode != 404 {
+		t.Errorf("Expected rewritten missing page to be 404, got %d", missing.StatusCode)
+	}
+}
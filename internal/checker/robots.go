@@ -0,0 +1,287 @@
+package checker
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// robotsRule is an Allow or Disallow line of robots.txt
+type robotsRule struct {
+	pattern string
+	allow   bool
+	match   *regexp.Regexp
+}
+
+// robotsRules holds the rules robots.txt applies to all crawlers (the
+// User-agent: * group) and the sitemaps it names
+type robotsRules struct {
+	rules    []robotsRule
+	sitemaps []string
+}
+
+// parseRobots reads a robots.txt file. Rules of groups for named crawlers
+// only are ignored.
+func parseRobots(content string) *robotsRules {
+	robots := &robotsRules{}
+	var agents []string
+	inRules := false
+	lines := bufio.NewScanner(strings.NewReader(content))
+	for lines.Scan() {
+		line, _, _ := strings.Cut(lines.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agents = append(agents, value)
+		case "allow", "disallow":
+			inRules = true
+			if value == "" || !slices.Contains(agents, "*") {
+				continue
+			}
+			robots.rules = append(robots.rules, robotsRule{pattern: value, allow: key == "allow", match: robotsPattern(value)})
+		case "sitemap":
+			robots.sitemaps = append(robots.sitemaps, value)
+		}
+	}
+	return robots
+}
+
+// robotsPattern compiles a robots.txt path pattern, a prefix in which *
+// matches any characters and a trailing $ anchors the end
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(pattern, "$")), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// disallows reports whether robots.txt keeps crawlers away from a URL path.
+// As crawlers do, the longest matching rule decides, and Allow wins a tie.
+func (r *robotsRules) disallows(urlPath string) bool {
+	var decided *robotsRule
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if !rule.match.MatchString(urlPath) {
+			continue
+		}
+		if decided == nil || len(rule.pattern) > len(decided.pattern) ||
+			(len(rule.pattern) == len(decided.pattern) && rule.allow) {
+			decided = rule
+		}
+	}
+	return decided != nil && !decided.allow
+}
+
+// robotsBlockStatuses are the statuses sites answer crawlers they turn
+// away; 999 is LinkedIn's
+var robotsBlockStatuses = []int{http.StatusUnauthorized, http.StatusForbidden, 999}
+
+// maxRobotsSize caps the robots.txt read from a host
+const maxRobotsSize = 512 << 10
+
+// hostRobots reads the robots.txt of the hosts of external links, each host
+// once per run, to tell links refused to crawlers from broken ones
+type hostRobots struct {
+	mu    sync.Mutex
+	rules map[string]*robotsRules
+}
+
+func newHostRobots() *hostRobots {
+	return &hostRobots{rules: make(map[string]*robotsRules)}
+}
+
+// disallows reports whether the robots.txt of target's host keeps crawlers
+// away from target. A robots.txt that can't be read disallows nothing. A nil
+// cache reads robots.txt without keeping it.
+func (h *hostRobots) disallows(client *http.Client, target *url.URL) bool {
+	origin := target.Scheme + "://" + target.Host
+	var robots *robotsRules
+	if h != nil {
+		h.mu.Lock()
+		robots = h.rules[origin]
+		h.mu.Unlock()
+	}
+	if robots == nil {
+		robots = fetchRobots(client, origin)
+		if h != nil {
+			h.mu.Lock()
+			h.rules[origin] = robots
+			h.mu.Unlock()
+		}
+	}
+
+	urlPath := target.EscapedPath()
+	if urlPath == "" {
+		urlPath = "/"
+	}
+	if target.RawQuery != "" {
+		urlPath += "?" + target.RawQuery
+	}
+	return robots.disallows(urlPath)
+}
+
+// fetchRobots reads the robots.txt of origin, returning no rules if there is
+// none or it can't be read
+func fetchRobots(client *http.Client, origin string) *robotsRules {
+	resp, err := client.Get(origin + "/robots.txt")
+	if err != nil {
+		return &robotsRules{}
+	}
+	defer closeBody(resp)
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return &robotsRules{}
+	}
+	return parseRobots(string(content))
+}
+
+// lintRobots cross-checks the robots.txt generated in public/ with the
+// site's links and sitemap, for the misconfigurations site restructures
+// leave behind: working internal links into paths robots.txt disallows
+// get a warning, unless the linking page is disallowed too, and pages the
+// sitemap lists but robots.txt disallows get a page warning.
+func lintRobots(files []*scanner.File, opts Options) {
+	if !opts.CheckPublic {
+		return
+	}
+	publicDir := filepath.Join(scanner.SiteRoot(opts.RootDir), "public")
+	content, err := os.ReadFile(filepath.Join(publicDir, "robots.txt"))
+	if err != nil {
+		return
+	}
+	robots := parseRobots(string(content))
+
+	paths := make(map[*scanner.File]string, len(files))
+	if len(robots.rules) > 0 {
+		for _, file := range files {
+			if pagePath := filePagePath(file, opts); pagePath != "" {
+				paths[file] = pagePath
+			}
+		}
+		lintRobotsLinks(files, paths, robots)
+	}
+
+	warned := make(map[string]bool)
+	for _, loc := range sitemapLocations(publicDir, robots.sitemaps, opts) {
+		if !robots.disallows(loc) || warned[normalizePagePath(loc)] {
+			continue
+		}
+		warned[normalizePagePath(loc)] = true
+		for _, file := range files {
+			if paths[file] == normalizePagePath(loc) {
+				file.Warnings = append(file.Warnings, "Page is listed in the sitemap but robots.txt disallows it")
+			}
+		}
+	}
+}
+
+// lintRobotsLinks warns about the internal links to paths robots.txt
+// disallows, from pages it doesn't
+func lintRobotsLinks(files []*scanner.File, paths map[*scanner.File]string, robots *robotsRules) {
+	for _, file := range files {
+		source, ok := paths[file]
+		if !ok || robots.disallows(source) {
+			continue
+		}
+		base, err := url.Parse(source)
+		if err != nil {
+			continue
+		}
+		for i := range file.Links {
+			link := &file.Links[i]
+			if link.Type != scanner.LinkTypeInternal || link.StatusCode >= 400 || strings.HasPrefix(link.URL, "#") {
+				continue
+			}
+			ref, err := url.Parse(link.URL)
+			if err != nil {
+				continue
+			}
+			target := base.ResolveReference(ref).Path
+			if robots.disallows(target) {
+				link.Warnings = append(link.Warnings, fmt.Sprintf("robots.txt disallows %s", target))
+			}
+		}
+	}
+}
+
+// sitemap is the part of a sitemap or sitemap index that lists URLs
+type sitemap struct {
+	URLs     []string `xml:"url>loc"`
+	Sitemaps []string `xml:"sitemap>loc"`
+}
+
+// sitemapLocations returns the URL paths of the pages listed in the site's
+// sitemaps in publicDir: sitemap.xml and those robots.txt names, following
+// sitemap indexes such as the one of a multilingual site. Sitemaps on other
+// hosts are ignored.
+func sitemapLocations(publicDir string, named []string, opts Options) []string {
+	queue := append([]string{"/sitemap.xml"}, named...)
+	seen := make(map[string]bool)
+	var locations []string
+	for len(queue) > 0 {
+		current, ok := sitemapPath(queue[0], opts)
+		queue = queue[1:]
+		if !ok || seen[current] {
+			continue
+		}
+		seen[current] = true
+
+		data, err := os.ReadFile(filepath.Join(publicDir, filepath.FromSlash(current)))
+		if err != nil {
+			continue
+		}
+		var parsed sitemap
+		if err := xml.Unmarshal(data, &parsed); err != nil {
+			continue
+		}
+		queue = append(queue, parsed.Sitemaps...)
+		for _, loc := range parsed.URLs {
+			if locPath, ok := sitemapPath(loc, opts); ok {
+				locations = append(locations, locPath)
+			}
+		}
+	}
+	return locations
+}
+
+// sitemapPath returns the path on the site of a URL in a sitemap or
+// robots.txt, which are absolute. Without a configured site URL, any host
+// is taken to be the site's.
+func sitemapPath(loc string, opts Options) (string, bool) {
+	loc = strings.TrimSpace(loc)
+	if relative, ok := opts.relativeToSite(loc); ok {
+		loc = relative
+	}
+	u, err := url.Parse(loc)
+	if err != nil || (u.Host != "" && opts.siteURL() != "") {
+		return "", false
+	}
+	if u.Path == "" {
+		return "/", true
+	}
+	return u.Path, true
+}
@@ -0,0 +1,110 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestRobotsDisallows(t *testing.T) {
+	robots := parseRobots(`# Generated by Hugo
+User-agent: Googlebot
+Disallow: /
+
+User-agent: *
+Disallow: /private/
+Allow: /private/press/
+Disallow: /*.pdf$
+Disallow: /search
+
+Sitemap: https://example.com/sitemap.xml
+`)
+
+	tests := map[string]bool{
+		"/":                  false,
+		"/private/":          true,
+		"/private/notes/":    true,
+		"/private/press/":    false,
+		"/docs/manual.pdf":   true,
+		"/docs/manual.pdf/x": false,
+		"/search/":           true,
+		"/searching":         true,
+		"/docs/":             false,
+	}
+	for urlPath, want := range tests {
+		if got := robots.disallows(urlPath); got != want {
+			t.Errorf("disallows(%q) = %v, want %v", urlPath, got, want)
+		}
+	}
+	if len(robots.sitemaps) != 1 || robots.sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("Expected the sitemap named in robots.txt, got %v", robots.sitemaps)
+	}
+}
+
+func TestLintRobots(t *testing.T) {
+	tmpDir := t.TempDir()
+	pages := map[string]string{
+		"public/robots.txt":            "User-agent: *\nDisallow: /drafts/\nDisallow: /old/\n",
+		"public/sitemap.xml":           `<?xml version="1.0" encoding="utf-8"?><sitemapindex><sitemap><loc>https://example.com/en/sitemap.xml</loc></sitemap></sitemapindex>`,
+		"public/en/sitemap.xml":        `<?xml version="1.0" encoding="utf-8"?><urlset><url><loc>https://example.com/old/page/</loc></url><url><loc>https://example.com/docs/</loc></url></urlset>`,
+		"public/old/page/index.html":   "",
+		"public/docs/index.html":       "",
+		"public/drafts/wip/index.html": "",
+		"content/docs/_index.md":       "",
+		"content/drafts/wip.md":        "",
+	}
+	files := make(map[string]*scanner.File)
+	var all []*scanner.File
+	for name, content := range pages {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Ext(name) == ".html" || filepath.Ext(name) == ".md" {
+			files[name] = &scanner.File{Path: path}
+			all = append(all, files[name])
+		}
+	}
+	internal := func(u string) scanner.Link {
+		return scanner.Link{URL: u, Type: scanner.LinkTypeInternal, StatusCode: 200}
+	}
+	files["content/docs/_index.md"].Links = []scanner.Link{internal("/drafts/wip/"), internal("../old/page/"), internal("/docs/intro/")}
+	files["content/drafts/wip.md"].Links = []scanner.Link{internal("/drafts/other/")}
+
+	lintRobots(all, Options{RootDir: tmpDir, CheckPublic: true, BaseURL: "https://example.com/"})
+
+	links := files["content/docs/_index.md"].Links
+	if len(links[0].Warnings) != 1 || links[0].Warnings[0] != "robots.txt disallows /drafts/wip/" {
+		t.Errorf("Expected a warning on the link to /drafts/wip/, got %v", links[0].Warnings)
+	}
+	if len(links[1].Warnings) != 1 || links[1].Warnings[0] != "robots.txt disallows /old/page/" {
+		t.Errorf("Expected a warning on the relative link to /old/page/, got %v", links[1].Warnings)
+	}
+	if len(links[2].Warnings) != 0 {
+		t.Errorf("Expected no warning on an allowed link, got %v", links[2].Warnings)
+	}
+	if warnings := files["content/drafts/wip.md"].Links[0].Warnings; len(warnings) != 0 {
+		t.Errorf("Expected no warning on links between disallowed pages, got %v", warnings)
+	}
+
+	for name, file := range files {
+		if name == "public/old/page/index.html" {
+			if len(file.Warnings) != 1 || file.Warnings[0] != "Page is listed in the sitemap but robots.txt disallows it" {
+				t.Errorf("Expected a sitemap warning on %s, got %v", name, file.Warnings)
+			}
+		} else if len(file.Warnings) != 0 {
+			t.Errorf("%s: expected no page warnings, got %v", name, file.Warnings)
+		}
+	}
+
+	files["public/old/page/index.html"].Warnings = nil
+	lintRobots(all, Options{RootDir: tmpDir})
+	if len(files["public/old/page/index.html"].Warnings) != 0 {
+		t.Errorf("Expected no check without -check-public, got %v", files["public/old/page/index.html"].Warnings)
+	}
+}
@@ -0,0 +1,24 @@
+package checker
+
+import (
+	"strings"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// applySecretScan reports links that embed credentials or long-lived
+// secrets as broken, without fetching them so the secret isn't sent
+// anywhere, and returns true if it did
+func applySecretScan(link *scanner.Link, linkURL string) bool {
+	findings := scanner.DetectSecrets(linkURL, time.Now())
+	if len(findings) == 0 {
+		return false
+	}
+
+	link.StatusCode = 0
+	link.ErrorMessage = strings.Join(findings, "; ")
+	link.ErrorCategory = scanner.CategoryCredentialLeak
+	link.LastChecked = time.Now()
+	return true
+}
@@ -0,0 +1,22 @@
+package checker
+
+import (
+	"net/http"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// recordSecurityHeaders records the security headers a page of the site
+// was served without: Strict-Transport-Security, which only applies over
+// HTTPS and isn't expected of a plain HTTP server such as a local preview,
+// and Content-Security-Policy. A policy set with a <meta http-equiv> tag
+// isn't seen.
+func recordSecurityHeaders(link *scanner.Link, resp *http.Response) {
+	link.MissingHeaders = nil
+	if resp.Request.URL.Scheme == "https" && resp.Header.Get("Strict-Transport-Security") == "" {
+		link.MissingHeaders = append(link.MissingHeaders, "Strict-Transport-Security")
+	}
+	if resp.Header.Get("Content-Security-Policy") == "" {
+		link.MissingHeaders = append(link.MissingHeaders, "Content-Security-Policy")
+	}
+}
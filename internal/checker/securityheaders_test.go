@@ -0,0 +1,62 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckInternalLink_SecurityHeaders(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/secure/":
+			w.Header().Set("Strict-Transport-Security", "max-age=31536000")
+			w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		case "/no-csp/":
+			w.Header().Set("Strict-Transport-Security", "max-age=31536000")
+		case "/missing/":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+
+	tests := []struct {
+		baseURL string
+		path    string
+		missing []string
+	}{
+		{server.URL, "/secure/", nil},
+		{server.URL, "/no-csp/", []string{"Content-Security-Policy"}},
+		{server.URL, "/bare/", []string{"Strict-Transport-Security", "Content-Security-Policy"}},
+		{server.URL, "/missing/", nil},
+		// HSTS isn't expected over plain HTTP
+		{plain.URL, "/bare/", []string{"Content-Security-Policy"}},
+	}
+	for _, tc := range tests {
+		link := &scanner.Link{URL: tc.path, Type: scanner.LinkTypeInternal}
+		if err := checkInternalLink(link, server.Client(), Options{BaseURL: tc.baseURL, SecurityHeaders: true}); err != nil {
+			t.Fatalf("checkInternalLink failed: %v", err)
+		}
+		if !slices.Equal(link.MissingHeaders, tc.missing) {
+			t.Errorf("%s%s: expected missing headers %v, got %v", tc.baseURL, tc.path, tc.missing, link.MissingHeaders)
+		}
+		if len(tc.missing) > 0 && (len(link.Warnings) != 1 || link.Warnings[0] != "Page served without "+strings.Join(tc.missing, ", ")) {
+			t.Errorf("%s%s: expected a warning naming the missing headers, got %v", tc.baseURL, tc.path, link.Warnings)
+		}
+	}
+
+	link := &scanner.Link{URL: "/bare/", Type: scanner.LinkTypeInternal}
+	if err := checkInternalLink(link, server.Client(), Options{BaseURL: server.URL}); err != nil {
+		t.Fatalf("checkInternalLink failed: %v", err)
+	}
+	if len(link.MissingHeaders) != 0 || len(link.Warnings) != 0 {
+		t.Errorf("Expected no security headers check unless enabled, got %v %v", link.MissingHeaders, link.Warnings)
+	}
+}
@@ -0,0 +1,243 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// CacheTokenEnv names the environment variable holding the bearer token sent
+// to an HTTP shared result cache
+const CacheTokenEnv = "HUGO_LINK_CHECKER_CACHE_TOKEN"
+
+// cacheBackend holds the encoded results of a FileStore: a local file, or a
+// shared backend, so a team's CI and local runs reuse each other's results
+type cacheBackend interface {
+	// read returns the stored cache, or nil if there is none yet
+	read() ([]byte, error)
+	write(data []byte) error
+}
+
+// IsSharedCache reports whether a result cache location names a shared
+// backend, an http(s):// or s3:// URL, rather than a local file
+func IsSharedCache(location string) bool {
+	lower := strings.ToLower(location)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "s3://")
+}
+
+// newCacheBackend returns the shared backend at location
+func newCacheBackend(location string) (cacheBackend, error) {
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shared cache URL %s: %v", location, err)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	if strings.EqualFold(parsed.Scheme, "s3") {
+		return newS3CacheBackend(parsed, client)
+	}
+	return &httpCacheBackend{url: location, token: os.Getenv(CacheTokenEnv), client: client}, nil
+}
+
+// httpCacheBackend keeps the cache at a URL of an HTTP cache service, read
+// with GET and written with PUT
+type httpCacheBackend struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (s *httpCacheBackend) read() ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	return readCacheResponse(s.client, req)
+}
+
+func (s *httpCacheBackend) write(data []byte) error {
+	req, err := http.NewRequest(http.MethodPut, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	return writeCacheRequest(s.client, req)
+}
+
+// s3CacheBackend keeps the cache in an S3 object, with requests signed with
+// AWS Signature Version 4 using the credentials of the standard AWS
+// environment variables
+type s3CacheBackend struct {
+	object       *url.URL
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+}
+
+// newS3CacheBackend returns the backend for s3://bucket/key. The object is
+// addressed on AWS, virtual-hosted style, unless AWS_ENDPOINT_URL_S3 or
+// AWS_ENDPOINT_URL names another S3-compatible service, addressed path
+// style.
+func newS3CacheBackend(location *url.URL, client *http.Client) (*s3CacheBackend, error) {
+	bucket, key := location.Host, strings.TrimPrefix(location.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid shared cache URL %s: expected s3://bucket/key", location)
+	}
+	backend := &s3CacheBackend{
+		region:       firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       client,
+	}
+	if backend.region == "" {
+		backend.region = "us-east-1"
+	}
+	if backend.accessKey == "" || backend.secretKey == "" {
+		return nil, fmt.Errorf("shared cache %s needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", location)
+	}
+
+	objectURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, backend.region, key)
+	if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+		objectURL = strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + key
+	}
+	object, err := url.Parse(objectURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %s: %v", objectURL, err)
+	}
+	object.RawPath = s3EscapePath(object.Path)
+	backend.object = object
+	return backend, nil
+}
+
+func (s *s3CacheBackend) read() ([]byte, error) {
+	req, err := s.request(http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	return readCacheResponse(s.client, req)
+}
+
+func (s *s3CacheBackend) write(data []byte) error {
+	req, err := s.request(http.MethodPut, data)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return writeCacheRequest(s.client, req)
+}
+
+// request returns a signed request for the object
+func (s *s3CacheBackend) request(method string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, s.object.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sign(req, body, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// s3Signer signs S3 requests. S3 canonicalizes the path as sent rather than
+// escaping it again, so the object URL carries its S3 encoding in RawPath.
+var s3Signer = v4.NewSigner(func(options *v4.SignerOptions) {
+	options.DisableURIPathEscaping = true
+})
+
+// sign adds the AWS Signature Version 4 headers for req, carrying body, at
+// time now
+func (s *s3CacheBackend) sign(req *http.Request, body []byte, now time.Time) error {
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	credentials := aws.Credentials{AccessKeyID: s.accessKey, SecretAccessKey: s.secretKey, SessionToken: s.sessionToken}
+	if err := s3Signer.SignHTTP(context.Background(), credentials, req, payloadHash, "s3", s.region, now); err != nil {
+		return fmt.Errorf("failed to sign shared result cache request: %v", err)
+	}
+	return nil
+}
+
+// s3EscapePath percent-encodes every byte of an object path except
+// unreserved characters and slashes, as S3 expects in the canonical request;
+// url.URL.EscapedPath leaves characters such as !*'()$& as they are
+func s3EscapePath(path string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.IndexByte("-_.~/", c) != -1 {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+// readCacheResponse sends a request reading a shared cache and returns the
+// body; a cache that doesn't exist yet reads as nil
+func readCacheResponse(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared result cache: %v", err)
+	}
+	defer closeBody(resp)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to read shared result cache: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared result cache: %v", err)
+	}
+	return data, nil
+}
+
+// writeCacheRequest sends a request writing a shared cache
+func writeCacheRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write shared result cache: %v", err)
+	}
+	defer closeBody(resp)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to write shared result cache: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// firstEnv returns the first of the environment variables names that is
+// set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
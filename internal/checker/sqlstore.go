@@ -0,0 +1,125 @@
+package checker
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+
+	// Registers the pure-Go "sqlite" driver, so builds need no cgo
+	_ "modernc.org/sqlite"
+)
+
+// sqliteCacheExtensions are the file extensions of result caches kept in a
+// SQLite database rather than a JSON file
+var sqliteCacheExtensions = []string{".db", ".sqlite", ".sqlite3"}
+
+// IsSQLiteCache reports whether a result cache location names a SQLite
+// database, a local file ending in .db, .sqlite, or .sqlite3
+func IsSQLiteCache(location string) bool {
+	if IsSharedCache(location) {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(location))
+	for _, sqliteExt := range sqliteCacheExtensions {
+		if ext == sqliteExt {
+			return true
+		}
+	}
+	return false
+}
+
+// SQLStore is a CacheStore keeping results in the link_results table of a
+// SQL database, one row per destination, so a large cache is read and
+// written a result at a time rather than as one document. The statements are
+// written for SQLite and also suit databases with the same upsert syntax and
+// ? placeholders.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore returns a store in the SQLite database at path, creating
+// the database and its table if needed
+func OpenSQLiteStore(path string) (*SQLStore, error) {
+	// Other runs sharing the database wait for its lock instead of failing
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result cache %s: %v", path, err)
+	}
+	// SQLite allows one writer at a time
+	db.SetMaxOpenConns(1)
+
+	store, err := NewSQLStore(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open result cache %s: %v", path, err)
+	}
+	return store, nil
+}
+
+// NewSQLStore returns a store in db, opened with the database/sql driver of
+// the caller's choice, creating its table if needed
+func NewSQLStore(db *sql.DB) (*SQLStore, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS link_results (
+		url TEXT PRIMARY KEY,
+		checked_at INTEGER NOT NULL,
+		result TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create result cache table: %v", err)
+	}
+	return &SQLStore{db: db}, nil
+}
+
+// Get returns the stored result for key
+func (s *SQLStore) Get(key string) (scanner.Link, bool, error) {
+	var encoded string
+	err := s.db.QueryRow(`SELECT result FROM link_results WHERE url = ?`, key).Scan(&encoded)
+	if errors.Is(err, sql.ErrNoRows) {
+		return scanner.Link{}, false, nil
+	}
+	if err != nil {
+		return scanner.Link{}, false, fmt.Errorf("failed to read cached result for %s: %v", key, err)
+	}
+	var link scanner.Link
+	if err := json.Unmarshal([]byte(encoded), &link); err != nil {
+		// A result written by another version is checked again
+		return scanner.Link{}, false, nil
+	}
+	return link, true, nil
+}
+
+// Set stores the result for key
+func (s *SQLStore) Set(key string, link scanner.Link) error {
+	encoded, err := json.Marshal(link)
+	if err != nil {
+		return fmt.Errorf("failed to encode result for %s: %v", key, err)
+	}
+	_, err = s.db.Exec(`INSERT INTO link_results (url, checked_at, result) VALUES (?, ?, ?)
+		ON CONFLICT (url) DO UPDATE SET checked_at = excluded.checked_at, result = excluded.result`,
+		key, link.LastChecked.UnixNano(), string(encoded))
+	if err != nil {
+		return fmt.Errorf("failed to store result for %s: %v", key, err)
+	}
+	return nil
+}
+
+// Sweep removes the results last checked before cutoff
+func (s *SQLStore) Sweep(cutoff time.Time) error {
+	if _, err := s.db.Exec(`DELETE FROM link_results WHERE checked_at < ?`, cutoff.UnixNano()); err != nil {
+		return fmt.Errorf("failed to sweep result cache: %v", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
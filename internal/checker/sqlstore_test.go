@@ -0,0 +1,79 @@
+package checker
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestIsSQLiteCache(t *testing.T) {
+	testCases := map[string]bool{
+		".link-cache.db":                  true,
+		"cache/links.SQLite":              true,
+		"links.sqlite3":                   true,
+		".link-cache.json":                false,
+		"https://cache.example.com/lc.db": false,
+		"s3://docs-ci/link-cache.sqlite":  false,
+		"":                                false,
+	}
+
+	for location, expected := range testCases {
+		if got := IsSQLiteCache(location); got != expected {
+			t.Errorf("%q: expected %v, got %v", location, expected, got)
+		}
+	}
+}
+
+func TestSQLStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore failed: %v", err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour).UTC()
+	recent := time.Now().UTC()
+	if err := store.Set("https://example.com/old", scanner.Link{StatusCode: 404, LastChecked: old}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Set("https://example.com/ok", scanner.Link{StatusCode: 503, LastChecked: old}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	// A later result replaces the earlier one
+	if err := store.Set("https://example.com/ok", scanner.Link{StatusCode: 200, LastChecked: recent}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Results survive reopening the database
+	cache := LoadResultCache(path, time.Hour)
+	if _, ok := cache.Store.(*SQLStore); !ok {
+		t.Fatalf("expected a SQLStore, got %T", cache.Store)
+	}
+	link, ok := cache.lookup("https://example.com/ok", false)
+	if !ok || link.StatusCode != 200 || !link.LastChecked.Equal(recent) {
+		t.Errorf("expected the latest result, got %+v (found %v)", link, ok)
+	}
+	if _, ok := cache.lookup("https://example.com/old", false); ok {
+		t.Error("expected a result older than the maximum age to be missed")
+	}
+	if _, ok := cache.lookup("https://example.com/old", true); !ok {
+		t.Error("expected a result of any age to be found when asked for")
+	}
+
+	// Saving sweeps the results older than the retention
+	cache.Retention = 24 * time.Hour
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, ok, _ := cache.Store.Get("https://example.com/old"); ok {
+		t.Error("expected the old result to be swept")
+	}
+	if _, ok, _ := cache.Store.Get("https://example.com/ok"); !ok {
+		t.Error("expected the recent result to be kept")
+	}
+}
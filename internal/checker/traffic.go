@@ -0,0 +1,130 @@
+package checker
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// Traffic maps page paths, normalized like /blog/post/, to the pageviews
+// an analytics export recorded for them
+type Traffic map[string]int64
+
+// LoadTraffic reads page traffic exported from analytics: a CSV file with
+// the page URL or path in the first column and its pageviews in the second,
+// or a JSON file (by its .json extension) with an object mapping URLs to
+// pageviews or an array of objects with the URL under url, page, or path
+// and the count under pageviews or views. CSV header rows and other rows
+// whose count isn't a number are skipped, and the counts of URLs that differ
+// only in their query or host are added up.
+func LoadTraffic(path string) (Traffic, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close traffic file: %v\n", closeErr)
+		}
+	}()
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return parseTrafficJSON(file, path)
+	}
+	return parseTrafficCSV(file, path)
+}
+
+// trafficRow is one page of a JSON traffic export in array form
+type trafficRow struct {
+	URL       string `json:"url"`
+	Page      string `json:"page"`
+	Path      string `json:"path"`
+	Pageviews *int64 `json:"pageviews"`
+	Views     *int64 `json:"views"`
+}
+
+// parseTrafficJSON reads the JSON traffic export at path from r
+func parseTrafficJSON(r io.Reader, path string) (Traffic, error) {
+	var document json.RawMessage
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to parse traffic file %s: %v", path, err)
+	}
+
+	traffic := make(Traffic)
+	var byURL map[string]int64
+	if err := json.Unmarshal(document, &byURL); err == nil {
+		for pageURL, views := range byURL {
+			traffic.add(pageURL, views)
+		}
+		return traffic, nil
+	}
+	var rows []trafficRow
+	if err := json.Unmarshal(document, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse traffic file %s: expected an object of URLs and pageviews or an array of pages", path)
+	}
+	for _, row := range rows {
+		pageURL := cmp.Or(row.URL, row.Page, row.Path)
+		views := cmp.Or(row.Pageviews, row.Views)
+		if views != nil {
+			traffic.add(pageURL, *views)
+		}
+	}
+	return traffic, nil
+}
+
+// parseTrafficCSV reads the traffic CSV at path from r
+func parseTrafficCSV(r io.Reader, path string) (Traffic, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	traffic := make(Traffic)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return traffic, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read traffic file %s: %v", path, err)
+		}
+		if len(record) < 2 {
+			continue
+		}
+		views, err := strconv.ParseInt(strings.ReplaceAll(strings.TrimSpace(record[1]), ",", ""), 10, 64)
+		if err != nil {
+			continue
+		}
+		traffic.add(record[0], views)
+	}
+}
+
+// add counts the pageviews of a URL or path from an analytics export
+// toward its page
+func (traffic Traffic) add(pageURL string, views int64) {
+	parsed, err := url.Parse(strings.TrimSpace(pageURL))
+	if err != nil || parsed.Path == "" || views < 0 {
+		return
+	}
+	traffic[normalizePagePath(parsed.Path)] += views
+}
+
+// applyTraffic records on each scanned page the pageviews traffic has for
+// it. Pages missing from the export keep none.
+func applyTraffic(files []*scanner.File, traffic Traffic, opts Options) {
+	if len(traffic) == 0 {
+		return
+	}
+	for _, file := range files {
+		if pagePath := filePagePath(file, opts); pagePath != "" {
+			file.Pageviews = traffic[pagePath]
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestParseTrafficCSV(t *testing.T) {
+	input := "Page path,Views\n" +
+		"/blog/post/,\"1,200\"\n" +
+		"https://example.com/blog/post/?utm_source=x,300\n" +
+		"/Docs/Setup,50\n" +
+		"# totals\n" +
+		"/about/,n/a\n"
+	traffic, err := parseTrafficCSV(strings.NewReader(input), "traffic.csv")
+	if err != nil {
+		t.Fatalf("parseTrafficCSV failed: %v", err)
+	}
+	expected := Traffic{"/blog/post/": 1500, "/docs/setup/": 50}
+	if len(traffic) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, traffic)
+	}
+	for page, views := range expected {
+		if traffic[page] != views {
+			t.Errorf("%s: expected %d views, got %d", page, views, traffic[page])
+		}
+	}
+}
+
+func TestParseTrafficJSON(t *testing.T) {
+	for name, input := range map[string]string{
+		"object": `{"/blog/post/": 1200, "https://example.com/blog/post/": 300, "/docs/setup": 50}`,
+		"array":  `[{"page": "/blog/post/", "views": 1200}, {"url": "/blog/post/?ref=x", "pageviews": 300}, {"path": "/Docs/Setup", "views": 50}, {"page": "/about/"}]`,
+	} {
+		traffic, err := parseTrafficJSON(strings.NewReader(input), "traffic.json")
+		if err != nil {
+			t.Fatalf("%s: parseTrafficJSON failed: %v", name, err)
+		}
+		expected := Traffic{"/blog/post/": 1500, "/docs/setup/": 50}
+		if len(traffic) != len(expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, traffic)
+		}
+		for page, views := range expected {
+			if traffic[page] != views {
+				t.Errorf("%s: %s: expected %d views, got %d", name, page, views, traffic[page])
+			}
+		}
+	}
+
+	if _, err := parseTrafficJSON(strings.NewReader(`"nope"`), "traffic.json"); err == nil {
+		t.Error("expected an error for JSON that is neither an object nor an array")
+	}
+}
+
+func TestApplyTraffic(t *testing.T) {
+	dir := t.TempDir()
+	post := filepath.Join(dir, "content", "blog", "post.md")
+	if err := os.MkdirAll(filepath.Dir(post), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(post, []byte("---\ntitle: Post\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []*scanner.File{{Path: post}, {Path: filepath.Join(dir, "content", "other.md")}}
+	applyTraffic(files, Traffic{"/blog/post/": 1500}, Options{RootDir: dir})
+	if files[0].Pageviews != 1500 || files[1].Pageviews != 0 {
+		t.Errorf("expected 1500 and 0 pageviews, got %d and %d", files[0].Pageviews, files[1].Pageviews)
+	}
+}
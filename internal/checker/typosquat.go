@@ -0,0 +1,144 @@
+package checker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// popularDomains are frequently linked domains that typosquatters imitate
+var popularDomains = []string{
+	"amazon.com", "apple.com", "bitbucket.org", "cloudflare.com", "docker.com",
+	"dropbox.com", "facebook.com", "github.com", "github.io", "gitlab.com",
+	"golang.org", "google.com", "googleapis.com", "instagram.com", "linkedin.com",
+	"medium.com", "microsoft.com", "mozilla.org", "netflix.com", "npmjs.com",
+	"paypal.com", "python.org", "reddit.com", "stackoverflow.com", "twitter.com",
+	"wikipedia.org", "wordpress.com", "youtube.com",
+}
+
+// confusables maps characters that render like ASCII letters to those
+// letters, covering the common Cyrillic and Greek homoglyphs
+var confusables = strings.NewReplacer(
+	"а", "a", "е", "e", "о", "o", "р", "p", "с", "c", "у", "y", "х", "x",
+	"і", "i", "ј", "j", "ѕ", "s", "ԁ", "d", "ɡ", "g", "һ", "h", "ӏ", "l",
+	"α", "a", "ο", "o", "ν", "v", "ι", "i", "κ", "k", "ρ", "p", "τ", "t",
+	"rn", "m", "vv", "w", "0", "o", "1", "l",
+)
+
+// minTypoLabelLength is the shortest domain label compared by edit
+// distance; shorter names are too close to each other to be meaningful
+const minTypoLabelLength = 5
+
+// lintTyposquats warns about external links whose domain is a near miss of
+// a popular domain or of a more frequently linked domain on the site
+func lintTyposquats(files []*scanner.File) {
+	counts := make(map[string]int)
+	for _, file := range files {
+		for _, link := range file.Links {
+			if domain := registrableDomain(link); domain != "" {
+				counts[domain]++
+			}
+		}
+	}
+
+	lookalikes := make(map[string]string)
+	for domain, count := range counts {
+		if slices.Contains(popularDomains, domain) {
+			continue
+		}
+		for _, target := range popularDomains {
+			if looksLike(domain, target) {
+				lookalikes[domain] = target
+				break
+			}
+		}
+		if _, found := lookalikes[domain]; found {
+			continue
+		}
+		for other, otherCount := range counts {
+			if other != domain && otherCount >= count && looksLike(domain, other) {
+				lookalikes[domain] = other
+				break
+			}
+		}
+	}
+
+	for _, file := range files {
+		for i := range file.Links {
+			link := &file.Links[i]
+			if target, found := lookalikes[registrableDomain(*link)]; found {
+				link.Warnings = append(link.Warnings, fmt.Sprintf("Domain %s looks like %s (possible typo or typosquat)", registrableDomain(*link), target))
+			}
+		}
+	}
+}
+
+// registrableDomain returns the registrable domain (eTLD+1) of an external
+// link in Unicode form, or "" for links without a host
+func registrableDomain(link scanner.Link) string {
+	if link.Type != scanner.LinkTypeExternal {
+		return ""
+	}
+	host := scanner.LinkDomain(link)
+	if host == "" {
+		return ""
+	}
+	if unicodeHost, err := idna.ToUnicode(host); err == nil {
+		host = unicodeHost
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// looksLike reports whether domain is a homoglyph of target or, for long
+// enough names, one edit away from it
+func looksLike(domain, target string) bool {
+	if domain == target {
+		return false
+	}
+	if confusables.Replace(domain) == confusables.Replace(target) {
+		return true
+	}
+
+	label, _, _ := strings.Cut(domain, ".")
+	if len([]rune(label)) < minTypoLabelLength {
+		return false
+	}
+	return editDistance(domain, target) == 1
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: insertions, deletions, substitutions, and adjacent transpositions
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
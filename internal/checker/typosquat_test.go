@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestLooksLike(t *testing.T) {
+	testCases := []struct {
+		domain   string
+		target   string
+		expected bool
+	}{
+		{"gooogle.com", "google.com", true},
+		{"githbu.com", "github.com", true},
+		{"gіthub.com", "github.com", true}, // Cyrillic і
+		{"rnicrosoft.com", "microsoft.com", true},
+		{"go.dev", "go.de", false},
+		{"google.com", "google.com", false},
+		{"example.com", "github.com", false},
+	}
+
+	for _, tc := range testCases {
+		if got := looksLike(tc.domain, tc.target); got != tc.expected {
+			t.Errorf("looksLike(%s, %s): expected %v, got %v", tc.domain, tc.target, tc.expected, got)
+		}
+	}
+}
+
+func TestLintTyposquats(t *testing.T) {
+	file := &scanner.File{
+		Path: "content/index.md",
+		Links: []scanner.Link{
+			scanner.NewLink("https://www.gooogle.com/search"),
+			scanner.NewLink("https://xn--gthub-n4a.com/"), // gіthub.com in punycode
+			scanner.NewLink("https://docs.example.com/a"),
+			scanner.NewLink("https://docs.example.com/b"),
+			scanner.NewLink("https://docs.exampel.com/c"),
+			scanner.NewLink("https://api.example.com/"),
+			scanner.NewLink("https://github.com/"),
+		},
+	}
+
+	lintTyposquats([]*scanner.File{file})
+
+	for i, expected := range []string{"google.com", "github.com", "", "", "example.com", "", ""} {
+		warnings := strings.Join(file.Links[i].Warnings, " ")
+		if expected == "" && warnings != "" {
+			t.Errorf("%s: unexpected warning %s", file.Links[i].URL, warnings)
+		}
+		if expected != "" && !strings.Contains(warnings, "looks like "+expected) {
+			t.Errorf("%s: expected lookalike warning for %s, got %q", file.Links[i].URL, expected, warnings)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ParseUnicodeForm validates a Unicode normalization form name. Valid names
+// are nfc, nfd, nfkc, nfkd, and none; the empty string selects nfc.
+func ParseUnicodeForm(name string) (string, error) {
+	switch form := strings.ToLower(strings.TrimSpace(name)); form {
+	case "", "nfc":
+		return "nfc", nil
+	case "nfd", "nfkc", "nfkd", "none":
+		return form, nil
+	default:
+		return "", fmt.Errorf("invalid unicode normalization %q: must be nfc, nfd, nfkc, nfkd, or none", name)
+	}
+}
+
+// normalizeString applies the named normalization form to s
+func normalizeString(s string, form string) string {
+	switch form {
+	case "none":
+		return s
+	case "nfd":
+		return norm.NFD.String(s)
+	case "nfkc":
+		return norm.NFKC.String(s)
+	case "nfkd":
+		return norm.NFKD.String(s)
+	default:
+		return norm.NFC.String(s)
+	}
+}
+
+// findNormalizedFile locates targetPath on disk when its components differ
+// from the names on disk only by Unicode normalization (e.g. NFC links to
+// NFD filenames on macOS). It returns the on-disk path, or "" if none.
+func findNormalizedFile(targetPath string, form string) string {
+	if form == "none" || isASCII(targetPath) {
+		return ""
+	}
+
+	volume := filepath.VolumeName(targetPath)
+	rest := strings.TrimPrefix(targetPath, volume)
+	current := volume
+	if filepath.IsAbs(targetPath) {
+		current += string(filepath.Separator)
+	}
+
+	for _, component := range strings.Split(filepath.Clean(rest), string(filepath.Separator)) {
+		if component == "" {
+			continue
+		}
+
+		candidate := filepath.Join(current, component)
+		if current == "" {
+			candidate = component
+		}
+		if _, err := os.Lstat(candidate); err == nil {
+			current = candidate
+			continue
+		}
+
+		dir := current
+		if dir == "" {
+			dir = "."
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return ""
+		}
+
+		want := normalizeString(component, form)
+		match := ""
+		for _, entry := range entries {
+			if normalizeString(entry.Name(), form) == want {
+				match = entry.Name()
+				break
+			}
+		}
+		if match == "" {
+			return ""
+		}
+		current = filepath.Join(current, match)
+	}
+
+	return current
+}
+
+// isASCII reports whether s contains only ASCII characters, which no
+// normalization form changes
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package checker
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestCheckInternalLink_UnicodeNormalization(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Store the filename decomposed (NFD), as macOS does
+	dir := filepath.Join(tmpDir, "content", norm.NFD.String("café"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.md"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	testCases := []struct {
+		url            string
+		form           string
+		expectedStatus int
+	}{
+		{norm.NFC.String("/café/"), "", 200},
+		{"/caf%C3%A9/", "nfc", 200},
+		{norm.NFC.String("/café/"), "none", 404},
+	}
+
+	for _, tc := range testCases {
+		link := &scanner.Link{URL: tc.url, Type: scanner.LinkTypeInternal}
+		if err := checkInternalLink(link, client, Options{RootDir: tmpDir, UnicodeForm: tc.form}); err != nil {
+			t.Fatalf("checkInternalLink failed: %v", err)
+		}
+		if link.StatusCode != tc.expectedStatus {
+			t.Errorf("%s (form %q): expected status %d, got %d", tc.url, tc.form, tc.expectedStatus, link.StatusCode)
+		}
+	}
+}
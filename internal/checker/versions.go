@@ -0,0 +1,78 @@
+package checker
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// DocVersions describes a versioned documentation section such as
+// content/docs/v1 and content/docs/v2
+type DocVersions struct {
+	// Section is the content section holding the versions, e.g. "docs"
+	Section string
+	// Versions are the names of the version directories
+	Versions []string
+	// Latest is the current version
+	Latest string
+	// Alias is the URL segment that points at Latest, e.g. "latest"
+	Alias string
+}
+
+// VersionOf returns the docs version a file belongs to, or "" for pages
+// outside the versioned section
+func (v *DocVersions) VersionOf(file *scanner.File) string {
+	path := file.Path
+	if file.SourcePath != "" {
+		path = file.SourcePath
+	}
+
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == v.Section && slices.Contains(v.Versions, parts[i+1]) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// resolve maps an absolute link into the versioned section onto the right
+// version tree: the alias points at the latest version, and unversioned
+// links to pages that don't exist resolve within the version of the page
+// containing them, if the page exists there. Unversioned pages such as
+// /docs/ and /docs/faq/ are linked as they are; exists reports whether a
+// link path names a page.
+func (v *DocVersions) resolve(linkPath, pageVersion string, exists func(string) bool) string {
+	prefix := "/" + v.Section + "/"
+	if !strings.HasPrefix(linkPath, prefix) {
+		return linkPath
+	}
+
+	rest := strings.TrimPrefix(linkPath, prefix)
+	first, remainder, hasSlash := strings.Cut(rest, "/")
+	if end := strings.IndexAny(first, "?#"); end != -1 {
+		first, remainder, hasSlash = first[:end], first[end:]+remainder, false
+	}
+
+	switch {
+	case v.Alias != "" && first == v.Alias:
+		if hasSlash {
+			return prefix + v.Latest + "/" + remainder
+		}
+		return prefix + v.Latest + remainder
+	case slices.Contains(v.Versions, first):
+		return linkPath
+	}
+
+	version := pageVersion
+	if version == "" {
+		version = v.Latest
+	}
+	versioned := prefix + version + "/" + rest
+	if exists(linkPath) || !exists(versioned) {
+		return linkPath
+	}
+	return versioned
+}
@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestDocVersions(t *testing.T) {
+	versions := &DocVersions{Section: "docs", Versions: []string{"v1", "v2"}, Latest: "v2", Alias: "latest"}
+
+	if got := versions.VersionOf(&scanner.File{Path: "content/docs/v1/install.md"}); got != "v1" {
+		t.Errorf("Expected v1, got %q", got)
+	}
+	if got := versions.VersionOf(&scanner.File{Path: "content/blog/post.md"}); got != "" {
+		t.Errorf("Expected unversioned page, got %q", got)
+	}
+
+	pages := map[string]bool{
+		"/docs/": true, "/docs/faq/": true,
+		"/docs/v1/install/": true, "/docs/v2/install/": true, "/docs/v2/upgrade/": true,
+	}
+	exists := func(linkPath string) bool { return pages[linkPath] }
+
+	testCases := []struct {
+		link        string
+		pageVersion string
+		expected    string
+	}{
+		{"/docs/latest/install/", "v1", "/docs/v2/install/"},
+		{"/docs/latest#intro", "", "/docs/v2#intro"},
+		{"/docs/v1/install/", "v2", "/docs/v1/install/"},
+		{"/docs/install/", "v1", "/docs/v1/install/"},
+		{"/docs/install/", "", "/docs/v2/install/"},
+		{"/blog/post/", "v1", "/blog/post/"},
+		// Unversioned pages are linked as they are
+		{"/docs/", "v1", "/docs/"},
+		{"/docs/faq/", "v1", "/docs/faq/"},
+		// Pages missing from the page's version too are left to be reported
+		{"/docs/upgrade/", "v1", "/docs/upgrade/"},
+		{"/docs/upgrade/", "v2", "/docs/v2/upgrade/"},
+	}
+	for _, tc := range testCases {
+		if got := versions.resolve(tc.link, tc.pageVersion, exists); got != tc.expected {
+			t.Errorf("resolve(%s, %s): expected %s, got %s", tc.link, tc.pageVersion, tc.expected, got)
+		}
+	}
+}
+
+func TestCheckLinks_Versions(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, page := range []string{"content/docs/_index.md", "content/docs/faq.md", "content/docs/v1/install.md",
+		"content/docs/v2/install.md", "content/docs/v2/upgrade.md"} {
+		path := filepath.Join(tmpDir, page)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# Page"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := &scanner.File{
+		Path: filepath.Join(tmpDir, "content/docs/v1/install.md"),
+		Links: []scanner.Link{
+			scanner.NewLink("/docs/latest/upgrade/"),
+			scanner.NewLink("/docs/upgrade/"),
+			scanner.NewLink("/docs/install/"),
+			scanner.NewLink("/docs/faq/"),
+		},
+	}
+	opts := Options{
+		RootDir:  tmpDir,
+		Versions: &DocVersions{Section: "docs", Versions: []string{"v1", "v2"}, Latest: "v2", Alias: "latest"},
+	}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, opts); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	if link := file.Links[0]; link.StatusCode != 200 || link.CheckedURL != "/docs/v2/upgrade/" {
+		t.Errorf("Expected latest alias resolved in v2, got status %d checked %q", link.StatusCode, link.CheckedURL)
+	}
+	if link := file.Links[1]; link.StatusCode != 404 || link.CheckedURL != "" {
+		t.Errorf("Expected a link missing from v1 reported as written, got status %d checked %q", link.StatusCode, link.CheckedURL)
+	}
+	if link := file.Links[2]; link.StatusCode != 200 || link.CheckedURL != "/docs/v1/install/" {
+		t.Errorf("Expected unversioned link checked in the page's v1 tree, got status %d checked %q", link.StatusCode, link.CheckedURL)
+	}
+	if link := file.Links[3]; link.StatusCode != 200 || link.CheckedURL != "" {
+		t.Errorf("Expected the unversioned FAQ checked as written, got status %d checked %q", link.StatusCode, link.CheckedURL)
+	}
+}
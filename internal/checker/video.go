@@ -0,0 +1,108 @@
+package checker
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// oEmbedEndpoints maps video hosting domains to the oEmbed endpoint used to
+// confirm that a video still exists. Video platforms serve a 200 "video
+// unavailable" page for removed videos, so a plain HEAD/GET is not enough.
+var oEmbedEndpoints = map[string]string{
+	"youtube.com":              "https://www.youtube.com/oembed",
+	"www.youtube.com":          "https://www.youtube.com/oembed",
+	"m.youtube.com":            "https://www.youtube.com/oembed",
+	"youtu.be":                 "https://www.youtube.com/oembed",
+	"vimeo.com":                "https://vimeo.com/api/oembed.json",
+	"www.vimeo.com":            "https://vimeo.com/api/oembed.json",
+	"player.vimeo.com":         "https://vimeo.com/api/oembed.json",
+	"www.youtube-nocookie.com": "https://www.youtube.com/oembed",
+}
+
+// oEmbedEndpoint returns the oEmbed endpoint for a video link, or "" if the
+// link is not a video on a known video platform. Channels, playlists, and
+// other pages on those platforms are checked as ordinary external links,
+// since oEmbed rejects them as if they were missing videos.
+func oEmbedEndpoint(linkURL string) string {
+	u, err := url.Parse(linkURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	endpoint := oEmbedEndpoints[host]
+	if endpoint == "" || !isVideoPath(host, u) {
+		return ""
+	}
+	return endpoint
+}
+
+// isVideoPath reports whether a URL on a video platform's host addresses a
+// single video: /watch?v= and /embed/ on YouTube, youtu.be/<id>,
+// vimeo.com/<digits>, and the Vimeo player's /video/<digits>
+func isVideoPath(host string, u *url.URL) bool {
+	first, rest, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	switch host {
+	case "youtu.be":
+		return first != "" && rest == ""
+	case "vimeo.com", "www.vimeo.com":
+		return isDigits(first)
+	case "player.vimeo.com":
+		id, _, _ := strings.Cut(rest, "/")
+		return first == "video" && isDigits(id)
+	default:
+		if first == "watch" && rest == "" {
+			return u.Query().Get("v") != ""
+		}
+		// /embed/videoseries embeds a playlist
+		return first == "embed" && rest != "" && rest != "videoseries"
+	}
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkVideoLink validates a YouTube/Vimeo link through the platform's oEmbed
+// endpoint. It returns true if the oEmbed response was conclusive and the
+// link result has been recorded; false means the caller should fall back to a
+// regular HTTP check.
+func checkVideoLink(client *http.Client, link *scanner.Link, endpoint string) bool {
+	query := url.Values{}
+	query.Set("url", link.URL)
+	query.Set("format", "json")
+
+	resp, err := client.Get(endpoint + "?" + query.Encode())
+	if err != nil {
+		return false
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		link.StatusCode = http.StatusOK
+		link.ErrorMessage = ""
+		return true
+	case http.StatusNotFound, http.StatusBadRequest:
+		// Removed or never-existing videos
+		link.StatusCode = http.StatusNotFound
+		link.ErrorMessage = "Video unavailable"
+		link.ErrorCategory = scanner.CategoryHTTP4xx
+		return true
+	default:
+		// Private or embed-restricted videos (401/403) and provider errors are
+		// not conclusive; let the regular check decide.
+		return false
+	}
+}
@@ -0,0 +1,74 @@
+package checker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckVideoLink(t *testing.T) {
+	// Fake oEmbed provider: only the "ok" video exists
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case "https://www.youtube.com/watch?v=ok":
+			w.WriteHeader(http.StatusOK)
+		case "https://www.youtube.com/watch?v=private":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	testCases := []struct {
+		url            string
+		conclusive     bool
+		expectedStatus int
+	}{
+		{"https://www.youtube.com/watch?v=ok", true, 200},
+		{"https://www.youtube.com/watch?v=removed", true, 404},
+		{"https://www.youtube.com/watch?v=private", false, 0},
+	}
+
+	for _, tc := range testCases {
+		link := &scanner.Link{URL: tc.url}
+		conclusive := checkVideoLink(client, link, server.URL)
+		if conclusive != tc.conclusive {
+			t.Errorf("%s: expected conclusive=%v, got %v", tc.url, tc.conclusive, conclusive)
+		}
+		if link.StatusCode != tc.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", tc.url, tc.expectedStatus, link.StatusCode)
+		}
+	}
+}
+
+func TestOEmbedEndpoint(t *testing.T) {
+	testCases := map[string]bool{
+		"https://www.youtube.com/watch?v=abc":                  true,
+		"https://youtu.be/abc":                                 true,
+		"https://www.youtube-nocookie.com/embed/abc":           true,
+		"https://vimeo.com/12345":                              true,
+		"https://player.vimeo.com/video/12345":                 true,
+		"https://www.youtube.com/@example":                     false,
+		"https://www.youtube.com/channel/UCabc":                false,
+		"https://www.youtube.com/playlist?list=PLabc":          false,
+		"https://www.youtube.com/embed/videoseries?list=PLabc": false,
+		"https://www.youtube.com/watch":                        false,
+		"https://www.youtube.com/":                             false,
+		"https://vimeo.com/example":                            false,
+		"https://vimeo.com/":                                   false,
+		"https://example.com/video":                            false,
+		"not a url %":                                          false,
+	}
+
+	for linkURL, expected := range testCases {
+		if got := oEmbedEndpoint(linkURL) != ""; got != expected {
+			t.Errorf("%s: expected video=%v, got %v", linkURL, expected, got)
+		}
+	}
+}
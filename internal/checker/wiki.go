@@ -0,0 +1,54 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// checkWikiLink resolves the page a wiki link such as [[Page Name#Heading]]
+// names the way refs are resolved (see checkRefLink): by path from the page
+// or the content directory, or by file name anywhere in the content tree.
+// The name is tried as written, then with spaces as dashes, as notes
+// migrated from Obsidian are often renamed. A heading is matched by its
+// text, which Obsidian links use in place of the anchor; block references
+// (#^id) are Obsidian's own and aren't checked.
+func checkWikiLink(link *scanner.Link, file *scanner.File, index anchorIndex, opts Options) {
+	pagePath, heading, _ := strings.Cut(link.URL, "#")
+	pagePath, heading = strings.TrimSpace(pagePath), strings.TrimSpace(heading)
+
+	found := file.Path
+	if pagePath != "" {
+		var matches []string
+		found, matches = findRefPage(pagePath, file.Path, opts)
+		if dashed := strings.ReplaceAll(pagePath, " ", "-"); found == "" && len(matches) == 0 && dashed != pagePath {
+			found, matches = findRefPage(dashed, file.Path, opts)
+		}
+		if found == "" {
+			link.StatusCode = 404
+			link.ErrorCategory = scanner.CategoryNotFoundLocal
+			if len(matches) > 1 {
+				link.ErrorMessage = ambiguousMessage(pagePath, matches, opts)
+			} else {
+				link.ErrorMessage = fmt.Sprintf("Wiki link target not found: %s", pagePath)
+			}
+			return
+		}
+		if lintUnrenderedLink(link, found, opts) {
+			return
+		}
+	}
+
+	if heading != "" && !strings.HasPrefix(heading, "^") {
+		anchors := index.anchors(found, opts.HeadingIDType)
+		if anchors != nil && !anchors[heading] && !anchors[scanner.Anchorize(heading, opts.HeadingIDType)] {
+			link.StatusCode = 404
+			link.ErrorMessage = "Anchor not found"
+			link.ErrorCategory = scanner.CategoryMissingAnchor
+			return
+		}
+	}
+	link.StatusCode = 200
+	link.ErrorMessage = ""
+}
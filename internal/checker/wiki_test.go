@@ -0,0 +1,71 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestCheckLinks_WikiLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	pages := map[string]string{
+		"content/docs/getting-started.md": "# Getting Started\n\n## First Steps\n",
+		"content/docs/Install Guide.md":   "# Install\n",
+		"content/docs/notes.md":           "# Notes\n",
+		"content/guides/faq.md":           "# FAQ\n",
+		"content/support/faq.md":          "# FAQ\n",
+		"content/blog/post.md":            "# Post\n\n## Local Heading\n",
+	}
+	for name, content := range pages {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wiki := func(target string) scanner.Link {
+		return scanner.Link{URL: target, Type: scanner.LinkTypeInternal, Kind: scanner.KindWiki}
+	}
+	file := &scanner.File{
+		Path: filepath.Join(tmpDir, "content", "blog", "post.md"),
+		Links: []scanner.Link{
+			wiki("Getting Started"),
+			wiki("Getting Started#First Steps"),
+			wiki("Install Guide"),
+			wiki("docs/notes"),
+			wiki("#Local Heading"),
+			wiki("Getting Started#^block-id"),
+			wiki("Release Notes"),
+			wiki("Getting Started#Missing"),
+			wiki("FAQ"),
+		},
+	}
+	if err := CheckLinksWithOptions([]*scanner.File{file}, Options{RootDir: tmpDir}); err != nil {
+		t.Fatalf("CheckLinksWithOptions failed: %v", err)
+	}
+
+	for _, link := range file.Links[:6] {
+		if link.StatusCode != 200 {
+			t.Errorf("Expected %s to resolve, got %d %q", link.URL, link.StatusCode, link.ErrorMessage)
+		}
+	}
+	expected := []struct {
+		message  string
+		category scanner.ErrorCategory
+	}{
+		{"Wiki link target not found: Release Notes", scanner.CategoryNotFoundLocal},
+		{"Anchor not found", scanner.CategoryMissingAnchor},
+		{"Ambiguous reference FAQ: matches content/guides/faq.md, content/support/faq.md", scanner.CategoryNotFoundLocal},
+	}
+	for i, want := range expected {
+		link := file.Links[6+i]
+		if link.StatusCode != 404 || link.ErrorMessage != want.message || link.ErrorCategory != want.category {
+			t.Errorf("%s: expected %q (%s), got %d %q (%s)", link.URL, want.message, want.category, link.StatusCode, link.ErrorMessage, link.ErrorCategory)
+		}
+	}
+}
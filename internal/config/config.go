@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config declares the Hugo sites checked in one invocation and the
+// rewrite rules applied to links before checking
+type Config struct {
+	Sites    []Site    `yaml:"sites"`
+	Rewrites []Rewrite `yaml:"rewrites"`
+	Versions *Versions `yaml:"versions"`
+	Rules    []Rule    `yaml:"rules"`
+	LinkText LinkText  `yaml:"link_text"`
+	// LinkBudget caps the links per page
+	LinkBudget LinkBudget `yaml:"link_budget"`
+	// Shortcodes maps custom shortcode names to their arguments that hold
+	// URLs: named ones by name, positional ones by their index from 0
+	Shortcodes map[string][]string `yaml:"shortcodes"`
+	// Sinks receive the summary and findings of each run, e.g. to post
+	// them to a webhook or export them as metrics
+	Sinks []Sink `yaml:"sinks"`
+}
+
+// Sink declares a report sink: its type, which selects the implementation,
+// and the settings of that type, which the implementation decodes itself
+type Sink struct {
+	Type string
+	node yaml.Node
+}
+
+// UnmarshalYAML reads the sink's type and keeps the whole entry for Decode
+func (s *Sink) UnmarshalYAML(node *yaml.Node) error {
+	var head struct {
+		Type string `yaml:"type"`
+	}
+	if err := node.Decode(&head); err != nil {
+		return err
+	}
+	s.Type, s.node = head.Type, *node
+	return nil
+}
+
+// Decode decodes the sink's settings into v, a struct with yaml tags
+func (s Sink) Decode(v any) error {
+	return s.node.Decode(v)
+}
+
+// LinkBudget caps the links on a page, e.g. to keep posts from turning into
+// link farms. Zero disables a limit.
+type LinkBudget struct {
+	MaxExternal int `yaml:"max_external"`
+	MaxTotal    int `yaml:"max_total"`
+}
+
+// LinkText enables the link text lint for a project
+type LinkText struct {
+	Enabled bool `yaml:"enabled"`
+	// Generic lists additional phrases treated as generic link text
+	Generic []string `yaml:"generic"`
+}
+
+// Rule is a link policy rule. A link violates the rule when all of its
+// non-empty matchers match.
+type Rule struct {
+	ID string `yaml:"id"`
+	// Severity is "error" (the default) or "warning"
+	Severity string `yaml:"severity"`
+	Message  string `yaml:"message"`
+	// Scheme matches the URL scheme, e.g. "http"
+	Scheme string `yaml:"scheme"`
+	// Host matches the host and its subdomains; globs like *.internal.corp work
+	Host string `yaml:"host"`
+	// Match is a regular expression matched against the whole URL
+	Match string `yaml:"match"`
+}
+
+// Rewrite maps links matching a regular expression to a replacement
+// before they are checked, e.g. a production domain onto a staging host
+type Rewrite struct {
+	Match   string `yaml:"match"`
+	Replace string `yaml:"replace"`
+}
+
+// Site is one Hugo site root in a multi-site config
+type Site struct {
+	// Name identifies the site in the combined report
+	Name string `yaml:"name"`
+	// Root is the Hugo site root, relative to the config file
+	Root string `yaml:"root"`
+	// BaseURL is where the site is served. Absolute links to it from the
+	// other declared sites are resolved against its local tree.
+	BaseURL string `yaml:"base_url"`
+	// CheckOnline checks the site's internal links online against BaseURL
+	// rather than against its local tree
+	CheckOnline bool `yaml:"check_online"`
+	// Content lists directories to scan, relative to Root (default: Root)
+	Content []string `yaml:"content"`
+	// Ignore holds regular expressions for links to ignore in this site
+	Ignore []string `yaml:"ignore"`
+	// Versions describes versioned docs in this site
+	Versions *Versions `yaml:"versions"`
+}
+
+// Load reads and validates a config file. Site roots are resolved relative
+// to the directory containing the file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %v", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %v", path, err)
+	}
+
+	baseDir := filepath.Dir(path)
+	names := make(map[string]bool)
+	for i := range cfg.Sites {
+		site := &cfg.Sites[i]
+		if site.Root == "" {
+			return nil, fmt.Errorf("site %d in %s has no root", i+1, path)
+		}
+		if !filepath.IsAbs(site.Root) {
+			site.Root = filepath.Join(baseDir, site.Root)
+		}
+		if site.Name == "" {
+			site.Name = filepath.Base(site.Root)
+		}
+		if names[site.Name] {
+			return nil, fmt.Errorf("duplicate site name %q in %s", site.Name, path)
+		}
+		names[site.Name] = true
+		if site.CheckOnline && site.BaseURL == "" {
+			return nil, fmt.Errorf("site %s in %s checks online but has no base_url", site.Name, path)
+		}
+		for _, pattern := range site.Ignore {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("site %s: invalid ignore pattern %q: %v", site.Name, pattern, err)
+			}
+		}
+	}
+
+	for _, rewrite := range cfg.Rewrites {
+		if _, err := regexp.Compile(rewrite.Match); err != nil {
+			return nil, fmt.Errorf("invalid rewrite pattern %q in %s: %v", rewrite.Match, path, err)
+		}
+	}
+
+	ids := make(map[string]bool)
+	for _, rule := range cfg.Rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("policy rule without id in %s", path)
+		}
+		if ids[rule.ID] {
+			return nil, fmt.Errorf("duplicate policy rule id %q in %s", rule.ID, path)
+		}
+		ids[rule.ID] = true
+	}
+
+	for name, args := range cfg.Shortcodes {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("shortcode %q in %s lists no URL arguments", name, path)
+		}
+	}
+
+	if cfg.LinkBudget.MaxExternal < 0 || cfg.LinkBudget.MaxTotal < 0 {
+		return nil, fmt.Errorf("negative link budget in %s", path)
+	}
+
+	for i, sink := range cfg.Sinks {
+		if sink.Type == "" {
+			return nil, fmt.Errorf("sink %d in %s has no type", i+1, path)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// ContentPaths returns the directories to scan for the site
+func (s Site) ContentPaths() []string {
+	if len(s.Content) == 0 {
+		return []string{s.Root}
+	}
+	paths := make([]string, 0, len(s.Content))
+	for _, dir := range s.Content {
+		paths = append(paths, filepath.Join(s.Root, dir))
+	}
+	return paths
+}
+
+// IgnorePatterns compiles the site's ignore patterns
+func (s Site) IgnorePatterns() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(s.Ignore))
+	for _, pattern := range s.Ignore {
+		// Patterns were validated by Load
+		patterns = append(patterns, regexp.MustCompile(pattern))
+	}
+	return patterns
+}
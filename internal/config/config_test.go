@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".hugo-link-checker.yaml")
+	data := `sites:
+  - name: docs
+    root: sites/docs
+    base_url: https://docs.example.com
+    content: [content]
+    ignore:
+      - ^https?://localhost
+  - root: sites/blog
+rewrites:
+  - match: ^https://docs\.example\.com/(.*)$
+    replace: /docs/$1
+link_budget:
+  max_external: 20
+shortcodes:
+  button: [href]
+  gallery-link: [0, image]
+sinks:
+  - type: webhook
+    url: https://hooks.example.com/links
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Sites) != 2 {
+		t.Fatalf("Expected 2 sites, got %d", len(cfg.Sites))
+	}
+
+	if len(cfg.Rewrites) != 1 || cfg.Rewrites[0].Replace != "/docs/$1" {
+		t.Errorf("Unexpected rewrites: %+v", cfg.Rewrites)
+	}
+	if cfg.LinkBudget.MaxExternal != 20 || cfg.LinkBudget.MaxTotal != 0 {
+		t.Errorf("Unexpected link budget: %+v", cfg.LinkBudget)
+	}
+	if args := cfg.Shortcodes["gallery-link"]; len(cfg.Shortcodes) != 2 || len(args) != 2 || args[0] != "0" || args[1] != "image" {
+		t.Errorf("Unexpected shortcodes: %v", cfg.Shortcodes)
+	}
+	var webhook struct {
+		URL string `yaml:"url"`
+	}
+	if len(cfg.Sinks) != 1 || cfg.Sinks[0].Type != "webhook" {
+		t.Fatalf("Unexpected sinks: %+v", cfg.Sinks)
+	}
+	if err := cfg.Sinks[0].Decode(&webhook); err != nil || webhook.URL != "https://hooks.example.com/links" {
+		t.Errorf("Expected the sink's settings to decode, got %+v %v", webhook, err)
+	}
+
+	docs := cfg.Sites[0]
+	if docs.Root != filepath.Join(dir, "sites/docs") {
+		t.Errorf("Expected root resolved against config dir, got %s", docs.Root)
+	}
+	if paths := docs.ContentPaths(); len(paths) != 1 || paths[0] != filepath.Join(dir, "sites/docs/content") {
+		t.Errorf("Unexpected content paths: %v", paths)
+	}
+	if patterns := docs.IgnorePatterns(); len(patterns) != 1 || !patterns[0].MatchString("http://localhost:1313/") {
+		t.Errorf("Unexpected ignore patterns: %v", patterns)
+	}
+
+	blog := cfg.Sites[1]
+	if blog.Name != "blog" {
+		t.Errorf("Expected name derived from root, got %s", blog.Name)
+	}
+	if paths := blog.ContentPaths(); len(paths) != 1 || paths[0] != blog.Root {
+		t.Errorf("Expected site root as default content path, got %v", paths)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	testCases := map[string]string{
+		"missing root":    "sites:\n  - name: a\n",
+		"duplicate name":  "sites:\n  - {name: a, root: x}\n  - {name: a, root: y}\n",
+		"bad pattern":     "sites:\n  - {root: x, ignore: ['[']}\n",
+		"online no url":   "sites:\n  - {root: x, check_online: true}\n",
+		"bad rewrite":     "rewrites:\n  - {match: '(', replace: x}\n",
+		"rule without id": "rules:\n  - {scheme: http}\n",
+		"duplicate rule":  "rules:\n  - {id: a, scheme: http}\n  - {id: a, host: x.com}\n",
+		"negative budget": "link_budget: {max_external: -1}\n",
+		"no url args":     "shortcodes:\n  button: []\n",
+		"sink no type":    "sinks:\n  - {url: 'https://example.com'}\n",
+	}
+
+	for name, data := range testCases {
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
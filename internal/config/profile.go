@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile adjusts scanning and resolution to the conventions of a theme
+type Profile struct {
+	Name string
+	// ExcludeDirs are directory names skipped while scanning
+	ExcludeDirs []string
+	// ContentDirs are content directories, relative to the site root, that
+	// internal links are also resolved against
+	ContentDirs []string
+	// LanguageContentDirs resolves internal links against the content
+	// directory of every language in the Hugo config
+	LanguageContentDirs bool
+}
+
+var profiles = map[string]Profile{
+	"default": {Name: "default"},
+	// Docsy and similar documentation themes keep each language under
+	// content/<lang>/ with /docs/ trees of _index.md sections, and vendor
+	// theme templates and npm packages into the site
+	"docsy": {
+		Name:                "docsy",
+		ExcludeDirs:         []string{"themes", "layouts", "node_modules", "resources"},
+		ContentDirs:         []string{"content/en"},
+		LanguageContentDirs: true,
+	},
+}
+
+// LookupProfile returns the named profile. The empty string selects the
+// default profile.
+func LookupProfile(name string) (Profile, error) {
+	if name == "" {
+		name = "default"
+	}
+	profile, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for known := range profiles {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return Profile{}, fmt.Errorf("unknown profile: %s (valid: %s)", name, strings.Join(names, ", "))
+	}
+	return profile, nil
+}
@@ -0,0 +1,22 @@
+package config
+
+import "testing"
+
+func TestLookupProfile(t *testing.T) {
+	profile, err := LookupProfile("")
+	if err != nil || profile.Name != "default" || len(profile.ExcludeDirs) != 0 {
+		t.Errorf("Expected empty default profile, got %+v (%v)", profile, err)
+	}
+
+	profile, err = LookupProfile("Docsy")
+	if err != nil {
+		t.Fatalf("LookupProfile failed: %v", err)
+	}
+	if !profile.LanguageContentDirs || len(profile.ExcludeDirs) == 0 {
+		t.Errorf("Unexpected docsy profile: %+v", profile)
+	}
+
+	if _, err := LookupProfile("unknown"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Versions describes versioned docs content such as content/docs/v1 and
+// content/docs/v2
+type Versions struct {
+	// Section is the content section holding one directory per version
+	Section string `yaml:"section"`
+	// List names the version directories (default: every subdirectory)
+	List []string `yaml:"list"`
+	// Latest is the current version (default: the highest version)
+	Latest string `yaml:"latest"`
+	// Alias is the URL segment that points at the latest version
+	Alias string `yaml:"alias"`
+	// OnlyLatest checks pages of the latest version only
+	OnlyLatest bool `yaml:"only_latest"`
+}
+
+// Resolve fills in the version list, latest version, and alias from the
+// content tree of the site at siteRoot
+func (v Versions) Resolve(siteRoot string) (Versions, error) {
+	if v.Section == "" {
+		return v, fmt.Errorf("versions: section is required")
+	}
+	v.Section = strings.Trim(v.Section, "/")
+
+	if len(v.List) == 0 {
+		sectionDir := filepath.Join(siteRoot, "content", v.Section)
+		entries, err := os.ReadDir(sectionDir)
+		if err != nil {
+			return v, fmt.Errorf("versions: failed to read %s: %v", sectionDir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && entry.Name() != v.Alias {
+				v.List = append(v.List, entry.Name())
+			}
+		}
+	}
+	if len(v.List) == 0 {
+		return v, fmt.Errorf("versions: no versions found in section %s", v.Section)
+	}
+
+	if v.Latest == "" {
+		v.Latest = slices.MaxFunc(v.List, compareVersions)
+	} else if !slices.Contains(v.List, v.Latest) {
+		return v, fmt.Errorf("versions: latest version %s is not one of %s", v.Latest, strings.Join(v.List, ", "))
+	}
+	if v.Alias == "" {
+		v.Alias = "latest"
+	}
+	return v, nil
+}
+
+// compareVersions orders version names naturally, so v10 sorts after v9
+func compareVersions(a, b string) int {
+	for a != "" && b != "" {
+		ra, rb := rune(a[0]), rune(b[0])
+		if unicode.IsDigit(ra) && unicode.IsDigit(rb) {
+			na, restA := leadingNumber(a)
+			nb, restB := leadingNumber(b)
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+			a, b = restA, restB
+			continue
+		}
+		if ra != rb {
+			if ra < rb {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
+}
+
+// leadingNumber splits the leading decimal number off s
+func leadingNumber(s string) (int, string) {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n, s[end:]
+}
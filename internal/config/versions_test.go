@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVersionsResolve(t *testing.T) {
+	siteRoot := t.TempDir()
+	for _, dir := range []string{"v1", "v9", "v10", "latest"} {
+		if err := os.MkdirAll(filepath.Join(siteRoot, "content", "docs", dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := Versions{Section: "/docs/", Alias: "latest"}.Resolve(siteRoot)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if versions.Section != "docs" || len(versions.List) != 3 {
+		t.Errorf("Expected three versions in docs, got %+v", versions)
+	}
+	if versions.Latest != "v10" {
+		t.Errorf("Expected v10 as latest, got %s", versions.Latest)
+	}
+
+	if _, err := (Versions{Section: "docs", List: []string{"v1"}, Latest: "v2"}).Resolve(siteRoot); err == nil {
+		t.Error("Expected error for latest version not in list")
+	}
+	if _, err := (Versions{}).Resolve(siteRoot); err == nil {
+		t.Error("Expected error for missing section")
+	}
+}
@@ -0,0 +1,191 @@
+package fixer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change of
+// a unified diff
+const diffContext = 3
+
+// replacement is one occurrence of a link destination to be replaced
+type replacement struct {
+	start, end int
+	fix        string
+}
+
+// ReplaceLinks replaces each link destination in content written as a key of
+// fixes with its value, and returns the new content and the number of
+// replacements. A URL is only replaced where it stands on its own between
+// the delimiters of a Markdown or HTML link, e.g. (url), "url", or <url>,
+// not where it is the start of a longer URL.
+func ReplaceLinks(content string, fixes map[string]string) (string, int) {
+	var found []replacement
+	for linkURL, fix := range fixes {
+		if linkURL == "" {
+			continue
+		}
+		for offset := 0; ; {
+			idx := strings.Index(content[offset:], linkURL)
+			if idx == -1 {
+				break
+			}
+			start := offset + idx
+			end := start + len(linkURL)
+			if (start == 0 || isLinkDelimiter(content[start-1])) && (end == len(content) || isLinkDelimiter(content[end])) {
+				found = append(found, replacement{start: start, end: end, fix: fix})
+			}
+			offset = start + 1
+		}
+	}
+	if len(found) == 0 {
+		return content, 0
+	}
+
+	// Longer URLs win where occurrences overlap
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].start != found[j].start {
+			return found[i].start < found[j].start
+		}
+		return found[i].end > found[j].end
+	})
+	var out strings.Builder
+	last, count := 0, 0
+	for _, r := range found {
+		if r.start < last {
+			continue
+		}
+		out.WriteString(content[last:r.start])
+		out.WriteString(r.fix)
+		last = r.end
+		count++
+	}
+	out.WriteString(content[last:])
+	return out.String(), count
+}
+
+// isLinkDelimiter reports whether c may surround a link destination
+func isLinkDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '"', '\'', '=', ' ', '\t', '\n', '\r':
+		return true
+	default:
+		return false
+	}
+}
+
+// change is a run of lines replaced between the old and new version of a
+// file: old[oldStart:oldEnd] became new[newStart:newEnd]
+type change struct {
+	oldStart, oldEnd int
+	newStart, newEnd int
+}
+
+// UnifiedDiff returns a unified diff from oldContent to newContent, as read
+// by patch and git apply, with the file named path in both headers under
+// the a/ and b/ prefixes of git. It returns "" when the contents are equal.
+// Lines changed in place, as by ReplaceLinks, are diffed line by line;
+// other edits show as one change.
+func UnifiedDiff(path, oldContent, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+	oldLines, newLines := splitLines(oldContent), splitLines(newContent)
+	changes := diffLines(oldLines, newLines)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", path, path)
+	for i := 0; i < len(changes); {
+		// A hunk takes in the following changes whose context overlaps
+		j := i
+		for j+1 < len(changes) && changes[j+1].oldStart-changes[j].oldEnd <= 2*diffContext {
+			j++
+		}
+		first, last := changes[i], changes[j]
+		oldStart := max(first.oldStart-diffContext, 0)
+		oldEnd := min(last.oldEnd+diffContext, len(oldLines))
+		newStart := first.newStart - (first.oldStart - oldStart)
+		newEnd := last.newEnd + (oldEnd - last.oldEnd)
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldEnd-oldStart), hunkRange(newStart, newEnd-newStart))
+		line := oldStart
+		for _, c := range changes[i : j+1] {
+			writeLines(&out, " ", oldLines[line:c.oldStart])
+			writeLines(&out, "-", oldLines[c.oldStart:c.oldEnd])
+			writeLines(&out, "+", newLines[c.newStart:c.newEnd])
+			line = c.oldEnd
+		}
+		writeLines(&out, " ", oldLines[line:oldEnd])
+		i = j + 1
+	}
+	return out.String()
+}
+
+// diffLines finds the changed runs of lines: everything between the common
+// prefix and suffix, split into the lines that differ when the line counts
+// match
+func diffLines(oldLines, newLines []string) []change {
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+	oldEnd, newEnd := len(oldLines)-suffix, len(newLines)-suffix
+
+	if oldEnd-prefix != newEnd-prefix {
+		return []change{{oldStart: prefix, oldEnd: oldEnd, newStart: prefix, newEnd: newEnd}}
+	}
+	var changes []change
+	for i := prefix; i < oldEnd; i++ {
+		if oldLines[i] == newLines[i] {
+			continue
+		}
+		if n := len(changes); n > 0 && changes[n-1].oldEnd == i {
+			changes[n-1].oldEnd++
+			changes[n-1].newEnd++
+			continue
+		}
+		changes = append(changes, change{oldStart: i, oldEnd: i + 1, newStart: i, newEnd: i + 1})
+	}
+	return changes
+}
+
+// splitLines splits content into lines, each with its newline; the last
+// line lacks one if the content doesn't end in a newline
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeLines writes lines with a diff prefix, marking a last line without a
+// newline as diff does
+func writeLines(out *strings.Builder, prefix string, lines []string) {
+	for _, line := range lines {
+		out.WriteString(prefix)
+		out.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start and length of a hunk's lines; an empty range
+// starts at the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
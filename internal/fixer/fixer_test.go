@@ -0,0 +1,68 @@
+package fixer
+
+import (
+	"testing"
+)
+
+func TestReplaceLinks(t *testing.T) {
+	content := `See [about](https://mysite.com/about/) and <a href="https://mysite.com/about/">about</a>.
+Not [this one](https://mysite.com/about/team/) or https://mysite.com/about/x.
+[def]: https://mysite.com/about/ "About"
+<https://mysite.com/about/>
+`
+	fixed, count := ReplaceLinks(content, map[string]string{"https://mysite.com/about/": "/about/"})
+	expected := `See [about](/about/) and <a href="/about/">about</a>.
+Not [this one](https://mysite.com/about/team/) or https://mysite.com/about/x.
+[def]: /about/ "About"
+</about/>
+`
+	if fixed != expected || count != 4 {
+		t.Errorf("Expected 4 replacements giving\n%s\ngot %d giving\n%s", expected, count, fixed)
+	}
+
+	if fixed, count := ReplaceLinks(content, map[string]string{"/elsewhere/": "/"}); fixed != content || count != 0 {
+		t.Errorf("Expected no replacements, got %d", count)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "1\n2\n3\n4\nold a\n6\n7\n8\n9\n10\n11\n12\n13\n14\nold b\nold c\n17\nlast"
+	new := "1\n2\n3\n4\nnew a\n6\n7\n8\n9\n10\n11\n12\n13\n14\nnew b\nnew c\n17\nlast"
+	expected := `--- a/content/page.md
++++ b/content/page.md
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-old a
++new a
+ 6
+ 7
+ 8
+@@ -12,7 +12,7 @@
+ 12
+ 13
+ 14
+-old b
+-old c
++new b
++new c
+ 17
+ last
+\ No newline at end of file
+`
+	if diff := UnifiedDiff("content/page.md", old, new); diff != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, diff)
+	}
+
+	// Changes whose context overlaps share a hunk
+	diff := UnifiedDiff("a.md", "a\nb\nc\nd\ne\n", "A\nb\nc\nd\nE\n")
+	expected = "--- a/a.md\n+++ b/a.md\n@@ -1,5 +1,5 @@\n-a\n+A\n b\n c\n d\n-e\n+E\n"
+	if diff != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, diff)
+	}
+
+	if diff := UnifiedDiff("a.md", "same\n", "same\n"); diff != "" {
+		t.Errorf("Expected no diff for equal contents, got %q", diff)
+	}
+}
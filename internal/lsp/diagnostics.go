@@ -0,0 +1,113 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// diagnosticSource names the server in the diagnostics it publishes
+const diagnosticSource = "hugo-link-checker"
+
+// fileDiagnostics returns the findings of a checked document as
+// diagnostics: broken links as errors (muted ones as information), link
+// warnings and discrepancies as warnings, and page warnings as warnings on
+// the first line. Links that weren't checked, such as external links still
+// waiting for their check, have none.
+func fileDiagnostics(file *scanner.File, text string) []diagnostic {
+	lines := strings.Split(text, "\n")
+	diagnostics := []diagnostic{}
+	for _, warning := range file.Warnings {
+		diagnostics = append(diagnostics, diagnostic{
+			Severity: severityWarning,
+			Source:   diagnosticSource,
+			Message:  warning,
+		})
+	}
+
+	for _, link := range file.Links {
+		diagnostics = append(diagnostics, linkDiagnostics(link, lines)...)
+	}
+	return diagnostics
+}
+
+// linkDiagnostics returns the diagnostics of a link at each place it
+// appears in the document
+func linkDiagnostics(link scanner.Link, lines []string) []diagnostic {
+	var diagnostics []diagnostic
+	for _, position := range link.Positions() {
+		at := link
+		at.Line, at.Column = position.Line, position.Column
+		linkRange := linkRange(at, lines)
+		if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
+			d := diagnostic{
+				Range:    linkRange,
+				Severity: severityError,
+				Code:     string(link.ErrorCategory),
+				Source:   diagnosticSource,
+				Message:  fmt.Sprintf("Broken link %s: %s", link.URL, describeFailure(link)),
+			}
+			if link.MutedUntil != "" {
+				d.Message += fmt.Sprintf(" (muted until %s)", link.MutedUntil)
+				d.Severity = severityInformation
+			}
+			if link.Fix != "" {
+				d.Message += fmt.Sprintf(" (fix: %s)", link.Fix)
+			}
+			diagnostics = append(diagnostics, d)
+		}
+
+		messages := append([]string(nil), link.Warnings...)
+		if link.Discrepancy != "" {
+			messages = append(messages, link.Discrepancy)
+		}
+		for _, message := range messages {
+			diagnostics = append(diagnostics, diagnostic{
+				Range:    linkRange,
+				Severity: severityWarning,
+				Source:   diagnosticSource,
+				Message:  message,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// describeFailure returns the error message of a broken link, or its status
+// when it has none
+func describeFailure(link scanner.Link) string {
+	if link.ErrorMessage != "" {
+		return link.ErrorMessage
+	}
+	return fmt.Sprintf("HTTP %d", link.StatusCode)
+}
+
+// linkRange returns the range of a link's URL in the document: the whole
+// URL when its column is known, otherwise its line. LSP positions count
+// UTF-16 code units, where the scanner counts bytes.
+func linkRange(link scanner.Link, lines []string) lspRange {
+	if link.Line < 1 || link.Line > len(lines) {
+		return lspRange{}
+	}
+	line := strings.TrimSuffix(lines[link.Line-1], "\r")
+	start, end := 0, len(line)
+	if link.Column > 0 && link.Column-1+len(link.URL) <= len(line) {
+		start = link.Column - 1
+		end = start + len(link.URL)
+	}
+	return lspRange{
+		Start: position{Line: link.Line - 1, Character: utf16Len(line[:start])},
+		End:   position{Line: link.Line - 1, Character: utf16Len(line[:end])},
+	}
+}
+
+// utf16Len returns the length of s in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
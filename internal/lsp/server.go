@@ -0,0 +1,406 @@
+// Package lsp implements a language server that checks the links of the
+// Markdown documents open in an editor as they are written.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/checker"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+	"github.com/infodancer/hugo-link-checker/internal/version"
+)
+
+// DefaultDebounce is how long a document must be left unchanged before its
+// external links are checked
+const DefaultDebounce = 1500 * time.Millisecond
+
+// ErrExitWithoutShutdown is returned by Run when the client sends exit
+// without asking the server to shut down first
+var ErrExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Options configures a language server
+type Options struct {
+	// Check configures the link checks. An empty RootDir selects the
+	// workspace root the client opens. External links are checked only
+	// with Check.CheckExternal.
+	Check checker.Options
+	// Parse configures which links are extracted from documents
+	Parse scanner.ParseOptions
+	// Ignore holds the patterns of links that are not checked
+	Ignore []*regexp.Regexp
+	// Debounce is how long a document must be left unchanged before its
+	// external links are checked. Zero selects DefaultDebounce.
+	Debounce time.Duration
+}
+
+// Server is a language server for Hugo content. Every change of an open
+// document is parsed and its internal links are checked at once against
+// the site's content tree; external links are answered from the results of
+// earlier checks, and checked once the document has been left unchanged for
+// the debounce interval. Findings are published as diagnostics.
+type Server struct {
+	opts Options
+	out  io.Writer
+
+	// writeMu serializes messages to the client
+	writeMu sync.Mutex
+
+	// mu guards docs and shutdown
+	mu       sync.Mutex
+	docs     map[string]*document
+	shutdown bool
+
+	// local checks internal links, answering external ones from the
+	// cache; external checks them all. Each is used under its own lock, so
+	// slow external checks don't hold up local ones.
+	localMu    sync.Mutex
+	local      *checker.Session
+	externalMu sync.Mutex
+	external   *checker.Session
+}
+
+// document is a document open in the client
+type document struct {
+	path    string
+	version int
+	text    string
+	// timer runs the debounced external check
+	timer *time.Timer
+}
+
+// NewServer returns a server writing to out
+func NewServer(opts Options, out io.Writer) *Server {
+	if opts.Debounce == 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.Check.Cache == nil {
+		opts.Check.Cache = checker.NewResultCache(checker.DefaultCacheMaxAge)
+	}
+	return &Server{
+		opts: opts,
+		out:  out,
+		docs: make(map[string]*document),
+	}
+}
+
+// Run serves the client's messages from in until it sends exit or closes
+// the connection
+func (s *Server) Run(in io.Reader) error {
+	reader := bufio.NewReader(in)
+	for {
+		body, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			s.stopTimers()
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		result, respErr := s.handle(msg)
+		if msg.ID == nil {
+			if respErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", msg.Method, respErr.Message)
+			}
+			continue
+		}
+		if err := s.reply(msg.ID, result, respErr); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification, returning the result of a
+// request
+func (s *Server) handle(msg message) (any, *responseError) {
+	switch msg.Method {
+	case "initialize":
+		var params initializeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.initialize(params)
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		s.update(params.TextDocument.URI, params.TextDocument.Version, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		// Documents are synced in full, so the last change is the text
+		if n := len(params.ContentChanges); n > 0 {
+			s.update(params.TextDocument.URI, params.TextDocument.Version, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didSave":
+		// Saving may add or move pages that links resolve to
+		s.refresh()
+		return nil, nil
+	case "textDocument/didClose":
+		var params documentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		s.close(params.TextDocument.URI)
+		return nil, nil
+	}
+	if strings.HasPrefix(msg.Method, "$/") {
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+}
+
+// initialize sets up the sessions checking the workspace's links
+func (s *Server) initialize(params initializeParams) (any, *responseError) {
+	opts := s.opts.Check
+	if opts.RootDir == "" {
+		opts.RootDir = params.RootPath
+		if path, ok := uriPath(params.RootURI); ok {
+			opts.RootDir = path
+		}
+	}
+	if opts.RootDir == "" {
+		opts.RootDir = "."
+	}
+
+	// Local checks answer external links from the cache alone; without
+	// external checking they are skipped
+	localOpts := opts
+	localOpts.CacheOnly = opts.CheckExternal
+	local, err := checker.NewSession(localOpts)
+	if err != nil {
+		return nil, &responseError{Code: codeInternalError, Message: err.Error()}
+	}
+	var external *checker.Session
+	if opts.CheckExternal {
+		external, err = checker.NewSession(opts)
+		if err != nil {
+			return nil, &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+	}
+	s.localMu.Lock()
+	s.local = local
+	s.localMu.Unlock()
+	s.externalMu.Lock()
+	s.external = external
+	s.externalMu.Unlock()
+
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync: textDocumentSyncOptions{OpenClose: true, Change: 1, Save: saveOptions{}},
+		},
+		ServerInfo: serverInfo{Name: diagnosticSource, Version: version.Version},
+	}, nil
+}
+
+// update records the new text of a document, publishes the results of its
+// local check, and schedules its external check
+func (s *Server) update(uri string, docVersion int, text string) {
+	path, ok := uriPath(uri)
+	if !ok || !scanner.IsContentFile(path) {
+		return
+	}
+
+	s.mu.Lock()
+	doc := s.docs[uri]
+	if doc == nil {
+		doc = &document{path: path}
+		s.docs[uri] = doc
+	}
+	doc.version, doc.text = docVersion, text
+	if doc.timer != nil {
+		doc.timer.Stop()
+	}
+	if s.opts.Check.CheckExternal {
+		doc.timer = time.AfterFunc(s.opts.Debounce, func() { s.checkExternal(uri, docVersion) })
+	}
+	s.mu.Unlock()
+
+	s.localMu.Lock()
+	file, err := s.check(s.local, path, text)
+	s.localMu.Unlock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	s.publish(uri, docVersion, file, text)
+}
+
+// checkExternal checks all links of a document that hasn't changed since
+// docVersion, and publishes the results if it still hasn't
+func (s *Server) checkExternal(uri string, docVersion int) {
+	s.mu.Lock()
+	doc := s.docs[uri]
+	if doc == nil || doc.version != docVersion {
+		s.mu.Unlock()
+		return
+	}
+	path, text := doc.path, doc.text
+	s.mu.Unlock()
+
+	s.externalMu.Lock()
+	file, err := s.check(s.external, path, text)
+	s.externalMu.Unlock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	s.publish(uri, docVersion, file, text)
+}
+
+// check parses text as the document at path and checks its links with
+// session
+func (s *Server) check(session *checker.Session, path, text string) (*scanner.File, error) {
+	if session == nil {
+		return nil, fmt.Errorf("document %s changed before initialize", path)
+	}
+	file := &scanner.File{Path: path, CanonicalPath: path}
+	scanner.ParseLinks(file, []byte(text), s.opts.Parse)
+	for i := range file.Links {
+		for _, pattern := range s.opts.Ignore {
+			if pattern.MatchString(file.Links[i].URL) {
+				file.Links[i].Ignored = true
+				break
+			}
+		}
+	}
+	if err := session.Check([]*scanner.File{file}); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// publish sends the diagnostics of a checked document, unless the document
+// has changed or closed since docVersion
+func (s *Server) publish(uri string, docVersion int, file *scanner.File, text string) {
+	s.mu.Lock()
+	doc := s.docs[uri]
+	current := doc != nil && doc.version == docVersion
+	s.mu.Unlock()
+	if !current {
+		return
+	}
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Version:     docVersion,
+		Diagnostics: fileDiagnostics(file, text),
+	})
+}
+
+// close forgets a closed document and clears its diagnostics
+func (s *Server) close(uri string) {
+	s.mu.Lock()
+	doc := s.docs[uri]
+	if doc == nil {
+		s.mu.Unlock()
+		return
+	}
+	if doc.timer != nil {
+		doc.timer.Stop()
+	}
+	delete(s.docs, uri)
+	s.mu.Unlock()
+
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: []diagnostic{}})
+}
+
+// refresh rebuilds the content index of both sessions
+func (s *Server) refresh() {
+	s.localMu.Lock()
+	if s.local != nil {
+		s.local.Refresh()
+	}
+	s.localMu.Unlock()
+	s.externalMu.Lock()
+	if s.external != nil {
+		s.external.Refresh()
+	}
+	s.externalMu.Unlock()
+}
+
+// stopTimers cancels the pending external checks
+func (s *Server) stopTimers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doc := range s.docs {
+		if doc.timer != nil {
+			doc.timer.Stop()
+		}
+	}
+}
+
+// reply sends the response to a request
+func (s *Server) reply(id json.RawMessage, result any, respErr *responseError) error {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if respErr != nil {
+		return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: id, Error: *respErr})
+	}
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// notify sends a notification to the client
+func (s *Server) notify(method string, params any) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// uriPath returns the file path of a file:// URI
+func uriPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	path := u.Path
+	// file:///C:/dir on Windows
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), true
+}
@@ -0,0 +1,228 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/checker"
+)
+
+// testClient drives a server over pipes
+type testClient struct {
+	t        *testing.T
+	in       *io.PipeWriter
+	messages chan map[string]any
+	done     chan error
+}
+
+func startServer(t *testing.T, opts Options) *testClient {
+	t.Helper()
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	c := &testClient{t: t, in: clientOut, messages: make(chan map[string]any, 16), done: make(chan error, 1)}
+
+	server := NewServer(opts, serverOut)
+	go func() {
+		c.done <- server.Run(serverIn)
+		_ = serverOut.Close()
+	}()
+	go func() {
+		reader := bufio.NewReader(clientIn)
+		for {
+			body, err := readMessage(reader)
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			var msg map[string]any
+			if err := json.Unmarshal(body, &msg); err != nil {
+				t.Errorf("invalid message %s: %v", body, err)
+			}
+			c.messages <- msg
+		}
+	}()
+	return c
+}
+
+func (c *testClient) send(msg map[string]any) {
+	c.t.Helper()
+	msg["jsonrpc"] = "2.0"
+	if err := writeMessage(c.in, msg); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testClient) receive() map[string]any {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("server closed the connection")
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timed out waiting for a message")
+	}
+	return nil
+}
+
+// diagnostics waits for the next diagnostics published for a document
+func (c *testClient) diagnostics() []any {
+	c.t.Helper()
+	msg := c.receive()
+	if msg["method"] != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("expected diagnostics, got %v", msg)
+	}
+	return msg["params"].(map[string]any)["diagnostics"].([]any)
+}
+
+func (c *testClient) shutdown() {
+	c.t.Helper()
+	c.send(map[string]any{"id": 99, "method": "shutdown"})
+	if msg := c.receive(); msg["id"] != float64(99) || msg["error"] != nil {
+		c.t.Fatalf("unexpected shutdown response %v", msg)
+	}
+	c.send(map[string]any{"method": "exit"})
+	if err := <-c.done; err != nil {
+		c.t.Errorf("Run failed: %v", err)
+	}
+}
+
+func writeSite(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "content", "posts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "content", "about.md"), []byte("# About\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestServer_InternalLinks(t *testing.T) {
+	root := writeSite(t)
+	c := startServer(t, Options{})
+
+	c.send(map[string]any{"id": 1, "method": "initialize", "params": map[string]any{"rootUri": "file://" + filepath.ToSlash(root)}})
+	init := c.receive()
+	result, _ := init["result"].(map[string]any)
+	if result == nil || result["capabilities"] == nil {
+		t.Fatalf("unexpected initialize response %v", init)
+	}
+	c.send(map[string]any{"method": "initialized", "params": map[string]any{}})
+
+	uri := "file://" + filepath.ToSlash(filepath.Join(root, "content", "posts", "draft.md"))
+	c.send(map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "markdown", "version": 1,
+			"text": "# Draft\n\nSee [about](/about/) and the [café](/café/) page.\n"},
+	}})
+	diagnostics := c.diagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic, got %v", diagnostics)
+	}
+	d := diagnostics[0].(map[string]any)
+	if d["severity"] != float64(severityError) || d["code"] != "not-found-local" || !strings.Contains(d["message"].(string), "/café/") {
+		t.Errorf("unexpected diagnostic %v", d)
+	}
+	// The range counts UTF-16 units: "See [about](/about/) and the [café](" is 36 long
+	want := map[string]any{
+		"start": map[string]any{"line": float64(2), "character": float64(36)},
+		"end":   map[string]any{"line": float64(2), "character": float64(42)},
+	}
+	if got, _ := json.Marshal(d["range"]); string(got) != mustJSON(t, want) {
+		t.Errorf("expected range %s, got %s", mustJSON(t, want), got)
+	}
+
+	// Fixing the link clears the diagnostic
+	c.send(map[string]any{"method": "textDocument/didChange", "params": map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []any{map[string]any{"text": "# Draft\n\nSee [about](/about/).\n"}},
+	}})
+	if diagnostics := c.diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	// Unknown requests are answered with an error
+	c.send(map[string]any{"id": 2, "method": "textDocument/hover", "params": map[string]any{}})
+	if msg := c.receive(); msg["error"] == nil {
+		t.Errorf("expected an error, got %v", msg)
+	}
+
+	c.send(map[string]any{"method": "textDocument/didClose", "params": map[string]any{"textDocument": map[string]any{"uri": uri}}})
+	if diagnostics := c.diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("expected diagnostics cleared on close, got %v", diagnostics)
+	}
+	c.shutdown()
+}
+
+func TestServer_DebouncedExternalLinks(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	root := writeSite(t)
+	c := startServer(t, Options{
+		Check:    checker.Options{RootDir: root, CheckExternal: true},
+		Debounce: 20 * time.Millisecond,
+	})
+	c.send(map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}})
+	c.receive()
+
+	uri := "file://" + filepath.ToSlash(filepath.Join(root, "content", "posts", "links.md"))
+	text := "[gone](" + server.URL + "/gone)\n"
+	c.send(map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "markdown", "version": 1, "text": text},
+	}})
+
+	// The external link isn't known yet, then is checked after the debounce
+	if diagnostics := c.diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics before the external check, got %v", diagnostics)
+	}
+	if diagnostics := c.diagnostics(); len(diagnostics) != 1 {
+		t.Fatalf("expected the external link reported broken, got %v", diagnostics)
+	}
+
+	// Later changes answer it from the cache at once
+	c.send(map[string]any{"method": "textDocument/didChange", "params": map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []any{map[string]any{"text": "Intro.\n\n" + text}},
+	}})
+	if diagnostics := c.diagnostics(); len(diagnostics) != 1 {
+		t.Errorf("expected the cached result, got %v", diagnostics)
+	}
+	c.diagnostics()
+	if requests.Load() == 0 {
+		t.Errorf("expected the external link to be requested")
+	}
+	c.shutdown()
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	c := startServer(t, Options{})
+	c.send(map[string]any{"method": "exit"})
+	if err := <-c.done; err != ErrExitWithoutShutdown {
+		t.Errorf("expected ErrExitWithoutShutdown, got %v", err)
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
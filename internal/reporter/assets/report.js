@@ -0,0 +1,369 @@
+// Renders the HTML report from the JSON result set embedded in the page.
+(function () {
+  "use strict";
+
+  var report = JSON.parse(document.getElementById("report-data").textContent);
+  var messages = JSON.parse(document.getElementById("report-messages").textContent) || {};
+  var root = document.getElementById("report");
+
+  function t(text) {
+    return messages[text] || text;
+  }
+
+  function el(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) {
+      node.className = className;
+    }
+    if (text !== undefined) {
+      node.textContent = text;
+    }
+    return node;
+  }
+
+  function isBroken(link) {
+    return link.status_code >= 400 || (link.status_code === 0 && !!link.error_message);
+  }
+
+  function hasWarnings(link) {
+    return (link.warnings && link.warnings.length > 0) || !!link.discrepancy;
+  }
+
+  function brokenStatus(link) {
+    var status = t("BROKEN");
+    if (link.error_message) {
+      status += " (" + link.error_message + ")";
+    }
+    if (link.error_category) {
+      status += " [" + link.error_category + "]";
+    }
+    if (link.resolved_site) {
+      status += " (" + t("checked in site %s").replace("%s", link.resolved_site) + ")";
+    }
+    if (link.muted_until) {
+      status += " (" + t("muted until %s").replace("%s", link.muted_until) + ")";
+    }
+    return status;
+  }
+
+  function summaryBox(title) {
+    var box = el("div", "summary");
+    box.appendChild(el("h2", "", title));
+    root.appendChild(box);
+    return box;
+  }
+
+  function table(box, headings, rows) {
+    var tableNode = el("table");
+    var head = el("tr");
+    headings.forEach(function (heading) {
+      head.appendChild(el("th", "", heading));
+    });
+    tableNode.appendChild(head);
+    rows.forEach(function (row) {
+      var tr = el("tr");
+      row.forEach(function (cell) {
+        tr.appendChild(el("td", "", String(cell)));
+      });
+      tableNode.appendChild(tr);
+    });
+    box.appendChild(tableNode);
+  }
+
+  function renderSummary() {
+    var summary = report.summary;
+    var counts = [
+      ["Files scanned", summary.total_files],
+      ["Total links", summary.total_links],
+      ["Unique links", summary.unique_links],
+      ["Broken links", summary.broken_links],
+      ["Internal links", summary.internal_links],
+      ["External links", summary.external_links],
+      ["Warnings", summary.warnings],
+      ["Local/online discrepancies", summary.discrepancies],
+      ["Skipped (unsupported scheme)", summary.unsupported_scheme],
+      ["Muted broken links", summary.muted_links || 0],
+      ["Fixed this run", summary.fixed_links || 0],
+      ["Unknown (not in cache)", summary.unknown || 0],
+      ["Not checked (offline)", summary.offline || 0],
+      ["Verified links", summary.verified_links || 0]
+    ];
+    var list = el("ul");
+    counts.forEach(function (count) {
+      list.appendChild(el("li", "", t(count[0]) + ": " + count[1]));
+    });
+    if (summary.coverage !== undefined) {
+      list.appendChild(el("li", "", t("Verification coverage") + ": " + summary.coverage.toFixed(1) + "%"));
+    }
+    if (summary.health_score !== undefined) {
+      list.appendChild(el("li", "", t("Health score") + ": " + summary.health_score.toFixed(1) + " (" + summary.health_grade + ")"));
+    }
+    summaryBox(t("Summary")).appendChild(list);
+
+    var reasons = Object.keys(summary.not_checked || {}).sort();
+    if (reasons.length > 0) {
+      table(summaryBox(t("Not checked")), [t("Reason"), t("Links")], reasons.map(function (reason) {
+        return [reason, summary.not_checked[reason]];
+      }));
+    }
+
+    var rules = Object.keys(summary.policy_violations || {}).sort();
+    if (rules.length > 0) {
+      table(summaryBox(t("Policy violations")), [t("Rule"), t("Links")], rules.map(function (rule) {
+        return [rule, summary.policy_violations[rule]];
+      }));
+    }
+
+    var pages = Object.keys(summary.stale_links || {}).sort();
+    if (pages.length > 0) {
+      table(summaryBox(t("Stale links by moved page")), [t("Page"), t("Links")], pages.map(function (page) {
+        return [page, summary.stale_links[page]];
+      }));
+    }
+
+    var groups = summary.redirect_groups || [];
+    if (groups.length > 0) {
+      table(summaryBox(t("URLs with the same destination")), [t("Destination"), t("URLs"), t("Links")], groups.map(function (group) {
+        return [group.destination, group.urls.join(", "), group.links];
+      }));
+    }
+
+    renderRollup(t("Sites"), t("Site"), summary.sites);
+    renderRollup(t("Sections"), t("Section"), summary.sections);
+  }
+
+  // Rollups are ordered by broken links, then name
+  function renderRollup(title, column, rollup) {
+    var names = Object.keys(rollup || {});
+    if (names.length <= 1) {
+      return;
+    }
+    names.sort(function (a, b) {
+      var diff = rollup[b].broken_links - rollup[a].broken_links;
+      return diff !== 0 ? diff : (a < b ? -1 : a > b ? 1 : 0);
+    });
+    table(summaryBox(title), [column, t("Files"), t("Links"), t("Broken")], names.map(function (name) {
+      var entry = rollup[name];
+      return [name, entry.files, entry.links, entry.broken_links];
+    }));
+  }
+
+  function renderMetadata() {
+    var metadata = report.metadata;
+    if (!metadata) {
+      return;
+    }
+    var box = el("div", "metadata");
+    box.appendChild(el("h2", "", t("Run")));
+    var list = el("ul");
+    [
+      ["Run ID", metadata.run_id],
+      ["Tool version", metadata.tool_version],
+      ["Started", metadata.started_at],
+      ["Duration", metadata.duration],
+      ["Host", metadata.hostname],
+      ["Site commit", metadata.git_commit]
+    ].forEach(function (item) {
+      list.appendChild(el("li", "", t(item[0]) + ": " + (item[1] || "")));
+    });
+    Object.keys(metadata.config || {}).sort().forEach(function (key) {
+      list.appendChild(el("li", "", "-" + key + "=" + metadata.config[key]));
+    });
+    box.appendChild(list);
+    root.appendChild(box);
+  }
+
+  // linksByFile groups the unique links by the files they were found in
+  function linksByFile() {
+    var byFile = {};
+    (report.files || []).forEach(function (file) {
+      byFile[file.path] = [];
+    });
+    report.links.forEach(function (link) {
+      (link.found_in_files || []).forEach(function (path) {
+        if (!byFile[path]) {
+          byFile[path] = [];
+        }
+        if (byFile[path].indexOf(link) === -1) {
+          byFile[path].push(link);
+        }
+      });
+    });
+    Object.keys(byFile).forEach(function (path) {
+      byFile[path].sort(function (a, b) {
+        return a.url < b.url ? -1 : a.url > b.url ? 1 : 0;
+      });
+    });
+    return byFile;
+  }
+
+  function fileDetails() {
+    var details = {};
+    (report.files || []).forEach(function (file) {
+      details[file.path] = file;
+    });
+    return details;
+  }
+
+  function linkLabel(link) {
+    var label = link.url;
+    if (link.text) {
+      label += " \"" + link.text + "\"";
+    }
+    if (link.title) {
+      label += " (" + t("title %s").replace("%s", "\"" + link.title + "\"") + ")";
+    }
+    return label;
+  }
+
+  // occurrenceNote describes where a link that appears more than once in
+  // the file at path is
+  function occurrenceNote(link, path) {
+    var positions = (link.positions || []).filter(function (position) {
+      return position.file === path;
+    });
+    if (positions.length < 2) {
+      return "";
+    }
+    var note = t("%d occurrences").replace("%d", String(positions.length));
+    var lines = positions.filter(function (position) {
+      return position.line;
+    }).map(function (position) {
+      return String(position.line);
+    });
+    if (lines.length) {
+      note += ": " + t("lines %s").replace("%s", lines.join(", "));
+    }
+    return " (" + note + ")";
+  }
+
+  function linkRows(link, path) {
+    var linkClass = link.type === "external" ? "external" : "internal";
+    var prefix = linkLabel(link) + " [" + t(linkClass) + "] - ";
+    var status = "ok";
+    var statusText = t("OK");
+    if (link.fixed) {
+      statusText += " (" + t("fixed this run") + ")";
+    }
+    if (link.skipped) {
+      status = "skipped";
+      statusText = t("SKIPPED") + " (" + link.skipped + ")";
+    }
+    if (isBroken(link)) {
+      status = "broken";
+      statusText = brokenStatus(link);
+    }
+
+    var rows = [el("div", "link " + status + " " + linkClass, prefix + statusText + occurrenceNote(link, path))];
+    (link.warnings || []).forEach(function (warning) {
+      rows.push(el("div", "link warning " + linkClass, prefix + t("WARNING") + " (" + warning + ")"));
+    });
+    if (link.discrepancy) {
+      rows.push(el("div", "link warning " + linkClass, prefix + t("DISCREPANCY") + " (" + link.discrepancy + ")"));
+    }
+    return rows;
+  }
+
+  function matches(link, filter, query) {
+    if (filter === "broken" && !isBroken(link)) {
+      return false;
+    }
+    if (filter === "warnings" && !hasWarnings(link)) {
+      return false;
+    }
+    return query === "" || [link.url, link.text, link.title].some(function (value) {
+      return !!value && value.toLowerCase().indexOf(query) !== -1;
+    });
+  }
+
+  function renderFiles(container, byFile, details, filter, query) {
+    container.textContent = "";
+    var shown = 0;
+    // Files with the most traffic first, so high-impact findings lead
+    var pageviews = function (path) {
+      return (details[path] && details[path].pageviews) || 0;
+    };
+    Object.keys(byFile).sort(function (a, b) {
+      return pageviews(b) - pageviews(a) || (a < b ? -1 : a > b ? 1 : 0);
+    }).forEach(function (path) {
+      var links = byFile[path].filter(function (link) {
+        return matches(link, filter, query);
+      });
+      var file = details[path];
+      var pageWarnings = (file && file.warnings) || [];
+      var showPageWarnings = filter !== "broken" && query === "" && pageWarnings.length > 0;
+      if (links.length === 0 && !showPageWarnings && (filter !== "all" || query !== "")) {
+        return;
+      }
+
+      var fileNode = el("div", "file");
+      fileNode.appendChild(el("h3", "", path));
+      if (file && file.canonical_path) {
+        appendField(fileNode, t("Canonical"), file.canonical_path);
+      }
+      if (file && file.pageviews) {
+        appendField(fileNode, t("Pageviews"), String(file.pageviews));
+      }
+      appendField(fileNode, t("Links found"), String(file ? file.links : byFile[path].length));
+      if (file && file.site) {
+        appendField(fileNode, t("Site"), file.site);
+      }
+      if (showPageWarnings) {
+        pageWarnings.forEach(function (warning) {
+          fileNode.appendChild(el("div", "link warning", t("WARNING") + " (" + warning + ")"));
+        });
+      }
+      links.forEach(function (link) {
+        linkRows(link, path).forEach(function (row) {
+          fileNode.appendChild(row);
+        });
+      });
+      container.appendChild(fileNode);
+      shown++;
+    });
+    if (shown === 0) {
+      container.appendChild(el("p", "", t("No links match the filter")));
+    }
+  }
+
+  function appendField(parent, label, value) {
+    var field = el("p");
+    field.appendChild(el("strong", "", label + ":"));
+    field.appendChild(document.createTextNode(" " + value));
+    parent.appendChild(field);
+  }
+
+  function renderControls(onChange) {
+    var controls = el("div", "controls");
+    var select = el("select");
+    [["all", t("All links")], ["broken", t("Broken links")], ["warnings", t("Warnings")]].forEach(function (option) {
+      var node = el("option", "", option[1]);
+      node.value = option[0];
+      select.appendChild(node);
+    });
+    var search = el("input");
+    search.type = "search";
+    search.placeholder = t("Search");
+    controls.appendChild(select);
+    controls.appendChild(search);
+    root.appendChild(controls);
+
+    function changed() {
+      onChange(select.value, search.value.trim().toLowerCase());
+    }
+    select.addEventListener("change", changed);
+    search.addEventListener("input", changed);
+  }
+
+  renderSummary();
+  renderMetadata();
+
+  var byFile = linksByFile();
+  var details = fileDetails();
+  var container = el("div", "files");
+  renderControls(function (filter, query) {
+    renderFiles(container, byFile, details, filter, query);
+  });
+  root.appendChild(container);
+  renderFiles(container, byFile, details, "all", "");
+})();
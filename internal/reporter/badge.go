@@ -0,0 +1,48 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// badge is a shields.io endpoint badge: https://shields.io/badges/endpoint-badge
+type badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// generateBadgeReport writes the health score and grade as a shields.io
+// endpoint badge, colored by grade, for READMEs and dashboards
+func generateBadgeReport(files []*scanner.File, writer io.Writer) error {
+	score := healthScore(files)
+	grade := healthGrade(score)
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(badge{
+		SchemaVersion: 1,
+		Label:         "link health",
+		Message:       fmt.Sprintf("%.1f (%s)", score, grade),
+		Color:         badgeColor(grade),
+	})
+}
+
+// badgeColor returns the shields.io color of a health grade
+func badgeColor(grade string) string {
+	switch grade {
+	case "A":
+		return "brightgreen"
+	case "B":
+		return "green"
+	case "C":
+		return "yellow"
+	case "D":
+		return "orange"
+	default:
+		return "red"
+	}
+}
@@ -0,0 +1,162 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// ChunkManifest indexes a JSON report split into one chunk file per content
+// section, so large reports can be ingested in parallel
+type ChunkManifest struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Metadata    *RunMetadata  `json:"metadata,omitempty"`
+	Summary     ReportSummary `json:"summary"`
+	Chunks      []ChunkEntry  `json:"chunks"`
+}
+
+// ChunkEntry describes one chunk file of a split report
+type ChunkEntry struct {
+	Section string `json:"section"`
+	Site    string `json:"site,omitempty"`
+	// Path is the chunk file, relative to the manifest
+	Path        string `json:"path"`
+	Files       int    `json:"files"`
+	Links       int    `json:"links"`
+	BrokenLinks int    `json:"broken_links"`
+}
+
+// JSONChunk holds the files and links of one content section. Links are
+// unique within the chunk; a link found in several sections appears in each.
+type JSONChunk struct {
+	Section string        `json:"section"`
+	Site    string        `json:"site,omitempty"`
+	Files   []FileSummary `json:"files"`
+	Links   []UniqueLink  `json:"links"`
+}
+
+// unsafeChunkChars matches characters kept out of chunk file names
+var unsafeChunkChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// chunkKey identifies the files of one chunk
+type chunkKey struct {
+	site    string
+	section string
+}
+
+// generateChunkedJSONReport writes the manifest to outputFile and one chunk
+// per site and section next to it. Chunks are built and written one at a
+// time, so only one section's unique links are held in memory.
+func generateChunkedJSONReport(files []*scanner.File, outputFile string, metadata *RunMetadata) error {
+	groups := make(map[chunkKey][]*scanner.File)
+	for _, file := range files {
+		key := chunkKey{site: file.Site, section: scanner.Section(file)}
+		groups[key] = append(groups[key], file)
+	}
+	keys := make([]chunkKey, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].site != keys[j].site {
+			return keys[i].site < keys[j].site
+		}
+		return keys[i].section < keys[j].section
+	})
+
+	manifest := ChunkManifest{
+		GeneratedAt: time.Now(),
+		Metadata:    metadata,
+		Summary:     calculateSummary(files),
+		Chunks:      make([]ChunkEntry, 0, len(keys)),
+	}
+
+	used := make(map[string]bool)
+	for _, key := range keys {
+		chunkFiles := groups[key]
+		name := chunkFileName(outputFile, key, used)
+		chunk := JSONChunk{
+			Section: key.section,
+			Site:    key.site,
+			Files:   getFileSummaries(chunkFiles),
+			Links:   getUniqueLinks(chunkFiles),
+		}
+		if err := writeJSONFile(filepath.Join(filepath.Dir(outputFile), name), chunk); err != nil {
+			return err
+		}
+
+		entry := ChunkEntry{Section: key.section, Site: key.site, Path: name, Files: len(chunkFiles)}
+		for _, file := range chunk.Files {
+			entry.Links += file.Links
+			entry.BrokenLinks += file.BrokenLinks
+		}
+		manifest.Chunks = append(manifest.Chunks, entry)
+	}
+
+	return writeJSONFile(outputFile, manifest)
+}
+
+// chunkNameParts splits the manifest name around the point where chunk
+// labels are inserted: report.json.gz gives report and .json.gz
+func chunkNameParts(outputFile string) (base, suffix string) {
+	base = filepath.Base(outputFile)
+	for _, ext := range []string{".gz", ".zst"} {
+		if strings.HasSuffix(strings.ToLower(base), ext) {
+			suffix = base[len(base)-len(ext):]
+			base = base[:len(base)-len(ext)]
+			break
+		}
+	}
+	if strings.HasSuffix(strings.ToLower(base), ".json") {
+		return base[:len(base)-len(".json")], base[len(base)-len(".json"):] + suffix
+	}
+	return base, ".json" + suffix
+}
+
+// chunkFileName names a chunk after the manifest, inserting the site and
+// section before the extension (report.json.gz becomes report.docs.json.gz).
+// Labels that sanitize to a name already used are numbered.
+func chunkFileName(outputFile string, key chunkKey, used map[string]bool) string {
+	label := key.section
+	if key.site != "" {
+		label = key.site + "." + key.section
+	}
+	label = strings.Trim(unsafeChunkChars.ReplaceAllString(label, "_"), "_.")
+	if label == "" {
+		label = "root"
+	}
+
+	base, suffix := chunkNameParts(outputFile)
+	name := base + "." + label + suffix
+	for i := 2; used[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s.%s-%d%s", base, label, i, suffix)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// writeJSONFile writes a value as indented JSON, compressed by extension
+func writeJSONFile(path string, value any) (err error) {
+	file, err := createOutput(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close output file: %v", closeErr)
+		}
+	}()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
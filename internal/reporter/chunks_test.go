@@ -0,0 +1,77 @@
+package reporter
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestChunkFileName(t *testing.T) {
+	used := make(map[string]bool)
+	tests := []struct {
+		output string
+		key    chunkKey
+		want   string
+	}{
+		{"out/report.json", chunkKey{section: "docs"}, "report.docs.json"},
+		{"report.json.gz", chunkKey{section: "docs"}, "report.docs.json.gz"},
+		{"report.zst", chunkKey{section: scanner.RootSection}, "report.root.json.zst"},
+		{"report.json", chunkKey{site: "blog", section: "posts"}, "report.blog.posts.json"},
+		{"report.json", chunkKey{section: "my docs"}, "report.my_docs.json"},
+		{"report.json", chunkKey{section: "my/docs"}, "report.my_docs-2.json"},
+	}
+
+	for _, tt := range tests {
+		if got := chunkFileName(tt.output, tt.key, used); got != tt.want {
+			t.Errorf("chunkFileName(%q, %+v) = %q, want %q", tt.output, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateReport_SplitSections(t *testing.T) {
+	dir := t.TempDir()
+	files := []*scanner.File{
+		{Path: "content/docs/a.md", Links: []scanner.Link{{URL: "/x/", StatusCode: 404}, {URL: "/y/", StatusCode: 200}}},
+		{Path: "content/docs/b.md", Links: []scanner.Link{{URL: "/y/", StatusCode: 200}}},
+		{Path: "content/posts/c.md", Links: []scanner.Link{{URL: "/y/", StatusCode: 200}}},
+	}
+
+	output := filepath.Join(dir, "report.json.gz")
+	err := GenerateReport(files, ReportOptions{Format: FormatJSON, OutputFile: output, SplitSections: true})
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	reader, err := OpenReport(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reader.Close() }()
+	var manifest ChunkManifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Chunks) != 2 || manifest.Summary.BrokenLinks != 1 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+
+	docs := manifest.Chunks[0]
+	if docs.Section != "docs" || docs.Path != "report.docs.json.gz" || docs.Files != 2 || docs.Links != 3 || docs.BrokenLinks != 1 {
+		t.Errorf("unexpected docs chunk entry %+v", docs)
+	}
+
+	chunkReader, err := OpenReport(filepath.Join(dir, docs.Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = chunkReader.Close() }()
+	var chunk JSONChunk
+	if err := json.NewDecoder(chunkReader).Decode(&chunk); err != nil {
+		t.Fatal(err)
+	}
+	if len(chunk.Files) != 2 || len(chunk.Links) != 2 {
+		t.Errorf("expected 2 files and 2 unique links in the docs chunk, got %+v", chunk)
+	}
+}
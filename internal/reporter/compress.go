@@ -0,0 +1,122 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Magic numbers of the compressed formats read transparently
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// compressedFile closes a compressing writer before the file beneath it
+type compressedFile struct {
+	io.WriteCloser
+	file *os.File
+}
+
+func (c *compressedFile) Close() error {
+	if err := c.WriteCloser.Close(); err != nil {
+		_ = c.file.Close()
+		return err
+	}
+	return c.file.Close()
+}
+
+// createOutput creates a report file, compressed with gzip or zstd when the
+// name ends in .gz or .zst
+func createOutput(path string) (io.WriteCloser, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		return &compressedFile{WriteCloser: gzip.NewWriter(file), file: file}, nil
+	case ".zst":
+		encoder, err := zstd.NewWriter(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		return &compressedFile{WriteCloser: encoder, file: file}, nil
+	default:
+		return file, nil
+	}
+}
+
+// decompressedFile closes a decompressing reader and the file beneath it
+type decompressedFile struct {
+	io.Reader
+	close func()
+	file  *os.File
+}
+
+func (d *decompressedFile) Close() error {
+	if d.close != nil {
+		d.close()
+	}
+	return d.file.Close()
+}
+
+// OpenReport opens a report file for reading, decompressing gzip and zstd
+// files transparently; the format is detected from the content, not the name
+func OpenReport(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := bufio.NewReader(file)
+	magic, _ := buffered.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		decoder, err := gzip.NewReader(buffered)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to read gzip report %s: %v", path, err)
+		}
+		return &decompressedFile{Reader: decoder, close: func() { _ = decoder.Close() }, file: file}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		decoder, err := zstd.NewReader(buffered)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to read zstd report %s: %v", path, err)
+		}
+		return &decompressedFile{Reader: decoder, close: decoder.Close, file: file}, nil
+	default:
+		return &decompressedFile{Reader: buffered, file: file}, nil
+	}
+}
+
+// ReadJSONReport reads a JSON report written with -format json, compressed
+// or not
+func ReadJSONReport(path string) (*JSONReport, error) {
+	reader, err := OpenReport(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %v", err)
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close report %s: %v\n", path, closeErr)
+		}
+	}()
+
+	var report JSONReport
+	if err := json.NewDecoder(reader).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %v", path, err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,41 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestGenerateReport_Compressed(t *testing.T) {
+	dir := t.TempDir()
+	files := []*scanner.File{{
+		Path:  "content/a.md",
+		Links: []scanner.Link{{URL: "/missing/", StatusCode: 404}},
+	}}
+
+	for _, name := range []string{"report.json", "report.json.gz", "report.json.zst"} {
+		path := filepath.Join(dir, name)
+		if err := GenerateReport(files, ReportOptions{Format: FormatJSON, OutputFile: path}); err != nil {
+			t.Fatalf("%s: GenerateReport failed: %v", name, err)
+		}
+
+		report, err := ReadJSONReport(path)
+		if err != nil {
+			t.Fatalf("%s: ReadJSONReport failed: %v", name, err)
+		}
+		if report.Summary.BrokenLinks != 1 || len(report.Links) != 1 || report.Links[0].URL != "/missing/" {
+			t.Errorf("%s: unexpected report %+v", name, report)
+		}
+	}
+
+	// Compressed files must not be plain JSON
+	data, err := os.ReadFile(filepath.Join(dir, "report.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > 0 && data[0] == '{' {
+		t.Error("expected report.json.gz to be compressed")
+	}
+}
@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// DomainCount is one entry of the outbound domain inventory
+type DomainCount struct {
+	Domain string
+	Links  int
+	Files  int
+}
+
+// DomainInventory returns every external domain linked from the files,
+// sorted by domain
+func DomainInventory(files []*scanner.File) []DomainCount {
+	counts := make(map[string]*DomainCount)
+	for _, file := range files {
+		seen := make(map[string]bool)
+		for _, link := range file.Links {
+			if link.Type != scanner.LinkTypeExternal {
+				continue
+			}
+			domain := scanner.LinkDomain(link)
+			if domain == "" {
+				continue
+			}
+			entry := counts[domain]
+			if entry == nil {
+				entry = &DomainCount{Domain: domain}
+				counts[domain] = entry
+			}
+			entry.Links++
+			if !seen[domain] {
+				entry.Files++
+				seen[domain] = true
+			}
+		}
+	}
+
+	inventory := make([]DomainCount, 0, len(counts))
+	for _, entry := range counts {
+		inventory = append(inventory, *entry)
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		return inventory[i].Domain < inventory[j].Domain
+	})
+	return inventory
+}
+
+// generateDomainsReport writes the outbound domain inventory, one domain per
+// line, in a form that can be committed as an allowlist
+func generateDomainsReport(files []*scanner.File, writer io.Writer) error {
+	inventory := DomainInventory(files)
+
+	if _, err := fmt.Fprintf(writer, "# External domains linked site-wide: %d\n", len(inventory)); err != nil {
+		return fmt.Errorf("failed to write domain inventory: %v", err)
+	}
+	for _, entry := range inventory {
+		if _, err := fmt.Fprintf(writer, "%s # %d links in %d files\n", entry.Domain, entry.Links, entry.Files); err != nil {
+			return fmt.Errorf("failed to write domain inventory: %v", err)
+		}
+	}
+	return nil
+}
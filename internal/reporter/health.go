@@ -0,0 +1,63 @@
+package reporter
+
+import "github.com/infodancer/hugo-link-checker/internal/scanner"
+
+// Severity weights of the health score: a broken link counts in full, a
+// muted one or a link with warnings or a discrepancy in part
+const (
+	brokenWeight  = 1.0
+	mutedWeight   = 0.25
+	warningWeight = 0.25
+)
+
+// healthScore rates the site from 0 to 100: the share of its links without
+// findings, each link counting the severity weight of its worst finding.
+// Links count by the pageviews of their page plus one when traffic is
+// known, so breakage on popular pages costs more, and pages missing from
+// the traffic data still count. A site without links scores 100.
+func healthScore(files []*scanner.File) float64 {
+	var total, penalty float64
+	for _, file := range files {
+		weight := 1 + float64(file.Pageviews)
+		for _, link := range file.Links {
+			total += weight
+			penalty += weight * linkSeverity(link)
+		}
+	}
+	if total == 0 {
+		return 100
+	}
+	return 100 * (1 - penalty/total)
+}
+
+// linkSeverity returns the weight of the worst finding of a link, or zero
+// for a link without findings
+func linkSeverity(link scanner.Link) float64 {
+	if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
+		if link.MutedUntil != "" {
+			return mutedWeight
+		}
+		return brokenWeight
+	}
+	if len(link.Warnings) > 0 || link.Discrepancy != "" {
+		return warningWeight
+	}
+	return 0
+}
+
+// healthGrade turns a health score into a letter grade, A for 95 and above
+// down to F below 60
+func healthGrade(score float64) string {
+	switch {
+	case score >= 95:
+		return "A"
+	case score >= 85:
+		return "B"
+	case score >= 75:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
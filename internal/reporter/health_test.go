@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestHealthScore(t *testing.T) {
+	ok := scanner.Link{URL: "/ok/", StatusCode: 200}
+	broken := scanner.Link{URL: "/gone/", StatusCode: 404, ErrorMessage: "File not found"}
+	warned := scanner.Link{URL: "/moved/", StatusCode: 200, Warnings: []string{"Redirects"}}
+	muted := scanner.Link{URL: "https://down.example/", StatusCode: 503, MutedUntil: "2030-01-01"}
+
+	testCases := []struct {
+		name  string
+		files []*scanner.File
+		score float64
+		grade string
+	}{
+		{"no links", nil, 100, "A"},
+		{"clean", []*scanner.File{{Links: []scanner.Link{ok, ok}}}, 100, "A"},
+		{"severity", []*scanner.File{{Links: []scanner.Link{ok, broken, warned, muted}}}, 62.5, "D"},
+		// The broken link is on a page without traffic, so it barely counts
+		{"traffic", []*scanner.File{{Links: []scanner.Link{broken}}, {Pageviews: 98, Links: []scanner.Link{ok}}}, 99, "A"},
+		{"popular breakage", []*scanner.File{{Pageviews: 98, Links: []scanner.Link{broken}}, {Links: []scanner.Link{ok}}}, 1, "F"},
+	}
+	for _, tc := range testCases {
+		score := healthScore(tc.files)
+		if math.Abs(score-tc.score) > 0.001 || healthGrade(score) != tc.grade {
+			t.Errorf("%s: expected %.1f (%s), got %.1f (%s)", tc.name, tc.score, tc.grade, score, healthGrade(score))
+		}
+	}
+}
+
+func TestGenerateBadgeReport(t *testing.T) {
+	files := []*scanner.File{{Links: []scanner.Link{
+		{URL: "/ok/", StatusCode: 200},
+		{URL: "/gone/", StatusCode: 404, ErrorMessage: "File not found"},
+	}}}
+
+	var out bytes.Buffer
+	if err := generateBadgeReport(files, &out); err != nil {
+		t.Fatalf("generateBadgeReport failed: %v", err)
+	}
+	var got badge
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("badge is not JSON: %v\n%s", err, out.String())
+	}
+	want := badge{SchemaVersion: 1, Label: "link health", Message: "50.0 (F)", Color: "red"}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
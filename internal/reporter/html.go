@@ -0,0 +1,90 @@
+package reporter
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// reportScript renders the HTML report from its embedded JSON result set
+//
+//go:embed assets/report.js
+var reportScript string
+
+// generateHTMLReport writes a page that embeds the JSON result set and
+// renders it client-side, so the one file serves both readers and tools
+func generateHTMLReport(files []*scanner.File, writer io.Writer, metadata *RunMetadata, msg messages, theme loadedTheme) error {
+	// json.Marshal escapes <, >, and &, so the data can't close its script element
+	data, err := json.Marshal(NewJSONReport(files, metadata))
+	if err != nil {
+		return fmt.Errorf("failed to encode HTML report data: %v", err)
+	}
+	catalog, err := json.Marshal(msg.catalog)
+	if err != nil {
+		return fmt.Errorf("failed to encode HTML report messages: %v", err)
+	}
+
+	title := msg.T("Hugo Link Checker Report")
+	if theme.title != "" {
+		title = theme.title
+	}
+
+	if _, err := fmt.Fprintf(writer, `<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="utf-8">
+    <title>%s</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 20px; }
+        .summary { background: #f5f5f5; padding: 15px; border-radius: 5px; margin-bottom: 20px; }
+        .metadata { font-size: 0.9em; color: #555; margin-bottom: 20px; }
+        .controls { margin-bottom: 20px; }
+        .controls select, .controls input { margin-right: 10px; padding: 4px; }
+        .file { margin-bottom: 20px; border: 1px solid #ddd; padding: 15px; border-radius: 5px; }
+        .file h3 { margin-top: 0; color: #333; }
+        .link { margin: 5px 0; padding: 5px; }
+        .link.broken { background: #ffe6e6; color: #d00; }
+        .link.ok { background: #e6ffe6; color: #060; }
+        .link.warning { background: #fff6e0; color: #960; }
+        .link.skipped { background: #f0f0f0; color: #666; }
+        .internal { font-style: italic; }
+        .external { font-weight: bold; }
+        .logo { max-height: 80px; display: block; margin-bottom: 10px; }
+    </style>
+`, msg.lang, html.EscapeString(title)); err != nil {
+		return fmt.Errorf("failed to write HTML header: %v", err)
+	}
+	if err := writeHTMLThemeStyle(writer, theme); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(writer, "</head>\n<body>\n"); err != nil {
+		return fmt.Errorf("failed to write HTML header: %v", err)
+	}
+	if err := writeHTMLLogo(writer, theme, title); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(writer, `    <h1>%s</h1>
+    <p>%s: %s</p>
+    <div id="report">
+        <noscript><p>%s</p></noscript>
+    </div>
+    <script type="application/json" id="report-data">%s</script>
+    <script type="application/json" id="report-messages">%s</script>
+    <script>
+%s
+    </script>
+</body>
+</html>`, html.EscapeString(title), msg.T("Generated"), time.Now().Format(time.RFC3339),
+		msg.T("This report needs JavaScript to display the results; the data is embedded in the page as JSON."),
+		data, catalog, reportScript); err != nil {
+		return fmt.Errorf("failed to write HTML report: %v", err)
+	}
+
+	return nil
+}
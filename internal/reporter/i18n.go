@@ -0,0 +1,74 @@
+package reporter
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language the report strings are written in
+const DefaultLanguage = "en"
+
+// locales holds one message catalog per language, mapping the English
+// report strings to their translations
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+// messages translates report strings; strings missing from the catalog are
+// reported in English
+type messages struct {
+	lang    string
+	catalog map[string]string
+}
+
+// T returns the translation of an English report string
+func (m messages) T(text string) string {
+	if translated, ok := m.catalog[text]; ok && translated != "" {
+		return translated
+	}
+	return text
+}
+
+// Languages returns the languages the report can be written in
+func Languages() []string {
+	languages := []string{DefaultLanguage}
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		return languages
+	}
+	for _, entry := range entries {
+		languages = append(languages, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// loadMessages returns the message catalog for a language; an empty
+// language or English returns an empty catalog
+func loadMessages(language string) (messages, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" || language == DefaultLanguage {
+		return messages{lang: DefaultLanguage}, nil
+	}
+
+	data, err := locales.ReadFile(path.Join("locales", language+".json"))
+	if err != nil {
+		return messages{}, fmt.Errorf("unsupported report language %q (supported: %s)", language, strings.Join(Languages(), ", "))
+	}
+
+	var catalog map[string]string
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return messages{}, fmt.Errorf("failed to parse %s message catalog: %v", language, err)
+	}
+	return messages{lang: language, catalog: catalog}, nil
+}
+
+// ValidateLanguage reports whether the report can be written in a language
+func ValidateLanguage(language string) error {
+	_, err := loadMessages(language)
+	return err
+}
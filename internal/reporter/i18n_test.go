@@ -0,0 +1,11 @@
+package reporter
+
+import "testing"
+
+func TestLoadMessages_Catalogs(t *testing.T) {
+	for _, language := range Languages() {
+		if err := ValidateLanguage(language); err != nil {
+			t.Errorf("%s: %v", language, err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// logfmtField is one key=value pair of a logfmt line
+type logfmtField struct {
+	key   string
+	value string
+}
+
+// generateLogfmtReport writes one logfmt line per finding: broken links at
+// level=error, link and page warnings and discrepancies at level=warning.
+// Lines are ordered by file and line, for grep and awk pipelines and log
+// ingestion without JSON parsing.
+func generateLogfmtReport(files []*scanner.File, writer io.Writer) error {
+	sortedFiles := make([]*scanner.File, len(files))
+	copy(sortedFiles, files)
+	sort.SliceStable(sortedFiles, func(i, j int) bool {
+		return reportPath(sortedFiles[i]) < reportPath(sortedFiles[j])
+	})
+
+	for _, file := range sortedFiles {
+		path := reportPath(file)
+		for _, warning := range file.Warnings {
+			if err := writeLogfmtLine(writer, []logfmtField{{"level", "warning"}, {"file", path}, {"msg", warning}}); err != nil {
+				return err
+			}
+		}
+
+		links := make([]scanner.Link, len(file.Links))
+		copy(links, file.Links)
+		sort.SliceStable(links, func(i, j int) bool {
+			return links[i].Line < links[j].Line
+		})
+		for _, link := range links {
+			fields := []logfmtField{{"file", path}}
+			if file.Pageviews > 0 {
+				fields = append(fields, logfmtField{"pageviews", strconv.FormatInt(file.Pageviews, 10)})
+			}
+			if link.Line > 0 {
+				fields = append(fields, logfmtField{"line", strconv.Itoa(link.Line)})
+			}
+			fields = append(fields, logfmtField{"url", link.URL})
+			if count := link.OccurrenceCount(); count > 1 {
+				var lines []string
+				for _, position := range link.Positions() {
+					lines = append(lines, strconv.Itoa(position.Line))
+				}
+				fields = append(fields, logfmtField{"occurrences", strconv.Itoa(count)}, logfmtField{"lines", strings.Join(lines, ",")})
+			}
+			if link.Text != "" {
+				fields = append(fields, logfmtField{"text", link.Text})
+			}
+			if link.Title != "" {
+				fields = append(fields, logfmtField{"title", link.Title})
+			}
+			fields = append(fields, logfmtField{"status", strconv.Itoa(link.StatusCode)})
+
+			if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
+				line := append([]logfmtField{{"level", "error"}}, fields...)
+				if link.ErrorCategory != "" {
+					line = append(line, logfmtField{"category", string(link.ErrorCategory)})
+				}
+				if link.MutedUntil != "" {
+					line = append(line, logfmtField{"muted_until", link.MutedUntil})
+				}
+				line = append(line, logfmtField{"msg", link.ErrorMessage})
+				if err := writeLogfmtLine(writer, line); err != nil {
+					return err
+				}
+			}
+
+			messages := append([]string(nil), link.Warnings...)
+			if link.Discrepancy != "" {
+				messages = append(messages, link.Discrepancy)
+			}
+			for _, message := range messages {
+				line := append([]logfmtField{{"level", "warning"}}, fields...)
+				line = append(line, logfmtField{"msg", message})
+				if err := writeLogfmtLine(writer, line); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeLogfmtLine writes the fields as one logfmt line
+func writeLogfmtLine(writer io.Writer, fields []logfmtField) error {
+	var line strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(field.key)
+		line.WriteByte('=')
+		line.WriteString(logfmtValue(field.value))
+	}
+	line.WriteByte('\n')
+	if _, err := io.WriteString(writer, line.String()); err != nil {
+		return fmt.Errorf("failed to write logfmt report: %v", err)
+	}
+	return nil
+}
+
+// logfmtValue quotes a value when it is empty or contains spaces, quotes,
+// equals signs, or control characters
+func logfmtValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '"' || r == '=' || r == '\\' || r == 0x7f {
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
@@ -0,0 +1,54 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestLogfmtReport(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "content/b.md", Links: []scanner.Link{
+			{URL: "/ok/", StatusCode: 200, Line: 3},
+			{URL: "https://example.com/a b", StatusCode: 404, ErrorMessage: "HTTP 404", ErrorCategory: scanner.CategoryHTTP4xx, Line: 9},
+			{URL: "/moved/", StatusCode: 200, Line: 5, Text: "the move", Title: "Moved", Warnings: []string{"Redirects to /new/"}},
+		}},
+		{Path: "content/a.md", Warnings: []string{"Page has 3 links, over the budget of 2"}, Links: []scanner.Link{
+			{URL: "https://down.example", ErrorMessage: "timeout"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := generateLogfmtReport(files, &buf); err != nil {
+		t.Fatalf("generateLogfmtReport failed: %v", err)
+	}
+	expected := strings.Join([]string{
+		`level=warning file=content/a.md msg="Page has 3 links, over the budget of 2"`,
+		`level=error file=content/a.md url=https://down.example status=0 msg=timeout`,
+		`level=warning file=content/b.md line=5 url=/moved/ text="the move" title=Moved status=200 msg="Redirects to /new/"`,
+		`level=error file=content/b.md line=9 url="https://example.com/a b" status=404 category=http-4xx msg="HTTP 404"`,
+		"",
+	}, "\n")
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestLogfmtValue(t *testing.T) {
+	tests := map[string]string{
+		"":             `""`,
+		"plain":        "plain",
+		"two words":    `"two words"`,
+		"a=b":          `"a=b"`,
+		`say "hi"`:     `"say \"hi\""`,
+		"line\nbreak":  `"line\nbreak"`,
+		"/path/ü.html": "/path/ü.html",
+	}
+	for value, want := range tests {
+		if got := logfmtValue(value); got != want {
+			t.Errorf("logfmtValue(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// FilesFromReport rebuilds the checked files of a JSON report, so reports of
+// several runs can be combined and rendered again in any format
+func FilesFromReport(report *JSONReport) []*scanner.File {
+	byPath := make(map[string]*scanner.File)
+	var files []*scanner.File
+	fileFor := func(path string) *scanner.File {
+		file, ok := byPath[path]
+		if !ok {
+			file = &scanner.File{Path: path, CanonicalPath: path}
+			byPath[path] = file
+			files = append(files, file)
+		}
+		return file
+	}
+
+	for _, summary := range report.Files {
+		file := fileFor(summary.Path)
+		if summary.CanonicalPath != "" {
+			file.CanonicalPath = summary.CanonicalPath
+		}
+		file.Site = summary.Site
+		file.Warnings = summary.Warnings
+		file.Pageviews = summary.Pageviews
+	}
+	for _, unique := range report.Links {
+		link := linkFromUnique(unique)
+		for _, path := range unique.FoundInFiles {
+			file := fileFor(path)
+			file.Links = append(file.Links, withPositions(link, unique.Positions, path))
+		}
+	}
+	return files
+}
+
+// linkFromUnique converts a report link back into a checked link
+func linkFromUnique(unique UniqueLink) scanner.Link {
+	linkType := scanner.LinkTypeInternal
+	if unique.Type == "external" {
+		linkType = scanner.LinkTypeExternal
+	}
+	return scanner.Link{
+		URL:           unique.URL,
+		Type:          linkType,
+		Kind:          scanner.LinkKind(unique.Kind),
+		LastChecked:   unique.LastChecked,
+		StatusCode:    unique.StatusCode,
+		ErrorMessage:  unique.ErrorMessage,
+		ErrorCategory: scanner.ErrorCategory(unique.Category),
+		Skipped:       scanner.SkipReason(unique.Skipped),
+		Ignored:       unique.Ignored,
+		Method:        unique.Method,
+		Text:          unique.Text,
+		Title:         unique.Title,
+
+		FinalURL:        unique.FinalURL,
+		RedirectStatus:  unique.RedirectStatus,
+		Canonical:       unique.Canonical,
+		ContentLocation: unique.ContentLocation,
+		Warnings:        unique.Warnings,
+		Discrepancy:     unique.Discrepancy,
+		MissingHeaders:  unique.MissingHeaders,
+		Width:           unique.Width,
+		Height:          unique.Height,
+		ContentType:     unique.ContentType,
+		Size:            unique.Size,
+		ResolvedSite:    unique.ResolvedSite,
+		MutedUntil:      unique.MutedUntil,
+		MovedPage:       unique.MovedPage,
+		Fixed:           unique.Fixed,
+		Fix:             unique.Fix,
+		PolicyRules:     unique.PolicyRules,
+	}
+}
+
+// withPositions returns link with its places in the file at path restored
+// from a report's positions
+func withPositions(link scanner.Link, positions []LinkPosition, path string) scanner.Link {
+	var occurrences []scanner.Occurrence
+	for _, position := range positions {
+		if position.File == path {
+			occurrences = append(occurrences, scanner.Occurrence{Line: position.Line, Column: position.Column})
+		}
+	}
+	if len(occurrences) > 0 {
+		link.Line, link.Column = occurrences[0].Line, occurrences[0].Column
+	}
+	if len(occurrences) > 1 {
+		link.Occurrences = occurrences
+	}
+	return link
+}
+
+// MergeFiles combines the files of several reports. Files that appear in
+// more than one report have their links joined; a destination reported for
+// the same file by several reports is kept once, with the most recent result.
+func MergeFiles(sets ...[]*scanner.File) []*scanner.File {
+	type mergedFile struct {
+		file *scanner.File
+		// origin records which report first contributed each destination
+		origin map[string]int
+	}
+	byPath := make(map[string]*mergedFile)
+	var merged []*scanner.File
+
+	for set, files := range sets {
+		for _, file := range files {
+			entry, ok := byPath[file.Path]
+			if !ok {
+				copied := *file
+				copied.Links = nil
+				entry = &mergedFile{file: &copied, origin: make(map[string]int)}
+				byPath[file.Path] = entry
+				merged = append(merged, entry.file)
+			} else {
+				if entry.file.Site == "" {
+					entry.file.Site = file.Site
+				}
+				for _, warning := range file.Warnings {
+					if !slices.Contains(entry.file.Warnings, warning) {
+						entry.file.Warnings = append(entry.file.Warnings, warning)
+					}
+				}
+			}
+
+			for _, link := range file.Links {
+				key := scanner.DestinationKey(link)
+				if first, seen := entry.origin[key]; seen && first != set {
+					replaceOlderResults(entry.file, key, link)
+					continue
+				}
+				entry.origin[key] = set
+				entry.file.Links = append(entry.file.Links, link)
+			}
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Path < merged[j].Path
+	})
+	return merged
+}
+
+// replaceOlderResults replaces the results of a file's links to a destination
+// with those of link when link was checked more recently
+func replaceOlderResults(file *scanner.File, key string, link scanner.Link) {
+	for i := range file.Links {
+		existing := &file.Links[i]
+		if scanner.DestinationKey(*existing) != key || !link.LastChecked.After(existing.LastChecked) {
+			continue
+		}
+		url := existing.URL
+		*existing = link
+		existing.URL = url
+	}
+}
+
+// ReadReportFiles reads the files of a JSON report for merging. Plain and
+// compressed reports are accepted, as are the manifests of split reports,
+// whose chunks are read from next to the manifest.
+func ReadReportFiles(path string) ([]*scanner.File, *RunMetadata, error) {
+	reader, err := OpenReport(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open report: %v", err)
+	}
+	var document struct {
+		JSONReport
+		Chunks []ChunkEntry `json:"chunks"`
+	}
+	err = json.NewDecoder(reader).Decode(&document)
+	if closeErr := reader.Close(); closeErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to close report %s: %v\n", path, closeErr)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse report %s: %v", path, err)
+	}
+
+	if len(document.Chunks) == 0 {
+		return FilesFromReport(&document.JSONReport), document.Metadata, nil
+	}
+
+	var sets [][]*scanner.File
+	for _, entry := range document.Chunks {
+		chunk, err := ReadJSONReport(filepath.Join(filepath.Dir(path), entry.Path))
+		if err != nil {
+			return nil, nil, err
+		}
+		sets = append(sets, FilesFromReport(chunk))
+	}
+	return MergeFiles(sets...), document.Metadata, nil
+}
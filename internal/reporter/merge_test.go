@@ -0,0 +1,90 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestMergeFiles_Shards(t *testing.T) {
+	newFiles := func() []*scanner.File {
+		return []*scanner.File{
+			{Path: "content/docs/a.md", CanonicalPath: "/docs/a/", Links: []scanner.Link{
+				{URL: "/x/", StatusCode: 404, ErrorMessage: "File not found", ErrorCategory: scanner.CategoryNotFoundLocal},
+				{URL: "https://example.com/", Type: scanner.LinkTypeExternal, StatusCode: 200},
+				{URL: "/y/", StatusCode: 200},
+				{URL: "https://ads.example.com/", Type: scanner.LinkTypeExternal, StatusCode: 200, Ignored: true},
+			}},
+			{Path: "content/docs/b.md", Links: []scanner.Link{
+				{URL: "/y/", StatusCode: 200},
+				{URL: "ftp://ftp.example.com/", Type: scanner.LinkTypeExternal, Skipped: scanner.SkipUnsupportedScheme},
+				{URL: "https://example.org/", Type: scanner.LinkTypeExternal, Skipped: scanner.SkipExternalDisabled},
+			}},
+		}
+	}
+	want := calculateSummary(newFiles())
+
+	// Render each shard as a JSON report, read it back, and merge
+	var sets [][]*scanner.File
+	for index := 1; index <= 2; index++ {
+		files := newFiles()
+		scanner.ShardFiles(files, index, 2)
+		report := NewJSONReport(files, nil)
+		sets = append(sets, FilesFromReport(&report))
+	}
+	merged := MergeFiles(sets...)
+
+	got := calculateSummary(merged)
+	if got.TotalFiles != want.TotalFiles || got.TotalLinks != want.TotalLinks || got.UniqueLinks != want.UniqueLinks ||
+		got.BrokenLinks != want.BrokenLinks || got.ExternalLinks != want.ExternalLinks {
+		t.Errorf("merged summary %+v, want %+v", got, want)
+	}
+	if merged[0].CanonicalPath != "/docs/a/" {
+		t.Errorf("expected canonical path to survive the merge, got %q", merged[0].CanonicalPath)
+	}
+	if got.BrokenByCategory[string(scanner.CategoryNotFoundLocal)] != 1 {
+		t.Errorf("expected the error category to survive the merge, got %v", got.BrokenByCategory)
+	}
+	for _, reason := range []string{"ignored", string(scanner.SkipUnsupportedScheme), string(scanner.SkipExternalDisabled)} {
+		if got.NotChecked[reason] != 1 {
+			t.Errorf("expected one %s link to survive the merge, got %v", reason, got.NotChecked)
+		}
+	}
+	if got.VerifiedLinks != 4 || got.Coverage != 100*4.0/7 {
+		t.Errorf("expected 4 of 7 links verified, got %d (%.1f%%)", got.VerifiedLinks, got.Coverage)
+	}
+}
+
+func TestMergeFiles_Deduplicates(t *testing.T) {
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	first := []*scanner.File{{Path: "content/a.md", Links: []scanner.Link{
+		{URL: "https://example.com/x", Type: scanner.LinkTypeExternal, StatusCode: 503, LastChecked: earlier},
+		{URL: "/y/", StatusCode: 200, LastChecked: later},
+	}}}
+	second := []*scanner.File{
+		{Path: "content/a.md", Links: []scanner.Link{
+			{URL: "https://EXAMPLE.com/x", Type: scanner.LinkTypeExternal, StatusCode: 200, LastChecked: later},
+			{URL: "/y/", StatusCode: 404, ErrorMessage: "File not found", LastChecked: earlier},
+		}},
+		{Path: "content/b.md", Links: []scanner.Link{{URL: "/y/", StatusCode: 200, LastChecked: later}}},
+	}
+
+	merged := MergeFiles(first, second)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(merged))
+	}
+	links := merged[0].Links
+	if len(links) != 2 {
+		t.Fatalf("expected duplicates to be dropped, got %+v", links)
+	}
+	// The newer result wins, keeping the URL as first reported
+	if links[0].URL != "https://example.com/x" || links[0].StatusCode != 200 {
+		t.Errorf("expected the newer result for the external link, got %+v", links[0])
+	}
+	if links[1].StatusCode != 200 {
+		t.Errorf("expected the older result to be ignored, got %+v", links[1])
+	}
+}
@@ -0,0 +1,44 @@
+package reporter
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/version"
+)
+
+// RunMetadata describes the run that produced a report, so archived reports
+// can be reproduced and audited
+type RunMetadata struct {
+	RunID       string            `json:"run_id,omitempty"`
+	ToolVersion string            `json:"tool_version"`
+	StartedAt   time.Time         `json:"started_at"`
+	Duration    string            `json:"duration"`
+	Hostname    string            `json:"hostname,omitempty"`
+	GitCommit   string            `json:"git_commit,omitempty"`
+	Config      map[string]string `json:"config,omitempty"`
+}
+
+// CollectRunMetadata gathers metadata for a run that started at startedAt,
+// recording the git commit of the site in siteDir (if it is a git checkout)
+// and the given configuration snapshot
+func CollectRunMetadata(siteDir string, startedAt time.Time, config map[string]string) RunMetadata {
+	metadata := RunMetadata{
+		ToolVersion: version.Version,
+		StartedAt:   startedAt,
+		Duration:    time.Since(startedAt).Round(time.Millisecond).String(),
+		Config:      config,
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		metadata.Hostname = hostname
+	}
+
+	if out, err := exec.Command("git", "-C", siteDir, "rev-parse", "HEAD").Output(); err == nil {
+		metadata.GitCommit = strings.TrimSpace(string(out))
+	}
+
+	return metadata
+}
@@ -0,0 +1,175 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// rdjsonResult is a Reviewdog Diagnostic Format (rdjson) document
+type rdjsonResult struct {
+	Source      rdjsonSource       `json:"source"`
+	Diagnostics []rdjsonDiagnostic `json:"diagnostics"`
+}
+
+type rdjsonSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type rdjsonDiagnostic struct {
+	Message     string             `json:"message"`
+	Location    rdjsonLocation     `json:"location"`
+	Severity    string             `json:"severity"`
+	Code        *rdjsonCode        `json:"code,omitempty"`
+	Suggestions []rdjsonSuggestion `json:"suggestions,omitempty"`
+}
+
+type rdjsonLocation struct {
+	Path  string       `json:"path"`
+	Range *rdjsonRange `json:"range,omitempty"`
+}
+
+type rdjsonRange struct {
+	Start rdjsonPosition  `json:"start"`
+	End   *rdjsonPosition `json:"end,omitempty"`
+}
+
+// rdjsonPosition is a position in a file; lines and byte columns count
+// from 1
+type rdjsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+type rdjsonCode struct {
+	Value string `json:"value"`
+}
+
+type rdjsonSuggestion struct {
+	Range rdjsonRange `json:"range"`
+	Text  string      `json:"text"`
+}
+
+// generateRDJSONReport writes the findings as diagnostics in the Reviewdog
+// Diagnostic Format, which reviewdog posts as PR review comments and editor
+// plugins show in place: broken links as errors (muted ones as info), link
+// and page warnings and discrepancies as warnings. Each diagnostic spans the
+// URL as written, and suggested fixes are included as suggestions.
+func generateRDJSONReport(files []*scanner.File, writer io.Writer) error {
+	sortedFiles := make([]*scanner.File, len(files))
+	copy(sortedFiles, files)
+	sort.SliceStable(sortedFiles, func(i, j int) bool {
+		return reportPath(sortedFiles[i]) < reportPath(sortedFiles[j])
+	})
+
+	result := rdjsonResult{
+		Source:      rdjsonSource{Name: "hugo-link-checker", URL: "https://github.com/infodancer/hugo-link-checker"},
+		Diagnostics: []rdjsonDiagnostic{},
+	}
+	for _, file := range sortedFiles {
+		path := reportPath(file)
+		for _, warning := range file.Warnings {
+			result.Diagnostics = append(result.Diagnostics, rdjsonDiagnostic{
+				Message:  warning,
+				Location: rdjsonLocation{Path: path},
+				Severity: "WARNING",
+			})
+		}
+
+		links := make([]scanner.Link, len(file.Links))
+		copy(links, file.Links)
+		sort.SliceStable(links, func(i, j int) bool {
+			return links[i].Line < links[j].Line
+		})
+		for _, link := range links {
+			result.Diagnostics = append(result.Diagnostics, linkDiagnostics(link, path)...)
+		}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to write rdjson report: %v", err)
+	}
+	return nil
+}
+
+// linkDiagnostics returns the diagnostics of a link in the file at path, at
+// each place it appears
+func linkDiagnostics(link scanner.Link, path string) []rdjsonDiagnostic {
+	var diagnostics []rdjsonDiagnostic
+	for _, position := range link.Positions() {
+		at := link
+		at.Line, at.Column = position.Line, position.Column
+		location := rdjsonLocation{Path: path, Range: linkRange(at)}
+
+		if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
+			diagnostic := rdjsonDiagnostic{
+				Message:  fmt.Sprintf("Broken link %s: %s", linkLabel(link, messages{}), describeFailure(link)),
+				Location: location,
+				Severity: "ERROR",
+			}
+			if link.MutedUntil != "" {
+				diagnostic.Message += fmt.Sprintf(" (muted until %s)", link.MutedUntil)
+				diagnostic.Severity = "INFO"
+			}
+			if link.ErrorCategory != "" {
+				diagnostic.Code = &rdjsonCode{Value: string(link.ErrorCategory)}
+			}
+			diagnostic.Suggestions = linkSuggestions(link, location.Range)
+			diagnostics = append(diagnostics, diagnostic)
+		}
+
+		messages := append([]string(nil), link.Warnings...)
+		if link.Discrepancy != "" {
+			messages = append(messages, link.Discrepancy)
+		}
+		for _, message := range messages {
+			diagnostics = append(diagnostics, rdjsonDiagnostic{
+				Message:     message,
+				Location:    location,
+				Severity:    "WARNING",
+				Suggestions: linkSuggestions(link, location.Range),
+			})
+		}
+	}
+	return diagnostics
+}
+
+// describeFailure returns the error message of a broken link, or its status
+// when it has none
+func describeFailure(link scanner.Link) string {
+	if link.ErrorMessage != "" {
+		return link.ErrorMessage
+	}
+	return fmt.Sprintf("HTTP %d", link.StatusCode)
+}
+
+// linkRange returns the range of a link's URL in its file: the whole URL
+// when its column is known, otherwise its line, or nil when that is unknown
+// too
+func linkRange(link scanner.Link) *rdjsonRange {
+	if link.Line == 0 {
+		return nil
+	}
+	if link.Column == 0 {
+		return &rdjsonRange{Start: rdjsonPosition{Line: link.Line}}
+	}
+	return &rdjsonRange{
+		Start: rdjsonPosition{Line: link.Line, Column: link.Column},
+		End:   &rdjsonPosition{Line: link.Line, Column: link.Column + len(link.URL)},
+	}
+}
+
+// linkSuggestions returns the suggested fix of a link as a replacement of
+// its URL, when both are known
+func linkSuggestions(link scanner.Link, urlRange *rdjsonRange) []rdjsonSuggestion {
+	if link.Fix == "" || urlRange == nil || urlRange.End == nil {
+		return nil
+	}
+	return []rdjsonSuggestion{{Range: *urlRange, Text: link.Fix}}
+}
@@ -0,0 +1,55 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestRDJSONReport(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "content/b.md", Links: []scanner.Link{
+			{URL: "/ok/", StatusCode: 200, Line: 3, Column: 8},
+			{URL: "/missing/", StatusCode: 404, ErrorMessage: "File not found", ErrorCategory: scanner.CategoryNotFoundLocal, Line: 9, Column: 12, Text: "click here", Title: "Setup guide"},
+			{URL: "https://mysite.com/about/", StatusCode: 200, Line: 5, Column: 4, Warnings: []string{"Absolute link to the site's own host"}, Fix: "/about/"},
+			{URL: "https://vendor.example/", StatusCode: 503, Line: 7, MutedUntil: "2030-01-01"},
+		}},
+		{Path: "content/a.md", Warnings: []string{"Page has 3 links, over the budget of 2"}},
+	}
+
+	var buf bytes.Buffer
+	if err := generateRDJSONReport(files, &buf); err != nil {
+		t.Fatalf("generateRDJSONReport failed: %v", err)
+	}
+	var result rdjsonResult
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Invalid rdjson: %v\n%s", err, buf.String())
+	}
+	if result.Source.Name != "hugo-link-checker" || len(result.Diagnostics) != 4 {
+		t.Fatalf("Expected 4 diagnostics, got %s", buf.String())
+	}
+
+	if d := result.Diagnostics[0]; d.Location.Path != "content/a.md" || d.Location.Range != nil || d.Severity != "WARNING" {
+		t.Errorf("Expected a page warning without a range first, got %+v", d)
+	}
+	d := result.Diagnostics[1]
+	if d.Severity != "WARNING" || d.Location.Range.Start != (rdjsonPosition{Line: 5, Column: 4}) ||
+		*d.Location.Range.End != (rdjsonPosition{Line: 5, Column: 29}) {
+		t.Errorf("Expected a warning spanning the URL, got %+v", d)
+	}
+	if len(d.Suggestions) != 1 || d.Suggestions[0].Text != "/about/" {
+		t.Errorf("Expected the fix as a suggestion, got %+v", d.Suggestions)
+	}
+	d = result.Diagnostics[2]
+	if d.Severity != "INFO" || d.Message != "Broken link https://vendor.example/: HTTP 503 (muted until 2030-01-01)" ||
+		d.Location.Range.End != nil {
+		t.Errorf("Expected a muted link as info on its line, got %+v", d)
+	}
+	d = result.Diagnostics[3]
+	if d.Severity != "ERROR" || d.Message != `Broken link /missing/ "click here" (title "Setup guide"): File not found` || d.Code.Value != "not-found-local" ||
+		d.Location.Range.Start != (rdjsonPosition{Line: 9, Column: 12}) {
+		t.Errorf("Expected a broken link error, got %+v", d)
+	}
+}
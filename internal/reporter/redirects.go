@@ -0,0 +1,102 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// RedirectGroup is a set of distinct external URLs on the site that end at
+// the same destination, at least one of them by redirect
+type RedirectGroup struct {
+	Destination string   `json:"destination"`
+	URLs        []string `json:"urls"`
+	// Links counts the links, across all files, using any of the URLs
+	Links int `json:"links"`
+}
+
+// findRedirectGroups groups the working external links by the URL they end
+// at after redirects. Fragments are left out, since they don't affect the
+// destination. Groups are ordered by link count, then destination.
+func findRedirectGroups(files []*scanner.File) []RedirectGroup {
+	type group struct {
+		urls       map[string]bool
+		links      int
+		redirected bool
+	}
+	groups := make(map[string]*group)
+	for _, file := range files {
+		for _, link := range file.Links {
+			if link.Type != scanner.LinkTypeExternal || link.StatusCode == 0 || link.StatusCode >= 400 {
+				continue
+			}
+			source := withoutFragment(link.URL)
+			destination := source
+			if link.FinalURL != "" {
+				destination = withoutFragment(link.FinalURL)
+			}
+			g := groups[destination]
+			if g == nil {
+				g = &group{urls: make(map[string]bool)}
+				groups[destination] = g
+			}
+			g.urls[source] = true
+			g.links++
+			if destination != source {
+				g.redirected = true
+			}
+		}
+	}
+
+	var result []RedirectGroup
+	for destination, g := range groups {
+		if len(g.urls) < 2 || !g.redirected {
+			continue
+		}
+		urls := make([]string, 0, len(g.urls))
+		for u := range g.urls {
+			urls = append(urls, u)
+		}
+		sort.Strings(urls)
+		result = append(result, RedirectGroup{Destination: destination, URLs: urls, Links: g.links})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Links != result[j].Links {
+			return result[i].Links > result[j].Links
+		}
+		return result[i].Destination < result[j].Destination
+	})
+	return result
+}
+
+// withoutFragment strips the fragment from a URL
+func withoutFragment(linkURL string) string {
+	linkURL, _, _ = strings.Cut(linkURL, "#")
+	return linkURL
+}
+
+// writeTextRedirectGroups writes the URLs sharing a destination in the text
+// summary
+func writeTextRedirectGroups(writer io.Writer, summary ReportSummary, msg messages) error {
+	if len(summary.RedirectGroups) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(writer, "  %s:\n", msg.T("URLs with the same destination")); err != nil {
+		return fmt.Errorf("failed to write summary: %v", err)
+	}
+	for _, group := range summary.RedirectGroups {
+		if _, err := fmt.Fprintf(writer, "    %s (%d %s):\n", group.Destination, group.Links, msg.T("Links")); err != nil {
+			return fmt.Errorf("failed to write summary: %v", err)
+		}
+		for _, u := range group.URLs {
+			if _, err := fmt.Fprintf(writer, "      %s\n", u); err != nil {
+				return fmt.Errorf("failed to write summary: %v", err)
+			}
+		}
+	}
+	return nil
+}
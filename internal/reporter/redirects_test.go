@@ -0,0 +1,42 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestFindRedirectGroups(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "content/a.md", Links: []scanner.Link{
+			{URL: "http://example.com/paper", Type: scanner.LinkTypeExternal, StatusCode: 200, FinalURL: "https://www.example.com/paper"},
+			{URL: "https://www.example.com/paper#results", Type: scanner.LinkTypeExternal, StatusCode: 200},
+			{URL: "https://example.com/other", Type: scanner.LinkTypeExternal, StatusCode: 200},
+		}},
+		{Path: "content/b.md", Links: []scanner.Link{
+			{URL: "https://doi.example.org/10.1/abc", Type: scanner.LinkTypeExternal, StatusCode: 200, FinalURL: "https://www.example.com/paper"},
+			{URL: "https://example.com/other#a", Type: scanner.LinkTypeExternal, StatusCode: 200},
+			{URL: "https://dead.example/paper", Type: scanner.LinkTypeExternal, StatusCode: 404, FinalURL: "https://www.example.com/paper"},
+		}},
+	}
+
+	groups := findRedirectGroups(files)
+	if len(groups) != 1 {
+		t.Fatalf("expected one group, got %+v", groups)
+	}
+	group := groups[0]
+	expected := []string{"http://example.com/paper", "https://doi.example.org/10.1/abc", "https://www.example.com/paper"}
+	if group.Destination != "https://www.example.com/paper" || group.Links != 3 || strings.Join(group.URLs, " ") != strings.Join(expected, " ") {
+		t.Errorf("unexpected group %+v", group)
+	}
+
+	var out bytes.Buffer
+	if err := writeTextRedirectGroups(&out, ReportSummary{RedirectGroups: groups}, messages{lang: DefaultLanguage}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "https://www.example.com/paper (3 Links):") {
+		t.Errorf("unexpected text summary:\n%s", out.String())
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/infodancer/hugo-link-checker/internal/scanner"
 )
@@ -19,19 +22,55 @@ const (
 	FormatText ReportFormat = "text"
 	FormatJSON ReportFormat = "json"
 	FormatHTML ReportFormat = "html"
+	// FormatDomains lists the external domains linked site-wide
+	FormatDomains ReportFormat = "domains"
+	// FormatTreemapCSV and FormatTreemapJSON roll broken links up by
+	// directory for treemap visualizations
+	FormatTreemapCSV  ReportFormat = "treemap-csv"
+	FormatTreemapJSON ReportFormat = "treemap-json"
+	// FormatLogfmt writes one logfmt line per finding
+	FormatLogfmt ReportFormat = "logfmt"
+	// FormatRDJSON writes the findings as Reviewdog Diagnostic Format
+	// diagnostics for editors and PR review tools
+	FormatRDJSON ReportFormat = "rdjson"
+	// FormatBadge writes the health score as a shields.io endpoint badge
+	FormatBadge ReportFormat = "badge"
 )
 
 type ReportOptions struct {
 	Format     ReportFormat
 	OutputFile string
+	// Metadata describes the run; it is included in JSON and HTML reports when set
+	Metadata *RunMetadata
+	// Language selects the message catalog of the text and HTML reports
+	Language string
+	// Theme customizes the title, logo, and styles of the HTML report
+	Theme *Theme
+	// SplitSections writes a JSON report as a manifest in OutputFile plus one
+	// chunk file per content section
+	SplitSections bool
 }
 
 type JSONReport struct {
 	GeneratedAt time.Time     `json:"generated_at"`
+	Metadata    *RunMetadata  `json:"metadata,omitempty"`
 	Summary     ReportSummary `json:"summary"`
+	Files       []FileSummary `json:"files"`
 	Links       []UniqueLink  `json:"links"`
 }
 
+// FileSummary describes one scanned file; Path matches the paths listed in
+// the found_in_files of its links
+type FileSummary struct {
+	Path          string   `json:"path"`
+	CanonicalPath string   `json:"canonical_path,omitempty"`
+	Site          string   `json:"site,omitempty"`
+	Links         int      `json:"links"`
+	BrokenLinks   int      `json:"broken_links"`
+	Warnings      []string `json:"warnings,omitempty"`
+	Pageviews     int64    `json:"pageviews,omitempty"`
+}
+
 type ReportSummary struct {
 	TotalFiles    int `json:"total_files"`
 	TotalLinks    int `json:"total_links"`
@@ -39,29 +78,172 @@ type ReportSummary struct {
 	BrokenLinks   int `json:"broken_links"`
 	InternalLinks int `json:"internal_links"`
 	ExternalLinks int `json:"external_links"`
+	Warnings      int `json:"warnings"`
+	Discrepancies int `json:"discrepancies"`
+
+	// MutedLinks counts broken links silenced by a mute; they are included
+	// in BrokenLinks but not in the exit code
+	MutedLinks int `json:"muted_links,omitempty"`
+
+	// FixedLinks counts the links that were broken in the report of an
+	// earlier run and work now
+	FixedLinks int `json:"fixed_links,omitempty"`
+
+	// UnsupportedScheme counts links skipped because their scheme is not
+	// in the allowlist
+	UnsupportedScheme int `json:"unsupported_scheme"`
+
+	// Unknown counts external links a cache-only run had no cached result
+	// for
+	Unknown int `json:"unknown,omitempty"`
+
+	// Offline counts links left unchecked because they need the network
+	// and the run was offline
+	Offline int `json:"offline,omitempty"`
+
+	// NotChecked counts the links left unverified, per reason: "ignored" for
+	// links matching an ignore pattern, otherwise the link's skip reason
+	NotChecked map[string]int `json:"not_checked,omitempty"`
+
+	// VerifiedLinks counts the links that were checked, working or broken;
+	// Coverage is their percentage of all links
+	VerifiedLinks int     `json:"verified_links"`
+	Coverage      float64 `json:"coverage"`
+
+	// HealthScore rates the site from 0 to 100 by its findings weighted by
+	// severity and page traffic; HealthGrade is its letter grade
+	HealthScore float64 `json:"health_score"`
+	HealthGrade string  `json:"health_grade"`
+
+	// BrokenByCategory counts broken links per error category
+	BrokenByCategory map[string]int `json:"broken_by_category,omitempty"`
+
+	// Sections rolls results up by Hugo content section
+	Sections map[string]*SectionSummary `json:"sections,omitempty"`
+
+	// PolicyViolations counts links per violated policy rule ID
+	PolicyViolations map[string]int `json:"policy_violations,omitempty"`
+
+	// Sites rolls results up by site in a multi-site run
+	Sites map[string]*SectionSummary `json:"sites,omitempty"`
+
+	// StaleLinks counts links to the old path of a moved page, keyed by the
+	// page's content file
+	StaleLinks map[string]int `json:"stale_links,omitempty"`
+
+	// RedirectGroups lists external URLs that end at the same destination,
+	// so citations can be normalized to one URL
+	RedirectGroups []RedirectGroup `json:"redirect_groups,omitempty"`
+}
+
+// SectionSummary holds the results for one content section or site
+type SectionSummary struct {
+	Files       int `json:"files"`
+	Links       int `json:"links"`
+	BrokenLinks int `json:"broken_links"`
 }
 
 type UniqueLink struct {
 	URL          string    `json:"url"`
 	Type         string    `json:"type"`
+	Kind         string    `json:"kind,omitempty"`
 	StatusCode   int       `json:"status_code"`
 	ErrorMessage string    `json:"error_message,omitempty"`
+	Category     string    `json:"error_category,omitempty"`
+	Skipped      string    `json:"skipped,omitempty"`
+	Ignored      bool      `json:"ignored,omitempty"`
+	Method       string    `json:"method,omitempty"`
 	LastChecked  time.Time `json:"last_checked"`
 	FoundInFiles []string  `json:"found_in_files"`
+
+	// Occurrences counts the places the link appears in all files, and
+	// Positions lists them
+	Occurrences int            `json:"occurrences"`
+	Positions   []LinkPosition `json:"positions,omitempty"`
+
+	// Text and Title are the anchor text and title of the link where it
+	// was first found
+	Text  string `json:"text,omitempty"`
+	Title string `json:"title,omitempty"`
+
+	FinalURL        string   `json:"final_url,omitempty"`
+	RedirectStatus  int      `json:"redirect_status,omitempty"`
+	Canonical       string   `json:"canonical,omitempty"`
+	ContentLocation string   `json:"content_location,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+	Discrepancy     string   `json:"discrepancy,omitempty"`
+	MissingHeaders  []string `json:"missing_headers,omitempty"`
+	Width           int      `json:"width,omitempty"`
+	Height          int      `json:"height,omitempty"`
+	ContentType     string   `json:"content_type,omitempty"`
+	Size            int64    `json:"size,omitempty"`
+
+	// Variants lists other spellings of the URL that normalize to it
+	Variants []string `json:"variants,omitempty"`
+
+	// ResolvedSite names the related site whose local tree the link was
+	// checked against
+	ResolvedSite string `json:"resolved_site,omitempty"`
+
+	// MutedUntil is the date through which the link's failures are muted
+	MutedUntil string `json:"muted_until,omitempty"`
+
+	// MovedPage is the content file of the moved page a stale link targets
+	MovedPage string `json:"moved_page,omitempty"`
+
+	// Fixed marks a link that was broken in an earlier run and works now
+	Fixed bool `json:"fixed,omitempty"`
+
+	// Pageviews is the traffic of the pages the link is found in, added up
+	Pageviews int64 `json:"pageviews,omitempty"`
+
+	// Fix is a suggested replacement for the URL as written
+	Fix string `json:"fix,omitempty"`
+
+	// PolicyRules lists the IDs of the policy rules the link violates
+	PolicyRules []string `json:"policy_rules,omitempty"`
 }
 
-// GenerateReport creates a report in the specified format
-func GenerateReport(files []*scanner.File, options ReportOptions) error {
+// LinkPosition is one place a link appears: its file and, when known, the
+// line and column its URL starts at
+type LinkPosition struct {
+	File   string `json:"file"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// GenerateReport creates a report in the specified format. Output files
+// ending in .gz or .zst are compressed.
+func GenerateReport(files []*scanner.File, options ReportOptions) (err error) {
 	var writer io.Writer = os.Stdout
 
+	msg, err := loadMessages(options.Language)
+	if err != nil {
+		return err
+	}
+	var theme loadedTheme
+	if options.Format == FormatHTML {
+		if theme, err = options.Theme.load(); err != nil {
+			return err
+		}
+	}
+
+	if options.Format == FormatJSON && options.SplitSections {
+		if options.OutputFile == "" {
+			return fmt.Errorf("a split JSON report needs an output file")
+		}
+		return generateChunkedJSONReport(files, options.OutputFile, options.Metadata)
+	}
+
 	if options.OutputFile != "" {
-		file, err := os.Create(options.OutputFile)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %v", err)
+		file, createErr := createOutput(options.OutputFile)
+		if createErr != nil {
+			return fmt.Errorf("failed to create output file: %v", createErr)
 		}
 		defer func() {
-			if closeErr := file.Close(); closeErr != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to close output file: %v\n", closeErr)
+			// Closing flushes compressed output, so a failure loses the report
+			if closeErr := file.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to close output file: %v", closeErr)
 			}
 		}()
 		writer = file
@@ -69,21 +251,37 @@ func GenerateReport(files []*scanner.File, options ReportOptions) error {
 
 	switch options.Format {
 	case FormatJSON:
-		return generateJSONReport(files, writer)
+		return generateJSONReport(files, writer, options.Metadata)
 	case FormatHTML:
-		return generateHTMLReport(files, writer)
+		return generateHTMLReport(files, writer, options.Metadata, msg, theme)
+	case FormatDomains:
+		return generateDomainsReport(files, writer)
+	case FormatTreemapCSV:
+		return generateTreemapCSVReport(files, writer)
+	case FormatTreemapJSON:
+		return generateTreemapJSONReport(files, writer)
+	case FormatLogfmt:
+		return generateLogfmtReport(files, writer)
+	case FormatRDJSON:
+		return generateRDJSONReport(files, writer)
+	case FormatBadge:
+		return generateBadgeReport(files, writer)
 	default:
-		return generateTextReport(files, writer)
+		return generateTextReport(files, writer, msg)
 	}
 }
 
-func generateTextReport(files []*scanner.File, writer io.Writer) error {
+func generateTextReport(files []*scanner.File, writer io.Writer, msg messages) error {
 	summary := calculateSummary(files)
 
-	// Sort files by absolute path
+	// Sort files by traffic, so findings on popular pages come first, then
+	// by absolute path
 	sortedFiles := make([]*scanner.File, len(files))
 	copy(sortedFiles, files)
 	sort.Slice(sortedFiles, func(i, j int) bool {
+		if sortedFiles[i].Pageviews != sortedFiles[j].Pageviews {
+			return sortedFiles[i].Pageviews > sortedFiles[j].Pageviews
+		}
 		absPathI, _ := filepath.Abs(sortedFiles[i].Path)
 		absPathJ, _ := filepath.Abs(sortedFiles[j].Path)
 		return absPathI < absPathJ
@@ -92,226 +290,377 @@ func generateTextReport(files []*scanner.File, writer io.Writer) error {
 	// Check if we're writing to stdout
 	isStdout := writer == os.Stdout
 
-	if _, err := fmt.Fprintf(writer, "Hugo Link Checker Report\n"); err != nil {
+	title := msg.T("Hugo Link Checker Report")
+	if _, err := fmt.Fprintf(writer, "%s\n", title); err != nil {
 		return fmt.Errorf("failed to write report header: %v", err)
 	}
-	if _, err := fmt.Fprintf(writer, "========================\n"); err != nil {
+	if _, err := fmt.Fprintf(writer, "%s\n", strings.Repeat("=", utf8.RuneCountInString(title))); err != nil {
 		return fmt.Errorf("failed to write report header: %v", err)
 	}
-	if _, err := fmt.Fprintf(writer, "Generated: %s\n\n", time.Now().Format(time.RFC3339)); err != nil {
+	if _, err := fmt.Fprintf(writer, "%s: %s\n\n", msg.T("Generated"), time.Now().Format(time.RFC3339)); err != nil {
 		return fmt.Errorf("failed to write report header: %v", err)
 	}
 
 	// Show summary at the beginning only if not writing to stdout
 	if !isStdout {
-		if _, err := fmt.Fprintf(writer, "Summary:\n"); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
-		}
-		if _, err := fmt.Fprintf(writer, "  Files scanned: %d\n", summary.TotalFiles); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
-		}
-		if _, err := fmt.Fprintf(writer, "  Total links: %d\n", summary.TotalLinks); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
-		}
-		if _, err := fmt.Fprintf(writer, "  Unique links: %d\n", summary.UniqueLinks); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
-		}
-		if _, err := fmt.Fprintf(writer, "  Broken links: %d\n", summary.BrokenLinks); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
-		}
-		if _, err := fmt.Fprintf(writer, "  Internal links: %d\n", summary.InternalLinks); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
+		if err := writeTextSummary(writer, summary, msg); err != nil {
+			return err
 		}
-		if _, err := fmt.Fprintf(writer, "  External links: %d\n\n", summary.ExternalLinks); err != nil {
+		if _, err := fmt.Fprintf(writer, "\n"); err != nil {
 			return fmt.Errorf("failed to write summary: %v", err)
 		}
 	}
 
-	// Filter files to only show markdown/HTML files with broken links
+	// Only show files with broken links or warnings. Besides content, these
+	// can be feeds, templates, configs, and manifests scanned for links.
 	for _, file := range sortedFiles {
-		if !isMarkdownOrHTML(file.Path) {
-			continue
-		}
-
-		// Check if this file has any broken links
+		// Check if this file has any broken links or warnings
 		var brokenLinks []scanner.Link
+		var warnedLinks []scanner.Link
 		for _, link := range file.Links {
 			if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
 				brokenLinks = append(brokenLinks, link)
 			}
+			if len(link.Warnings) > 0 || link.Discrepancy != "" {
+				warnedLinks = append(warnedLinks, link)
+			}
 		}
 
-		// Only show files that have broken links
-		if len(brokenLinks) == 0 {
+		// Only show files that have broken links or warnings
+		if len(brokenLinks) == 0 && len(warnedLinks) == 0 && len(file.Warnings) == 0 {
 			continue
 		}
 
-		if _, err := fmt.Fprintf(writer, "File: %s\n", file.Path); err != nil {
+		if _, err := fmt.Fprintf(writer, "%s: %s\n", msg.T("File"), file.Path); err != nil {
 			return fmt.Errorf("failed to write file info: %v", err)
 		}
-		if _, err := fmt.Fprintf(writer, "  Canonical: %s\n", file.CanonicalPath); err != nil {
+		if _, err := fmt.Fprintf(writer, "  %s: %s\n", msg.T("Canonical"), file.CanonicalPath); err != nil {
 			return fmt.Errorf("failed to write file info: %v", err)
 		}
-		if _, err := fmt.Fprintf(writer, "  Links (broken/total): %d/%d\n", len(brokenLinks), len(file.Links)); err != nil {
+		if file.Site != "" {
+			if _, err := fmt.Fprintf(writer, "  %s: %s\n", msg.T("Site"), file.Site); err != nil {
+				return fmt.Errorf("failed to write file info: %v", err)
+			}
+		}
+		if file.SourcePath != "" {
+			if _, err := fmt.Fprintf(writer, "  %s: %s\n", msg.T("Source"), file.SourcePath); err != nil {
+				return fmt.Errorf("failed to write file info: %v", err)
+			}
+		}
+		if file.Pageviews > 0 {
+			if _, err := fmt.Fprintf(writer, "  %s: %d\n", msg.T("Pageviews"), file.Pageviews); err != nil {
+				return fmt.Errorf("failed to write file info: %v", err)
+			}
+		}
+		if _, err := fmt.Fprintf(writer, "  %s: %d/%d\n", msg.T("Links (broken/total)"), len(brokenLinks), len(file.Links)); err != nil {
 			return fmt.Errorf("failed to write file info: %v", err)
 		}
 
 		// Only show broken links
 		for _, link := range brokenLinks {
-			status := "BROKEN"
-			if link.ErrorMessage != "" {
-				status = fmt.Sprintf("BROKEN (%s)", link.ErrorMessage)
-			}
+			status := brokenStatus(link, msg)
 
-			linkType := "internal"
+			linkType := msg.T("internal")
 			if link.Type == scanner.LinkTypeExternal {
-				linkType = "external"
+				linkType = msg.T("external")
 			}
 
-			if _, err := fmt.Fprintf(writer, "    %s [%s] - %s\n", link.URL, linkType, status); err != nil {
+			if _, err := fmt.Fprintf(writer, "    %s [%s] - %s%s\n", linkLabel(link, msg), linkType, status, occurrenceNote(link, msg)); err != nil {
 				return fmt.Errorf("failed to write link info: %v", err)
 			}
 		}
+
+		for _, warning := range file.Warnings {
+			if _, err := fmt.Fprintf(writer, "    %s (%s)\n", msg.T("WARNING"), warning); err != nil {
+				return fmt.Errorf("failed to write file info: %v", err)
+			}
+		}
+		for _, link := range warnedLinks {
+			for _, warning := range link.Warnings {
+				if _, err := fmt.Fprintf(writer, "    %s - %s (%s)\n", linkLabel(link, msg), msg.T("WARNING"), warning); err != nil {
+					return fmt.Errorf("failed to write link info: %v", err)
+				}
+			}
+			if link.Discrepancy != "" {
+				if _, err := fmt.Fprintf(writer, "    %s - %s (%s)\n", linkLabel(link, msg), msg.T("DISCREPANCY"), link.Discrepancy); err != nil {
+					return fmt.Errorf("failed to write link info: %v", err)
+				}
+			}
+		}
 		if _, err := fmt.Fprintf(writer, "\n"); err != nil {
 			return fmt.Errorf("failed to write newline: %v", err)
 		}
 	}
 
+	if err := writeTextFixed(writer, sortedFiles, msg); err != nil {
+		return err
+	}
+
 	// Show summary at the end if writing to stdout
 	if isStdout {
-		if _, err := fmt.Fprintf(writer, "Summary:\n"); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
-		}
-		if _, err := fmt.Fprintf(writer, "  Files scanned: %d\n", summary.TotalFiles); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
-		}
-		if _, err := fmt.Fprintf(writer, "  Total links: %d\n", summary.TotalLinks); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
-		}
-		if _, err := fmt.Fprintf(writer, "  Unique links: %d\n", summary.UniqueLinks); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
+		if err := writeTextSummary(writer, summary, msg); err != nil {
+			return err
 		}
-		if _, err := fmt.Fprintf(writer, "  Broken links: %d\n", summary.BrokenLinks); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
+	}
+
+	return nil
+}
+
+// writeTextFixed lists the links that were broken in an earlier run and
+// work now, by file
+func writeTextFixed(writer io.Writer, files []*scanner.File, msg messages) error {
+	heading := false
+	for _, file := range files {
+		for _, link := range file.Links {
+			if !link.Fixed {
+				continue
+			}
+			if !heading {
+				if _, err := fmt.Fprintf(writer, "%s:\n", msg.T("Fixed this run")); err != nil {
+					return fmt.Errorf("failed to write fixed links: %v", err)
+				}
+				heading = true
+			}
+			if _, err := fmt.Fprintf(writer, "  %s: %s\n", reportPath(file), linkLabel(link, msg)); err != nil {
+				return fmt.Errorf("failed to write fixed links: %v", err)
+			}
 		}
-		if _, err := fmt.Fprintf(writer, "  Internal links: %d\n", summary.InternalLinks); err != nil {
-			return fmt.Errorf("failed to write summary: %v", err)
+	}
+	if heading {
+		if _, err := fmt.Fprintf(writer, "\n"); err != nil {
+			return fmt.Errorf("failed to write newline: %v", err)
 		}
-		if _, err := fmt.Fprintf(writer, "  External links: %d\n", summary.ExternalLinks); err != nil {
+	}
+	return nil
+}
+
+// writeTextSummary writes the summary section of the text report
+func writeTextSummary(writer io.Writer, summary ReportSummary, msg messages) error {
+	if _, err := fmt.Fprintf(writer, "%s:\n", msg.T("Summary")); err != nil {
+		return fmt.Errorf("failed to write summary: %v", err)
+	}
+	for _, count := range summaryCounts(summary) {
+		if _, err := fmt.Fprintf(writer, "  %s: %d\n", msg.T(count.label), count.value); err != nil {
 			return fmt.Errorf("failed to write summary: %v", err)
 		}
 	}
-
-	return nil
+	if _, err := fmt.Fprintf(writer, "  %s: %.1f%%\n", msg.T("Verification coverage"), summary.Coverage); err != nil {
+		return fmt.Errorf("failed to write summary: %v", err)
+	}
+	if _, err := fmt.Fprintf(writer, "  %s: %.1f (%s)\n", msg.T("Health score"), summary.HealthScore, summary.HealthGrade); err != nil {
+		return fmt.Errorf("failed to write summary: %v", err)
+	}
+	if err := writeTextNotChecked(writer, summary, msg); err != nil {
+		return err
+	}
+	if err := writeTextPolicy(writer, summary, msg); err != nil {
+		return err
+	}
+	if err := writeTextStaleLinks(writer, summary, msg); err != nil {
+		return err
+	}
+	if err := writeTextRedirectGroups(writer, summary, msg); err != nil {
+		return err
+	}
+	if err := writeTextRollup(writer, msg.T("Sites"), summary.Sites, msg); err != nil {
+		return err
+	}
+	return writeTextRollup(writer, msg.T("Sections"), summary.Sections, msg)
 }
 
-func generateJSONReport(files []*scanner.File, writer io.Writer) error {
-	summary := calculateSummary(files)
-	uniqueLinks := getUniqueLinks(files)
+// summaryCount is one labelled count of the report summary
+type summaryCount struct {
+	label string
+	value int
+}
 
-	report := JSONReport{
-		GeneratedAt: time.Now(),
-		Summary:     summary,
-		Links:       uniqueLinks,
+// summaryCounts lists the summary counts in report order, labelled in English
+func summaryCounts(summary ReportSummary) []summaryCount {
+	return []summaryCount{
+		{"Files scanned", summary.TotalFiles},
+		{"Total links", summary.TotalLinks},
+		{"Unique links", summary.UniqueLinks},
+		{"Broken links", summary.BrokenLinks},
+		{"Internal links", summary.InternalLinks},
+		{"External links", summary.ExternalLinks},
+		{"Warnings", summary.Warnings},
+		{"Local/online discrepancies", summary.Discrepancies},
+		{"Skipped (unsupported scheme)", summary.UnsupportedScheme},
+		{"Muted broken links", summary.MutedLinks},
+		{"Fixed this run", summary.FixedLinks},
+		{"Unknown (not in cache)", summary.Unknown},
+		{"Not checked (offline)", summary.Offline},
+		{"Verified links", summary.VerifiedLinks},
 	}
+}
 
-	encoder := json.NewEncoder(writer)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(report)
+// brokenStatus describes why a broken link failed
+func brokenStatus(link scanner.Link, msg messages) string {
+	status := msg.T("BROKEN")
+	if link.ErrorMessage != "" {
+		status = fmt.Sprintf("%s (%s)", status, link.ErrorMessage)
+	}
+	if link.ErrorCategory != "" {
+		status = fmt.Sprintf("%s [%s]", status, link.ErrorCategory)
+	}
+	if link.ResolvedSite != "" {
+		status = fmt.Sprintf("%s (%s)", status, fmt.Sprintf(msg.T("checked in site %s"), link.ResolvedSite))
+	}
+	if link.MutedUntil != "" {
+		status = fmt.Sprintf("%s (%s)", status, fmt.Sprintf(msg.T("muted until %s"), link.MutedUntil))
+	}
+	return status
 }
 
-func generateHTMLReport(files []*scanner.File, writer io.Writer) error {
-	summary := calculateSummary(files)
+// occurrenceNote describes where a link that appears more than once in its
+// file is, e.g. " (3 occurrences: lines 4, 12, 30)"; it is empty for a link
+// that appears once
+func occurrenceNote(link scanner.Link, msg messages) string {
+	if link.OccurrenceCount() < 2 {
+		return ""
+	}
+	var lines []string
+	for _, position := range link.Positions() {
+		if position.Line > 0 {
+			lines = append(lines, strconv.Itoa(position.Line))
+		}
+	}
+	note := fmt.Sprintf(msg.T("%d occurrences"), link.OccurrenceCount())
+	if len(lines) > 0 {
+		note = fmt.Sprintf("%s: %s", note, fmt.Sprintf(msg.T("lines %s"), strings.Join(lines, ", ")))
+	}
+	return " (" + note + ")"
+}
 
-	// Sort files by absolute path
-	sortedFiles := make([]*scanner.File, len(files))
-	copy(sortedFiles, files)
-	sort.Slice(sortedFiles, func(i, j int) bool {
-		absPathI, _ := filepath.Abs(sortedFiles[i].Path)
-		absPathJ, _ := filepath.Abs(sortedFiles[j].Path)
-		return absPathI < absPathJ
-	})
+// linkLabel returns the URL of a link followed by its anchor text and
+// title, when it has them, which are easier to find on the page than the
+// URL alone
+func linkLabel(link scanner.Link, msg messages) string {
+	label := link.URL
+	if link.Text != "" {
+		label = fmt.Sprintf("%s \"%s\"", label, link.Text)
+	}
+	if link.Title != "" {
+		label = fmt.Sprintf("%s (%s)", label, fmt.Sprintf(msg.T("title %s"), "\""+link.Title+"\""))
+	}
+	return label
+}
 
-	if _, err := fmt.Fprintf(writer, `<!DOCTYPE html>
-<html>
-<head>
-    <title>Hugo Link Checker Report</title>
-    <style>
-        body { font-family: Arial, sans-serif; margin: 20px; }
-        .summary { background: #f5f5f5; padding: 15px; border-radius: 5px; margin-bottom: 20px; }
-        .file { margin-bottom: 20px; border: 1px solid #ddd; padding: 15px; border-radius: 5px; }
-        .file h3 { margin-top: 0; color: #333; }
-        .link { margin: 5px 0; padding: 5px; }
-        .link.broken { background: #ffe6e6; color: #d00; }
-        .link.ok { background: #e6ffe6; color: #060; }
-        .internal { font-style: italic; }
-        .external { font-weight: bold; }
-    </style>
-</head>
-<body>
-    <h1>Hugo Link Checker Report</h1>
-    <p>Generated: %s</p>
-    
-    <div class="summary">
-        <h2>Summary</h2>
-        <ul>
-            <li>Files scanned: %d</li>
-            <li>Total links: %d</li>
-            <li>Unique links: %d</li>
-            <li>Broken links: %d</li>
-            <li>Internal links: %d</li>
-            <li>External links: %d</li>
-        </ul>
-    </div>
-`, time.Now().Format(time.RFC3339), summary.TotalFiles, summary.TotalLinks,
-		summary.UniqueLinks, summary.BrokenLinks, summary.InternalLinks, summary.ExternalLinks); err != nil {
-		return fmt.Errorf("failed to write HTML header: %v", err)
+// writeTextNotChecked writes the counts of unverified links of the text
+// summary, per reason
+func writeTextNotChecked(writer io.Writer, summary ReportSummary, msg messages) error {
+	if len(summary.NotChecked) == 0 {
+		return nil
 	}
 
-	for _, file := range sortedFiles {
-		if _, err := fmt.Fprintf(writer, `    <div class="file">
-        <h3>%s</h3>
-        <p><strong>Canonical:</strong> %s</p>
-        <p><strong>Links found:</strong> %d</p>
-`, file.Path, file.CanonicalPath, len(file.Links)); err != nil {
-			return fmt.Errorf("failed to write file info: %v", err)
+	if _, err := fmt.Fprintf(writer, "  %s:\n", msg.T("Not checked")); err != nil {
+		return fmt.Errorf("failed to write summary: %v", err)
+	}
+	for _, reason := range sortedKeys(summary.NotChecked) {
+		if _, err := fmt.Fprintf(writer, "    %s: %d\n", reason, summary.NotChecked[reason]); err != nil {
+			return fmt.Errorf("failed to write summary: %v", err)
 		}
+	}
+	return nil
+}
 
-		for _, link := range file.Links {
-			status := "ok"
-			statusText := "OK"
-			if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
-				status = "broken"
-				statusText = "BROKEN"
-				if link.ErrorMessage != "" {
-					statusText = fmt.Sprintf("BROKEN (%s)", link.ErrorMessage)
-				}
-			}
-
-			linkClass := "internal"
-			if link.Type == scanner.LinkTypeExternal {
-				linkClass = "external"
-			}
+// writeTextPolicy writes the policy violation counts of the text summary
+func writeTextPolicy(writer io.Writer, summary ReportSummary, msg messages) error {
+	if len(summary.PolicyViolations) == 0 {
+		return nil
+	}
 
-			if _, err := fmt.Fprintf(writer, `        <div class="link %s %s">%s [%s] - %s</div>
-`, status, linkClass, link.URL, linkClass, statusText); err != nil {
-				return fmt.Errorf("failed to write link info: %v", err)
-			}
+	if _, err := fmt.Fprintf(writer, "  %s:\n", msg.T("Policy violations")); err != nil {
+		return fmt.Errorf("failed to write summary: %v", err)
+	}
+	for _, rule := range sortedKeys(summary.PolicyViolations) {
+		if _, err := fmt.Fprintf(writer, "    %s: %d\n", rule, summary.PolicyViolations[rule]); err != nil {
+			return fmt.Errorf("failed to write summary: %v", err)
 		}
+	}
+	return nil
+}
 
-		if _, err := fmt.Fprintf(writer, "    </div>\n"); err != nil {
-			return fmt.Errorf("failed to write HTML: %v", err)
+// writeTextStaleLinks writes the stale link counts of the text summary,
+// grouped by the page whose URL changed
+func writeTextStaleLinks(writer io.Writer, summary ReportSummary, msg messages) error {
+	if len(summary.StaleLinks) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(writer, "  %s:\n", msg.T("Stale links by moved page")); err != nil {
+		return fmt.Errorf("failed to write summary: %v", err)
+	}
+	for _, page := range sortedKeys(summary.StaleLinks) {
+		if _, err := fmt.Fprintf(writer, "    %s: %d\n", page, summary.StaleLinks[page]); err != nil {
+			return fmt.Errorf("failed to write summary: %v", err)
 		}
 	}
+	return nil
+}
+
+// sortedKeys returns the keys of a count map in order
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
 
-	if _, err := fmt.Fprintf(writer, `</body>
-</html>`); err != nil {
-		return fmt.Errorf("failed to write HTML footer: %v", err)
+// writeTextRollup writes a per-section or per-site rollup of the text summary
+func writeTextRollup(writer io.Writer, title string, rollup map[string]*SectionSummary, msg messages) error {
+	if len(rollup) <= 1 {
+		return nil
 	}
 
+	if _, err := fmt.Fprintf(writer, "  %s (%s):\n", title, msg.T("broken/links in files")); err != nil {
+		return fmt.Errorf("failed to write summary: %v", err)
+	}
+	for _, name := range sortedRollupNames(rollup) {
+		entry := rollup[name]
+		if _, err := fmt.Fprintf(writer, "    %s: %s\n", name, fmt.Sprintf(msg.T("%d/%d in %d files"), entry.BrokenLinks, entry.Links, entry.Files)); err != nil {
+			return fmt.Errorf("failed to write summary: %v", err)
+		}
+	}
 	return nil
 }
 
+// sortedRollupNames returns rollup keys ordered by broken links, then name
+func sortedRollupNames(rollup map[string]*SectionSummary) []string {
+	names := make([]string, 0, len(rollup))
+	for name := range rollup {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		bi, bj := rollup[names[i]].BrokenLinks, rollup[names[j]].BrokenLinks
+		if bi != bj {
+			return bi > bj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// NewJSONReport builds the result set shared by the JSON and HTML reports
+// and the findings handed to report sinks
+func NewJSONReport(files []*scanner.File, metadata *RunMetadata) JSONReport {
+	return JSONReport{
+		GeneratedAt: time.Now(),
+		Metadata:    metadata,
+		Summary:     calculateSummary(files),
+		Files:       getFileSummaries(files),
+		Links:       getUniqueLinks(files),
+	}
+}
+
+func generateJSONReport(files []*scanner.File, writer io.Writer, metadata *RunMetadata) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewJSONReport(files, metadata))
+}
+
 func calculateSummary(files []*scanner.File) ReportSummary {
 	summary := ReportSummary{
 		TotalFiles: len(files),
@@ -319,11 +668,36 @@ func calculateSummary(files []*scanner.File) ReportSummary {
 
 	uniqueURLs := make(map[string]bool)
 
+	summary.Sections = make(map[string]*SectionSummary)
+
 	for _, file := range files {
 		summary.TotalLinks += len(file.Links)
+		summary.Warnings += len(file.Warnings)
+
+		sectionName := scanner.Section(file)
+		section := summary.Sections[sectionName]
+		if section == nil {
+			section = &SectionSummary{}
+			summary.Sections[sectionName] = section
+		}
+		section.Files++
+		section.Links += len(file.Links)
+
+		site := &SectionSummary{}
+		if file.Site != "" {
+			if summary.Sites == nil {
+				summary.Sites = make(map[string]*SectionSummary)
+			}
+			if summary.Sites[file.Site] == nil {
+				summary.Sites[file.Site] = &SectionSummary{}
+			}
+			site = summary.Sites[file.Site]
+		}
+		site.Files++
+		site.Links += len(file.Links)
 
 		for _, link := range file.Links {
-			uniqueURLs[link.URL] = true
+			uniqueURLs[uniqueLinkKey(file, link)] = true
 
 			if link.Type == scanner.LinkTypeExternal {
 				summary.ExternalLinks++
@@ -331,20 +705,111 @@ func calculateSummary(files []*scanner.File) ReportSummary {
 				summary.InternalLinks++
 			}
 
+			if link.Fixed {
+				summary.FixedLinks++
+			}
 			if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
 				summary.BrokenLinks++
+				section.BrokenLinks++
+				site.BrokenLinks++
+				if link.MutedUntil != "" {
+					summary.MutedLinks++
+				}
+				if link.ErrorCategory != "" {
+					if summary.BrokenByCategory == nil {
+						summary.BrokenByCategory = make(map[string]int)
+					}
+					summary.BrokenByCategory[string(link.ErrorCategory)]++
+				}
+			}
+
+			summary.Warnings += len(link.Warnings)
+			if reason := notCheckedReason(link); reason != "" {
+				if summary.NotChecked == nil {
+					summary.NotChecked = make(map[string]int)
+				}
+				summary.NotChecked[reason]++
+			} else {
+				summary.VerifiedLinks++
+			}
+			switch link.Skipped {
+			case scanner.SkipUnsupportedScheme:
+				summary.UnsupportedScheme++
+			case scanner.SkipUnknown:
+				summary.Unknown++
+			case scanner.SkipOffline:
+				summary.Offline++
+			}
+			if link.Discrepancy != "" {
+				summary.Discrepancies++
+			}
+			for _, rule := range link.PolicyRules {
+				if summary.PolicyViolations == nil {
+					summary.PolicyViolations = make(map[string]int)
+				}
+				summary.PolicyViolations[rule]++
+			}
+			if link.MovedPage != "" {
+				if summary.StaleLinks == nil {
+					summary.StaleLinks = make(map[string]int)
+				}
+				summary.StaleLinks[link.MovedPage]++
 			}
 		}
 	}
 
 	summary.UniqueLinks = len(uniqueURLs)
+	summary.Coverage = 100
+	if summary.TotalLinks > 0 {
+		summary.Coverage = 100 * float64(summary.VerifiedLinks) / float64(summary.TotalLinks)
+	}
+	summary.HealthScore = healthScore(files)
+	summary.HealthGrade = healthGrade(summary.HealthScore)
+	summary.RedirectGroups = findRedirectGroups(files)
 	return summary
 }
 
-// isMarkdownOrHTML checks if a file is a markdown or HTML file based on its extension
-func isMarkdownOrHTML(filePath string) bool {
-	ext := strings.ToLower(filepath.Ext(filePath))
-	return ext == ".md" || ext == ".markdown" || ext == ".html" || ext == ".htm"
+// notCheckedReason returns why a link was left unverified, or "" for a
+// checked link
+func notCheckedReason(link scanner.Link) string {
+	if link.Ignored {
+		return "ignored"
+	}
+	return string(link.Skipped)
+}
+
+// reportPath returns the path a finding should be attributed to: the
+// content source for generated public/ files, otherwise the file itself
+func reportPath(file *scanner.File) string {
+	if file.SourcePath != "" {
+		return file.SourcePath
+	}
+	return file.Path
+}
+
+// getFileSummaries lists the scanned files sorted by path
+func getFileSummaries(files []*scanner.File) []FileSummary {
+	summaries := make([]FileSummary, 0, len(files))
+	for _, file := range files {
+		summary := FileSummary{
+			Path:          reportPath(file),
+			CanonicalPath: file.CanonicalPath,
+			Site:          file.Site,
+			Links:         len(file.Links),
+			Warnings:      file.Warnings,
+			Pageviews:     file.Pageviews,
+		}
+		for _, link := range file.Links {
+			if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
+				summary.BrokenLinks++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Path < summaries[j].Path
+	})
+	return summaries
 }
 
 func getUniqueLinks(files []*scanner.File) []UniqueLink {
@@ -352,21 +817,60 @@ func getUniqueLinks(files []*scanner.File) []UniqueLink {
 
 	for _, file := range files {
 		for _, link := range file.Links {
-			if existing, exists := linkMap[link.URL]; exists {
-				existing.FoundInFiles = append(existing.FoundInFiles, file.Path)
+			key := uniqueLinkKey(file, link)
+			var positions []LinkPosition
+			for _, position := range link.Positions() {
+				positions = append(positions, LinkPosition{File: reportPath(file), Line: position.Line, Column: position.Column})
+			}
+			if existing, exists := linkMap[key]; exists {
+				existing.Occurrences += link.OccurrenceCount()
+				existing.Positions = append(existing.Positions, positions...)
+				existing.FoundInFiles = append(existing.FoundInFiles, reportPath(file))
+				existing.Pageviews += file.Pageviews
+				if link.URL != existing.URL && !slices.Contains(existing.Variants, link.URL) {
+					existing.Variants = append(existing.Variants, link.URL)
+				}
 			} else {
 				linkType := "internal"
 				if link.Type == scanner.LinkTypeExternal {
 					linkType = "external"
 				}
 
-				linkMap[link.URL] = &UniqueLink{
+				linkMap[key] = &UniqueLink{
 					URL:          link.URL,
 					Type:         linkType,
+					Kind:         string(link.Kind),
 					StatusCode:   link.StatusCode,
 					ErrorMessage: link.ErrorMessage,
+					Category:     string(link.ErrorCategory),
+					Skipped:      string(link.Skipped),
+					Ignored:      link.Ignored,
+					Method:       link.Method,
 					LastChecked:  link.LastChecked,
-					FoundInFiles: []string{file.Path},
+					FoundInFiles: []string{reportPath(file)},
+					Occurrences:  link.OccurrenceCount(),
+					Positions:    positions,
+					Text:         link.Text,
+					Title:        link.Title,
+
+					FinalURL:        link.FinalURL,
+					RedirectStatus:  link.RedirectStatus,
+					Canonical:       link.Canonical,
+					ContentLocation: link.ContentLocation,
+					Warnings:        link.Warnings,
+					Discrepancy:     link.Discrepancy,
+					MissingHeaders:  link.MissingHeaders,
+					Width:           link.Width,
+					Height:          link.Height,
+					ContentType:     link.ContentType,
+					Size:            link.Size,
+					ResolvedSite:    link.ResolvedSite,
+					MutedUntil:      link.MutedUntil,
+					MovedPage:       link.MovedPage,
+					Fixed:           link.Fixed,
+					Fix:             link.Fix,
+					PolicyRules:     link.PolicyRules,
+					Pageviews:       file.Pageviews,
 				}
 			}
 		}
@@ -376,6 +880,31 @@ func getUniqueLinks(files []*scanner.File) []UniqueLink {
 	for _, link := range linkMap {
 		result = append(result, *link)
 	}
+	// Links on popular pages first
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Pageviews != result[j].Pageviews {
+			return result[i].Pageviews > result[j].Pageviews
+		}
+		if result[i].URL != result[j].URL {
+			return result[i].URL < result[j].URL
+		}
+		return result[i].FoundInFiles[0] < result[j].FoundInFiles[0]
+	})
 
 	return result
 }
+
+// uniqueLinkKey returns the key of the report entry a link is listed under.
+// Internal links point into their own site, and relative ones, such as
+// cover.png, into the page holding them, so they are only listed together
+// with links to the same path from the same site or page.
+func uniqueLinkKey(file *scanner.File, link scanner.Link) string {
+	key := scanner.DestinationKey(link)
+	if link.Type == scanner.LinkTypeExternal {
+		return key
+	}
+	if !strings.HasPrefix(key, "/") {
+		key = file.Path + " " + key
+	}
+	return file.Site + " " + key
+}
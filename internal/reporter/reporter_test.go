@@ -0,0 +1,114 @@
+package reporter
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestTextReport_Fixed(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "content/a.md", Links: []scanner.Link{
+			{URL: "/docs/", StatusCode: 200, Text: "the docs", Fixed: true},
+			{URL: "/ok/", StatusCode: 200},
+			{URL: "/gone/", StatusCode: 404, ErrorMessage: "File not found"},
+		}},
+	}
+
+	if summary := calculateSummary(files); summary.FixedLinks != 1 {
+		t.Errorf("Expected 1 fixed link, got %d", summary.FixedLinks)
+	}
+
+	var buf bytes.Buffer
+	if err := generateTextReport(files, &buf, messages{}); err != nil {
+		t.Fatalf("generateTextReport failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Fixed this run:\n  content/a.md: /docs/ \"the docs\"\n") {
+		t.Errorf("Expected the fixed link to be listed, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "  Fixed this run: 1\n") {
+		t.Errorf("Expected the fixed count in the summary, got:\n%s", buf.String())
+	}
+}
+
+func TestTextReport_Traffic(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "content/a.md", Links: []scanner.Link{{URL: "/quiet/", StatusCode: 404, ErrorMessage: "File not found"}}},
+		{Path: "content/b.md", Pageviews: 1200, Links: []scanner.Link{{URL: "/popular/", StatusCode: 404, ErrorMessage: "File not found"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := generateTextReport(files, &buf, messages{}); err != nil {
+		t.Fatalf("generateTextReport failed: %v", err)
+	}
+	report := buf.String()
+	popular, quiet := strings.Index(report, "File: content/b.md"), strings.Index(report, "File: content/a.md")
+	if popular == -1 || quiet == -1 || popular > quiet {
+		t.Errorf("Expected the page with traffic first, got:\n%s", report)
+	}
+	if !strings.Contains(report, "  Pageviews: 1200\n") {
+		t.Errorf("Expected the page's pageviews, got:\n%s", report)
+	}
+
+	links := getUniqueLinks(files)
+	if len(links) != 2 || links[0].URL != "/popular/" || links[0].Pageviews != 1200 {
+		t.Errorf("Expected /popular/ with 1200 pageviews first, got %+v", links)
+	}
+}
+
+func TestTextReport_Occurrences(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "content/a.md", Links: []scanner.Link{{
+			URL: "/missing/", StatusCode: 404, ErrorMessage: "File not found", Line: 4, Column: 7,
+			Occurrences: []scanner.Occurrence{{Line: 4, Column: 7}, {Line: 12, Column: 3}, {Line: 30}},
+		}}},
+		{Path: "content/b.md", Links: []scanner.Link{{URL: "/missing/", StatusCode: 404, ErrorMessage: "File not found", Line: 2}}},
+	}
+
+	var buf bytes.Buffer
+	if err := generateTextReport(files, &buf, messages{}); err != nil {
+		t.Fatalf("generateTextReport failed: %v", err)
+	}
+	report := buf.String()
+	if !strings.Contains(report, "/missing/ [internal] - BROKEN (File not found) (3 occurrences: lines 4, 12, 30)\n") {
+		t.Errorf("Expected the occurrences of the link, got:\n%s", report)
+	}
+	if strings.Count(report, "occurrences") != 1 {
+		t.Errorf("Expected no note for a link that appears once, got:\n%s", report)
+	}
+
+	links := getUniqueLinks(files)
+	if len(links) != 1 || links[0].Occurrences != 4 || len(links[0].Positions) != 4 {
+		t.Fatalf("Expected one link with 4 occurrences, got %+v", links)
+	}
+	if position := links[0].Positions[1]; position != (LinkPosition{File: "content/a.md", Line: 12, Column: 3}) {
+		t.Errorf("Expected the second occurrence at content/a.md:12:3, got %+v", position)
+	}
+}
+
+func TestGetUniqueLinks_RelativeInternal(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "content/posts/a/index.md", Links: []scanner.Link{{URL: "cover.png", StatusCode: 200}, {URL: "/about/", StatusCode: 200}}},
+		{Path: "content/posts/b/index.md", Links: []scanner.Link{{URL: "cover.png", StatusCode: 404, ErrorMessage: "File not found"}, {URL: "/about/", StatusCode: 200}}},
+	}
+
+	links := getUniqueLinks(files)
+	if len(links) != 3 {
+		t.Fatalf("Expected /about/ once and cover.png per page, got %+v", links)
+	}
+	if links[0].URL != "/about/" || len(links[0].FoundInFiles) != 2 {
+		t.Errorf("Expected /about/ found in both pages, got %+v", links[0])
+	}
+	if links[2].URL != "cover.png" || links[2].StatusCode != 404 || !slices.Equal(links[2].FoundInFiles, []string{"content/posts/b/index.md"}) {
+		t.Errorf("Expected the broken cover.png of b on its own, got %+v", links[2])
+	}
+
+	// Reports read back for merging keep each page's result
+	report := NewJSONReport(files, nil)
+	if summary := calculateSummary(FilesFromReport(&report)); summary.BrokenLinks != 1 {
+		t.Errorf("Expected the broken cover.png to survive the report, got %d broken links", summary.BrokenLinks)
+	}
+}
@@ -0,0 +1,109 @@
+package reporter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Theme customizes the look of the HTML report, e.g. for client-facing reports
+type Theme struct {
+	// Title replaces the report title in the page title and heading
+	Title string
+	// Logo is an image URL or a local image file shown above the heading;
+	// local files are embedded so the report stays a single file
+	Logo string
+	// CSSFile is a stylesheet added after the built-in styles
+	CSSFile string
+}
+
+// loadedTheme is a theme with its files read and ready to write
+type loadedTheme struct {
+	title   string
+	logoSrc string
+	css     string
+}
+
+// load reads the files a theme refers to
+func (t *Theme) load() (loadedTheme, error) {
+	var loaded loadedTheme
+	if t == nil {
+		return loaded, nil
+	}
+	loaded.title = strings.TrimSpace(t.Title)
+
+	if t.Logo != "" {
+		src, err := logoSource(t.Logo)
+		if err != nil {
+			return loaded, err
+		}
+		loaded.logoSrc = src
+	}
+
+	if t.CSSFile != "" {
+		data, err := os.ReadFile(t.CSSFile)
+		if err != nil {
+			return loaded, fmt.Errorf("failed to read report CSS file: %v", err)
+		}
+		loaded.css = string(data)
+	}
+	return loaded, nil
+}
+
+// Validate reports whether the files a theme refers to can be read
+func (t *Theme) Validate() error {
+	_, err := t.load()
+	return err
+}
+
+// logoSource returns the src attribute of the logo: URLs are used as given,
+// local files are embedded as a data URI
+func logoSource(logo string) (string, error) {
+	lower := strings.ToLower(logo)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "data:") {
+		return logo, nil
+	}
+
+	data, err := os.ReadFile(logo)
+	if err != nil {
+		return "", fmt.Errorf("failed to read report logo: %v", err)
+	}
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(logo)))
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("report logo %s is not a recognized image type", logo)
+	}
+	if i := strings.Index(mimeType, ";"); i != -1 {
+		mimeType = mimeType[:i]
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// writeHTMLThemeStyle writes the theme's stylesheet after the built-in styles
+func writeHTMLThemeStyle(writer io.Writer, theme loadedTheme) error {
+	if theme.css == "" {
+		return nil
+	}
+	// A stylesheet can't contain markup, so only the closing tag needs guarding
+	css := strings.ReplaceAll(theme.css, "</style", `<\/style`)
+	if _, err := fmt.Fprintf(writer, "    <style>\n%s\n    </style>\n", css); err != nil {
+		return fmt.Errorf("failed to write HTML theme: %v", err)
+	}
+	return nil
+}
+
+// writeHTMLLogo writes the theme's logo above the report heading
+func writeHTMLLogo(writer io.Writer, theme loadedTheme, alt string) error {
+	if theme.logoSrc == "" {
+		return nil
+	}
+	if _, err := fmt.Fprintf(writer, "    <img class=\"logo\" src=\"%s\" alt=\"%s\">\n",
+		html.EscapeString(theme.logoSrc), html.EscapeString(alt)); err != nil {
+		return fmt.Errorf("failed to write HTML theme: %v", err)
+	}
+	return nil
+}
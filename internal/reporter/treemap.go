@@ -0,0 +1,151 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// treemapRoot is the path of the treemap's root node, which holds every file
+const treemapRoot = "."
+
+// TreemapNode is one directory of the broken link treemap, with the counts
+// of all files below it
+type TreemapNode struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+	// Section is the content section of the files below the directory,
+	// empty if they span several sections
+	Section     string         `json:"section,omitempty"`
+	Files       int            `json:"files"`
+	Links       int            `json:"links"`
+	BrokenLinks int            `json:"broken_links"`
+	Children    []*TreemapNode `json:"children,omitempty"`
+
+	parent   string
+	sections map[string]bool
+}
+
+// BuildTreemap rolls the results up by directory, at every depth, into a
+// tree rooted at ".". Files are attributed to their content source.
+func BuildTreemap(files []*scanner.File) *TreemapNode {
+	root := &TreemapNode{Name: treemapRoot, Path: treemapRoot, sections: make(map[string]bool)}
+	nodes := map[string]*TreemapNode{treemapRoot: root}
+
+	for _, file := range files {
+		broken := 0
+		for _, link := range file.Links {
+			if link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "") {
+				broken++
+			}
+		}
+		section := scanner.Section(file)
+
+		// Walk from the root down to the file's directory
+		dir := path.Clean(filepath.ToSlash(filepath.Dir(reportPath(file))))
+		chain := []*TreemapNode{root}
+		if dir != treemapRoot {
+			parts := strings.Split(dir, "/")
+			for i, part := range parts {
+				if part == "" {
+					continue
+				}
+				parent := chain[len(chain)-1]
+				nodePath := strings.Join(parts[:i+1], "/")
+				node := nodes[nodePath]
+				if node == nil {
+					node = &TreemapNode{Name: part, Path: nodePath, Depth: parent.Depth + 1, parent: parent.Path, sections: make(map[string]bool)}
+					nodes[nodePath] = node
+					parent.Children = append(parent.Children, node)
+				}
+				chain = append(chain, node)
+			}
+		}
+
+		for _, node := range chain {
+			node.Files++
+			node.Links += len(file.Links)
+			node.BrokenLinks += broken
+			node.sections[section] = true
+		}
+	}
+
+	for _, node := range nodes {
+		if len(node.sections) == 1 {
+			for section := range node.sections {
+				node.Section = section
+			}
+		}
+		// Most broken first, so the largest cleanup targets lead
+		sort.Slice(node.Children, func(i, j int) bool {
+			a, b := node.Children[i], node.Children[j]
+			if a.BrokenLinks != b.BrokenLinks {
+				return a.BrokenLinks > b.BrokenLinks
+			}
+			return a.Path < b.Path
+		})
+	}
+	return root
+}
+
+// walk visits the node and its descendants depth-first
+func (n *TreemapNode) walk(visit func(*TreemapNode) error) error {
+	if err := visit(n); err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := child.walk(visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateTreemapCSVReport writes the treemap as one row per directory with
+// its parent, the id/parent form treemap tools such as Plotly accept
+func generateTreemapCSVReport(files []*scanner.File, writer io.Writer) error {
+	out := csv.NewWriter(writer)
+	if err := out.Write([]string{"path", "parent", "name", "depth", "section", "files", "links", "broken_links"}); err != nil {
+		return fmt.Errorf("failed to write treemap: %v", err)
+	}
+	err := BuildTreemap(files).walk(func(node *TreemapNode) error {
+		return out.Write([]string{
+			node.Path,
+			node.parent,
+			node.Name,
+			strconv.Itoa(node.Depth),
+			node.Section,
+			strconv.Itoa(node.Files),
+			strconv.Itoa(node.Links),
+			strconv.Itoa(node.BrokenLinks),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write treemap: %v", err)
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("failed to write treemap: %v", err)
+	}
+	return nil
+}
+
+// generateTreemapJSONReport writes the treemap as a nested tree, the form
+// d3.hierarchy and similar tools accept
+func generateTreemapJSONReport(files []*scanner.File, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(BuildTreemap(files)); err != nil {
+		return fmt.Errorf("failed to write treemap: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+func TestBuildTreemap(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "content/docs/guide/a.md", Links: []scanner.Link{{URL: "/x/", StatusCode: 404}, {URL: "/y/", StatusCode: 200}}},
+		{Path: "content/docs/b.md", Links: []scanner.Link{{URL: "https://down.example", ErrorMessage: "timeout"}}},
+		{Path: "content/posts/c.md", Links: []scanner.Link{{URL: "/y/", StatusCode: 200}}},
+		{Path: "public/posts/c/index.html", SourcePath: "content/posts/c.md", Links: []scanner.Link{{URL: "/z/", StatusCode: 404}}},
+	}
+
+	root := BuildTreemap(files)
+	if root.Path != "." || root.Files != 4 || root.Links != 5 || root.BrokenLinks != 3 || root.Section != "" {
+		t.Errorf("Unexpected root %+v", root)
+	}
+	if len(root.Children) != 1 || root.Children[0].Path != "content" {
+		t.Fatalf("Expected a single content node, got %+v", root.Children)
+	}
+
+	content := root.Children[0]
+	if len(content.Children) != 2 {
+		t.Fatalf("Expected docs and posts, got %+v", content.Children)
+	}
+	docs, posts := content.Children[0], content.Children[1]
+	if docs.Path != "content/docs" || docs.BrokenLinks != 2 || docs.Section != "docs" || docs.Depth != 2 {
+		t.Errorf("Unexpected docs node %+v", docs)
+	}
+	if posts.Path != "content/posts" || posts.Files != 2 || posts.BrokenLinks != 1 {
+		t.Errorf("Expected public pages under their source, got %+v", posts)
+	}
+	if len(docs.Children) != 1 || docs.Children[0].Path != "content/docs/guide" || docs.Children[0].Depth != 3 {
+		t.Errorf("Unexpected docs children %+v", docs.Children)
+	}
+}
+
+func TestTreemapCSVReport(t *testing.T) {
+	files := []*scanner.File{
+		{Path: "content/docs/a.md", Links: []scanner.Link{{URL: "/x/", StatusCode: 404}}},
+	}
+
+	var buf bytes.Buffer
+	if err := generateTreemapCSVReport(files, &buf); err != nil {
+		t.Fatalf("generateTreemapCSVReport failed: %v", err)
+	}
+	expected := strings.Join([]string{
+		"path,parent,name,depth,section,files,links,broken_links",
+		".,,.,0,docs,1,1,1",
+		"content,.,content,1,docs,1,1,1",
+		"content/docs,content,docs,2,docs,1,1,1",
+		"",
+	}, "\n")
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,230 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"golang.org/x/net/html"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Heading ID styles, matching Hugo's markup.goldmark.parser.autoHeadingIDType
+const (
+	HeadingIDGitHub      = "github"
+	HeadingIDGitHubASCII = "github-ascii"
+	HeadingIDBlackfriday = "blackfriday"
+)
+
+// id="..." and name="..." attributes in HTML
+var htmlAnchorRegex = regexp.MustCompile(`(?i)\s(?:id|name)\s*=\s*["']([^"']+)["']`)
+
+// ParseHeadingIDType validates an autoHeadingIDType value. The empty
+// string selects the github style, Hugo's default.
+func ParseHeadingIDType(idType string) (string, error) {
+	switch strings.ToLower(idType) {
+	case "", HeadingIDGitHub:
+		return HeadingIDGitHub, nil
+	case HeadingIDGitHubASCII:
+		return HeadingIDGitHubASCII, nil
+	case HeadingIDBlackfriday:
+		return HeadingIDBlackfriday, nil
+	default:
+		return "", fmt.Errorf("invalid heading ID type: %s (valid: github, github-ascii, blackfriday)", idType)
+	}
+}
+
+// anchorParser parses Markdown as Hugo's goldmark does by default, with
+// {#id} attributes on headings
+var anchorParser = parser.NewParser(
+	parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+	parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+	parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+	parser.WithAttribute(),
+)
+
+// ParseAnchors returns the fragment identifiers a page defines: heading IDs
+// of Markdown files, generated the way Hugo does for idType, and id/name
+// attributes of HTML elements. Markdown is parsed with goldmark, so setext
+// headings, headings in lists and blockquotes, and {#id} attributes count,
+// and headings in code blocks don't. HTML files are read with the HTML5
+// tokenizer, so the unquoted attributes of minified pages Hugo generated
+// count too.
+func ParseAnchors(path string, idType string) (map[string]bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if !strings.HasSuffix(strings.ToLower(path), ".md") {
+		return parseHTMLAnchors(string(content)), nil
+	}
+	return parseMarkdownAnchors(content[frontMatterEnd(content):], idType), nil
+}
+
+// parseMarkdownAnchors returns the heading IDs of a Markdown document and the
+// id/name attributes of the HTML in it
+func parseMarkdownAnchors(source []byte, idType string) map[string]bool {
+	anchors := make(map[string]bool)
+	doc := anchorParser.Parse(text.NewReader(source))
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			if id, ok := n.AttributeString("id"); ok {
+				if id, ok := id.([]byte); ok {
+					anchors[string(id)] = true
+					break
+				}
+			}
+			anchors[uniqueID(anchors, Anchorize(headingText(n, source), idType))] = true
+		case *ast.HTMLBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				addHTMLAnchors(anchors, line.Value(source))
+			}
+			if n.HasClosure() {
+				addHTMLAnchors(anchors, n.ClosureLine.Value(source))
+			}
+		case *ast.RawHTML:
+			for i := 0; i < n.Segments.Len(); i++ {
+				segment := n.Segments.At(i)
+				addHTMLAnchors(anchors, segment.Value(source))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return anchors
+}
+
+// addHTMLAnchors adds the id and name attributes of raw HTML to anchors
+func addHTMLAnchors(anchors map[string]bool, raw []byte) {
+	for _, match := range htmlAnchorRegex.FindAllSubmatch(raw, -1) {
+		anchors[string(match[1])] = true
+	}
+}
+
+// parseHTMLAnchors returns the id attributes of the elements of an HTML
+// document and the name attributes of its <a> elements
+func parseHTMLAnchors(content string) map[string]bool {
+	anchors := make(map[string]bool)
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return anchors
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+		name, hasAttr := tokenizer.TagName()
+		for hasAttr {
+			var key, value []byte
+			key, value, hasAttr = tokenizer.TagAttr()
+			if len(value) > 0 && (string(key) == "id" || (string(key) == "name" && string(name) == "a")) {
+				anchors[string(value)] = true
+			}
+		}
+	}
+}
+
+// headingText returns the text a heading renders to, without its inline
+// Markdown and HTML, which Hugo builds the heading's ID from
+func headingText(heading *ast.Heading, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(heading, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			b.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(n.Value)
+		case *ast.AutoLink:
+			b.Write(n.Label(source))
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return unescapeMarkdown(b.String())
+}
+
+// uniqueID makes id unique among the IDs seen so far on the page, as Hugo
+// does: an empty ID becomes "heading" and duplicates get -1, -2, ... suffixes
+func uniqueID(seen map[string]bool, id string) string {
+	if id == "" {
+		id = "heading"
+	}
+	if !seen[id] {
+		return id
+	}
+	for i := 1; ; i++ {
+		candidate := id + "-" + strconv.Itoa(i)
+		if !seen[candidate] {
+			return candidate
+		}
+	}
+}
+
+// Anchorize turns heading text into the ID Hugo generates for it with the
+// given autoHeadingIDType
+func Anchorize(text string, idType string) string {
+	if idType == HeadingIDBlackfriday {
+		return blackfridayAnchor(text)
+	}
+
+	asciiOnly := idType == HeadingIDGitHubASCII
+	if asciiOnly {
+		// Remove accents so é becomes e rather than being dropped
+		if stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), text); err == nil {
+			text = stripped
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(text) {
+		switch {
+		case asciiOnly && r > unicode.MaxASCII:
+		case r == '-' || r == ' ':
+			b.WriteRune('-')
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// blackfridayAnchor implements Blackfriday's SanitizedAnchorName: runs of
+// anything other than letters and numbers collapse to a single dash
+func blackfridayAnchor(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if dash && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			dash = false
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
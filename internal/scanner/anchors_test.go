@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAnchors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.md")
+	content := "# Getting Started\n\n## Install the CLI ##\n\n### Custom {#my-id}\n\n```sh\n# not a heading\n```\n\n<a name=\"legacy\"></a>\n<div id='box'></div>\n\n" +
+		"## FAQ\n\n## FAQ\n\n## FAQ\n\n## Using `go_test` with **flags**\n\n## See [the docs](/docs/)\n\n## !!!\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	anchors, err := ParseAnchors(path, HeadingIDGitHub)
+	if err != nil {
+		t.Fatalf("ParseAnchors failed: %v", err)
+	}
+
+	expected := []string{"getting-started", "install-the-cli", "my-id", "legacy", "box",
+		"faq", "faq-1", "faq-2", "using-go_test-with-flags", "see-the-docs", "heading"}
+	for _, id := range expected {
+		if !anchors[id] {
+			t.Errorf("Expected anchor %s in %v", id, anchors)
+		}
+	}
+	if anchors["not-a-heading"] {
+		t.Error("Headings inside code fences should not define anchors")
+	}
+}
+
+func TestParseAnchors_Goldmark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "---\ntitle: Front Matter\n---\n" +
+		"Setext Heading\n==============\n\n" +
+		"Second Level\n------------\n\n" +
+		"- ## In a List\n\n" +
+		"> ### In a Quote {#quoted}\n\n" +
+		"## Attributes {.wide #custom-id}\n\n" +
+		"    # indented code, not a heading\n\n" +
+		"~~~\n## fenced, not a heading\n~~~\n\n" +
+		"## Escaped \\*stars\\*\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	anchors, err := ParseAnchors(path, HeadingIDGitHub)
+	if err != nil {
+		t.Fatalf("ParseAnchors failed: %v", err)
+	}
+	for _, id := range []string{"setext-heading", "second-level", "in-a-list", "quoted", "custom-id", "escaped-stars"} {
+		if !anchors[id] {
+			t.Errorf("Expected anchor %s in %v", id, anchors)
+		}
+	}
+	for _, id := range []string{"title-front-matter", "in-a-quote", "attributes-wide-custom-id", "indented-code-not-a-heading", "fenced-not-a-heading"} {
+		if anchors[id] {
+			t.Errorf("Unexpected anchor %s in %v", id, anchors)
+		}
+	}
+}
+
+func TestParseAnchors_HTML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.html")
+	content := "<h2 id=install>Install</h2><div\n  class=\"tabs\" id='tab-1'></div><a name=\"legacy\"></a><input name=\"q\"><p id=\"a&amp;b\"></p>\n" +
+		"<pre><code># not a heading</code></pre>\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	anchors, err := ParseAnchors(path, HeadingIDGitHub)
+	if err != nil {
+		t.Fatalf("ParseAnchors failed: %v", err)
+	}
+	for _, id := range []string{"install", "tab-1", "legacy", "a&b"} {
+		if !anchors[id] {
+			t.Errorf("Expected anchor %s in %v", id, anchors)
+		}
+	}
+	if anchors["q"] || anchors["not-a-heading"] {
+		t.Errorf("Expected only id attributes and <a> names, got %v", anchors)
+	}
+}
+
+func TestAnchorize(t *testing.T) {
+	testCases := []struct {
+		text     string
+		idType   string
+		expected string
+	}{
+		{"Hello, World!", HeadingIDGitHub, "hello-world"},
+		{"  Trim Me  ", HeadingIDGitHub, "trim-me"},
+		{"A -- B", HeadingIDGitHub, "a----b"},
+		{"Café au lait", HeadingIDGitHub, "café-au-lait"},
+		{"Café au lait", HeadingIDGitHubASCII, "cafe-au-lait"},
+		{"日本語の見出し", HeadingIDGitHub, "日本語の見出し"},
+		{"日本語 heading", HeadingIDGitHubASCII, "-heading"},
+		{"Привет мир", HeadingIDGitHub, "привет-мир"},
+		{"Hello, World!", HeadingIDBlackfriday, "hello-world"},
+		{"A -- B", HeadingIDBlackfriday, "a-b"},
+		{"snake_case name", HeadingIDBlackfriday, "snake-case-name"},
+	}
+
+	for _, tc := range testCases {
+		if got := Anchorize(tc.text, tc.idType); got != tc.expected {
+			t.Errorf("Anchorize(%q, %s): expected %q, got %q", tc.text, tc.idType, tc.expected, got)
+		}
+	}
+
+	if _, err := ParseHeadingIDType("mixed"); err == nil {
+		t.Error("Expected error for unknown heading ID type")
+	}
+}
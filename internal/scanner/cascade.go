@@ -0,0 +1,184 @@
+package scanner
+
+import (
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Cascade applies the cascade blocks in the front matter of section pages
+// (_index files) to the pages below them, as Hugo does, so their URLs and
+// build options can be computed from the values they actually get
+type Cascade struct {
+	contentDir string
+	// blocks caches the cascade blocks of each section directory, relative
+	// to the content directory; sections without any map to nil
+	blocks map[string][]cascadeBlock
+}
+
+// cascadeBlock is one cascade block: the values it sets and, when it has a
+// _target, the pages it applies to
+type cascadeBlock struct {
+	values FrontMatter
+	// path is a glob matching the logical paths of the targeted pages
+	path *regexp.Regexp
+	kind string
+}
+
+// NewCascade returns a Cascade for the content directory contentDir.
+// Section pages are read as they are needed.
+func NewCascade(contentDir string) *Cascade {
+	return &Cascade{contentDir: contentDir, blocks: make(map[string][]cascadeBlock)}
+}
+
+// Apply returns the front matter of the content file at relPath, relative to
+// the content directory, with the values cascaded from the sections above
+// it added. The page's own values win, then those of nearer sections. A
+// section's cascade doesn't apply to the section page itself.
+func (c *Cascade) Apply(relPath string, fm FrontMatter) FrontMatter {
+	if c == nil {
+		return fm
+	}
+	relPath = filepath.ToSlash(relPath)
+	logical, kind := cascadeTarget(relPath)
+
+	dir := path.Dir(relPath)
+	if strings.TrimSuffix(path.Base(relPath), path.Ext(relPath)) == "_index" {
+		// Start from the parent section
+		if dir == "." {
+			return fm
+		}
+		dir = path.Dir(dir)
+	}
+
+	result, copied := fm, false
+	for {
+		for _, block := range c.sectionBlocks(dir) {
+			if (block.path != nil && !block.path.MatchString(logical)) || (block.kind != "" && block.kind != kind) {
+				continue
+			}
+			for key, value := range block.values {
+				if _, ok := result.Get(key); ok {
+					continue
+				}
+				if !copied {
+					// Leave the caller's map alone
+					result, copied = maps.Clone(fm), true
+					if result == nil {
+						result = make(FrontMatter)
+					}
+				}
+				result[key] = value
+			}
+		}
+		if dir == "." {
+			return result
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// sectionBlocks returns the cascade blocks of the _index page of dir
+func (c *Cascade) sectionBlocks(dir string) []cascadeBlock {
+	if blocks, ok := c.blocks[dir]; ok {
+		return blocks
+	}
+	var blocks []cascadeBlock
+	for _, ext := range []string{".md", ".markdown", ".html", ".htm"} {
+		indexPath := filepath.Join(c.contentDir, filepath.FromSlash(dir), "_index"+ext)
+		if _, err := os.Stat(indexPath); err != nil {
+			continue
+		}
+		// Front matter Hugo can't parse fails the build; it's reported
+		// where the page itself is read
+		fm, _ := ParseFrontMatter(indexPath)
+		value, _ := fm.Get("cascade")
+		blocks = parseCascade(value)
+		break
+	}
+	c.blocks[dir] = blocks
+	return blocks
+}
+
+// parseCascade reads a cascade value: a map of values, or a list of them
+// (TOML's [[cascade]]), each optionally restricted with _target or target
+func parseCascade(value any) []cascadeBlock {
+	var entries []map[string]any
+	switch v := value.(type) {
+	case map[string]any:
+		entries = append(entries, v)
+	case []any:
+		for _, item := range v {
+			if entry, ok := item.(map[string]any); ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	var blocks []cascadeBlock
+	for _, entry := range entries {
+		block := cascadeBlock{values: make(FrontMatter)}
+		for key, value := range entry {
+			if !strings.EqualFold(key, "_target") && !strings.EqualFold(key, "target") {
+				block.values[key] = value
+				continue
+			}
+			target := FrontMatter(nil)
+			if m, ok := value.(map[string]any); ok {
+				target = m
+			}
+			if pattern := target.String("path"); pattern != "" {
+				block.path = globPattern(pattern)
+			}
+			block.kind = strings.ToLower(target.String("kind"))
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// cascadeTarget returns the logical path and kind Hugo matches cascade
+// targets against for the content file at relPath: /blog/post for
+// blog/post.md and blog/post/index.md, with kind page, section, or home
+func cascadeTarget(relPath string) (string, string) {
+	withoutExt := strings.TrimSuffix(relPath, path.Ext(relPath))
+	kind := "page"
+	switch path.Base(withoutExt) {
+	case "_index":
+		kind = "section"
+		withoutExt = path.Dir(withoutExt)
+	case "index":
+		withoutExt = path.Dir(withoutExt)
+	}
+	logical := "/" + strings.ToLower(strings.TrimPrefix(withoutExt, "."))
+	if logical == "/" && kind == "section" {
+		kind = "home"
+	}
+	return logical, kind
+}
+
+// globPattern compiles a cascade target path glob, where * matches within a
+// path segment and ** across segments
+func globPattern(glob string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	glob = strings.ToLower(glob)
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"):
+			pattern.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			pattern.WriteString("[^/]*")
+		case glob[i] == '?':
+			pattern.WriteString("[^/]")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	pattern.WriteString("$")
+	return regexp.MustCompile(pattern.String())
+}
@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCascade_Apply(t *testing.T) {
+	contentDir := t.TempDir()
+	pages := map[string]string{
+		"_index.md":      "---\ncascade:\n  banner: site\n  build:\n    list: always\n---\n",
+		"docs/_index.md": "---\ncascade:\n  banner: docs\n  build:\n    render: never\n---\n",
+		"blog/_index.md": "+++\n[[cascade]]\nlayout = 'post'\n[cascade._target]\npath = '/blog/**'\nkind = 'page'\n[[cascade]]\nurl = '/elsewhere/'\n[cascade._target]\npath = '/blog/*/deep'\n+++\n",
+	}
+	for name, content := range pages {
+		path := filepath.Join(contentDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cascade := NewCascade(contentDir)
+	own := FrontMatter{"banner": "own"}
+	if fm := cascade.Apply("docs/guide/install.md", own); fm.String("banner") != "own" || fm.Build().Render != "never" {
+		t.Errorf("expected the page's own banner and the docs build options, got %v", fm)
+	}
+	if len(own) != 1 {
+		t.Errorf("expected the page's front matter left alone, got %v", own)
+	}
+	if fm := cascade.Apply("docs/guide/install.md", nil); fm.String("banner") != "docs" {
+		t.Errorf("expected the nearest section's banner, got %v", fm)
+	}
+	// A section's cascade applies below it, not to the section page itself
+	if fm := cascade.Apply("docs/_index.md", nil); fm.String("banner") != "site" || fm.Build().Render != "always" {
+		t.Errorf("expected only the site cascade on the docs section, got %v", fm)
+	}
+	if fm := cascade.Apply("_index.md", nil); fm != nil {
+		t.Errorf("expected nothing cascaded to the home page, got %v", fm)
+	}
+
+	// Targets restrict blocks by path and kind
+	if fm := cascade.Apply("blog/post/index.md", nil); fm.String("layout") != "post" || fm.String("url") != "" {
+		t.Errorf("expected the targeted layout only, got %v", fm)
+	}
+	if fm := cascade.Apply("blog/2024/deep.md", nil); fm.String("url") != "/elsewhere/" {
+		t.Errorf("expected the url targeted at /blog/*/deep, got %v", fm)
+	}
+	if fm := cascade.Apply("blog/news/_index.md", nil); fm.String("layout") != "" {
+		t.Errorf("expected the page-only block to skip sections, got %v", fm)
+	}
+}
@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// isCSSFile reports whether a file is a stylesheet
+func isCSSFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".css")
+}
+
+// parseCSSURLs extracts the url() references and @import targets of a
+// stylesheet or style attribute: background images, fonts, and imported
+// stylesheets. URLs may be quoted with " or ' or unquoted; comments are
+// skipped, as are data: URIs and references to fragments of the page.
+func parseCSSURLs(css string) []foundLink {
+	css = maskCSSComments(css)
+	lower := strings.ToLower(css)
+
+	var links []foundLink
+	add := func(url string, offset int) {
+		url = strings.TrimSpace(url)
+		if url == "" || strings.HasPrefix(url, "#") || strings.HasPrefix(strings.ToLower(url), "data:") {
+			return
+		}
+		links = append(links, foundLink{url: url, offset: offset, kind: KindCSS})
+	}
+
+	for i := 0; i < len(css); i++ {
+		switch {
+		case strings.HasPrefix(lower[i:], "url(") && (i == 0 || !isCSSNameByte(css[i-1])):
+			start := i
+			i += len("url(")
+			for i < len(css) && isCSSSpace(css[i]) {
+				i++
+			}
+			if i < len(css) && (css[i] == '"' || css[i] == '\'') {
+				url, end := readCSSString(css, i)
+				add(url, start)
+				i = end
+			} else {
+				end := strings.IndexByte(css[i:], ')')
+				if end == -1 {
+					return links
+				}
+				add(unescapeCSS(css[i:i+end]), start)
+				i += end
+			}
+		case strings.HasPrefix(lower[i:], "@import"):
+			start := i
+			i += len("@import")
+			for i < len(css) && isCSSSpace(css[i]) {
+				i++
+			}
+			// @import url(...) is found as a url() reference
+			if i < len(css) && (css[i] == '"' || css[i] == '\'') {
+				url, end := readCSSString(css, i)
+				add(url, start)
+				i = end
+			} else {
+				i--
+			}
+		}
+	}
+	return links
+}
+
+// readCSSString reads the quoted string starting at css[start], returning
+// its value and the index of its closing quote
+func readCSSString(css string, start int) (string, int) {
+	quote := css[start]
+	var value strings.Builder
+	i := start + 1
+	for ; i < len(css) && css[i] != quote && css[i] != '\n'; i++ {
+		if css[i] == '\\' && i+1 < len(css) {
+			i++
+		}
+		value.WriteByte(css[i])
+	}
+	return value.String(), i
+}
+
+// unescapeCSS removes the backslashes escaping characters of an unquoted
+// URL, e.g. "a\ b.png"
+func unescapeCSS(value string) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	var unescaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		unescaped.WriteByte(value[i])
+	}
+	return unescaped.String()
+}
+
+// maskCSSComments blanks out /* ... */ comments, keeping offsets intact
+func maskCSSComments(css string) string {
+	if !strings.Contains(css, "/*") {
+		return css
+	}
+	masked := []byte(css)
+	for i := 0; i+1 < len(masked); i++ {
+		if masked[i] != '/' || masked[i+1] != '*' {
+			continue
+		}
+		end := strings.Index(css[i+2:], "*/")
+		if end == -1 {
+			end = len(css) - i - 2
+		} else {
+			end += 2
+		}
+		for j := i; j < i+2+end; j++ {
+			if masked[j] != '\n' {
+				masked[j] = ' '
+			}
+		}
+		i += 1 + end
+	}
+	return string(masked)
+}
+
+// isCSSSpace reports whether c is CSS whitespace
+func isCSSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// isCSSNameByte reports whether c may be part of a CSS identifier, so that
+// e.g. "myurl(" isn't taken for a url() reference
+func isCSSNameByte(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLinksFromFile_CSS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "site.css")
+	content := `@import "reset.css";
+@import url('/css/theme.css') screen;
+/* .old { background: url(/img/old.png); } */
+body {
+  background: #fff url( "../img/paper.png" ) repeat;
+}
+@font-face {
+  font-family: "Inter";
+  src: url(/fonts/inter.woff2) format("woff2"),
+       url(data:font/woff;base64,AAAA) format("woff");
+}
+.icon { mask: url(#mask); background-image: URL(icon\ 1.svg); }
+.x { --myurl(a): 1; }
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []struct {
+		url  string
+		line int
+	}{
+		{"reset.css", 1},
+		{"/css/theme.css", 2},
+		{"../img/paper.png", 5},
+		{"/fonts/inter.woff2", 9},
+		{"icon 1.svg", 12},
+	}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), file.Links)
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Line != want.line || link.Kind != KindCSS {
+			t.Errorf("link %d: expected %q on line %d, got %q (kind %q) on line %d", i, want.url, want.line, link.URL, link.Kind, link.Line)
+		}
+	}
+}
+
+func TestParseLinksFromFile_InlineStyles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	content := `<style>
+  .hero { background: url(/img/hero.jpg); }
+</style>
+<div style="background-image: url('/img/banner.png')">Banner</div>
+<a href="/about/" style="background: url(/img/arrow.svg)">About</a>
+<p>Not CSS: url(/img/text.png)</p>
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []struct {
+		url  string
+		kind LinkKind
+		text string
+		line int
+	}{
+		{"/img/hero.jpg", KindCSS, "", 2},
+		{"/img/banner.png", KindCSS, "", 4},
+		{"/about/", "", "About", 5},
+		{"/img/arrow.svg", KindCSS, "", 5},
+	}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), file.Links)
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Kind != want.kind || link.Text != want.text || link.Line != want.line {
+			t.Errorf("link %d: expected %q (kind %q, text %q, line %d), got %q (kind %q, text %q, line %d)", i, want.url, want.kind, want.text, want.line, link.URL, link.Kind, link.Text, link.Line)
+		}
+	}
+}
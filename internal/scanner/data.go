@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDataKeys are the fields of Hugo data files whose values are links:
+// anything named like a URL, plus the names link lists and resource
+// catalogs commonly use
+var DefaultDataKeys = []string{"*url", "*urls", "link", "links", "href", "src", "image", "images", "website", "homepage", "page"}
+
+// IsDataFile reports whether path is a data file Hugo reads: YAML, TOML, or
+// JSON
+func IsDataFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".toml", ".json":
+		return true
+	}
+	return false
+}
+
+// ParseDataFile adds the links in the fields of a Hugo data file selected by
+// keys to the file's links. A key selects the fields whose name or path,
+// such as "sponsors[0].logo", matches it, and everything nested under them;
+// keys match case-insensitively and may hold * wildcards, so "*url" selects
+// url, homepage_url, and imageURL. Only values that look like URLs or file
+// paths are links. Paths without a leading slash are content paths, relative
+// to the site root like those given to relURL.
+func ParseDataFile(file *File, keys []string) error {
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", file.Path, err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var values any
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	default:
+		err = json.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse data file %s: %v", file.Path, err)
+	}
+
+	found := make(map[string]string)
+	collectDataLinks(values, "", "", keys, false, found)
+	paths := make([]string, 0, len(found))
+	for fieldPath := range found {
+		paths = append(paths, fieldPath)
+	}
+	sort.Strings(paths)
+
+	content := string(data)
+	lines := newLineIndex(content)
+	seen := make(map[string]bool)
+	for _, link := range file.Links {
+		seen[link.URL] = true
+	}
+	for _, fieldPath := range paths {
+		value := found[fieldPath]
+		linkURL := SitePath(value)
+		if seen[linkURL] {
+			continue
+		}
+		seen[linkURL] = true
+		link := NewLink(linkURL)
+		if offset := strings.Index(content, value); offset != -1 {
+			link.Line = lines.line(offset)
+		}
+		file.Links = append(file.Links, link)
+	}
+	return nil
+}
+
+// collectDataLinks walks value, the field named name at fieldPath, adding
+// its links to found, keyed by path, once it or one of its parents is
+// selected by keys
+func collectDataLinks(value any, fieldPath, name string, keys []string, selected bool, found map[string]string) {
+	if !selected && fieldPath != "" {
+		for _, key := range keys {
+			if matchDataKey(key, fieldPath) || (name != "" && matchDataKey(key, name)) {
+				selected = true
+				break
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for name, child := range v {
+			childPath := name
+			if fieldPath != "" {
+				childPath = fieldPath + "." + name
+			}
+			collectDataLinks(child, childPath, name, keys, selected, found)
+		}
+	case []any:
+		for i, child := range v {
+			collectDataLinks(child, fmt.Sprintf("%s[%d]", fieldPath, i), "", keys, selected, found)
+		}
+	case string:
+		if link := strings.TrimSpace(v); selected && looksLikeLink(link) {
+			found[fieldPath] = link
+		}
+	}
+}
+
+// matchDataKey reports whether a data key pattern matches a field name or
+// path, case-insensitively. Brackets are list indexes, not character
+// classes.
+func matchDataKey(pattern, field string) bool {
+	pattern, field = strings.ToLower(pattern), strings.ToLower(field)
+	if !strings.ContainsAny(pattern, "*?") {
+		return pattern == field
+	}
+	matched, err := path.Match(strings.ReplaceAll(pattern, "[", `\[`), field)
+	return err == nil && matched
+}
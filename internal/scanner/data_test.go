@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDataFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"resources.yaml": "- title: Getting started\n  url: https://example.com/guide\n- title: Installing\n  page: docs/install.md\n  notes: see setup.md\n",
+		"sponsors.toml":  "[[sponsor]]\nname = \"Acme\"\nhomepage_URL = \"https://acme.example/\"\nlogo = \"/images/acme.png\"\n",
+		"links.json":     `{"footer": {"links": ["/about/", "https://example.org/"]}, "version": "1.2"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		keys     []string
+		expected []string
+		lines    []int
+	}{
+		{"resources.yaml", DefaultDataKeys, []string{"https://example.com/guide", "/docs/install.md"}, []int{2, 4}},
+		{"sponsors.toml", DefaultDataKeys, []string{"https://acme.example/"}, []int{3}},
+		{"sponsors.toml", []string{"sponsor[0].logo"}, []string{"/images/acme.png"}, []int{4}},
+		{"sponsors.toml", []string{"sponsor[*].logo"}, []string{"/images/acme.png"}, []int{4}},
+		{"links.json", []string{"footer"}, []string{"/about/", "https://example.org/"}, []int{1, 1}},
+	}
+	for _, tt := range tests {
+		file := &File{Path: filepath.Join(dir, tt.name)}
+		if err := ParseDataFile(file, tt.keys); err != nil {
+			t.Fatalf("%s: ParseDataFile failed: %v", tt.name, err)
+		}
+		if len(file.Links) != len(tt.expected) {
+			t.Errorf("%s %v: expected %v, got %+v", tt.name, tt.keys, tt.expected, file.Links)
+			continue
+		}
+		for i, link := range file.Links {
+			if link.URL != tt.expected[i] || link.Line != tt.lines[i] {
+				t.Errorf("%s: expected %s on line %d, got %s on line %d", tt.name, tt.expected[i], tt.lines[i], link.URL, link.Line)
+			}
+		}
+	}
+
+	broken := &File{Path: filepath.Join(dir, "broken.json")}
+	if err := os.WriteFile(broken.Path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ParseDataFile(broken, DefaultDataKeys); err == nil {
+		t.Error("expected an error for a malformed data file")
+	}
+}
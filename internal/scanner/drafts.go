@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// WithoutDrafts drops the content pages of a site that are drafts, by their
+// own front matter or a section's cascade, from files. Hugo doesn't publish
+// drafts, so their links never reach the live site. contentDirs are the
+// site's content directories besides content/, relative to siteRoot.
+func WithoutDrafts(files []*File, siteRoot string, contentDirs []string) []*File {
+	dirs := []string{filepath.Join(siteRoot, "content")}
+	for _, dir := range contentDirs {
+		if dir != "content" {
+			dirs = append(dirs, filepath.Join(siteRoot, dir))
+		}
+	}
+	cascades := make(map[string]*Cascade)
+
+	var filtered []*File
+	for _, file := range files {
+		if !IsContentFile(file.Path) || !isDraft(file.Path, dirs, cascades) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// isDraft reports whether the content file at path, in one of contentDirs,
+// is a draft. Files outside the content directories, such as the HTML in
+// public/, aren't pages and never are.
+func isDraft(path string, contentDirs []string, cascades map[string]*Cascade) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, dir := range contentDirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absDir, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if cascades[absDir] == nil {
+			cascades[absDir] = NewCascade(absDir)
+		}
+		// Unreadable front matter is reported when the page is parsed
+		fm, _ := ParseFrontMatter(abs)
+		return cascades[absDir].Apply(rel, fm).Draft()
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithoutDrafts(t *testing.T) {
+	tmpDir := t.TempDir()
+	pages := map[string]string{
+		"content/posts/published.md":       "---\ntitle: Published\n---\n",
+		"content/posts/wip.md":             "---\ndraft: true\n---\n",
+		"content/posts/bundle/index.md":    "+++\ndraft = true\n+++\n",
+		"content/posts/quoted.md":          "---\ndraft: \"true\"\n---\n",
+		"content/posts/undrafted.md":       "---\ndraft: false\n---\n",
+		"content/upcoming/_index.md":       "---\ncascade:\n  draft: true\n---\n",
+		"content/upcoming/launch.md":       "---\ntitle: Launch\n---\n",
+		"content/upcoming/override.md":     "---\ndraft: false\n---\n",
+		"content/de/posts/entwurf.md":      "---\ndraft: true\n---\n",
+		"public/posts/wip/index.html":      "<p>draft: true</p>",
+		"static/notes/draft.md":            "---\ndraft: true\n---\n",
+		"content/posts/no-front-matter.md": "Just text\n",
+	}
+	var files []*File
+	for name, content := range pages {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, &File{Path: path})
+	}
+
+	kept := make(map[string]bool)
+	for _, file := range WithoutDrafts(files, tmpDir, []string{"content/de"}) {
+		rel, _ := filepath.Rel(tmpDir, file.Path)
+		kept[filepath.ToSlash(rel)] = true
+	}
+	for name := range pages {
+		draft := name == "content/posts/wip.md" || name == "content/posts/bundle/index.md" ||
+			name == "content/posts/quoted.md" || name == "content/upcoming/launch.md" ||
+			name == "content/de/posts/entwurf.md"
+		if kept[name] == draft {
+			t.Errorf("%s: expected kept %v, got %v", name, !draft, kept[name])
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package scanner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Attributes that carry per-link expectations, on HTML elements or in a
+// Markdown attribute block after a link: [text](url){data-lc-expected=301}
+const (
+	AttrExpected   = "data-lc-expected"
+	AttrSkip       = "data-lc-skip"
+	AttrRedirectTo = "data-lc-redirect-to"
+)
+
+// Expectation is what the author of a link declared about it
+type Expectation struct {
+	// Status is the expected HTTP status; a 3xx status is compared with
+	// the first response, before redirects are followed
+	Status int `json:"status,omitempty"`
+	// Skip leaves the link unchecked
+	Skip bool `json:"skip,omitempty"`
+	// RedirectTo is the URL the link must end at after redirects
+	RedirectTo string `json:"redirect_to,omitempty"`
+}
+
+// parseExpectation reads the data-lc-* attributes of a link. It returns nil
+// when there are none, and a warning for each invalid value.
+func parseExpectation(attrs map[string]string) (*Expectation, []string) {
+	var expect Expectation
+	var warnings []string
+	found := false
+
+	if value, ok := attrs[AttrExpected]; ok {
+		status, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || status < 100 || status > 599 {
+			warnings = append(warnings, fmt.Sprintf("Invalid %s value %q, expected an HTTP status", AttrExpected, value))
+		} else {
+			expect.Status = status
+			found = true
+		}
+	}
+	if value, ok := attrs[AttrSkip]; ok {
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "", "true":
+			expect.Skip = true
+			found = true
+		case "false":
+		default:
+			warnings = append(warnings, fmt.Sprintf("Invalid %s value %q, expected true or false", AttrSkip, value))
+		}
+	}
+	if value, ok := attrs[AttrRedirectTo]; ok {
+		if value = strings.TrimSpace(value); value == "" {
+			warnings = append(warnings, fmt.Sprintf("Empty %s value", AttrRedirectTo))
+		} else {
+			expect.RedirectTo = value
+			found = true
+		}
+	}
+
+	if !found {
+		return nil, warnings
+	}
+	return &expect, warnings
+}
+
+// parseAttributeBlock parses a Markdown attribute block such as
+// {.class #id key=value key="quoted value" flag} (without the braces) into
+// its key/value attributes; classes and IDs are left out
+func parseAttributeBlock(block string) map[string]string {
+	attrs := make(map[string]string)
+	for i := 0; i < len(block); {
+		if block[i] == ' ' || block[i] == '\t' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(block) && block[i] != ' ' && block[i] != '\t' && block[i] != '=' {
+			i++
+		}
+		key := block[start:i]
+		value := ""
+		if i < len(block) && block[i] == '=' {
+			i++
+			if i < len(block) && (block[i] == '"' || block[i] == '\'') {
+				quote := block[i]
+				end := strings.IndexByte(block[i+1:], quote)
+				if end == -1 {
+					end = len(block) - i - 1
+				}
+				value = block[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(block) && block[i] != ' ' && block[i] != '\t' {
+					i++
+				}
+				value = block[start:i]
+			}
+		}
+		if key != "" && key[0] != '.' && key[0] != '#' {
+			attrs[strings.ToLower(key)] = value
+		}
+	}
+	return attrs
+}
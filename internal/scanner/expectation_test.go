@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLinksFromFile_Expectations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := `[old](https://example.com/old){data-lc-expected=301 data-lc-redirect-to="https://example.com/new"}
+[dead](https://gone.example/){.example data-lc-expected=404}
+[draft](https://staging.example/){data-lc-skip}
+[plain](https://example.com/plain) {not attributes}
+<a href="https://example.com/html" data-lc-skip="true">HTML</a>
+[typo](https://example.com/typo){data-lc-expected=30x}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	links := make(map[string]Link)
+	for _, link := range file.Links {
+		links[link.URL] = link
+	}
+	if len(links) != 6 {
+		t.Fatalf("expected 6 links, got %+v", file.Links)
+	}
+
+	if expect := links["https://example.com/old"].Expect; expect == nil || expect.Status != 301 || expect.RedirectTo != "https://example.com/new" {
+		t.Errorf("unexpected expectation for /old: %+v", expect)
+	}
+	if expect := links["https://gone.example/"].Expect; expect == nil || expect.Status != 404 {
+		t.Errorf("unexpected expectation for gone.example: %+v", expect)
+	}
+	if expect := links["https://staging.example/"].Expect; expect == nil || !expect.Skip {
+		t.Errorf("expected staging.example to be skipped, got %+v", expect)
+	}
+	if expect := links["https://example.com/html"].Expect; expect == nil || !expect.Skip {
+		t.Errorf("expected the HTML link to be skipped, got %+v", expect)
+	}
+	if links["https://example.com/plain"].Expect != nil {
+		t.Errorf("a separated brace block isn't an attribute block")
+	}
+	if typo := links["https://example.com/typo"]; typo.Expect != nil || len(typo.Warnings) != 1 {
+		t.Errorf("expected a warning for an invalid status, got %+v", typo)
+	}
+}
@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// rssFeed is the part of an RSS 2.0 feed that holds podcast enclosures
+type rssFeed struct {
+	Items []struct {
+		Enclosures []struct {
+			URL    string `xml:"url,attr"`
+			Length string `xml:"length,attr"`
+		} `xml:"enclosure"`
+	} `xml:"channel>item"`
+}
+
+// ParseEnclosuresFromFeed reads an RSS feed and adds the enclosure of each
+// item to the file's links. Files that aren't RSS feeds yield no links.
+func ParseEnclosuresFromFeed(file *File) error {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", file.Path, err)
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", file.Path, closeErr)
+		}
+	}()
+
+	var feed rssFeed
+	decoder := xml.NewDecoder(f)
+	decoder.Strict = false
+	if err := decoder.Decode(&feed); err != nil {
+		return fmt.Errorf("error reading feed %s: %w", file.Path, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range feed.Items {
+		for _, enclosure := range item.Enclosures {
+			enclosureURL := strings.TrimSpace(enclosure.URL)
+			if enclosureURL == "" || seen[enclosureURL] {
+				continue
+			}
+			seen[enclosureURL] = true
+
+			link := NewLink(enclosureURL)
+			link.Enclosure = true
+			if length, err := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64); err == nil {
+				link.EnclosureLength = length
+			}
+			file.Links = append(file.Links, link)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEnclosuresFromFeed(t *testing.T) {
+	feed := `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+  <channel>
+    <title>Podcast</title>
+    <link>https://example.com/</link>
+    <item>
+      <title>Episode 1</title>
+      <link>https://example.com/episodes/1/</link>
+      <enclosure url="https://cdn.example.com/ep1.mp3" length="48213" type="audio/mpeg"/>
+    </item>
+    <item>
+      <title>Episode 2</title>
+      <enclosure url="https://cdn.example.com/ep2.mp3" length="unknown" type="audio/mpeg"/>
+    </item>
+    <item>
+      <title>Episode 1 again</title>
+      <enclosure url="https://cdn.example.com/ep1.mp3" length="48213" type="audio/mpeg"/>
+    </item>
+  </channel>
+</rss>`
+	path := filepath.Join(t.TempDir(), "index.xml")
+	if err := os.WriteFile(path, []byte(feed), 0644); err != nil {
+		t.Fatalf("Failed to write feed: %v", err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseEnclosuresFromFeed(file); err != nil {
+		t.Fatalf("ParseEnclosuresFromFeed failed: %v", err)
+	}
+	if len(file.Links) != 2 {
+		t.Fatalf("Expected 2 enclosures, got %d: %v", len(file.Links), file.Links)
+	}
+
+	first := file.Links[0]
+	if first.URL != "https://cdn.example.com/ep1.mp3" || !first.Enclosure || first.EnclosureLength != 48213 || first.Type != LinkTypeExternal {
+		t.Errorf("Unexpected first enclosure: %+v", first)
+	}
+	if second := file.Links[1]; !second.Enclosure || second.EnclosureLength != 0 {
+		t.Errorf("Expected an enclosure without a declared length, got %+v", second)
+	}
+}
@@ -0,0 +1,31 @@
+package scanner
+
+import "regexp"
+
+var (
+	// figurePattern matches a figure shortcode call, {{< figure ... >}} or
+	// {{% figure ... %}}
+	figurePattern = regexp.MustCompile(`\{\{[<%]-?\s*figure\s((?:[^}]|\}[^}])*)\}\}`)
+	// figureSrcPattern matches the src parameter of a figure shortcode,
+	// quoted with " or ` or unquoted
+	figureSrcPattern = regexp.MustCompile("(?:^|\\s)src\\s*=\\s*(?:\"([^\"]*)\"|`([^`]*)`|([^\\s\"`>%]+))")
+)
+
+// parseFigureShortcodes extracts the image sources of the figure shortcodes
+// in a Markdown document. Shortcodes starting in code, blanked out in masked,
+// are skipped; src may be a raw string, which looks like a code span.
+func parseFigureShortcodes(content, masked string) []foundLink {
+	var links []foundLink
+	for _, match := range figurePattern.FindAllStringSubmatchIndex(content, -1) {
+		if masked[match[0]] != '{' {
+			continue
+		}
+		params := content[match[2]:match[3]]
+		src := figureSrcPattern.FindStringSubmatch(params)
+		if src == nil {
+			continue
+		}
+		links = append(links, foundLink{url: src[1] + src[2] + src[3], offset: match[0], kind: KindImage})
+	}
+	return links
+}
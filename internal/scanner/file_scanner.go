@@ -4,12 +4,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
 // EnumerateFiles recursively finds all files with the specified extensions
 // and returns a map of canonical paths to File structs to ensure uniqueness
 func EnumerateFiles(rootDir string, extensions []string) (map[string]*File, error) {
+	return EnumerateFilesExcluding(rootDir, extensions, nil)
+}
+
+// EnumerateFilesExcluding works like EnumerateFiles but also skips
+// directories whose name is in excludeDirs
+func EnumerateFilesExcluding(rootDir string, extensions []string, excludeDirs []string) (map[string]*File, error) {
 	files := make(map[string]*File)
 
 	// Normalize the extensions to include the dot
@@ -29,8 +36,12 @@ func EnumerateFiles(rootDir string, extensions []string) (map[string]*File, erro
 
 		// Skip directories, but check for "public" directory to skip entirely
 		if info.IsDir() {
-			// Skip the "public" directory and all its contents
-			if info.Name() == "public" {
+			// Skip the "public" directory and all its contents, unless it
+			// is the directory being enumerated
+			if info.Name() == "public" && path != rootDir {
+				return filepath.SkipDir
+			}
+			if path != rootDir && slices.Contains(excludeDirs, info.Name()) {
 				return filepath.SkipDir
 			}
 			return nil
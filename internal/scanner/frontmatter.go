@@ -0,0 +1,271 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter is the front matter of a content file. Keys are matched
+// case-insensitively, as Hugo does.
+type FrontMatter map[string]any
+
+// ParseFrontMatter reads the YAML (---), TOML (+++), or JSON front matter of
+// a content file. A file without front matter yields nil.
+func ParseFrontMatter(path string) (FrontMatter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return parseFrontMatter(path, data)
+}
+
+// frontMatterEnd returns the offset the content of a file starts at, past
+// its front matter; 0 if it has none or the front matter isn't closed
+func frontMatterEnd(data []byte) int {
+	start := len(data) - len(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	body := data[start:]
+
+	if bytes.HasPrefix(body, []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		var values map[string]any
+		if err := decoder.Decode(&values); err != nil {
+			return 0
+		}
+		return start + int(decoder.InputOffset())
+	}
+
+	line, rest, found := bytes.Cut(body, []byte("\n"))
+	delimiter := string(bytes.TrimSpace(line))
+	if !found || (delimiter != "---" && delimiter != "+++") {
+		return 0
+	}
+	offset := start + len(line) + 1
+	for len(rest) > 0 {
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		offset += len(line) + 1
+		if string(bytes.TrimSpace(line)) == delimiter {
+			return min(offset, len(data))
+		}
+	}
+	return 0
+}
+
+func parseFrontMatter(path string, data []byte) (FrontMatter, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	values := make(map[string]any)
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		if err := json.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+			return nil, fmt.Errorf("failed to parse front matter of %s: %v", path, err)
+		}
+		return values, nil
+	}
+
+	lines := bufio.NewScanner(bytes.NewReader(data))
+	lines.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if !lines.Scan() {
+		return nil, nil
+	}
+	delimiter := strings.TrimSpace(lines.Text())
+	if delimiter != "---" && delimiter != "+++" {
+		return nil, nil
+	}
+
+	var block bytes.Buffer
+	closed := false
+	for lines.Scan() {
+		if strings.TrimSpace(lines.Text()) == delimiter {
+			closed = true
+			break
+		}
+		block.WriteString(lines.Text())
+		block.WriteByte('\n')
+	}
+	if !closed {
+		return nil, fmt.Errorf("failed to parse front matter of %s: missing closing %s", path, delimiter)
+	}
+
+	if delimiter == "+++" {
+		err := toml.Unmarshal(block.Bytes(), &values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse front matter of %s: %v", path, err)
+		}
+	} else if err := yaml.Unmarshal(block.Bytes(), &values); err != nil {
+		return nil, fmt.Errorf("failed to parse front matter of %s: %v", path, err)
+	}
+	return values, nil
+}
+
+// Get returns the value of a key
+func (fm FrontMatter) Get(key string) (any, bool) {
+	if value, ok := fm[key]; ok {
+		return value, true
+	}
+	for k, value := range fm {
+		if strings.EqualFold(k, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// String returns the string value of a key, or "" if unset
+func (fm FrontMatter) String(key string) string {
+	value, _ := fm.Get(key)
+	s, _ := value.(string)
+	return strings.TrimSpace(s)
+}
+
+// Strings returns the string values of a list key, such as aliases. A single
+// string is returned as a list of one.
+func (fm FrontMatter) Strings(key string) []string {
+	value, _ := fm.Get(key)
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// BuildOptions are the build options of a page, which control whether Hugo
+// renders it and lists it in collections such as .Pages
+type BuildOptions struct {
+	// Render is always, never, or link: link makes the page's permalink
+	// available without rendering the page
+	Render string
+	// List is always, never, or local
+	List string
+}
+
+// Build returns the build options of the front matter, set under build or,
+// before Hugo 0.123, _build. Unset options default to always, and the
+// booleans older sites use map to always and never.
+func (fm FrontMatter) Build() BuildOptions {
+	opts := BuildOptions{Render: "always", List: "always"}
+	value, ok := fm.Get("build")
+	if !ok {
+		value, _ = fm.Get("_build")
+	}
+	build, _ := value.(map[string]any)
+	if build == nil {
+		return opts
+	}
+	option := func(key, fallback string) string {
+		value, _ := FrontMatter(build).Get(key)
+		switch v := value.(type) {
+		case string:
+			if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+				return v
+			}
+		case bool:
+			if !v {
+				return "never"
+			}
+			return "always"
+		}
+		return fallback
+	}
+	opts.Render = option("render", opts.Render)
+	opts.List = option("list", opts.List)
+	return opts
+}
+
+// Draft reports whether the front matter marks the page as a draft, which
+// Hugo only builds with --buildDrafts
+func (fm FrontMatter) Draft() bool {
+	value, _ := fm.Get("draft")
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(strings.TrimSpace(v), "true")
+	}
+	return false
+}
+
+// DefaultFrontMatterURLKeys are the front matter fields whose values are
+// links: cover and featured images, the images Hugo's Open Graph template
+// reads, and the canonical URL of themes such as PaperMod
+var DefaultFrontMatterURLKeys = []string{"image", "images", "cover", "featured_image", "canonicalURL"}
+
+// frontMatterLinks returns the links in the fields of fm selected by keys. A
+// key selects the fields of that name at any depth, or the field at that
+// path such as "cover.image", and everything nested under them; keys match
+// case-insensitively. Only values that look like URLs or file paths are
+// links, so e.g. the alt text of a cover is left out.
+func frontMatterLinks(fm FrontMatter, keys []string) []string {
+	found := make(map[string]string)
+	collectFrontMatterLinks(map[string]any(fm), "", "", keys, false, found)
+
+	paths := make([]string, 0, len(found))
+	for path := range found {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	links := make([]string, 0, len(paths))
+	for _, path := range paths {
+		links = append(links, found[path])
+	}
+	return links
+}
+
+// collectFrontMatterLinks walks value, the field named name at path, adding
+// its links to found, keyed by path, once it or one of its parents is
+// selected by keys
+func collectFrontMatterLinks(value any, path, name string, keys []string, selected bool, found map[string]string) {
+	if !selected && path != "" {
+		for _, key := range keys {
+			if strings.EqualFold(key, path) || strings.EqualFold(key, name) {
+				selected = true
+				break
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for name, child := range v {
+			childPath := name
+			if path != "" {
+				childPath = path + "." + name
+			}
+			collectFrontMatterLinks(child, childPath, name, keys, selected, found)
+		}
+	case []any:
+		for i, child := range v {
+			collectFrontMatterLinks(child, fmt.Sprintf("%s[%d]", path, i), "", keys, selected, found)
+		}
+	case string:
+		if link := strings.TrimSpace(v); selected && looksLikeLink(link) {
+			found[path] = link
+		}
+	}
+}
+
+// looksLikeLink reports whether a front matter value is a URL or a file
+// path rather than text: it has no spaces, and has a scheme, starts with a
+// slash or dot, or has a file extension
+func looksLikeLink(s string) bool {
+	if s == "" || strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	return strings.Contains(s, "://") || strings.HasPrefix(s, "/") || strings.HasPrefix(s, ".") || path.Ext(s) != ""
+}
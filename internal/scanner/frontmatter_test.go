@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	testCases := map[string]string{
+		"yaml.md": "---\ntitle: Hello\nslug: hi\naliases:\n  - /old/\n  - /older/\n---\nBody with --- in it\n",
+		"toml.md": "+++\ntitle = 'Hello'\nslug = 'hi'\naliases = ['/old/', '/older/']\n+++\nBody\n",
+		"json.md": "{\n  \"title\": \"Hello\",\n  \"slug\": \"hi\",\n  \"aliases\": [\"/old/\", \"/older/\"]\n}\nBody\n",
+	}
+
+	dir := t.TempDir()
+	for name, content := range testCases {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		fm, err := ParseFrontMatter(path)
+		if err != nil {
+			t.Fatalf("%s: ParseFrontMatter failed: %v", name, err)
+		}
+		if fm.String("Title") != "Hello" || fm.String("slug") != "hi" {
+			t.Errorf("%s: unexpected front matter %v", name, fm)
+		}
+		if aliases := fm.Strings("aliases"); !reflect.DeepEqual(aliases, []string{"/old/", "/older/"}) {
+			t.Errorf("%s: unexpected aliases %v", name, aliases)
+		}
+	}
+}
+
+func TestParseFrontMatter_Missing(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.md")
+	if err := os.WriteFile(plain, []byte("# Title\n\nNo front matter\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fm, err := ParseFrontMatter(plain)
+	if err != nil || fm != nil {
+		t.Errorf("Expected no front matter, got %v, %v", fm, err)
+	}
+
+	unclosed := filepath.Join(dir, "unclosed.md")
+	if err := os.WriteFile(unclosed, []byte("---\ntitle: Hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFrontMatter(unclosed); err == nil {
+		t.Error("Expected an error for unclosed front matter")
+	}
+}
+
+func TestParseLinksFromFile_FrontMatter(t *testing.T) {
+	testCases := map[string]string{
+		"yaml.md": `---
+title: A trip
+image: /images/trip.png
+cover:
+  image: cover.jpg
+  alt: Sunset
+images:
+  - https://cdn.example.com/og.png
+canonicalURL: https://example.com/trip/
+description: notes.txt is not a field we check
+---
+See [the map](/map/).
+`,
+		"toml.md": `+++
+title = "A trip"
+image = "/images/trip.png"
+canonicalURL = "https://example.com/trip/"
+images = ["https://cdn.example.com/og.png"]
+[cover]
+image = "cover.jpg"
+alt = "Sunset"
++++
+See [the map](/map/).
+`,
+	}
+
+	dir := t.TempDir()
+	for name, content := range testCases {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		file := &File{Path: path}
+		if err := ParseLinksFromFile(file, false); err != nil {
+			t.Fatalf("%s: ParseLinksFromFile failed: %v", name, err)
+		}
+		kinds := make(map[string]LinkKind)
+		for _, link := range file.Links {
+			kinds[link.URL] = link.Kind
+		}
+		expected := map[string]LinkKind{
+			"/images/trip.png":               KindFrontMatter,
+			"cover.jpg":                      KindFrontMatter,
+			"https://cdn.example.com/og.png": KindFrontMatter,
+			"https://example.com/trip/":      KindFrontMatter,
+			"/map/":                          "",
+		}
+		if !reflect.DeepEqual(kinds, expected) {
+			t.Errorf("%s: expected links %v, got %v", name, expected, kinds)
+		}
+		if file.Links[0].URL != "/images/trip.png" || file.Links[0].Line != 3 {
+			t.Errorf("%s: expected /images/trip.png first, on line 3, got %q on line %d", name, file.Links[0].URL, file.Links[0].Line)
+		}
+
+		file = &File{Path: path}
+		if err := ParseLinksFromFileWithOptions(file, ParseOptions{}); err != nil {
+			t.Fatalf("%s: ParseLinksFromFileWithOptions failed: %v", name, err)
+		}
+		if len(file.Links) != 1 || file.Links[0].URL != "/map/" {
+			t.Errorf("%s: expected only /map/ without front matter keys, got %+v", name, file.Links)
+		}
+	}
+}
+
+func TestFrontMatter_Build(t *testing.T) {
+	testCases := map[string]BuildOptions{
+		"---\ntitle: Plain\n---\n":                                         {Render: "always", List: "always"},
+		"---\nbuild:\n  render: never\n  list: local\n---\n":               {Render: "never", List: "local"},
+		"+++\n[_build]\nrender = false\nlist = true\n+++\n":                {Render: "never", List: "always"},
+		"{\n  \"build\": {\"Render\": \"Link\", \"list\": \"never\"}\n}\n": {Render: "link", List: "never"},
+	}
+
+	dir := t.TempDir()
+	for content, want := range testCases {
+		path := filepath.Join(dir, "page.md")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fm, err := ParseFrontMatter(path)
+		if err != nil {
+			t.Fatalf("ParseFrontMatter failed: %v", err)
+		}
+		if got := fm.Build(); got != want {
+			t.Errorf("%q: expected %+v, got %+v", content, want, got)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package scanner
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlLinkAttrs lists, per element, the attributes whose values are link
+// destinations. Extracting another element's links takes only an entry here.
+// A <source> inside a <video> or <audio> is keyed "media source".
+var htmlLinkAttrs = map[string][]string{
+	"a":            {"href"},
+	"link":         {"href"},
+	"video":        {"src", "poster"},
+	"audio":        {"src"},
+	"track":        {"src"},
+	"media source": {"src"},
+	"iframe":       {"src"},
+	"embed":        {"src"},
+	"object":       {"data"},
+}
+
+// htmlImageAttrs lists, per element, the attributes whose values are image
+// sources. A <source> inside a <picture> is keyed "picture source".
+var htmlImageAttrs = map[string][]string{
+	"img":            {"src"},
+	"picture source": {"src"},
+}
+
+// htmlSrcsetAttrs lists, per element, the attributes holding a srcset: a
+// comma-separated list of image candidates, each checked on its own
+var htmlSrcsetAttrs = map[string][]string{
+	"img":            {"srcset"},
+	"picture source": {"srcset"},
+}
+
+// DefaultLazyLoadAttrs are the data attributes lazy-loading scripts and
+// themes keep image sources in until the image scrolls into view
+var DefaultLazyLoadAttrs = []string{"data-src", "data-srcset", "data-background"}
+
+// parseHTMLLinks extracts the links of HTML, in HTML files or embedded in
+// Markdown, with the HTML5 tokenizer: tags may span lines, attributes may be
+// in any order and quoted with " or ' or unquoted, and character references
+// in values are decoded. The text of an <a> element is its inner HTML and
+// its title is its title attribute. The url() references of <style> elements
+// and style attributes are links too, and so are the lazyAttrs of any
+// element, as image sources; those ending in srcset hold srcset lists.
+func parseHTMLLinks(content string, lazyAttrs []string) []foundLink {
+	var links []foundLink
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	offset := 0
+	// anchor is the index in links of the <a> element whose text is being
+	// read, or -1
+	anchor, textStart := -1, 0
+	// open counts the open <picture>, <video>, and <audio> elements, which
+	// give their <source> children different attributes
+	open := make(map[string]int)
+	// inStyle is set while reading the text of a <style> element
+	inStyle := false
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return links
+		}
+		start := offset
+		offset += len(tokenizer.Raw())
+
+		if tokenType == html.TextToken && inStyle {
+			for _, link := range parseCSSURLs(content[start:offset]) {
+				link.offset += start
+				links = append(links, link)
+			}
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken && tokenType != html.EndTagToken {
+			continue
+		}
+		name, hasAttr := tokenizer.TagName()
+		tag := string(name)
+		inStyle = tag == "style" && tokenType == html.StartTagToken
+		if tag == "a" && anchor != -1 {
+			// The element ends here, or at an <a> that implicitly closes it
+			links[anchor].text = strings.Join(strings.Fields(content[textStart:start]), " ")
+			anchor = -1
+		}
+		switch tag {
+		case "picture", "video", "audio":
+			if tokenType == html.StartTagToken {
+				open[tag]++
+			} else if tokenType == html.EndTagToken && open[tag] > 0 {
+				open[tag]--
+			}
+		}
+		if tokenType == html.EndTagToken || !hasAttr {
+			continue
+		}
+
+		element := tag
+		if tag == "source" {
+			if open["picture"] > 0 {
+				element = "picture source"
+			} else if open["video"] > 0 || open["audio"] > 0 {
+				element = "media source"
+			}
+		}
+		if tag == "meta" {
+			if metaURL := parseMetaURL(tokenizer); metaURL != "" {
+				links = append(links, foundLink{url: metaURL, offset: start, kind: KindMetadata})
+			}
+			continue
+		}
+		linkAttrs, imageAttrs, srcsetAttrs := htmlLinkAttrs[element], htmlImageAttrs[element], htmlSrcsetAttrs[element]
+		first := len(links)
+		var attrs map[string]string
+		var rel, title string
+		var styleLinks []foundLink
+		for more := true; more; {
+			var key, value []byte
+			key, value, more = tokenizer.TagAttr()
+			switch string(key) {
+			case "rel":
+				rel = string(value)
+			case "title":
+				title = strings.Join(strings.Fields(string(value)), " ")
+			}
+			for _, attr := range linkAttrs {
+				if string(key) == attr {
+					links = append(links, foundLink{url: string(value), offset: start})
+				}
+			}
+			for _, attr := range imageAttrs {
+				if string(key) == attr {
+					links = append(links, foundLink{url: string(value), offset: start, kind: KindImage})
+				}
+			}
+			for _, attr := range srcsetAttrs {
+				if string(key) == attr {
+					for _, candidate := range parseSrcset(string(value)) {
+						links = append(links, foundLink{url: candidate, offset: start, kind: KindImage})
+					}
+				}
+			}
+			if string(key) == "style" {
+				styleLinks = parseCSSURLs(string(value))
+			}
+			if slices.Contains(lazyAttrs, string(key)) {
+				if strings.HasSuffix(string(key), "srcset") {
+					for _, candidate := range parseSrcset(string(value)) {
+						links = append(links, foundLink{url: candidate, offset: start, kind: KindImage})
+					}
+				} else {
+					links = append(links, foundLink{url: string(value), offset: start, kind: KindImage})
+				}
+			}
+			if strings.HasPrefix(string(key), "data-lc-") {
+				if attrs == nil {
+					attrs = make(map[string]string)
+				}
+				attrs[string(key)] = string(value)
+			}
+		}
+		for i := first; i < len(links); i++ {
+			links[i].attrs = attrs
+			if tag == "a" {
+				links[i].title = title
+			}
+			if tag == "link" && slices.Contains(strings.Fields(strings.ToLower(rel)), "canonical") {
+				links[i].kind = KindMetadata
+			}
+		}
+		if tag == "a" && tokenType == html.StartTagToken && len(links) > first {
+			anchor, textStart = len(links)-1, offset
+		}
+		for _, link := range styleLinks {
+			link.offset = start
+			links = append(links, link)
+		}
+	}
+}
+
+// metaURLProperties are the property or name values of <meta> tags whose
+// content is a URL
+var metaURLProperties = []string{"og:image", "og:image:url", "og:image:secure_url", "og:url", "twitter:image", "twitter:image:src"}
+
+// parseMetaURL returns the URL of a <meta> tag read by tokenizer: the
+// content of an Open Graph or Twitter card URL property, or the target of a
+// refresh. Other meta tags have none.
+func parseMetaURL(tokenizer *html.Tokenizer) string {
+	var property, httpEquiv, content string
+	for more := true; more; {
+		var key, value []byte
+		key, value, more = tokenizer.TagAttr()
+		switch string(key) {
+		case "property", "name":
+			if property == "" {
+				property = strings.ToLower(strings.TrimSpace(string(value)))
+			}
+		case "http-equiv":
+			httpEquiv = strings.ToLower(strings.TrimSpace(string(value)))
+		case "content":
+			content = string(value)
+		}
+	}
+
+	if httpEquiv == "refresh" {
+		return refreshURL(content)
+	}
+	if slices.Contains(metaURLProperties, property) {
+		return strings.TrimSpace(content)
+	}
+	return ""
+}
+
+// refreshURL returns the target of a meta refresh content value such as
+// "0; url=/new/", or "" for a refresh of the page itself
+func refreshURL(content string) string {
+	_, target, found := strings.Cut(content, ";")
+	if !found {
+		_, target, found = strings.Cut(content, ",")
+	}
+	if !found {
+		return ""
+	}
+	target = strings.TrimSpace(target)
+	if len(target) >= 3 && strings.EqualFold(target[:3], "url") {
+		if rest := strings.TrimSpace(target[3:]); strings.HasPrefix(rest, "=") {
+			target = strings.TrimSpace(rest[1:])
+		}
+	}
+	if len(target) >= 2 && (target[0] == '\'' || target[0] == '"') {
+		if end := strings.IndexByte(target[1:], target[0]); end != -1 {
+			target = target[1 : end+1]
+		}
+	}
+	return strings.TrimSpace(target)
+}
+
+// parseSrcset returns the image URLs of a srcset attribute, following the
+// HTML parsing rules: a URL runs to the next whitespace, and a comma ends a
+// candidate only after its descriptors ("480w", "2x") or right after the URL,
+// so URLs may themselves contain commas
+func parseSrcset(srcset string) []string {
+	var urls []string
+	isSpace := func(c byte) bool {
+		return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+	}
+	for i := 0; i < len(srcset); {
+		for i < len(srcset) && (isSpace(srcset[i]) || srcset[i] == ',') {
+			i++
+		}
+		start := i
+		for i < len(srcset) && !isSpace(srcset[i]) {
+			i++
+		}
+		candidate := srcset[start:i]
+		if trimmed := strings.TrimRight(candidate, ","); trimmed != candidate {
+			// A URL ending in commas has no descriptors
+			if trimmed != "" {
+				urls = append(urls, trimmed)
+			}
+			continue
+		}
+		if candidate != "" {
+			urls = append(urls, candidate)
+		}
+
+		// Skip the descriptors, up to a comma outside parentheses
+		depth := 0
+		for ; i < len(srcset); i++ {
+			if srcset[i] == '(' {
+				depth++
+			} else if srcset[i] == ')' && depth > 0 {
+				depth--
+			} else if srcset[i] == ',' && depth == 0 {
+				break
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,303 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLinksFromFile_HTMLTokenizer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	content := `<link
+  rel="stylesheet"
+  href="/css/site.css">
+<a class=nav href='/single/'>Single</a> <a href=/unquoted/>Unquoted</a>
+<a title="First" data-x="y"
+   href="/multi/?a=1&amp;b=2">Multi
+   line</a>
+<script>var s = '<a href="/in-script/">';</script>
+<img alt="Logo" src="/img/logo.png">
+<a href="/unclosed/">Unclosed <a href="/next/">Next</a>
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, true); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+
+	expected := []struct {
+		url  string
+		text string
+		line int
+	}{
+		{"/css/site.css", "", 1},
+		{"/single/", "Single", 4},
+		{"/unquoted/", "Unquoted", 4},
+		{"/multi/?a=1&b=2", "Multi line", 5},
+		{"/img/logo.png", "", 9},
+		{"/unclosed/", "Unclosed", 10},
+		{"/next/", "Next", 10},
+	}
+	if len(file.Links) != len(expected) {
+		for _, link := range file.Links {
+			t.Logf("found %q (%q, line %d)", link.URL, link.Text, link.Line)
+		}
+		t.Fatalf("Expected %d links, got %d", len(expected), len(file.Links))
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Text != want.text || link.Line != want.line {
+			t.Errorf("link %d: expected %q (%q, line %d), got %q (%q, line %d)", i, want.url, want.text, want.line, link.URL, link.Text, link.Line)
+		}
+	}
+}
+
+func TestParseSrcset(t *testing.T) {
+	testCases := []struct {
+		srcset   string
+		expected []string
+	}{
+		{"/img/a.png", []string{"/img/a.png"}},
+		{"/img/a-480.webp 480w, /img/a-800.webp 800w", []string{"/img/a-480.webp", "/img/a-800.webp"}},
+		{" a.jpg 1x ,\n  a@2x.jpg 2x ", []string{"a.jpg", "a@2x.jpg"}},
+		{"https://res.example.com/w_100,h_100/a.jpg 100w, https://res.example.com/w_200,h_200/a.jpg 200w", []string{"https://res.example.com/w_100,h_100/a.jpg", "https://res.example.com/w_200,h_200/a.jpg"}},
+		{"a.jpg, b.jpg 2x", []string{"a.jpg", "b.jpg"}},
+		{"a.jpg,b.jpg 2x", []string{"a.jpg,b.jpg"}},
+		{"", nil},
+	}
+	for _, tc := range testCases {
+		got := parseSrcset(tc.srcset)
+		if len(got) != len(tc.expected) {
+			t.Errorf("%q: expected %q, got %q", tc.srcset, tc.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.expected[i] {
+				t.Errorf("%q: expected %q, got %q", tc.srcset, tc.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestParseLinksFromFile_Srcset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "<img src=\"/img/a-480.webp\"\n  srcset=\"/img/a-480.webp 480w, /img/a-800.webp 800w\"\n  sizes=\"(max-width: 600px) 480px, 800px\">\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, true); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	if len(file.Links) != 2 || file.Links[0].URL != "/img/a-480.webp" || file.Links[1].URL != "/img/a-800.webp" {
+		t.Fatalf("expected both candidates once, got %+v", file.Links)
+	}
+	for _, link := range file.Links {
+		if link.Kind != KindImage || link.Line != 1 {
+			t.Errorf("%s: expected an image on line 1, got kind %q on line %d", link.URL, link.Kind, link.Line)
+		}
+	}
+}
+
+func TestParseLinksFromFile_LazyLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	content := "<img src=\"/img/blank.gif\" data-src=\"/img/a.jpg\" data-srcset=\"/img/a-1x.jpg 1x, /img/a-2x.jpg 2x\">\n" +
+		"<div class=\"hero\" data-background=\"/img/hero.jpg\" data-bg=\"/img/bg.jpg\"></div>\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, true); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []string{"/img/blank.gif", "/img/a.jpg", "/img/a-1x.jpg", "/img/a-2x.jpg", "/img/hero.jpg"}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("expected %v, got %+v", expected, file.Links)
+	}
+	for i, link := range file.Links {
+		if link.URL != expected[i] || link.Kind != KindImage {
+			t.Errorf("link %d: expected image %s, got %s (kind %q)", i, expected[i], link.URL, link.Kind)
+		}
+	}
+
+	// Other attributes can be configured, and none at all
+	file = &File{Path: path}
+	if err := ParseLinksFromFileWithOptions(file, ParseOptions{CheckImages: true, LazyLoadAttrs: []string{"data-bg"}}); err != nil {
+		t.Fatalf("ParseLinksFromFileWithOptions failed: %v", err)
+	}
+	if len(file.Links) != 2 || file.Links[1].URL != "/img/bg.jpg" {
+		t.Errorf("expected only src and data-bg, got %+v", file.Links)
+	}
+}
+
+func TestParseLinksFromFile_Picture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := `Intro text.
+
+<picture>
+  <source type="image/avif" srcset="/img/hero.avif 1x, /img/hero@2x.avif 2x">
+  <source type="image/webp" src="/img/hero.webp">
+  <img src="/img/hero.jpg" alt="Hero">
+</picture>
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, true); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []struct {
+		url  string
+		line int
+	}{
+		{"/img/hero.avif", 4},
+		{"/img/hero@2x.avif", 4},
+		{"/img/hero.webp", 5},
+		{"/img/hero.jpg", 6},
+	}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), file.Links)
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Line != want.line || link.Kind != KindImage {
+			t.Errorf("link %d: expected image %q on line %d, got %q (kind %q) on line %d", i, want.url, want.line, link.URL, link.Kind, link.Line)
+		}
+	}
+}
+
+func TestParseLinksFromFile_Media(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	content := `<video controls poster="/img/poster.jpg">
+  <source src="/media/clip.webm" type="video/webm">
+  <source src="https://cdn.example.com/clip.mp4" type="video/mp4">
+  <track kind="captions" src="/media/clip.en.vtt" srclang="en">
+</video>
+<audio src="/media/episode.mp3"></audio>
+<video src="/media/short.mp4"></video>
+<source src="/stray.mp4">
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Media are links, checked without -check-images
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []struct {
+		url  string
+		line int
+	}{
+		{"/img/poster.jpg", 1},
+		{"/media/clip.webm", 2},
+		{"https://cdn.example.com/clip.mp4", 3},
+		{"/media/clip.en.vtt", 4},
+		{"/media/episode.mp3", 6},
+		{"/media/short.mp4", 7},
+	}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), file.Links)
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Line != want.line {
+			t.Errorf("link %d: expected %q on line %d, got %q on line %d", i, want.url, want.line, link.URL, link.Line)
+		}
+	}
+}
+
+func TestParseLinksFromFile_Embeds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "A widget:\n\n" +
+		"<iframe src=\"https://www.youtube.com/embed/abc123\" allowfullscreen></iframe>\n" +
+		"<embed type=\"application/pdf\" src=\"/files/guide.pdf\">\n" +
+		"<object data=\"/files/diagram.svg\" type=\"image/svg+xml\"></object>\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []string{"https://www.youtube.com/embed/abc123", "/files/guide.pdf", "/files/diagram.svg"}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), file.Links)
+	}
+	for i, url := range expected {
+		if file.Links[i].URL != url || file.Links[i].Line != i+3 {
+			t.Errorf("link %d: expected %q on line %d, got %q on line %d", i, url, i+3, file.Links[i].URL, file.Links[i].Line)
+		}
+	}
+}
+
+func TestParseLinksFromFile_HeadMetadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.html")
+	content := `<head>
+<link rel="canonical" href="https://example.com/post/">
+<link rel="stylesheet" href="/css/site.css">
+<meta http-equiv="refresh" content="0; URL='/new-post/'">
+<meta property="og:image" content="https://example.com/img/card.png">
+<meta property="og:url" content=" https://example.com/post/?ref=og ">
+<meta name="twitter:image" content="/img/twitter.png">
+<meta name="description" content="https://not-a-link.example.com/">
+<meta http-equiv="refresh" content="30">
+</head>
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []struct {
+		url  string
+		kind LinkKind
+		line int
+	}{
+		{"https://example.com/post/", KindMetadata, 2},
+		{"/css/site.css", "", 3},
+		{"/new-post/", KindMetadata, 4},
+		{"https://example.com/img/card.png", KindMetadata, 5},
+		{"https://example.com/post/?ref=og", KindMetadata, 6},
+		{"/img/twitter.png", KindMetadata, 7},
+	}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), file.Links)
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Kind != want.kind || link.Line != want.line {
+			t.Errorf("link %d: expected %q (kind %q, line %d), got %q (kind %q, line %d)", i, want.url, want.kind, want.line, link.URL, link.Kind, link.Line)
+		}
+	}
+}
+
+func TestRefreshURL(t *testing.T) {
+	testCases := map[string]string{
+		"0; url=/new/":           "/new/",
+		"5;URL='https://x.com/'": "https://x.com/",
+		"0, url=\"/quoted/\"":    "/quoted/",
+		"3; /bare/":              "/bare/",
+		"10":                     "",
+		"0;url = /spaced/ ":      "/spaced/",
+		"0; urlish/path":         "urlish/path",
+	}
+	for content, expected := range testCases {
+		if got := refreshURL(content); got != expected {
+			t.Errorf("%q: expected %q, got %q", content, expected, got)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	// linkTagPattern matches a <link> tag; iconAttrPattern its attributes,
+	// including values that are Go templates
+	linkTagPattern  = regexp.MustCompile(`(?is)<link\s(?:[^>{]|\{\{.*?\}\})*>`)
+	iconAttrPattern = regexp.MustCompile(`(?is)([\w-]+)\s*=\s*(?:"((?:[^"{]|\{\{.*?\}\})*)"|'((?:[^'{]|\{\{.*?\}\})*)')`)
+	// templateLiteralPattern finds the string literal in a template such
+	// as {{ "favicon.ico" | relURL }}
+	templateLiteralPattern = regexp.MustCompile("\\{\\{.*?(?:\"([^\"]+)\"|`([^`]+)`).*?\\}\\}")
+)
+
+// iconRels are the rel values of links to favicons, touch icons, and web
+// manifests
+var iconRels = []string{"icon", "shortcut", "apple-touch-icon", "apple-touch-icon-precomposed", "mask-icon", "manifest"}
+
+// IsIconRel reports whether a rel attribute names a favicon, touch icon, or
+// web manifest
+func IsIconRel(rel string) bool {
+	for _, value := range strings.Fields(strings.ToLower(rel)) {
+		for _, iconRel := range iconRels {
+			if value == iconRel {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SitePath returns the link for a path given to Hugo's relURL or absURL:
+// paths without a leading slash are relative to the site root
+func SitePath(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" || strings.HasPrefix(path, "/") || !isInternalLink(path) {
+		return path
+	}
+	return "/" + path
+}
+
+// ParseHeadIcons adds the favicon, touch icon, and web manifest links of a
+// template, such as a head partial, to the file's links. Hrefs written as
+// templates are resolved when they hold a string literal, as in
+// {{ "favicon.ico" | relURL }}; hrefs computed from params are skipped.
+func ParseHeadIcons(file *File) error {
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", file.Path, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, link := range file.Links {
+		seen[link.URL] = true
+	}
+
+	for _, tag := range linkTagPattern.FindAllString(string(data), -1) {
+		var rel, href string
+		for _, attr := range iconAttrPattern.FindAllStringSubmatch(tag, -1) {
+			switch strings.ToLower(attr[1]) {
+			case "rel":
+				rel = attr[2] + attr[3]
+			case "href":
+				href = strings.TrimSpace(attr[2] + attr[3])
+			}
+		}
+		if !IsIconRel(rel) {
+			continue
+		}
+
+		if strings.Contains(href, "{{") {
+			match := templateLiteralPattern.FindStringSubmatch(href)
+			if match == nil {
+				continue
+			}
+			href = SitePath(match[1] + match[2])
+		}
+		if href == "" || seen[href] {
+			continue
+		}
+		seen[href] = true
+		file.Links = append(file.Links, NewLink(href))
+	}
+	return nil
+}
+
+// ParseWebManifest adds the icons listed in a web manifest to the file's
+// links. Relative icon paths are resolved against manifestURL, the site path
+// the manifest is served from.
+func ParseWebManifest(file *File, manifestURL string) error {
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", file.Path, err)
+	}
+
+	var manifest struct {
+		Icons []struct {
+			Src string `json:"src"`
+		} `json:"icons"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse web manifest %s: %w", file.Path, err)
+	}
+
+	base, err := url.Parse(manifestURL)
+	if err != nil {
+		return fmt.Errorf("invalid manifest URL %s: %w", manifestURL, err)
+	}
+	seen := make(map[string]bool)
+	for _, icon := range manifest.Icons {
+		src := strings.TrimSpace(icon.Src)
+		if src == "" {
+			continue
+		}
+		if ref, err := url.Parse(src); err == nil && isInternalLink(src) {
+			src = base.ResolveReference(ref).String()
+		}
+		if seen[src] {
+			continue
+		}
+		seen[src] = true
+		file.Links = append(file.Links, NewLink(src))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseHeadIcons(t *testing.T) {
+	partial := `<head>
+  <link rel="icon" href="/favicon.ico">
+  <link rel="shortcut icon" href="{{ "images/favicon.png" | relURL }}">
+  <link rel="apple-touch-icon" sizes="180x180" href='{{ absURL "apple-touch-icon.png" }}'>
+  <link rel="manifest" href="{{ "site.webmanifest" | relURL }}">
+  <link rel="icon" href="{{ .Site.Params.favicon | relURL }}">
+  <link rel="stylesheet" href="/css/main.css">
+</head>`
+	path := filepath.Join(t.TempDir(), "head.html")
+	if err := os.WriteFile(path, []byte(partial), 0644); err != nil {
+		t.Fatalf("Failed to write partial: %v", err)
+	}
+
+	file := &File{Path: path, Links: []Link{NewLink("/favicon.ico")}}
+	if err := ParseHeadIcons(file); err != nil {
+		t.Fatalf("ParseHeadIcons failed: %v", err)
+	}
+
+	expected := []string{"/favicon.ico", "/images/favicon.png", "/apple-touch-icon.png", "/site.webmanifest"}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected links %v, got %v", expected, file.Links)
+	}
+	for i, url := range expected {
+		if file.Links[i].URL != url {
+			t.Errorf("Link %d: expected %s, got %s", i, url, file.Links[i].URL)
+		}
+	}
+}
+
+func TestParseWebManifest(t *testing.T) {
+	manifest := `{"name": "Docs", "icons": [
+		{"src": "android-chrome-192.png", "sizes": "192x192"},
+		{"src": "/icons/android-chrome-512.png", "sizes": "512x512"},
+		{"src": "https://cdn.example.com/icon.png"}
+	]}`
+	path := filepath.Join(t.TempDir(), "site.webmanifest")
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseWebManifest(file, "/assets/site.webmanifest"); err != nil {
+		t.Fatalf("ParseWebManifest failed: %v", err)
+	}
+
+	expected := []string{"/assets/android-chrome-192.png", "/icons/android-chrome-512.png", "https://cdn.example.com/icon.png"}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected links %v, got %v", expected, file.Links)
+	}
+	for i, url := range expected {
+		if file.Links[i].URL != url {
+			t.Errorf("Link %d: expected %s, got %s", i, url, file.Links[i].URL)
+		}
+	}
+	if file.Links[2].Type != LinkTypeExternal {
+		t.Errorf("Expected the CDN icon to be external")
+	}
+}
+
+func TestSitePath(t *testing.T) {
+	tests := map[string]string{
+		"favicon.ico":               "/favicon.ico",
+		"/favicon.ico":              "/favicon.ico",
+		"https://example.com/x.ico": "https://example.com/x.ico",
+		"":                          "",
+	}
+	for path, want := range tests {
+		if got := SitePath(path); got != want {
+			t.Errorf("SitePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
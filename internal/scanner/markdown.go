@@ -0,0 +1,420 @@
+package scanner
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// foundLink is a link destination found in a file, before it becomes a Link
+type foundLink struct {
+	url  string
+	text string
+	// title is the link's title: the quoted title of a Markdown link or the
+	// title attribute of an HTML anchor
+	title string
+	// offset is the byte offset in the file the link starts at
+	offset int
+	// attrs are the attributes given to the link, such as data-lc-expected
+	attrs map[string]string
+	// kind is the kind of link, e.g. KindImage for image sources
+	kind LinkKind
+	// unreferenced marks link reference definitions and footnotes nothing
+	// refers to
+	unreferenced bool
+}
+
+// isMarkdownFile reports whether a file is Markdown rather than HTML
+func isMarkdownFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseMarkdown extracts the inline links, images, autolinks, and link
+// reference definitions of a Markdown document, parsing it with goldmark as
+// Hugo does: brackets nest in link text, parentheses balance in
+// destinations, <...> destinations may hold spaces, and backslash escapes
+// are honored. Code blocks and code spans hold examples, not links; the
+// document is also returned with them blanked out, keeping line breaks so
+// offsets and line numbers still match, for the parsers of HTML and
+// shortcodes.
+//
+// References to undefined labels ([text][label], [label][], and footnotes
+// such as [^1]) are returned as KindReference links, and footnotes defined
+// but never referenced too; definitions nothing refers to are marked.
+func parseMarkdown(content string) ([]foundLink, string) {
+	source := []byte(maskShortcodeSpaces(content))
+	spans := make(map[ast.Node]linkSpan)
+	pc := parser.NewContext()
+	pc.Set(linkSpansKey, spans)
+	doc := markdownParser.Parse(text.NewReader(source), parser.WithContext(pc))
+
+	masked := []byte(content)
+	blank := func(start, end int) {
+		for i := start; i < end; i++ {
+			if masked[i] != '\n' {
+				masked[i] = ' '
+			}
+		}
+	}
+
+	var links []foundLink
+	// jumps maps where a link goldmark parsed starts to where it ends, so
+	// the search for references below doesn't read it again
+	jumps := make(map[int]int)
+	refs := newReferences()
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				blank(lines.At(i).Start, lines.At(i).Stop)
+			}
+		case *ast.FencedCodeBlock:
+			blank(n.Pos(), fencedCodeEnd(source, n))
+		case *ast.CodeSpan:
+			blank(n.Pos(), codeSpanEnd(source, n))
+			return ast.WalkSkipChildren, nil
+		case *ast.LinkReferenceDefinition:
+			// A footnote definition such as [^1]: https://example.com reads
+			// as a link reference definition without the footnote extension
+			if bytes.HasPrefix(n.Label, []byte("^")) {
+				break
+			}
+			lines := n.Lines()
+			jumps[n.Pos()] = lines.At(lines.Len() - 1).Stop
+			refs.define(string(n.Label), len(links))
+			links = append(links, foundLink{url: markdownText(n.Destination), title: markdownTitle(n.Title), offset: n.Pos()})
+		case *ast.AutoLink:
+			if n.AutoLinkType == ast.AutoLinkURL {
+				linkURL := string(n.URL(source))
+				links = append(links, foundLink{url: linkURL, text: linkURL, offset: n.Pos()})
+			}
+		case *ast.Link:
+			if n.Reference != nil && bytes.HasPrefix(n.Reference.Value, []byte("^")) {
+				// A footnote reference, left to the search below
+				break
+			}
+			span := spans[n]
+			jumps[n.Pos()] = span.end
+			if n.Reference != nil {
+				// The definition is the link; this only uses it
+				refs.useDefinition(string(n.Reference.Value))
+				break
+			}
+			link := foundLink{url: markdownText(n.Destination), title: markdownTitle(n.Title), offset: n.Pos()}
+			link.text = strings.Join(strings.Fields(content[n.Pos()+1:span.closing]), " ")
+			links = append(links, withAttributeBlock(link, content, span.end))
+		case *ast.Image:
+			span := spans[n]
+			jumps[n.Pos()] = span.end
+			if n.Reference != nil {
+				refs.useDefinition(string(n.Reference.Value))
+				break
+			}
+			// An image's alt text isn't link text
+			link := foundLink{url: markdownText(n.Destination), title: markdownTitle(n.Title), offset: n.Pos(), kind: KindImage}
+			links = append(links, withAttributeBlock(link, content, span.end))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	// goldmark leaves references to undefined labels and footnotes as text;
+	// look for them in the rest of the document, with code blanked out but
+	// reference text taken from the original, since it may itself contain
+	// code
+	code := string(masked)
+	for i := 0; i < len(code); i++ {
+		if end, ok := jumps[i]; ok {
+			i = end - 1
+			continue
+		}
+		if i == 0 || code[i-1] == '\n' {
+			// A footnote's text follows its label and may hold links
+			if label, end, ok := parseFootnoteDefinition(code, i); ok {
+				refs.defineFootnote(label, i)
+				i = end - 1
+				continue
+			}
+		}
+
+		switch code[i] {
+		case '\\':
+			i++
+		case '[':
+			closing, ok := matchBracket(code, i)
+			if !ok {
+				continue
+			}
+			if code[i+1] == '^' {
+				refs.useFootnote(code[i+2:closing], i)
+				i = closing
+				continue
+			}
+			// In [text][label](/url) the link is [label](/url); [text] is
+			// just text
+			if _, ok := jumps[closing+1]; ok {
+				continue
+			}
+			jumps[closing] = refs.use(code, content, i, closing)
+		}
+	}
+	return refs.resolve(links), code
+}
+
+// markdownParser parses Markdown with the CommonMark syntax Hugo's goldmark
+// renderer starts from. Its link parser records the span of each link.
+var markdownParser = parser.NewParser(
+	parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+	parser.WithInlineParsers(
+		util.Prioritized(parser.NewCodeSpanParser(), 100),
+		util.Prioritized(linkSpanParser{parser.NewLinkParser()}, 200),
+		util.Prioritized(parser.NewAutoLinkParser(), 300),
+		util.Prioritized(parser.NewRawHTMLParser(), 400),
+		util.Prioritized(parser.NewEmphasisParser(), 500),
+	),
+	parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+)
+
+// linkSpansKey holds the map of links to their spans for the document
+// being parsed
+var linkSpansKey = parser.NewContextKey()
+
+// linkSpan is where a link's text closes and where the link ends; the AST
+// only records where a link starts
+type linkSpan struct {
+	closing int
+	end     int
+}
+
+// linkSpanParser wraps goldmark's link parser, recording the span of each
+// link and image it parses
+type linkSpanParser struct {
+	parser.InlineParser
+}
+
+func (p linkSpanParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	_, closing := block.Position()
+	node := p.InlineParser.Parse(parent, block, pc)
+	switch node.(type) {
+	case *ast.Link, *ast.Image:
+		_, end := block.Position()
+		if spans, ok := pc.Get(linkSpansKey).(map[ast.Node]linkSpan); ok {
+			spans[node] = linkSpan{closing: closing.Start, end: end.Start}
+		}
+	}
+	return node
+}
+
+// CloseBlock lets the link parser drop the brackets left open in a block
+func (p linkSpanParser) CloseBlock(parent ast.Node, block text.Reader, pc parser.Context) {
+	if closer, ok := p.InlineParser.(parser.CloseBlocker); ok {
+		closer.CloseBlock(parent, block, pc)
+	}
+}
+
+// shortcodeSpace stands in for the spaces and tabs of shortcodes while
+// goldmark parses a document. Hugo expands shortcodes before rendering
+// Markdown, so [text]({{< ref "page" >}}) is a link even though its
+// destination has spaces.
+const shortcodeSpace = "\x1a"
+
+// maskShortcodeSpaces replaces the spaces and tabs inside {{ and }} with
+// shortcodeSpace, keeping offsets unchanged
+func maskShortcodeSpaces(content string) string {
+	masked := []byte(content)
+	for i := 0; i < len(content); {
+		start := strings.Index(content[i:], "{{")
+		if start == -1 {
+			break
+		}
+		start += i
+		end := strings.Index(content[start:], "}}")
+		if end == -1 {
+			break
+		}
+		end += start
+		for j := start; j < end; j++ {
+			if masked[j] == ' ' || masked[j] == '\t' {
+				masked[j] = shortcodeSpace[0]
+			}
+		}
+		i = end + 2
+	}
+	return string(masked)
+}
+
+// markdownText returns a destination or title as written, with backslash
+// escapes removed and the spaces of shortcodes put back
+func markdownText(value []byte) string {
+	return strings.ReplaceAll(unescapeMarkdown(string(value)), shortcodeSpace, " ")
+}
+
+// markdownTitle returns a link title with runs of whitespace collapsed
+func markdownTitle(value []byte) string {
+	return strings.Join(strings.Fields(markdownText(value)), " ")
+}
+
+// withAttributeBlock returns link with the attributes of the block that may
+// follow it at end: [text](url){data-lc-expected=301}
+func withAttributeBlock(link foundLink, content string, end int) foundLink {
+	if end < len(content) && content[end] == '{' {
+		if length := strings.IndexAny(content[end:], "}\n"); length != -1 && content[end+length] == '}' {
+			link.attrs = parseAttributeBlock(content[end+1 : end+length])
+		}
+	}
+	return link
+}
+
+// fencedCodeEnd returns the offset just past a fenced code block, including
+// its closing fence if it has one
+func fencedCodeEnd(source []byte, n *ast.FencedCodeBlock) int {
+	end := len(source)
+	if lines := n.Lines(); lines.Len() > 0 {
+		end = lines.At(lines.Len() - 1).Stop
+	} else if newline := bytes.IndexByte(source[n.Pos():], '\n'); newline != -1 {
+		end = n.Pos() + newline + 1
+	}
+	if end >= len(source) {
+		return len(source)
+	}
+	// The closing fence is a line of the opening fence's character only
+	line := source[end:]
+	if newline := bytes.IndexByte(line, '\n'); newline != -1 {
+		line = line[:newline]
+	}
+	fence := bytes.TrimSpace(line)
+	if len(fence) >= 3 && len(bytes.Trim(fence, string(source[n.Pos()]))) == 0 {
+		end += len(line)
+	}
+	return end
+}
+
+// codeSpanEnd returns the offset just past the closing backticks of a code
+// span
+func codeSpanEnd(source []byte, n *ast.CodeSpan) int {
+	run := 0
+	for n.Pos()+run < len(source) && source[n.Pos()+run] == '`' {
+		run++
+	}
+	from := n.Pos() + run
+	if last, ok := n.LastChild().(*ast.Text); ok {
+		from = last.Segment.Stop
+	}
+	closing := bytes.Index(source[from:], bytes.Repeat([]byte("`"), run))
+	if closing == -1 {
+		return from
+	}
+	return from + closing + run
+}
+
+// matchBracket returns the index of the bracket closing the one at start,
+// skipping nested pairs and escaped brackets. The search stops at a blank
+// line, which ends the paragraph.
+func matchBracket(content string, start int) (int, bool) {
+	depth := 0
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i, true
+			}
+		case '\n':
+			if isBlankLine(content, i+1) {
+				return 0, false
+			}
+		}
+	}
+	return 0, false
+}
+
+// isBlankLine reports whether the line starting at start holds only
+// whitespace
+func isBlankLine(content string, start int) bool {
+	line := content[start:]
+	if end := strings.IndexByte(line, '\n'); end != -1 {
+		line = line[:end]
+	}
+	return strings.TrimSpace(line) == ""
+}
+
+// unescapeMarkdown removes backslash escapes before ASCII punctuation
+func unescapeMarkdown(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		out.WriteByte(s[i])
+	}
+	return out.String()
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) != -1
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// lineIndex maps byte offsets to line numbers
+type lineIndex []int
+
+// newLineIndex records the offset each line of content starts at
+func newLineIndex(content string) lineIndex {
+	starts := lineIndex{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// line returns the line an offset falls on, counting from 1
+func (starts lineIndex) line(offset int) int {
+	return sort.Search(len(starts), func(i int) bool { return starts[i] > offset })
+}
+
+// column returns the byte column, counting from 1, that linkURL starts at
+// on the line of offset, where a link starts, or 0 if the URL isn't written
+// out as is on that line, e.g. in a tag spanning lines or with escapes
+func (starts lineIndex) column(content string, offset int, linkURL string) int {
+	line := starts.line(offset)
+	lineEnd := len(content)
+	if line < len(starts) {
+		lineEnd = starts[line]
+	}
+	idx := strings.Index(content[offset:lineEnd], linkURL)
+	if idx == -1 {
+		return 0
+	}
+	return offset + idx - starts[line-1] + 1
+}
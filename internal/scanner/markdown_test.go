@@ -0,0 +1,238 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLinksFromFile_CommonMark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := `See [the [nested] guide](/guide/) and [Go](https://en.wikipedia.org/wiki/Go_(programming_language)).
+A [link with a title](/titled/ "The title") and [one with
+wrapped text](/wrapped/).
+
+[![badge](/img/badge.svg)](https://ci.example.com/build)
+[escaped](/a\_b/) [angle](</with space/>) [code ` + "`x`" + `](/code/)
+[shortcode]({{< ref "other" >}}) <https://example.com/raw>
+
+[unclosed link text
+
+[def]: https://example.com/def "Definition"
+[^1]: A footnote, not a link
+Not a link: [text] (/spaced/), nor is the footnote[^1]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, true); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+
+	expected := []struct {
+		url  string
+		text string
+		line int
+	}{
+		{"/guide/", "the [nested] guide", 1},
+		{"https://en.wikipedia.org/wiki/Go_(programming_language)", "Go", 1},
+		{"/titled/", "link with a title", 2},
+		{"/wrapped/", "one with wrapped text", 2},
+		{"https://ci.example.com/build", "![badge](/img/badge.svg)", 5},
+		{"/img/badge.svg", "", 5},
+		{"/a_b/", "escaped", 6},
+		{"/with space/", "angle", 6},
+		{"/code/", "code `x`", 6},
+		{`{{< ref "other" >}}`, "shortcode", 7},
+		{"https://example.com/raw", "https://example.com/raw", 7},
+		{"https://example.com/def", "", 11},
+	}
+	if len(file.Links) != len(expected) {
+		for _, link := range file.Links {
+			t.Logf("found %q (%q, line %d)", link.URL, link.Text, link.Line)
+		}
+		t.Fatalf("Expected %d links, got %d", len(expected), len(file.Links))
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Text != want.text || link.Line != want.line {
+			t.Errorf("link %d: expected %q (%q, line %d), got %q (%q, line %d)", i, want.url, want.text, want.line, link.URL, link.Text, link.Line)
+		}
+	}
+}
+
+func TestParseLinksFromFile_SkipsIndentedCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "Intro\n\n    [code](http://indented.example)\n    <a href=\"/indented-html/\">x</a>\n\nA [real link](/real/)\n" +
+		"    continues the paragraph, [not code](/lazy/)\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	if len(file.Links) != 2 || file.Links[0].URL != "/real/" || file.Links[1].URL != "/lazy/" {
+		t.Errorf("expected only /real/ and /lazy/, got %+v", file.Links)
+	}
+}
+
+func TestParseLinksFromFile_HTMLIgnoresMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	content := "<p>[not markdown](/md/)</p>\n<a href=\"/html/\">HTML</a>\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	if len(file.Links) != 1 || file.Links[0].URL != "/html/" || file.Links[0].Line != 2 {
+		t.Errorf("expected only /html/ on line 2, got %+v", file.Links)
+	}
+}
+
+func TestParseLinksFromFile_SkipsCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "Use `[text](https://example.com/span)` for links and ``<a href=\"/span-html/\">`` in HTML.\n" +
+		"```markdown\n" +
+		"[example](https://example.com/fenced)\n" +
+		"<https://example.com/fenced-autolink>\n" +
+		"```\n" +
+		"~~~~\n" +
+		"<a href=\"/tilde/\">x</a>\n" +
+		"~~~\n" +
+		"still code: [x](/tilde-long/)\n" +
+		"~~~~\n" +
+		"A [real link](/real/) with `code` and an unmatched ` backtick [too](/too/).\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	if len(file.Links) != 2 || file.Links[0].URL != "/real/" || file.Links[1].URL != "/too/" {
+		t.Errorf("expected only /real/ and /too/, got %+v", file.Links)
+	}
+	if len(file.Links) > 0 && file.Links[0].Line != 11 {
+		t.Errorf("expected /real/ on line 11, got %d", file.Links[0].Line)
+	}
+}
+
+func TestParseLinksFromFile_Images(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.md")
+	content := "![Map](map.png) and [a link](/about/)\n" +
+		"<img alt=\"Logo\" src=\"/images/logo.png\">\n" +
+		"{{< figure src=\"diagram.svg\" title=\"Diagram\" >}}\n" +
+		"{{% figure class=\"wide\" src=`/images/wide.jpg` %}}\n" +
+		"{{< figure src=photo.jpg >}}\n" +
+		"`{{< figure src=\"code.png\" >}}`\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, true); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []struct {
+		url  string
+		kind LinkKind
+	}{
+		{"map.png", KindImage},
+		{"/about/", ""},
+		{"/images/logo.png", KindImage},
+		{"diagram.svg", KindImage},
+		{"/images/wide.jpg", KindImage},
+		{"photo.jpg", KindImage},
+	}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), file.Links)
+	}
+	for i, want := range expected {
+		if file.Links[i].URL != want.url || file.Links[i].Kind != want.kind {
+			t.Errorf("link %d: expected %q (kind %q), got %q (kind %q)", i, want.url, want.kind, file.Links[i].URL, file.Links[i].Kind)
+		}
+	}
+
+	file = &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	if len(file.Links) != 1 || file.Links[0].URL != "/about/" {
+		t.Errorf("Expected only /about/ without image checking, got %+v", file.Links)
+	}
+}
+
+func TestParseLinksFromFile_Columns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "See [the guide](/guide/) and <a href=\"/about/\">ü</a>.\n" +
+		"<a\n  href=\"/wrapped/\">x</a> <a href=\"/q?a=1&amp;b=2\">y</a>\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := map[string]int{"/guide/": 17, "/about/": 39, "/wrapped/": 0, "/q?a=1&b=2": 0}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), file.Links)
+	}
+	for _, link := range file.Links {
+		if link.Column != expected[link.URL] {
+			t.Errorf("%s: expected column %d, got %d", link.URL, expected[link.URL], link.Column)
+		}
+	}
+}
+
+func TestParseLinksFromFile_Wrapped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "Read [the installation\nguide][Install\n  Guide] first, or ![a diagram\nof the setup](/img/setup.png).\n" +
+		"The [destination is on\nthe next line](\n/next/ \"A title that\nwraps\") here.\n" +
+		"\n" +
+		"[install\nguide]: /install/\n" +
+		"  \"Installing\n  the site\"\n" +
+		"[not a\n\nlabel]: /split/\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, true); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []struct {
+		url   string
+		text  string
+		title string
+		line  int
+	}{
+		{"/img/setup.png", "", "", 3},
+		{"/next/", "destination is on the next line", "A title that wraps", 5},
+		{"/install/", "", "Installing the site", 10},
+	}
+	if len(file.Links) != len(expected) {
+		for _, link := range file.Links {
+			t.Logf("found %q (%q, line %d)", link.URL, link.Text, link.Line)
+		}
+		t.Fatalf("Expected %d links, got %d", len(expected), len(file.Links))
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Text != want.text || link.Title != want.title || link.Line != want.line {
+			t.Errorf("link %d: expected %q (%q, %q, line %d), got %q (%q, %q, line %d)",
+				i, want.url, want.text, want.title, want.line, link.URL, link.Text, link.Title, link.Line)
+		}
+	}
+	if file.Links[2].Unreferenced {
+		t.Error("Expected the wrapped reference to use the wrapped definition")
+	}
+}
@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// DefaultTrackingParams are common analytics query parameters that don't
+// change the destination of a link
+var DefaultTrackingParams = []string{"utm_*", "fbclid", "gclid", "dclid", "msclkid", "mc_cid", "mc_eid", "_ga", "igshid"}
+
+// NormalizeURL returns the form of an external URL used to decide whether
+// two links point at the same destination: the scheme and host are lower
+// cased, default ports are removed, trailing slashes are normalized, and
+// spaces are encoded. Internal links and URLs that fail to parse are
+// returned unchanged.
+func NormalizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		u.Host = host + ":" + port
+	} else if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	} else {
+		u.Host = host
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	} else if u.Path != "/" {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	}
+	u.RawQuery = strings.ReplaceAll(u.RawQuery, " ", "%20")
+
+	return u.String()
+}
+
+// StripQueryParams removes query parameters whose names match any of the
+// glob patterns (e.g. "utm_*") from an external URL. The URL is returned
+// unchanged if nothing matches or it cannot be parsed.
+func StripQueryParams(raw string, patterns []string) string {
+	if len(patterns) == 0 || !strings.Contains(raw, "?") {
+		return raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	query := u.Query()
+	stripped := false
+	for name := range query {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, strings.ToLower(name)); matched {
+				query.Del(name)
+				stripped = true
+				break
+			}
+		}
+	}
+	if !stripped {
+		return raw
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// DestinationKey returns the key identifying where a link points, used to
+// deduplicate links for checking and reporting
+func DestinationKey(link Link) string {
+	if link.CheckedURL != "" {
+		return NormalizeURL(link.CheckedURL)
+	}
+	return NormalizeURL(link.URL)
+}
+
+// LinkDomain returns the lowercased host an external link points at, or ""
+// for links without a host such as internal and mailto links
+func LinkDomain(link Link) string {
+	linkURL := link.URL
+	if link.CheckedURL != "" {
+		linkURL = link.CheckedURL
+	}
+	if IsProtocolRelative(linkURL) {
+		linkURL = "https:" + linkURL
+	}
+	u, err := url.Parse(linkURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
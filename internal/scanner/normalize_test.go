@@ -0,0 +1,69 @@
+package scanner
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	same := []string{
+		"http://example.com",
+		"http://example.com/",
+		"HTTP://EXAMPLE.COM",
+		"http://Example.com:80/",
+	}
+	for _, raw := range same {
+		if got := NormalizeURL(raw); got != "http://example.com/" {
+			t.Errorf("%s: expected http://example.com/, got %s", raw, got)
+		}
+	}
+
+	testCases := map[string]string{
+		"https://example.com:443/docs/":             "https://example.com/docs",
+		"https://example.com:8443/docs":             "https://example.com:8443/docs",
+		"https://example.com/Path?q=1":              "https://example.com/Path?q=1",
+		"https://example.com/my file.pdf?q=a b":     "https://example.com/my%20file.pdf?q=a%20b",
+		"https://example.com/my%20file.pdf?q=a%20b": "https://example.com/my%20file.pdf?q=a%20b",
+		"/internal/page/":                           "/internal/page/",
+		"mailto:someone@example.com":                "mailto:someone@example.com",
+	}
+	for raw, expected := range testCases {
+		if got := NormalizeURL(raw); got != expected {
+			t.Errorf("%s: expected %s, got %s", raw, expected, got)
+		}
+	}
+}
+
+func TestStripQueryParams(t *testing.T) {
+	testCases := map[string]string{
+		"https://example.com/a?utm_source=x&utm_medium=y":  "https://example.com/a",
+		"https://example.com/a?id=7&fbclid=abc":            "https://example.com/a?id=7",
+		"https://example.com/a?id=7":                       "https://example.com/a?id=7",
+		"https://example.com/a":                            "https://example.com/a",
+		"/internal/?utm_source=x":                          "/internal/?utm_source=x",
+		"https://example.com/a?UTM_Campaign=spring&page=2": "https://example.com/a?page=2",
+	}
+
+	for raw, expected := range testCases {
+		if got := StripQueryParams(raw, DefaultTrackingParams); got != expected {
+			t.Errorf("%s: expected %s, got %s", raw, expected, got)
+		}
+	}
+
+	a := Link{URL: "https://example.com/a?utm_source=x", CheckedURL: "https://example.com/a"}
+	b := Link{URL: "https://EXAMPLE.com/a"}
+	if DestinationKey(a) != DestinationKey(b) {
+		t.Errorf("Expected stripped and plain URLs to share a destination key")
+	}
+}
+
+func TestLinkDomain(t *testing.T) {
+	testCases := map[string]string{
+		"https://Example.COM:8443/page": "example.com",
+		"//cdn.example.org/lib.js":      "cdn.example.org",
+		"mailto:someone@example.com":    "",
+		"/about/":                       "",
+	}
+	for input, expected := range testCases {
+		if got := LinkDomain(NewLink(input)); got != expected {
+			t.Errorf("LinkDomain(%s): expected %q, got %q", input, expected, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// parseCacheVersion identifies the parser that wrote a parse cache; bump it
+// whenever parsing changes so caches from older versions are discarded
+const parseCacheVersion = 19
+
+// ParseCache remembers the links parsed from each file together with the
+// file's size and modification time, so files unchanged since the last run
+// are not parsed again. It needs no version control, only the file system.
+type ParseCache struct {
+	Version int `json:"version"`
+	// CheckImages, FrontMatterKeys, Shortcodes, WikiLinks, and
+	// LazyLoadAttrs record the parse settings; a cache written with other
+	// settings is discarded
+	CheckImages     bool                       `json:"check_images"`
+	FrontMatterKeys []string                   `json:"front_matter_keys"`
+	Shortcodes      map[string][]string        `json:"shortcodes,omitempty"`
+	WikiLinks       bool                       `json:"wiki_links,omitempty"`
+	LazyLoadAttrs   []string                   `json:"lazy_load_attrs,omitempty"`
+	Files           map[string]parseCacheEntry `json:"files"`
+
+	path string
+	seen map[string]bool
+
+	// Hits and Misses count the files served from the cache and parsed
+	Hits   int `json:"-"`
+	Misses int `json:"-"`
+}
+
+// parseCacheEntry holds the parse result of one file
+type parseCacheEntry struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Links   []Link    `json:"links"`
+}
+
+// LoadParseCache reads the parse cache at path. A missing, unreadable, or
+// outdated cache yields an empty one rather than an error, since the cache
+// only saves time.
+func LoadParseCache(path string, opts ParseOptions) *ParseCache {
+	cache := &ParseCache{
+		Version:         parseCacheVersion,
+		CheckImages:     opts.CheckImages,
+		FrontMatterKeys: opts.FrontMatterKeys,
+		Shortcodes:      opts.Shortcodes,
+		WikiLinks:       opts.WikiLinks,
+		LazyLoadAttrs:   opts.LazyLoadAttrs,
+		Files:           make(map[string]parseCacheEntry),
+		path:            path,
+		seen:            make(map[string]bool),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	var stored ParseCache
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != parseCacheVersion || stored.CheckImages != opts.CheckImages ||
+		!slices.Equal(stored.FrontMatterKeys, opts.FrontMatterKeys) || !maps.EqualFunc(stored.Shortcodes, opts.Shortcodes, slices.Equal) || stored.WikiLinks != opts.WikiLinks ||
+		!slices.Equal(stored.LazyLoadAttrs, opts.LazyLoadAttrs) || stored.Files == nil {
+		return cache
+	}
+	cache.Files = stored.Files
+	return cache
+}
+
+// Parse fills in the links of file, from the cache when the file's size and
+// modification time are unchanged, otherwise by parsing it
+func (c *ParseCache) Parse(file *File) error {
+	c.seen[file.Path] = true
+
+	info, err := os.Stat(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to stat file %s: %w", file.Path, err)
+	}
+	if entry, ok := c.Files[file.Path]; ok && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+		file.Links = append([]Link(nil), entry.Links...)
+		c.Hits++
+		return nil
+	}
+
+	opts := ParseOptions{CheckImages: c.CheckImages, FrontMatterKeys: c.FrontMatterKeys, Shortcodes: c.Shortcodes, WikiLinks: c.WikiLinks, LazyLoadAttrs: c.LazyLoadAttrs}
+	if err := ParseLinksFromFileWithOptions(file, opts); err != nil {
+		delete(c.Files, file.Path)
+		return err
+	}
+	c.Misses++
+	c.Files[file.Path] = parseCacheEntry{
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		Links:   append([]Link(nil), file.Links...),
+	}
+	return nil
+}
+
+// Save writes the cache back to disk, dropping files not seen in this run
+func (c *ParseCache) Save() error {
+	for path := range c.Files {
+		if !c.seen[path] {
+			delete(c.Files, path)
+		}
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode parse cache: %v", err)
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write parse cache: %v", err)
+	}
+	return nil
+}
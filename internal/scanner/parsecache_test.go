@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseCache(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.md")
+	cachePath := filepath.Join(dir, "cache.json")
+	if err := os.WriteFile(page, []byte("[a](/a/)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first run parses the file
+	cache := LoadParseCache(cachePath, ParseOptions{})
+	file := &File{Path: page}
+	if err := cache.Parse(file); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cache.Misses != 1 || len(file.Links) != 1 {
+		t.Fatalf("expected a parse, got %d misses and links %+v", cache.Misses, file.Links)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// An unchanged file comes from the cache
+	cache = LoadParseCache(cachePath, ParseOptions{})
+	file = &File{Path: page}
+	if err := cache.Parse(file); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cache.Hits != 1 || len(file.Links) != 1 || file.Links[0].URL != "/a/" {
+		t.Errorf("expected a cache hit, got %d hits and links %+v", cache.Hits, file.Links)
+	}
+
+	// A changed file is parsed again
+	if err := os.WriteFile(page, []byte("[a](/a/) [b](/b/)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(page, later, later); err != nil {
+		t.Fatal(err)
+	}
+	file = &File{Path: page}
+	if err := cache.Parse(file); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cache.Misses != 1 || len(file.Links) != 2 {
+		t.Errorf("expected the changed file to be parsed, got %d misses and links %+v", cache.Misses, file.Links)
+	}
+
+	// A cache written with other parse settings is discarded
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if cache := LoadParseCache(cachePath, ParseOptions{CheckImages: true}); len(cache.Files) != 0 {
+		t.Errorf("expected the cache to be discarded when image checking changes")
+	}
+	if cache := LoadParseCache(cachePath, ParseOptions{Shortcodes: map[string][]string{"button": {"href"}}}); len(cache.Files) != 0 {
+		t.Errorf("expected the cache to be discarded when shortcodes change")
+	}
+}
+
+func TestParseCache_WikiLinks(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.md")
+	if err := os.WriteFile(page, []byte("See [[Release Notes]].\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := LoadParseCache(filepath.Join(dir, "cache.json"), ParseOptions{WikiLinks: true})
+	file := &File{Path: page}
+	if err := cache.Parse(file); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(file.Links) != 1 || file.Links[0].Kind != KindWiki {
+		t.Errorf("expected the wiki link to be parsed, got %+v", file.Links)
+	}
+}
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/infodancer/hugo-link-checker/hugo"
+)
+
+// SiteRoot returns the Hugo site root for a scan root. When scanning from
+// within a content directory, the site root is the directory containing it.
+func SiteRoot(rootDir string) string {
+	if strings.Contains(rootDir, "/content/") {
+		parts := strings.Split(rootDir, "/content/")
+		if len(parts) > 0 {
+			return parts[0]
+		}
+	}
+	return rootDir
+}
+
+// PublicPermalink computes the URL path a file in public/ is served at,
+// given its path relative to the public directory
+func PublicPermalink(relPath string) string {
+	relPath = filepath.ToSlash(relPath)
+	if filepath.Base(relPath) == "index.html" {
+		dir := strings.Trim(filepath.Dir(relPath), "./")
+		if dir == "" {
+			return "/"
+		}
+		return "/" + strings.ToLower(dir) + "/"
+	}
+
+	// uglyURLs output: public/about.html is served at /about.html, but
+	// corresponds to the same page as /about/
+	return "/" + strings.ToLower(strings.TrimSuffix(relPath, filepath.Ext(relPath))) + "/"
+}
+
+// MapPublicSources sets SourcePath on files generated into the site's
+// public directory, pointing each at the content file that produced it, so
+// failures found in generated HTML can be fixed in the Markdown source. urls
+// gives the paths content pages are served at.
+func MapPublicSources(files []*File, siteRoot string, urls *hugo.URLResolver) error {
+	contentDir := filepath.Join(siteRoot, "content")
+	publicDir, err := filepath.Abs(filepath.Join(siteRoot, "public"))
+	if err != nil {
+		return err
+	}
+
+	sources := make(map[string]string)
+	err = filepath.Walk(contentDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() || !IsContentFile(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(contentDir, path)
+		if err != nil {
+			return err
+		}
+		fm, _ := ParseFrontMatter(path)
+		pageURL := urls.PageURL(hugo.Page{Path: rel, FrontMatter: fm})
+		// uglyURLs pages are generated as about.html, served at the same
+		// path PublicPermalink gives about/index.html
+		if strings.HasSuffix(pageURL, ".html") {
+			pageURL = strings.TrimSuffix(pageURL, ".html") + "/"
+		}
+		sources[strings.ToLower(pageURL)] = path
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		rel, err := filepath.Rel(publicDir, file.CanonicalPath)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if source, ok := sources[PublicPermalink(rel)]; ok {
+			file.SourcePath = source
+		}
+	}
+
+	return nil
+}
+
+// IsContentFile reports whether a path is a Hugo content source file
+func IsContentFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".html", ".htm":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMapPublicSources(t *testing.T) {
+	siteRoot := t.TempDir()
+
+	sources := []string{
+		filepath.Join("content", "about.md"),
+		filepath.Join("content", "posts", "hello", "index.md"),
+	}
+	generated := []string{
+		filepath.Join("public", "about", "index.html"),
+		filepath.Join("public", "posts", "hello", "index.html"),
+		filepath.Join("public", "tags", "index.html"),
+	}
+	for _, path := range append(sources, generated...) {
+		full := filepath.Join(siteRoot, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(full, nil, 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	publicFiles, err := EnumerateFiles(filepath.Join(siteRoot, "public"), []string{".html"})
+	if err != nil {
+		t.Fatalf("EnumerateFiles failed: %v", err)
+	}
+	if len(publicFiles) != len(generated) {
+		t.Fatalf("Expected %d public files, got %d", len(generated), len(publicFiles))
+	}
+
+	if err := MapPublicSources(GetFileList(publicFiles), siteRoot, nil); err != nil {
+		t.Fatalf("MapPublicSources failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"about": filepath.Join(siteRoot, sources[0]),
+		"hello": filepath.Join(siteRoot, sources[1]),
+		"tags":  "",
+	}
+	for _, file := range publicFiles {
+		name := filepath.Base(filepath.Dir(file.Path))
+		if file.SourcePath != expected[name] {
+			t.Errorf("%s: expected source %q, got %q", file.Path, expected[name], file.SourcePath)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package scanner
+
+import (
+	"strings"
+)
+
+// references tracks the link reference definitions and footnotes of a
+// Markdown document and where they are used. Hugo renders a reference to an
+// undefined label as literal text, so it is a broken link; a definition
+// nothing refers to is dead weight, and an unreferenced footnote is dropped.
+type references struct {
+	// definitions maps normalized labels to the index of their definition
+	// in the links found; the first definition of a label wins
+	definitions map[string]int
+	used        map[string]bool
+	// pending holds the full and collapsed references, which are checked
+	// against the definitions once the whole document has been read
+	pending []pendingReference
+
+	// footnotes maps footnote labels to the offset of their definition
+	footnotes     map[string]int
+	footnoteOrder []string
+	footnotesUsed map[string]bool
+	footnoteRefs  []pendingReference
+}
+
+// pendingReference is a use of a label that may be defined further on
+type pendingReference struct {
+	label  string
+	link   foundLink
+	report bool
+}
+
+func newReferences() *references {
+	return &references{
+		definitions:   make(map[string]int),
+		used:          make(map[string]bool),
+		footnotes:     make(map[string]int),
+		footnotesUsed: make(map[string]bool),
+	}
+}
+
+// define records the definition of label, the link at index in the links
+// found
+func (r *references) define(label string, index int) {
+	key := normalizeLabel(label)
+	if _, ok := r.definitions[key]; !ok {
+		r.definitions[key] = index
+	}
+}
+
+// useDefinition records a use of the definition of label by a reference
+// link goldmark resolved
+func (r *references) useDefinition(label string) {
+	r.used[normalizeLabel(label)] = true
+}
+
+// defineFootnote records the definition of a footnote at offset
+func (r *references) defineFootnote(label string, offset int) {
+	if _, ok := r.footnotes[label]; !ok {
+		r.footnotes[label] = offset
+		r.footnoteOrder = append(r.footnoteOrder, label)
+	}
+}
+
+// useFootnote records a footnote reference such as [^1] at offset
+func (r *references) useFootnote(label string, offset int) {
+	if label == "" || strings.ContainsAny(label, " \t\n") {
+		return
+	}
+	r.footnotesUsed[label] = true
+	linkURL := "[^" + label + "]"
+	r.footnoteRefs = append(r.footnoteRefs, pendingReference{
+		label:  label,
+		link:   foundLink{url: linkURL, offset: offset, kind: KindReference},
+		report: true,
+	})
+}
+
+// use records the reference whose text runs from the bracket at start to
+// the one at closing: a full reference [text][label], a collapsed one
+// [label][], or a shortcut one [label]. It returns the index just past the
+// reference. Shortcut references to undefined labels are left alone, since
+// brackets are common in prose ([sic], [1]), as are references right after
+// a word, such as the indexes of matrix[i][j].
+func (r *references) use(content, text string, start, closing int) int {
+	label, end := content[start+1:closing], closing+1
+	shortcut := true
+	if end < len(content) && content[end] == '[' {
+		if labelEnd, ok := matchBracket(content, end); ok {
+			if inner := content[end+1 : labelEnd]; strings.TrimSpace(inner) != "" {
+				label = inner
+			}
+			end = labelEnd + 1
+			shortcut = false
+		}
+	}
+
+	key := normalizeLabel(label)
+	if key == "" {
+		return end
+	}
+	r.used[key] = true
+	if shortcut {
+		return end
+	}
+
+	link := foundLink{url: "[" + label + "]", offset: start, kind: KindReference}
+	if start > 0 && content[start-1] == '!' {
+		link.offset = start - 1
+	} else {
+		link.text = strings.Join(strings.Fields(text[start+1:closing]), " ")
+	}
+	report := start == 0 || !isWordByte(content[start-1])
+	r.pending = append(r.pending, pendingReference{label: key, link: link, report: report})
+	return end
+}
+
+// resolve returns links with the references to undefined labels and the
+// unreferenced footnotes added, and unused definitions marked
+func (r *references) resolve(links []foundLink) []foundLink {
+	for _, ref := range r.pending {
+		if _, ok := r.definitions[ref.label]; !ok && ref.report {
+			links = append(links, ref.link)
+		}
+	}
+	for label, index := range r.definitions {
+		if !r.used[label] {
+			links[index].unreferenced = true
+		}
+	}
+
+	for _, ref := range r.footnoteRefs {
+		if _, ok := r.footnotes[ref.label]; !ok {
+			links = append(links, ref.link)
+		}
+	}
+	for _, label := range r.footnoteOrder {
+		if !r.footnotesUsed[label] {
+			linkURL := "[^" + label + "]"
+			links = append(links, foundLink{url: linkURL, offset: r.footnotes[label], kind: KindReference, unreferenced: true})
+		}
+	}
+	return links
+}
+
+// parseFootnoteDefinition matches the start of a footnote definition, such
+// as [^1]: The text, on the line starting at start. It returns the label and
+// the index just past the colon.
+func parseFootnoteDefinition(content string, start int) (string, int, bool) {
+	i := start
+	for indent := 0; indent < 3 && i < len(content) && content[i] == ' '; indent++ {
+		i++
+	}
+	if !strings.HasPrefix(content[i:], "[^") {
+		return "", 0, false
+	}
+	closing := strings.IndexByte(content[i:], ']')
+	if closing == -1 {
+		return "", 0, false
+	}
+	closing += i
+	label := content[i+2 : closing]
+	if label == "" || strings.ContainsAny(label, " \t\n[") || closing+1 >= len(content) || content[closing+1] != ':' {
+		return "", 0, false
+	}
+	return label, closing + 2, true
+}
+
+// normalizeLabel folds a link label the way CommonMark matches them:
+// case-insensitively, with runs of whitespace collapsed and the ends trimmed
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+func isWordByte(c byte) bool {
+	return isASCIILetter(c) || isASCIIDigit(c) || c == '_'
+}
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLinksFromFile_References(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "See [the guide][Guide  Page], [faq][], and [spec].\n" +
+		"A [typo][gone], an ![image][missing-img], and [empty][missing].\n" +
+		"Text[^1] and another[^nope], but not matrix[i][j] or [sic].\n" +
+		"`[in code][gone-too]`\n" +
+		"\n" +
+		"[guide page]: /guide/\n" +
+		"[FAQ]: /faq/\n" +
+		"[spec]: https://example.com/spec\n" +
+		"[unused]: /unused/\n" +
+		"[^1]: A footnote with [a link](/note/).\n" +
+		"[^orphan]: Nothing refers to this.\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, true); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	expected := []struct {
+		url          string
+		kind         LinkKind
+		line         int
+		unreferenced bool
+	}{
+		{"[gone]", KindReference, 2, false},
+		{"[missing-img]", KindReference, 2, false},
+		{"[missing]", KindReference, 2, false},
+		{"[^nope]", KindReference, 3, false},
+		{"/guide/", "", 6, false},
+		{"/faq/", "", 7, false},
+		{"https://example.com/spec", "", 8, false},
+		{"/unused/", "", 9, true},
+		{"/note/", "", 10, false},
+		{"[^orphan]", KindReference, 11, true},
+	}
+	if len(file.Links) != len(expected) {
+		for _, link := range file.Links {
+			t.Logf("found %q (line %d)", link.URL, link.Line)
+		}
+		t.Fatalf("Expected %d links, got %d", len(expected), len(file.Links))
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Kind != want.kind || link.Line != want.line || link.Unreferenced != want.unreferenced {
+			t.Errorf("link %d: expected %q (%q, line %d, unreferenced %v), got %q (%q, line %d, unreferenced %v)",
+				i, want.url, want.kind, want.line, want.unreferenced, link.URL, link.Kind, link.Line, link.Unreferenced)
+		}
+	}
+	if file.Links[0].Text != "typo" || file.Links[0].Column != 9 {
+		t.Errorf("expected the text and column of [typo][gone], got %q and %d", file.Links[0].Text, file.Links[0].Column)
+	}
+}
+
+func TestParseLinksFromFile_FootnoteWithURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	// Without the footnote extension, CommonMark reads this footnote as a
+	// link reference definition labeled ^1
+	content := "See the source[^1].\n\n[^1]: https://example.com/source\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	if len(file.Links) != 0 {
+		t.Errorf("expected no links or broken references, got %+v", file.Links)
+	}
+}
@@ -1,11 +1,10 @@
 package scanner
 
 import (
-	"bufio"
 	"fmt"
 	"net/url"
 	"os"
-	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -18,6 +17,79 @@ const (
 	LinkTypeExternal
 )
 
+// SkipReason explains why a link was deliberately not checked
+type SkipReason string
+
+const (
+	// SkipUnsupportedScheme marks links whose scheme is not in the allowlist
+	SkipUnsupportedScheme SkipReason = "unsupported-scheme"
+	// SkipAnnotated marks links their author asked not to check with
+	// data-lc-skip
+	SkipAnnotated SkipReason = "annotated"
+	// SkipUnknown marks external links a cache-only run has no cached
+	// result for
+	SkipUnknown SkipReason = "unknown"
+	// SkipOffline marks links that need the network in an offline run
+	SkipOffline SkipReason = "offline"
+	// SkipExternalDisabled marks external links in a run that doesn't
+	// check them
+	SkipExternalDisabled SkipReason = "external-disabled"
+)
+
+// LinkKind distinguishes what a link's destination is used for
+type LinkKind string
+
+const (
+	// KindImage marks image sources: Markdown images, <img src>, and the
+	// src of figure shortcodes
+	KindImage LinkKind = "image"
+	// KindMetadata marks page metadata URLs: <link rel="canonical">, meta
+	// refresh targets, and Open Graph and Twitter card URLs. They must be
+	// absolute, even on the site's own domain.
+	KindMetadata LinkKind = "metadata"
+	// KindFrontMatter marks links in the front matter of Markdown files,
+	// such as cover images. Relative ones are found in the page's bundle.
+	KindFrontMatter LinkKind = "front-matter"
+	// KindCSS marks url() references and @import targets of stylesheets,
+	// <style> elements, and style attributes, such as background images and
+	// fonts. Relative ones are relative to the stylesheet.
+	KindCSS LinkKind = "css"
+	// KindReference marks Markdown references to undefined labels, such as
+	// [text][label] and [^1], and footnotes nothing refers to; the URL is
+	// the reference as written
+	KindReference LinkKind = "reference"
+	// KindWiki marks wiki links such as [[Page Name]]; the URL is the page
+	// they name, which is found in the content tree by path or file name
+	KindWiki LinkKind = "wiki"
+	// KindPageRef marks menu entries' pageRef, a page path resolved against
+	// the content tree like a ref shortcode's; the URL is the path
+	KindPageRef LinkKind = "page-ref"
+)
+
+// ErrorCategory classifies why a link is broken
+type ErrorCategory string
+
+const (
+	CategoryDNS                ErrorCategory = "dns"
+	CategoryTimeout            ErrorCategory = "timeout"
+	CategoryConnectionRefused  ErrorCategory = "connection-refused"
+	CategoryTLS                ErrorCategory = "tls"
+	CategoryNetwork            ErrorCategory = "network"
+	CategoryHTTP4xx            ErrorCategory = "http-4xx"
+	CategoryHTTP5xx            ErrorCategory = "http-5xx"
+	CategoryNotFoundLocal      ErrorCategory = "not-found-local"
+	CategoryRobotsBlocked      ErrorCategory = "robots-blocked"
+	CategoryInvalidURL         ErrorCategory = "invalid-url"
+	CategoryContentMismatch    ErrorCategory = "content-mismatch"
+	CategoryMissingAnchor      ErrorCategory = "missing-anchor"
+	CategoryPolicy             ErrorCategory = "policy"
+	CategoryDomainNotAllowed   ErrorCategory = "domain-not-allowed"
+	CategoryCredentialLeak     ErrorCategory = "credential-leak"
+	CategoryStaleURL           ErrorCategory = "stale-url"
+	CategoryExpectation        ErrorCategory = "expectation"
+	CategoryUndefinedReference ErrorCategory = "undefined-reference"
+)
+
 // Link represents a link found in a file
 type Link struct {
 	URL          string    `json:"url"`
@@ -25,7 +97,124 @@ type Link struct {
 	LastChecked  time.Time `json:"last_checked"`
 	StatusCode   int       `json:"status_code"`
 	ErrorMessage string    `json:"error_message,omitempty"`
-	Ignored      bool      `json:"ignored,omitempty"`
+	// ErrorCategory classifies the failure of a broken link
+	ErrorCategory ErrorCategory `json:"error_category,omitempty"`
+	Ignored       bool          `json:"ignored,omitempty"`
+	Skipped       SkipReason    `json:"skipped,omitempty"`
+	Method        string        `json:"method,omitempty"`
+
+	// CheckedURL is the URL actually requested when it differs from the
+	// URL written in the source, e.g. after tracking parameters are removed
+	CheckedURL string `json:"checked_url,omitempty"`
+
+	// FinalURL is the URL an external link ends at after following
+	// redirects, set when it differs from the URL requested
+	FinalURL string `json:"final_url,omitempty"`
+
+	// RedirectStatus is the status of the first response of an external
+	// link that was redirected, e.g. 301
+	RedirectStatus int `json:"redirect_status,omitempty"`
+
+	// Canonical and ContentLocation record the Link: rel=canonical and
+	// Content-Location response headers of external links
+	Canonical       string   `json:"canonical,omitempty"`
+	ContentLocation string   `json:"content_location,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+
+	// Discrepancy describes a disagreement between the local and online
+	// checks of an internal link
+	Discrepancy string `json:"discrepancy,omitempty"`
+
+	// MissingHeaders lists the security headers the page of an internal
+	// link checked online was served without
+	MissingHeaders []string `json:"missing_headers,omitempty"`
+
+	// PolicyRules lists the IDs of the policy rules the link violates
+	PolicyRules []string `json:"policy_rules,omitempty"`
+
+	// Text is the link text as written, for Markdown and HTML anchors
+	Text string `json:"text,omitempty"`
+
+	// Title is the link title as written: the quoted title of a Markdown
+	// link or the title attribute of an HTML anchor
+	Title string `json:"title,omitempty"`
+
+	// Line is the line of the file the link first appears on, counting
+	// from 1; zero when unknown
+	Line int `json:"line,omitempty"`
+
+	// Column is the byte column the URL starts at on Line, counting from 1;
+	// zero when unknown
+	Column int `json:"column,omitempty"`
+
+	// Occurrences lists every place the URL appears in the file, in order,
+	// when it appears more than once; Line and Column are the first
+	Occurrences []Occurrence `json:"occurrences,omitempty"`
+
+	// Expect holds the expectations the author declared for the link with
+	// data-lc-* attributes
+	Expect *Expectation `json:"expect,omitempty"`
+
+	// Fix is a suggested replacement for the URL as written, e.g. the
+	// relative form of an absolute link to the site's own domain
+	Fix string `json:"fix,omitempty"`
+
+	// ResolvedSite names the related site whose local tree an absolute link
+	// was checked against
+	ResolvedSite string `json:"resolved_site,omitempty"`
+
+	// MutedUntil is the date (YYYY-MM-DD) through which a mute silences
+	// the link: it is checked and reported, but not counted as a failure
+	MutedUntil string `json:"muted_until,omitempty"`
+
+	// MovedPage is the content file of the page a stale link points at:
+	// its front matter url or slug moved it away from the linked path
+	MovedPage string `json:"moved_page,omitempty"`
+
+	// Fixed marks a link that was broken in the report of an earlier run
+	// and works now
+	Fixed bool `json:"fixed,omitempty"`
+
+	// Kind marks links that aren't plain links, such as image sources
+	Kind LinkKind `json:"kind,omitempty"`
+
+	// Unreferenced marks Markdown link reference definitions and footnotes
+	// that nothing in the page refers to
+	Unreferenced bool `json:"unreferenced,omitempty"`
+
+	// Width and Height are the decoded dimensions of image links
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// Enclosure marks a podcast enclosure from an RSS feed, with the
+	// length in bytes the feed declares for it
+	Enclosure       bool  `json:"enclosure,omitempty"`
+	EnclosureLength int64 `json:"enclosure_length,omitempty"`
+
+	// ContentType and Size are the served Content-Type and size in bytes of
+	// checked enclosures
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// Occurrence is one place a link's URL appears in its file
+type Occurrence struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+// Positions returns the places the link appears in its file: its
+// Occurrences, or its one position
+func (link Link) Positions() []Occurrence {
+	if len(link.Occurrences) > 0 {
+		return link.Occurrences
+	}
+	return []Occurrence{{Line: link.Line, Column: link.Column}}
+}
+
+// OccurrenceCount returns the number of times the link appears in its file
+func (link Link) OccurrenceCount() int {
+	return max(len(link.Occurrences), 1)
 }
 
 // File represents a file and its links
@@ -33,6 +222,19 @@ type File struct {
 	Path          string `json:"path"`
 	CanonicalPath string `json:"canonical_path"`
 	Links         []Link `json:"links"`
+
+	// SourcePath is the content file that generated this file, set for
+	// files scanned from Hugo's public directory
+	SourcePath string `json:"source_path,omitempty"`
+
+	// Site names the site the file belongs to in a multi-site run
+	Site string `json:"site,omitempty"`
+
+	// Warnings apply to the page as a whole rather than one of its links
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Pageviews is the page's traffic, from an analytics export
+	Pageviews int64 `json:"pageviews,omitempty"`
 }
 
 // isInternalLink determines if a link is internal (relative) or external
@@ -41,6 +243,11 @@ func isInternalLink(linkURL string) bool {
 	cleanURL := strings.Trim(linkURL, "<>")
 	cleanURL = strings.TrimSpace(cleanURL)
 
+	// Protocol-relative URLs (//cdn.example.com/lib.js) point at another host
+	if IsProtocolRelative(cleanURL) {
+		return false
+	}
+
 	// Parse the cleaned URL
 	u, err := url.Parse(cleanURL)
 	if err != nil {
@@ -57,6 +264,12 @@ func isInternalLink(linkURL string) bool {
 	return true
 }
 
+// IsProtocolRelative reports whether a URL is protocol-relative, i.e. it
+// names a host but inherits the scheme of the page (//cdn.example.com/lib.js)
+func IsProtocolRelative(linkURL string) bool {
+	return strings.HasPrefix(linkURL, "//") && len(linkURL) > 2 && linkURL[2] != '/'
+}
+
 // NewLink creates a new Link with the appropriate type
 func NewLink(linkURL string) Link {
 	linkType := LinkTypeInternal
@@ -70,97 +283,120 @@ func NewLink(linkURL string) Link {
 	}
 }
 
-// ParseLinksFromFile reads a file and extracts all links using regex
+// ParseOptions configures which links are extracted from files
+type ParseOptions struct {
+	// CheckImages extracts image sources
+	CheckImages bool
+	// FrontMatterKeys selects the front matter fields of Markdown files
+	// whose values are links, such as cover images
+	FrontMatterKeys []string
+	// Shortcodes maps the names of custom shortcodes in Markdown files to
+	// their arguments that hold URLs, named ones by name and positional
+	// ones by their index from 0
+	Shortcodes map[string][]string
+	// WikiLinks extracts the wiki links of Markdown files, [[Page Name]]
+	// and [[page|text]]
+	WikiLinks bool
+	// LazyLoadAttrs names the HTML attributes, such as data-src, whose
+	// values are image sources loaded lazily
+	LazyLoadAttrs []string
+}
+
+// ParseLinksFromFile reads a file and extracts all links, with the default
+// front matter fields and lazy-loading attributes. Image sources are only
+// extracted when checkImages is set.
 func ParseLinksFromFile(file *File, checkImages bool) error {
-	// Regular expressions for different link formats
-	// Markdown: [text](url), <url>, [ref]: url
-	// HTML: <a href="url">, <link href="url">
-	linkRegexes := []*regexp.Regexp{
-		regexp.MustCompile(`\[([^\]]*)\]\(([^)]+)\)`),                        // [text](url) - markdown
-		regexp.MustCompile(`<(https?://[^>]+)>`),                             // <http://example.com> - markdown autolinks
-		regexp.MustCompile(`^\s*\[([^\]]+)\]:\s*(.+)$`),                      // [ref]: url - markdown reference definitions
-		regexp.MustCompile(`<a\s+[^>]*href\s*=\s*["']([^"']+)["'][^>]*>`),    // <a href="url"> - HTML
-		regexp.MustCompile(`<link\s+[^>]*href\s*=\s*["']([^"']+)["'][^>]*>`), // <link href="url"> - HTML
+	return ParseLinksFromFileWithOptions(file, ParseOptions{CheckImages: checkImages, FrontMatterKeys: DefaultFrontMatterURLKeys, LazyLoadAttrs: DefaultLazyLoadAttrs})
+}
+
+// ParseLinksFromFileWithOptions reads a file and extracts all links.
+// Markdown files are parsed following the CommonMark link syntax. Their front
+// matter fields under opts.FrontMatterKeys and shortcode arguments under
+// opts.Shortcodes are links too, and so are wiki links with opts.WikiLinks.
+// HTML, in both HTML and Markdown files, is read with the HTML5 tokenizer,
+// and stylesheets for their url() references.
+func ParseLinksFromFileWithOptions(file *File, opts ParseOptions) error {
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", file.Path, err)
 	}
+	ParseLinks(file, data, opts)
+	return nil
+}
+
+// ParseLinks extracts all links from data, the content of file, like
+// ParseLinksFromFileWithOptions. Editors use it to parse unsaved buffers.
+func ParseLinks(file *File, data []byte, opts ParseOptions) {
+	content := string(data)
 
-	// Add image link patterns if image checking is enabled
-	if checkImages {
-		imageRegexes := []*regexp.Regexp{
-			regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`),                     // ![alt](url) - markdown images
-			regexp.MustCompile(`<img\s+[^>]*src\s*=\s*["']([^"']+)["'][^>]*>`), // <img src="url"> - HTML images
+	var found []foundLink
+	if isCSSFile(file.Path) {
+		found = parseCSSURLs(content)
+	} else {
+		htmlContent := content
+		if isMarkdownFile(file.Path) {
+			// Front matter Hugo can't parse fails the build; its links are
+			// left to that error
+			fm, _ := parseFrontMatter(file.Path, data)
+			for _, linkURL := range frontMatterLinks(fm, opts.FrontMatterKeys) {
+				found = append(found, foundLink{url: linkURL, offset: max(strings.Index(content, linkURL), 0), kind: KindFrontMatter})
+			}
+			// HTML in code blocks and code spans is an example, not markup
+			var links []foundLink
+			links, htmlContent = parseMarkdown(content)
+			found = append(found, links...)
+			found = append(found, parseFigureShortcodes(content, htmlContent)...)
+			found = append(found, parseShortcodeLinks(content, htmlContent, opts.Shortcodes)...)
+			if opts.WikiLinks {
+				found = append(found, parseWikiLinks(content, htmlContent)...)
+			}
 		}
-		linkRegexes = append(linkRegexes, imageRegexes...)
+		found = append(found, parseHTMLLinks(htmlContent, opts.LazyLoadAttrs)...)
 	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].offset < found[j].offset
+	})
 
-	// Open the file
-	f, err := os.Open(file.Path)
-	if err != nil {
-		return fmt.Errorf("failed to open file %s: %w", file.Path, err)
-	}
-	defer func() {
-		if closeErr := f.Close(); closeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", file.Path, closeErr)
+	// Repeats of a link are recorded as further occurrences of the first
+	// one. A repeat with its own kind, text, or attributes, such as a
+	// data-lc-expected status, is a link of its own.
+	linkIndex := make(map[string]int)
+	// foundAt records the places links were found at, since the same place
+	// may be found by more than one parser
+	foundAt := make(map[string]bool)
+	lines := newLineIndex(content)
+	for _, f := range found {
+		linkURL := strings.TrimSpace(f.url)
+
+		// Skip empty URLs or fragment-only links
+		if linkURL == "" || linkURL == "#" || (f.kind == KindImage && !opts.CheckImages) {
+			continue
 		}
-	}()
-
-	// Track unique links to avoid duplicates
-	linkMap := make(map[string]bool)
-
-	// Read file line by line
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Apply each regex to find links
-		for _, regex := range linkRegexes {
-			matches := regex.FindAllStringSubmatch(line, -1)
-			for _, match := range matches {
-				var linkURL string
-				if len(match) >= 3 {
-					// For [text](url) format, URL is in match[2]
-					linkURL = strings.TrimSpace(match[2])
-				} else if len(match) >= 2 {
-					// For <url> format, URL is in match[1]
-					linkURL = strings.TrimSpace(match[1])
-				}
-
-				if linkURL == "" {
-					continue
-				}
-
-				// Remove any title part from the URL (everything after first space or quote)
-				if spaceIdx := strings.Index(linkURL, " "); spaceIdx != -1 {
-					linkURL = linkURL[:spaceIdx]
-				}
-				if quoteIdx := strings.Index(linkURL, `"`); quoteIdx != -1 {
-					linkURL = linkURL[:quoteIdx]
-				}
-
-				// Clean the URL by removing angle brackets and trimming whitespace
-				linkURL = strings.Trim(linkURL, "<>")
-				linkURL = strings.TrimSpace(linkURL)
-
-				// Skip empty URLs or fragment-only links
-				if linkURL == "" || linkURL == "#" {
-					continue
-				}
-
-				// Check if we've already seen this link
-				if linkMap[linkURL] {
-					continue
-				}
-				linkMap[linkURL] = true
-
-				// Create and add the link
-				link := NewLink(linkURL)
-				file.Links = append(file.Links, link)
-			}
+
+		occurrence := Occurrence{Line: lines.line(f.offset), Column: lines.column(content, f.offset, linkURL)}
+		place := fmt.Sprintf("%s\x00%d:%d", linkURL, occurrence.Line, occurrence.Column)
+		if foundAt[place] {
+			continue
 		}
-	}
+		foundAt[place] = true
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading file %s: %w", file.Path, err)
-	}
+		key := fmt.Sprintf("%s\x00%s\x00%s\x00%v", linkURL, f.kind, strings.TrimSpace(f.text), f.attrs)
+		if index, seen := linkIndex[key]; seen {
+			link := &file.Links[index]
+			link.Occurrences = append(link.Positions(), occurrence)
+			continue
+		}
+		linkIndex[key] = len(file.Links)
 
-	return nil
+		// Create and add the link
+		link := NewLink(linkURL)
+		link.Kind = f.kind
+		link.Unreferenced = f.unreferenced
+		link.Text = strings.TrimSpace(f.text)
+		link.Title = f.title
+		link.Line = occurrence.Line
+		link.Column = occurrence.Column
+		link.Expect, link.Warnings = parseExpectation(f.attrs)
+		file.Links = append(file.Links, link)
+	}
 }
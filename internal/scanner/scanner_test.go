@@ -3,6 +3,7 @@ package scanner
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -279,6 +280,27 @@ func TestEnumerateFiles(t *testing.T) {
 	}
 }
 
+func TestEnumerateFilesExcluding(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"content/page.md", "themes/docsy/layouts/list.html", "node_modules/pkg/README.md"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# Test"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := EnumerateFilesExcluding(tmpDir, []string{".md", ".html"}, []string{"themes", "node_modules"})
+	if err != nil {
+		t.Fatalf("EnumerateFilesExcluding failed: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("Expected only content/page.md, got %d files", len(files))
+	}
+}
+
 func TestLinkTypeDetection(t *testing.T) {
 	testCases := []struct {
 		url      string
@@ -293,7 +315,8 @@ func TestLinkTypeDetection(t *testing.T) {
 		{"page.html", LinkTypeInternal},
 		{"mailto:test@example.com", LinkTypeExternal},
 		{"#fragment", LinkTypeInternal},
-		{"", LinkTypeInternal}, // Empty URL treated as internal
+		{"//cdn.example.com/lib.js", LinkTypeExternal}, // Protocol-relative
+		{"", LinkTypeInternal},                         // Empty URL treated as internal
 	}
 
 	for _, tc := range testCases {
@@ -303,3 +326,111 @@ func TestLinkTypeDetection(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLinksFromFile_Text(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "See [the guide](/guide/) and ![diagram](/img/d.png).\n<https://example.com/raw>\n<a class=\"x\" href=\"/about/\">About <em>us</em></a>\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, true); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"/guide/":                 "the guide",
+		"/img/d.png":              "",
+		"https://example.com/raw": "https://example.com/raw",
+		"/about/":                 "About <em>us</em>",
+	}
+	for _, link := range file.Links {
+		if text, ok := expected[link.URL]; ok && link.Text != text {
+			t.Errorf("%s: expected text %q, got %q", link.URL, text, link.Text)
+		}
+	}
+	if len(file.Links) != len(expected) {
+		t.Errorf("Expected %d links, got %d", len(expected), len(file.Links))
+	}
+}
+
+func TestParseLinksFromFile_Title(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "See [the guide](/guide/ \"The \\\"full\\\" guide\") and [faq](/faq/ 'Questions\n  answered').\n" +
+		"[plain](/plain/) and <a href=\"/about/\" title=\"About &amp; contact\">About</a>\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"/guide/": `The "full" guide`,
+		"/faq/":   "Questions answered",
+		"/plain/": "",
+		"/about/": "About & contact",
+	}
+	for _, link := range file.Links {
+		if title, ok := expected[link.URL]; ok && link.Title != title {
+			t.Errorf("%s: expected title %q, got %q", link.URL, title, link.Title)
+		}
+	}
+	if len(file.Links) != len(expected) {
+		t.Errorf("Expected %d links, got %d", len(expected), len(file.Links))
+	}
+}
+
+func TestParseLinks_RepeatsKeepAttributes(t *testing.T) {
+	file := &File{Path: "page.html"}
+	content := `<a href="/old/">Old</a> <a href="/old/" data-lc-expected="301">Old</a> <img src="/old/" alt="">`
+	ParseLinks(file, []byte(content), ParseOptions{CheckImages: true})
+
+	if len(file.Links) != 3 {
+		t.Fatalf("Expected 3 links, got %+v", file.Links)
+	}
+	if file.Links[0].Expect != nil || file.Links[1].Expect == nil || file.Links[1].Expect.Status != 301 {
+		t.Errorf("Expected only the second link to carry an expectation, got %+v and %+v", file.Links[0].Expect, file.Links[1].Expect)
+	}
+	if file.Links[2].Kind != KindImage {
+		t.Errorf("Expected the image to keep its kind, got %q", file.Links[2].Kind)
+	}
+}
+
+func TestParseLinksFromFile_Occurrences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "See [setup](/guide/) first.\n\nThen [other](/other/).\n\nBack to [setup](/guide/) and [again](/guide/).\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	if len(file.Links) != 3 {
+		t.Fatalf("Expected 3 links, got %+v", file.Links)
+	}
+
+	link := file.Links[0]
+	if link.URL != "/guide/" || link.Line != 1 || link.OccurrenceCount() != 2 {
+		t.Fatalf("Expected /guide/ on line 1 with 2 occurrences, got %+v", link)
+	}
+	var lines []int
+	for _, position := range link.Positions() {
+		lines = append(lines, position.Line)
+	}
+	if !slices.Equal(lines, []int{1, 5}) {
+		t.Errorf("Expected occurrences on lines 1 and 5, got %v", lines)
+	}
+	if file.Links[1].OccurrenceCount() != 1 || len(file.Links[1].Occurrences) != 0 {
+		t.Errorf("Expected /other/ to occur once, got %+v", file.Links[1])
+	}
+	// A repeat with other text keeps it, as a link of its own
+	if again := file.Links[2]; again.URL != "/guide/" || again.Text != "again" || again.Line != 5 || again.OccurrenceCount() != 1 {
+		t.Errorf("Expected the repeat with other text as its own link, got %+v", again)
+	}
+}
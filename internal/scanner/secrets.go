@@ -0,0 +1,126 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSignedURLLifetime is the longest expiry a signed URL may have before
+// it is treated as a leaked long-lived credential
+const maxSignedURLLifetime = 24 * time.Hour
+
+var (
+	secretPatterns = []struct {
+		name  string
+		regex *regexp.Regexp
+	}{
+		{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+		{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+		{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	}
+	jwtRegex = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.(eyJ[A-Za-z0-9_-]+)\.[A-Za-z0-9_-]+`)
+
+	// Query parameters that carry a secret when given a long value
+	secretParams = map[string]bool{
+		"api_key": true, "apikey": true, "access_token": true, "token": true,
+		"auth": true, "password": true, "passwd": true, "secret": true, "client_secret": true,
+	}
+)
+
+// DetectSecrets returns findings for credentials and secrets embedded in a
+// URL: passwords in the userinfo, known API key and token formats, secret
+// query parameters, and signed URLs or JWTs that stay valid for longer than
+// a day after now
+func DetectSecrets(rawURL string, now time.Time) []string {
+	var findings []string
+
+	u, err := url.Parse(rawURL)
+	if err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			findings = append(findings, "URL embeds credentials (user:password@host)")
+		}
+	}
+
+	for _, pattern := range secretPatterns {
+		if pattern.regex.MatchString(rawURL) {
+			findings = append(findings, "URL embeds a "+pattern.name)
+		}
+	}
+
+	if match := jwtRegex.FindStringSubmatch(rawURL); match != nil && jwtLongLived(match[1], now) {
+		findings = append(findings, "URL embeds a JWT without a short expiry")
+	}
+
+	if err != nil {
+		return findings
+	}
+	query := u.Query()
+	for name, values := range query {
+		if secretParams[strings.ToLower(name)] {
+			for _, value := range values {
+				if len(value) >= 16 {
+					findings = append(findings, "URL embeds a secret in the "+name+" parameter")
+					break
+				}
+			}
+		}
+	}
+
+	if lifetime, ok := signedURLLifetime(query, now); ok && lifetime > maxSignedURLLifetime {
+		findings = append(findings, "URL is signed and stays valid for "+lifetime.Round(time.Hour).String())
+	}
+
+	return findings
+}
+
+// jwtLongLived reports whether a JWT payload has no expiry or one more than
+// a day after now
+func jwtLongLived(payload string, now time.Time) bool {
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return false
+	}
+	var claims struct {
+		Exp *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return false
+	}
+	if claims.Exp == nil {
+		return true
+	}
+	return time.Unix(int64(*claims.Exp), 0).Sub(now) > maxSignedURLLifetime
+}
+
+// signedURLLifetime returns how long an AWS, Google Cloud Storage, or Azure
+// SAS signed URL remains valid
+func signedURLLifetime(query url.Values, now time.Time) (time.Duration, bool) {
+	for _, prefix := range []string{"X-Amz-", "X-Goog-"} {
+		if query.Get(prefix+"Signature") == "" {
+			continue
+		}
+		seconds, err := strconv.Atoi(query.Get(prefix + "Expires"))
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if query.Get("sig") != "" && query.Get("se") != "" {
+		expiry, err := time.Parse(time.RFC3339, query.Get("se"))
+		if err != nil {
+			if expiry, err = time.Parse("2006-01-02", query.Get("se")); err != nil {
+				return 0, false
+			}
+		}
+		return expiry.Sub(now), true
+	}
+
+	return 0, false
+}
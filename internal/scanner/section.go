@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// RootSection names files that sit directly in content/ rather than in a section
+const RootSection = "(root)"
+
+// Section returns the Hugo content section a file belongs to: the first
+// directory below content/. Files generated into public/ are attributed
+// through their source path. Files outside any content directory use the
+// first directory of their path.
+func Section(file *File) string {
+	path := file.Path
+	if file.SourcePath != "" {
+		path = file.SourcePath
+	}
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+
+	for i, part := range parts {
+		if part == "content" && i+1 < len(parts) {
+			if i+2 < len(parts) {
+				return parts[i+1]
+			}
+			return RootSection
+		}
+	}
+
+	for _, part := range parts[:len(parts)-1] {
+		if part != "" && part != "." && part != ".." {
+			return part
+		}
+	}
+	return RootSection
+}
+
+// FilterSections returns the files that belong to one of the given sections
+func FilterSections(files []*File, sections []string) []*File {
+	wanted := make(map[string]bool, len(sections))
+	for _, section := range sections {
+		wanted[strings.Trim(section, "/")] = true
+	}
+
+	var filtered []*File
+	for _, file := range files {
+		if wanted[Section(file)] {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,32 @@
+package scanner
+
+import "testing"
+
+func TestSection(t *testing.T) {
+	testCases := []struct {
+		file     File
+		expected string
+	}{
+		{File{Path: "site/content/posts/hello.md"}, "posts"},
+		{File{Path: "content/docs/guide/install.md"}, "docs"},
+		{File{Path: "content/about.md"}, RootSection},
+		{File{Path: "public/posts/hello/index.html", SourcePath: "content/posts/hello.md"}, "posts"},
+		{File{Path: "testdata/test.md"}, "testdata"},
+		{File{Path: "README.md"}, RootSection},
+	}
+
+	for _, tc := range testCases {
+		if got := Section(&tc.file); got != tc.expected {
+			t.Errorf("%s: expected section %s, got %s", tc.file.Path, tc.expected, got)
+		}
+	}
+
+	files := []*File{
+		{Path: "content/posts/a.md"},
+		{Path: "content/docs/b.md"},
+		{Path: "content/posts/c.md"},
+	}
+	if filtered := FilterSections(files, []string{"posts/"}); len(filtered) != 2 {
+		t.Errorf("Expected 2 files in posts, got %d", len(filtered))
+	}
+}
@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// ParseShard parses a shard specification "i/n", selecting the i-th of n
+// shards counted from 1
+func ParseShard(spec string) (index, total int, err error) {
+	before, after, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid shard %q: expected i/n, e.g. 2/4", spec)
+	}
+	index, indexErr := strconv.Atoi(strings.TrimSpace(before))
+	total, totalErr := strconv.Atoi(strings.TrimSpace(after))
+	if indexErr != nil || totalErr != nil || total < 1 || index < 1 || index > total {
+		return 0, 0, fmt.Errorf("invalid shard %q: expected i/n with 1 <= i <= n", spec)
+	}
+	return index, total, nil
+}
+
+// InShard reports whether a link belongs to shard index of total. Links are
+// assigned by a hash of their destination, so every job of a matrix agrees on
+// the partition and equivalent spellings of a URL land in the same shard.
+func InShard(link Link, index, total int) bool {
+	if total <= 1 {
+		return true
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(DestinationKey(link)))
+	return int(hash.Sum32()%uint32(total)) == index-1
+}
+
+// ShardFiles keeps only the links of each file that belong to shard index of
+// total. Files are kept even when none of their links remain, so every shard
+// reports the full set of files.
+func ShardFiles(files []*File, index, total int) {
+	if total <= 1 {
+		return
+	}
+	for _, file := range files {
+		kept := file.Links[:0]
+		for _, link := range file.Links {
+			if InShard(link, index, total) {
+				kept = append(kept, link)
+			}
+		}
+		file.Links = kept
+	}
+}
@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	index, total, err := ParseShard("2/4")
+	if err != nil || index != 2 || total != 4 {
+		t.Errorf("ParseShard(2/4) = %d, %d, %v", index, total, err)
+	}
+
+	for _, spec := range []string{"", "2", "0/4", "5/4", "1/0", "a/b"} {
+		if _, _, err := ParseShard(spec); err == nil {
+			t.Errorf("ParseShard(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestShardFiles(t *testing.T) {
+	const total = 3
+	newFiles := func() []*File {
+		file := &File{Path: "content/a.md"}
+		for i := 0; i < 30; i++ {
+			file.Links = append(file.Links, NewLink(fmt.Sprintf("https://example.com/page-%d", i)))
+		}
+		return []*File{file}
+	}
+
+	// Every link lands in exactly one shard
+	seen := make(map[string]int)
+	for index := 1; index <= total; index++ {
+		files := newFiles()
+		ShardFiles(files, index, total)
+		for _, link := range files[0].Links {
+			seen[link.URL]++
+		}
+	}
+	if len(seen) != 30 {
+		t.Errorf("expected all 30 links across shards, got %d", len(seen))
+	}
+	for url, count := range seen {
+		if count != 1 {
+			t.Errorf("%s appears in %d shards", url, count)
+		}
+	}
+
+	// Equivalent spellings share a shard
+	a, b := NewLink("https://Example.com/x"), NewLink("https://example.com/x")
+	for index := 1; index <= total; index++ {
+		if InShard(a, index, total) != InShard(b, index, total) {
+			t.Errorf("equivalent URLs split across shards")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// shortcodePattern matches a shortcode call with arguments, {{< name ... >}}
+	// or {{% name ... %}}
+	shortcodePattern = regexp.MustCompile(`\{\{[<%]-?\s*([\w./-]+)\s((?:[^}]|\}[^}])*?)-?[>%]\}\}`)
+	// shortcodeArgPattern matches one shortcode argument, named or
+	// positional, quoted with " or ` or unquoted
+	shortcodeArgPattern = regexp.MustCompile("(?:([\\w-]+)\\s*=\\s*)?(?:\"((?:[^\"\\\\]|\\\\.)*)\"|`([^`]*)`|([^\\s\"`]+))")
+)
+
+// parseShortcodeLinks extracts the arguments of custom shortcodes that hold
+// URLs, such as {{< button href="/signup/" >}}. shortcodes maps a shortcode
+// name to its URL arguments: named ones by name, positional ones by their
+// index from 0. Shortcodes starting in code, blanked out in masked, are
+// skipped.
+func parseShortcodeLinks(content, masked string, shortcodes map[string][]string) []foundLink {
+	if len(shortcodes) == 0 {
+		return nil
+	}
+
+	var links []foundLink
+	for _, match := range shortcodePattern.FindAllStringSubmatchIndex(content, -1) {
+		if masked[match[0]] != '{' {
+			continue
+		}
+		params, ok := shortcodes[content[match[2]:match[3]]]
+		if !ok {
+			continue
+		}
+
+		argsStart := match[4]
+		position := 0
+		for _, arg := range shortcodeArgPattern.FindAllStringSubmatchIndex(content[argsStart:match[5]], -1) {
+			var key string
+			if arg[2] != -1 {
+				key = content[argsStart+arg[2] : argsStart+arg[3]]
+			} else {
+				key = strconv.Itoa(position)
+				position++
+			}
+			if !slices.Contains(params, key) {
+				continue
+			}
+			for group := 4; group < len(arg); group += 2 {
+				if arg[group] == -1 {
+					continue
+				}
+				value := content[argsStart+arg[group] : argsStart+arg[group+1]]
+				if group == 4 {
+					value = strings.ReplaceAll(value, `\"`, `"`)
+				}
+				links = append(links, foundLink{url: value, offset: argsStart + arg[group]})
+				break
+			}
+		}
+	}
+	return links
+}
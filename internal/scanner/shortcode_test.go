@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLinksFromFile_CustomShortcodes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "{{< button href=\"/signup/\" class=\"primary\" >}}Sign up{{< /button >}}\n" +
+		"{{% card title=\"Docs\" link=`https://docs.example.com/` %}}\n" +
+		"{{< download \"/files/guide.pdf\" \"Guide\" >}}\n" +
+		"{{< button\n    class=\"wide\"\n    href=/pricing/ >}}\n" +
+		"{{< other href=\"/not-configured/\" >}}\n" +
+		"`{{< button href=\"/in-code/\" >}}`\n" +
+		"{{< card title=\"No link\" >}}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	opts := ParseOptions{Shortcodes: map[string][]string{
+		"button":   {"href"},
+		"card":     {"link"},
+		"download": {"0"},
+	}}
+	if err := ParseLinksFromFileWithOptions(file, opts); err != nil {
+		t.Fatalf("ParseLinksFromFileWithOptions failed: %v", err)
+	}
+	expected := []struct {
+		url  string
+		line int
+	}{
+		{"/signup/", 1},
+		{"https://docs.example.com/", 2},
+		{"/files/guide.pdf", 3},
+		{"/pricing/", 6},
+	}
+	if len(file.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), file.Links)
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Line != want.line || link.Kind != "" {
+			t.Errorf("link %d: expected %q on line %d, got %q (kind %q) on line %d", i, want.url, want.line, link.URL, link.Kind, link.Line)
+		}
+	}
+
+	// Without configured shortcodes, their arguments aren't links
+	file = &File{Path: path}
+	if err := ParseLinksFromFile(file, false); err != nil {
+		t.Fatalf("ParseLinksFromFile failed: %v", err)
+	}
+	if len(file.Links) != 0 {
+		t.Errorf("Expected no links, got %+v", file.Links)
+	}
+}
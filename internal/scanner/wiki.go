@@ -0,0 +1,30 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// wikiLinkPattern matches a wiki link as Obsidian writes them, [[Page Name]],
+// [[page#Heading]], or [[page|link text]], along with the ! of an embed
+var wikiLinkPattern = regexp.MustCompile(`(!?)\[\[([^\[\]|\n]+)(?:\|([^\[\]\n]*))?\]\]`)
+
+// parseWikiLinks extracts the wiki links of a Markdown document, the target
+// as the URL and the alias, if any, as the text. Embeds (![[...]]) include
+// the content of another note rather than link to it and are left out, as
+// are links in code, blanked out in masked.
+func parseWikiLinks(content, masked string) []foundLink {
+	var links []foundLink
+	for _, match := range wikiLinkPattern.FindAllStringSubmatchIndex(masked, -1) {
+		if match[3] > match[2] {
+			continue
+		}
+		target := strings.TrimSpace(content[match[4]:match[5]])
+		text := target
+		if match[6] != -1 {
+			text = content[match[6]:match[7]]
+		}
+		links = append(links, foundLink{url: target, text: text, offset: match[0], kind: KindWiki})
+	}
+	return links
+}
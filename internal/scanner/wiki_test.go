@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLinksFromFile_WikiLinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.md")
+	content := "See [[Getting Started]] and [[docs/Install#Requirements|the requirements]].\n" +
+		"An embed ![[diagram.png]] and `[[in code]]`.\n" +
+		"[[#Local heading]]\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &File{Path: path}
+	if err := ParseLinksFromFileWithOptions(file, ParseOptions{WikiLinks: true}); err != nil {
+		t.Fatalf("ParseLinksFromFileWithOptions failed: %v", err)
+	}
+	expected := []struct {
+		url  string
+		text string
+		line int
+	}{
+		{"Getting Started", "Getting Started", 1},
+		{"docs/Install#Requirements", "the requirements", 1},
+		{"#Local heading", "#Local heading", 3},
+	}
+	if len(file.Links) != len(expected) {
+		for _, link := range file.Links {
+			t.Logf("found %q (line %d)", link.URL, link.Line)
+		}
+		t.Fatalf("Expected %d links, got %d", len(expected), len(file.Links))
+	}
+	for i, want := range expected {
+		link := file.Links[i]
+		if link.URL != want.url || link.Text != want.text || link.Line != want.line || link.Kind != KindWiki {
+			t.Errorf("link %d: expected %q (%q, line %d), got %q (%q, line %d, kind %q)", i, want.url, want.text, want.line, link.URL, link.Text, link.Line, link.Kind)
+		}
+	}
+
+	// Without the option, wiki links are plain text
+	file = &File{Path: path}
+	if err := ParseLinksFromFileWithOptions(file, ParseOptions{}); err != nil {
+		t.Fatalf("ParseLinksFromFileWithOptions failed: %v", err)
+	}
+	if len(file.Links) != 0 {
+		t.Errorf("expected no links without WikiLinks, got %+v", file.Links)
+	}
+}
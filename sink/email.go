@@ -0,0 +1,116 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxListedLinks caps the broken links listed in an email or issue; the
+// JSON report has them all
+const maxListedLinks = 100
+
+// emailSink mails a summary of the run and its broken links through an
+// SMTP server, using STARTTLS when the server offers it
+type emailSink struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	// Username and Password authenticate with the server when set; $VAR and
+	// ${VAR} in the password are expanded from the environment
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	// Subject replaces the default subject, which counts the broken links
+	Subject string `yaml:"subject"`
+	// OnlyFailures skips runs without broken links
+	OnlyFailures bool `yaml:"only_failures"`
+
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func newEmailSink(decode func(v any) error) (Sink, error) {
+	sink := &emailSink{Port: 587, sendMail: smtp.SendMail}
+	if err := decode(sink); err != nil {
+		return nil, err
+	}
+	switch {
+	case sink.Host == "":
+		return nil, errors.New("no host")
+	case sink.From == "":
+		return nil, errors.New("no from address")
+	case len(sink.To) == 0:
+		return nil, errors.New("no to addresses")
+	}
+	return sink, nil
+}
+
+func (s *emailSink) Send(findings Findings) error {
+	if s.OnlyFailures && findings.Summary.BrokenLinks == 0 {
+		return nil
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, os.ExpandEnv(s.Password), s.Host)
+	}
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	if err := s.sendMail(addr, auth, s.From, s.To, s.message(findings)); err != nil {
+		return fmt.Errorf("failed to mail findings to %s: %v", strings.Join(s.To, ", "), err)
+	}
+	return nil
+}
+
+// message returns the email, headers and plain text body
+func (s *emailSink) message(findings Findings) []byte {
+	summary := findings.Summary
+	subject := s.Subject
+	if subject == "" {
+		subject = fmt.Sprintf("Link check: %d broken links, health %.1f (%s)", summary.BrokenLinks, summary.HealthScore, summary.HealthGrade)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", findings.GeneratedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+
+	fmt.Fprintf(&msg, "Files: %d\r\nLinks: %d\r\nBroken links: %d\r\nWarnings: %d\r\nHealth score: %.1f (%s)\r\n",
+		summary.TotalFiles, summary.TotalLinks, summary.BrokenLinks, summary.Warnings, summary.HealthScore, summary.HealthGrade)
+	if findings.Metadata != nil && findings.Metadata.RunID != "" {
+		fmt.Fprintf(&msg, "Run: %s\r\n", findings.Metadata.RunID)
+	}
+
+	broken := findings.Broken()
+	if len(broken) > 0 {
+		msg.WriteString("\r\nBroken links:\r\n")
+	}
+	for i, link := range broken {
+		if i == maxListedLinks {
+			fmt.Fprintf(&msg, "\r\n... and %d more\r\n", len(broken)-i)
+			break
+		}
+		fmt.Fprintf(&msg, "\r\n%s\r\n  %s\r\n", link.URL, linkProblem(link))
+		for _, file := range link.FoundInFiles {
+			fmt.Fprintf(&msg, "  in %s\r\n", file)
+		}
+	}
+	return []byte(msg.String())
+}
+
+// linkProblem describes why a link is broken, e.g. "404: Not Found"
+func linkProblem(link Link) string {
+	switch {
+	case link.StatusCode > 0 && link.ErrorMessage != "":
+		return fmt.Sprintf("%d: %s", link.StatusCode, link.ErrorMessage)
+	case link.StatusCode > 0:
+		return strconv.Itoa(link.StatusCode)
+	default:
+		return link.ErrorMessage
+	}
+}
@@ -0,0 +1,180 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/version"
+)
+
+// defaultIssueTitle is the title of the issue a github-issue sink keeps
+const defaultIssueTitle = "Broken links found by hugo-link-checker"
+
+// gitHubIssueSink keeps one GitHub issue listing the broken links: it opens
+// the issue when links break, updates it on later runs, and closes it once
+// every link works again
+type gitHubIssueSink struct {
+	// Repository is owner/name
+	Repository string `yaml:"repository"`
+	// Token defaults to $GITHUB_TOKEN; $VAR and ${VAR} are expanded from
+	// the environment
+	Token  string   `yaml:"token"`
+	Title  string   `yaml:"title"`
+	Labels []string `yaml:"labels"`
+	// APIURL is the REST API root, for GitHub Enterprise Server
+	APIURL string `yaml:"api_url"`
+
+	client *http.Client
+}
+
+// gitHubIssue is the part of an issue the sink reads
+type gitHubIssue struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	PullRequest any    `json:"pull_request"`
+}
+
+func newGitHubIssueSink(decode func(v any) error) (Sink, error) {
+	sink := &gitHubIssueSink{
+		Token:  "${GITHUB_TOKEN}",
+		Title:  defaultIssueTitle,
+		APIURL: "https://api.github.com",
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	if err := decode(sink); err != nil {
+		return nil, err
+	}
+	if owner, name, ok := strings.Cut(sink.Repository, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid repository %q: expected owner/name", sink.Repository)
+	}
+	if sink.Title == "" {
+		return nil, errors.New("empty title")
+	}
+	sink.APIURL = strings.TrimSuffix(sink.APIURL, "/")
+	return sink, nil
+}
+
+func (s *gitHubIssueSink) Send(findings Findings) error {
+	issue, err := s.openIssue()
+	if err != nil {
+		return err
+	}
+
+	if findings.Summary.BrokenLinks == 0 {
+		if issue == nil {
+			return nil
+		}
+		path := fmt.Sprintf("/repos/%s/issues/%d", s.Repository, issue.Number)
+		if err := s.call(http.MethodPost, path+"/comments", map[string]any{"body": "All links work again."}, nil); err != nil {
+			return err
+		}
+		return s.call(http.MethodPatch, path, map[string]any{"state": "closed"}, nil)
+	}
+
+	body := issueBody(findings)
+	if issue != nil {
+		return s.call(http.MethodPatch, fmt.Sprintf("/repos/%s/issues/%d", s.Repository, issue.Number), map[string]any{"body": body}, nil)
+	}
+	fields := map[string]any{"title": s.Title, "body": body}
+	if len(s.Labels) > 0 {
+		fields["labels"] = s.Labels
+	}
+	return s.call(http.MethodPost, "/repos/"+s.Repository+"/issues", fields, nil)
+}
+
+// openIssue returns the open issue with the sink's title, or nil if there
+// is none. Only the first page of open issues, filtered by the sink's
+// labels, is searched.
+func (s *gitHubIssueSink) openIssue() (*gitHubIssue, error) {
+	query := url.Values{"state": {"open"}, "per_page": {"100"}}
+	if len(s.Labels) > 0 {
+		query.Set("labels", strings.Join(s.Labels, ","))
+	}
+	var issues []gitHubIssue
+	if err := s.call(http.MethodGet, "/repos/"+s.Repository+"/issues?"+query.Encode(), nil, &issues); err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		if issue.PullRequest == nil && issue.Title == s.Title {
+			return &issue, nil
+		}
+	}
+	return nil, nil
+}
+
+// call sends a request to the GitHub API with fields as its JSON body,
+// decoding the response into result when it isn't nil
+func (s *gitHubIssueSink) call(method, path string, fields map[string]any, result any) error {
+	var body io.Reader
+	if fields != nil {
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return fmt.Errorf("failed to encode GitHub request: %v", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, s.APIURL+path, body)
+	if err != nil {
+		return fmt.Errorf("invalid GitHub API url %s: %v", s.APIURL, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "hugo-link-checker/"+version.Version)
+	if fields != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := os.ExpandEnv(s.Token); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update GitHub issue in %s: %v", s.Repository, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("GitHub API %s %s answered %s", method, path, resp.Status)
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to read GitHub API response: %v", err)
+		}
+	}
+	return nil
+}
+
+// issueBody returns the Markdown body of the issue: the summary and a table
+// of the broken links
+func issueBody(findings Findings) string {
+	summary := findings.Summary
+	var body strings.Builder
+	fmt.Fprintf(&body, "**%d broken links** in %d files, health score %.1f (%s)", summary.BrokenLinks, summary.TotalFiles, summary.HealthScore, summary.HealthGrade)
+	if findings.Metadata != nil && findings.Metadata.RunID != "" {
+		fmt.Fprintf(&body, ", run `%s`", findings.Metadata.RunID)
+	}
+	body.WriteString(".\n\n| Link | Problem | Found in |\n| --- | --- | --- |\n")
+
+	broken := findings.Broken()
+	for i, link := range broken {
+		if i == maxListedLinks {
+			fmt.Fprintf(&body, "\n... and %d more; see the JSON report for all of them.\n", len(broken)-i)
+			break
+		}
+		fmt.Fprintf(&body, "| %s | %s | %s |\n", tableCell(link.URL), tableCell(linkProblem(link)), tableCell(strings.Join(link.FoundInFiles, ", ")))
+	}
+	fmt.Fprintf(&body, "\nUpdated %s by hugo-link-checker.\n", findings.GeneratedAt.UTC().Format(time.RFC3339))
+	return body.String()
+}
+
+// tableCell escapes a value for a Markdown table cell
+func tableCell(value string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+}
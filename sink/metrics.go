@@ -0,0 +1,96 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// metricsSink writes the summary of a run as Prometheus gauges to a file,
+// for the node_exporter textfile collector or a step pushing it to a
+// Pushgateway
+type metricsSink struct {
+	File string `yaml:"file"`
+	// Labels are added to every metric, e.g. site: docs
+	Labels map[string]string `yaml:"labels"`
+}
+
+func newMetricsSink(decode func(v any) error) (Sink, error) {
+	sink := &metricsSink{}
+	if err := decode(sink); err != nil {
+		return nil, err
+	}
+	if sink.File == "" {
+		return nil, errors.New("no file")
+	}
+	return sink, nil
+}
+
+// Send replaces the metrics file in one rename, so collectors never read
+// it half written
+func (s *metricsSink) Send(findings Findings) error {
+	summary := findings.Summary
+	var out strings.Builder
+	gauge := func(name, help string, value float64, labels map[string]string) {
+		fmt.Fprintf(&out, "# HELP hugo_link_checker_%s %s\n# TYPE hugo_link_checker_%s gauge\n", name, help, name)
+		fmt.Fprintf(&out, "hugo_link_checker_%s%s %g\n", name, s.labels(labels), value)
+	}
+	gauge("files", "Files scanned.", float64(summary.TotalFiles), nil)
+	gauge("links", "Links found.", float64(summary.TotalLinks), nil)
+	gauge("unique_links", "Unique links found.", float64(summary.UniqueLinks), nil)
+	gauge("broken_links", "Broken links.", float64(summary.BrokenLinks), nil)
+	gauge("warnings", "Warnings.", float64(summary.Warnings), nil)
+	gauge("coverage_percent", "Percentage of links verified.", summary.Coverage, nil)
+	gauge("health_score", "Health score from 0 to 100.", summary.HealthScore, nil)
+	gauge("last_run_timestamp_seconds", "Time of the run.", float64(findings.GeneratedAt.Unix()), nil)
+
+	fmt.Fprintf(&out, "# HELP hugo_link_checker_broken_links_by_category Broken links per error category.\n# TYPE hugo_link_checker_broken_links_by_category gauge\n")
+	for _, category := range slices.Sorted(maps.Keys(summary.BrokenByCategory)) {
+		fmt.Fprintf(&out, "hugo_link_checker_broken_links_by_category%s %d\n",
+			s.labels(map[string]string{"category": category}), summary.BrokenByCategory[category])
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.File), filepath.Base(s.File)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics %s: %v", s.File, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(out.String()); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write metrics %s: %v", s.File, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics %s: %v", s.File, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics %s: %v", s.File, err)
+	}
+	if err := os.Rename(tmp.Name(), s.File); err != nil {
+		return fmt.Errorf("failed to write metrics %s: %v", s.File, err)
+	}
+	return nil
+}
+
+// labels renders the sink's labels and extra into a label set, sorted by
+// name, or "" when there are none
+func (s *metricsSink) labels(extra map[string]string) string {
+	all := maps.Clone(s.Labels)
+	if all == nil {
+		all = make(map[string]string)
+	}
+	maps.Copy(all, extra)
+	if len(all) == 0 {
+		return ""
+	}
+	names := slices.Sorted(maps.Keys(all))
+	pairs := make([]string, len(names))
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, escaper.Replace(all[name]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
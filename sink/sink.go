@@ -0,0 +1,138 @@
+// Package sink passes the summary and findings of a finished run on to
+// notification targets: a webhook, a Prometheus metrics file, email, or a
+// GitHub issue. Sinks are declared by type in the sinks section of the
+// config file; other programs add their own types with Register before
+// running the checker with cli.Main.
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/reporter"
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+)
+
+// Sink receives the summary and findings of a finished run and passes them
+// on, e.g. to a chat webhook, a metrics file, or an issue tracker
+type Sink interface {
+	Send(findings Findings) error
+}
+
+// Summary, Metadata, Link, and Page are the parts of the JSON report a sink
+// receives; their fields are documented with the JSON report
+type (
+	Summary  = reporter.ReportSummary
+	Metadata = reporter.RunMetadata
+	Link     = reporter.UniqueLink
+	Page     = reporter.FileSummary
+)
+
+// Findings is what a run hands its sinks
+type Findings struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Metadata    *Metadata `json:"metadata,omitempty"`
+	Summary     Summary   `json:"summary"`
+	// Links are the unique links that are broken or have warnings
+	Links []Link `json:"links"`
+	// Pages are the files with page warnings
+	Pages []Page `json:"pages,omitempty"`
+}
+
+// Broken returns the links of the findings that are broken, leaving out
+// those with only warnings
+func (f Findings) Broken() []Link {
+	var broken []Link
+	for _, link := range f.Links {
+		if isBroken(link) {
+			broken = append(broken, link)
+		}
+	}
+	return broken
+}
+
+func isBroken(link Link) bool {
+	return link.StatusCode >= 400 || (link.StatusCode == 0 && link.ErrorMessage != "")
+}
+
+// Factory creates a sink from its entry in the config file; decode decodes
+// the entry's settings into a struct with yaml tags
+type Factory func(decode func(v any) error) (Sink, error)
+
+var (
+	factoriesMu sync.RWMutex
+	// factories maps the sink types to their factories
+	factories = map[string]Factory{
+		"webhook":      newWebhookSink,
+		"metrics":      newMetricsSink,
+		"email":        newEmailSink,
+		"github-issue": newGitHubIssueSink,
+	}
+)
+
+// Register makes a sink type available to the config file, replacing the
+// built-in type of the same name if there is one
+func Register(sinkType string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[sinkType] = factory
+}
+
+// New creates a sink of a registered type from its settings
+func New(sinkType string, decode func(v any) error) (Sink, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[sinkType]
+	known := slices.Sorted(maps.Keys(factories))
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown sink type %q (known types: %s)", sinkType, strings.Join(known, ", "))
+	}
+	sink, err := factory(decode)
+	if err != nil {
+		return nil, fmt.Errorf("%s sink: %v", sinkType, err)
+	}
+	return sink, nil
+}
+
+// NewFindings collects the findings of a run for its sinks
+func NewFindings(files []*scanner.File, metadata *Metadata) Findings {
+	report := reporter.NewJSONReport(files, metadata)
+	findings := Findings{
+		GeneratedAt: report.GeneratedAt,
+		Metadata:    report.Metadata,
+		Summary:     report.Summary,
+		Links:       []Link{},
+	}
+	for _, link := range report.Links {
+		if isBroken(link) || len(link.Warnings) > 0 {
+			findings.Links = append(findings.Links, link)
+		}
+	}
+	for _, file := range report.Files {
+		if len(file.Warnings) > 0 {
+			findings.Pages = append(findings.Pages, file)
+		}
+	}
+	return findings
+}
+
+// Send hands the findings of a run to each sink. A failing sink doesn't
+// keep the others from receiving them; all failures are returned.
+func Send(files []*scanner.File, metadata *Metadata, sinks []Sink) error {
+	if len(sinks) == 0 {
+		return nil
+	}
+	findings := NewFindings(files, metadata)
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Send(findings); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,292 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/infodancer/hugo-link-checker/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// sinkSettings returns the decode function of a sink configured with the
+// given YAML
+func sinkSettings(t *testing.T, data string) func(v any) error {
+	t.Helper()
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &node); err != nil {
+		t.Fatal(err)
+	}
+	return node.Decode
+}
+
+func sinkTestFiles() []*scanner.File {
+	return []*scanner.File{
+		{
+			Path: "content/post.md",
+			Links: []scanner.Link{
+				{URL: "/missing/", Type: scanner.LinkTypeInternal, StatusCode: 404, ErrorMessage: "File not found", ErrorCategory: scanner.CategoryNotFoundLocal},
+				{URL: "https://example.com/", Type: scanner.LinkTypeExternal, StatusCode: 200},
+				{URL: "http://example.org/", Type: scanner.LinkTypeExternal, StatusCode: 200, Warnings: []string{"Insecure link"}},
+			},
+		},
+		{Path: "content/about.md", Warnings: []string{"Page is listed in the sitemap but robots.txt disallows it"}},
+	}
+}
+
+type recordingSink struct {
+	received []Findings
+	err      error
+}
+
+func (s *recordingSink) Send(findings Findings) error {
+	s.received = append(s.received, findings)
+	return s.err
+}
+
+func TestSend(t *testing.T) {
+	failing := &recordingSink{err: errors.New("unreachable")}
+	working := &recordingSink{}
+	err := Send(sinkTestFiles(), &Metadata{RunID: "run-1"}, []Sink{failing, working})
+	if err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("Expected the failing sink's error, got %v", err)
+	}
+	if len(working.received) != 1 {
+		t.Fatalf("Expected every sink to receive the findings, got %d", len(working.received))
+	}
+
+	findings := working.received[0]
+	if findings.Metadata == nil || findings.Metadata.RunID != "run-1" || findings.Summary.BrokenLinks != 1 {
+		t.Errorf("Unexpected summary: %+v %+v", findings.Metadata, findings.Summary)
+	}
+	var urls []string
+	for _, link := range findings.Links {
+		urls = append(urls, link.URL)
+	}
+	if strings.Join(urls, " ") != "/missing/ http://example.org/" {
+		t.Errorf("Expected the broken and warned links only, got %v", urls)
+	}
+	if len(findings.Pages) != 1 || findings.Pages[0].Path != "content/about.md" {
+		t.Errorf("Expected the page with warnings, got %+v", findings.Pages)
+	}
+}
+
+func TestNewSink(t *testing.T) {
+	if _, err := New("pager", sinkSettings(t, "type: pager\n")); err == nil || !strings.Contains(err.Error(), "known types: email, github-issue, metrics, webhook") {
+		t.Errorf("Expected an unknown type to be rejected, got %v", err)
+	}
+	if _, err := New("webhook", sinkSettings(t, "type: webhook\n")); err == nil {
+		t.Error("Expected a webhook without url to be rejected")
+	}
+
+	custom := &recordingSink{}
+	Register("recording", func(decode func(v any) error) (Sink, error) {
+		return custom, nil
+	})
+	defer func() {
+		factoriesMu.Lock()
+		delete(factories, "recording")
+		factoriesMu.Unlock()
+	}()
+	if sink, err := New("recording", sinkSettings(t, "type: recording\n")); err != nil || sink != custom {
+		t.Errorf("Expected the registered sink, got %v %v", sink, err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var posted Findings
+	var auth string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+			t.Errorf("Failed to decode the posted findings: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("HOOK_TOKEN", "secret")
+	sink, err := New("webhook", sinkSettings(t, "type: webhook\nurl: "+server.URL+"\nheaders:\n  Authorization: Bearer ${HOOK_TOKEN}\n"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := sink.Send(NewFindings(sinkTestFiles(), nil)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Expected the header expanded from the environment, got %q", auth)
+	}
+	if posted.Summary.BrokenLinks != 1 || len(posted.Links) != 2 || posted.Links[0].URL != "/missing/" {
+		t.Errorf("Unexpected posted findings: %+v", posted)
+	}
+
+	sink, err = New("webhook", sinkSettings(t, "type: webhook\nurl: "+server.URL+"\nonly_failures: true\n"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := sink.Send(NewFindings(nil, nil)); err != nil || requests != 1 {
+		t.Errorf("Expected no post for a run without broken links, got %d requests, %v", requests, err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+	sink, err = New("webhook", sinkSettings(t, "type: webhook\nurl: "+failing.URL+"\n"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := sink.Send(NewFindings(nil, nil)); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected the webhook's status as the error, got %v", err)
+	}
+}
+
+func TestMetricsSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.prom")
+	sink, err := New("metrics", sinkSettings(t, "type: metrics\nfile: "+path+"\nlabels:\n  site: docs\n"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := sink.Send(NewFindings(sinkTestFiles(), nil)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE hugo_link_checker_broken_links gauge\n",
+		`hugo_link_checker_broken_links{site="docs"} 1` + "\n",
+		`hugo_link_checker_links{site="docs"} 3` + "\n",
+		`hugo_link_checker_broken_links_by_category{category="not-found-local",site="docs"} 1` + "\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in the metrics, got:\n%s", want, data)
+		}
+	}
+	if matches, _ := filepath.Glob(path + ".tmp*"); len(matches) != 0 {
+		t.Errorf("Expected no temporary files left, got %v", matches)
+	}
+}
+
+func TestEmailSink(t *testing.T) {
+	if _, err := New("email", sinkSettings(t, "type: email\nhost: smtp.example.com\nfrom: links@example.com\n")); err == nil {
+		t.Error("Expected an email sink without recipients to be rejected")
+	}
+
+	t.Setenv("SMTP_PASSWORD", "secret")
+	created, err := New("email", sinkSettings(t, "type: email\nhost: smtp.example.com\nusername: links\npassword: ${SMTP_PASSWORD}\nfrom: links@example.com\nto: [web@example.com, ops@example.com]\n"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	sink := created.(*emailSink)
+	var addr string
+	var to []string
+	var msg []byte
+	sink.sendMail = func(a string, auth smtp.Auth, from string, recipients []string, message []byte) error {
+		addr, to, msg = a, recipients, message
+		if auth == nil {
+			t.Error("Expected authentication")
+		}
+		return nil
+	}
+	if err := sink.Send(NewFindings(sinkTestFiles(), &Metadata{RunID: "run-1"})); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if addr != "smtp.example.com:587" || strings.Join(to, " ") != "web@example.com ops@example.com" {
+		t.Errorf("Unexpected delivery to %s for %v", addr, to)
+	}
+	for _, want := range []string{
+		"To: web@example.com, ops@example.com\r\n",
+		"Subject: Link check: 1 broken links, health",
+		"Run: run-1\r\n",
+		"\r\n/missing/\r\n  404: File not found\r\n  in content/post.md\r\n",
+	} {
+		if !strings.Contains(string(msg), want) {
+			t.Errorf("Expected %q in the message, got:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(string(msg), "http://example.org/") {
+		t.Errorf("Expected links with only warnings left out, got:\n%s", msg)
+	}
+}
+
+func TestGitHubIssueSink(t *testing.T) {
+	var issues []map[string]any
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer gh-token" {
+			t.Errorf("Expected the token, got %q", r.Header.Get("Authorization"))
+		}
+		var fields map[string]any
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&fields)
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/team/site/issues":
+			if r.URL.Query().Get("labels") != "links" {
+				t.Errorf("Expected issues filtered by label, got %s", r.URL.RawQuery)
+			}
+			var open []map[string]any
+			for _, issue := range issues {
+				if issue["state"] == "open" {
+					open = append(open, issue)
+				}
+			}
+			_ = json.NewEncoder(w).Encode(append(open, map[string]any{"number": 7, "title": defaultIssueTitle, "pull_request": map[string]any{}}))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/team/site/issues":
+			fields["number"], fields["state"] = len(issues)+1, "open"
+			issues = append(issues, fields)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/team/site/issues/1":
+			for key, value := range fields {
+				issues[0][key] = value
+			}
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/team/site/issues/1/comments":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+	sink, err := New("github-issue", sinkSettings(t, "type: github-issue\nrepository: team/site\nlabels: [links]\napi_url: "+server.URL+"/\n"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// Broken links open an issue, and later runs update it
+	for range 2 {
+		if err := sink.Send(NewFindings(sinkTestFiles(), nil)); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	if len(issues) != 1 || issues[0]["title"] != defaultIssueTitle || !strings.Contains(issues[0]["body"].(string), "| /missing/ | 404: File not found | content/post.md |") {
+		t.Fatalf("Expected one issue listing the broken link, got %+v", issues)
+	}
+
+	// Once the links work, the issue is closed
+	if err := sink.Send(NewFindings(nil, nil)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if issues[0]["state"] != "closed" {
+		t.Errorf("Expected the issue closed, got %+v", issues[0])
+	}
+	expected := "GET /repos/team/site/issues,POST /repos/team/site/issues,GET /repos/team/site/issues,PATCH /repos/team/site/issues/1," +
+		"GET /repos/team/site/issues,POST /repos/team/site/issues/1/comments,PATCH /repos/team/site/issues/1"
+	if strings.Join(requests, ",") != expected {
+		t.Errorf("Unexpected requests %v", requests)
+	}
+
+	if _, err := New("github-issue", sinkSettings(t, "type: github-issue\nrepository: site\n")); err == nil {
+		t.Error("Expected a repository without owner to be rejected")
+	}
+}
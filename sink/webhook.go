@@ -0,0 +1,66 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/infodancer/hugo-link-checker/internal/version"
+)
+
+// webhookSink POSTs the findings as JSON to a URL, such as a chat
+// integration or a dashboard's ingest endpoint
+type webhookSink struct {
+	URL string `yaml:"url"`
+	// Headers are sent with the request; $VAR and ${VAR} in their values
+	// are expanded from the environment, so tokens stay out of the config
+	Headers map[string]string `yaml:"headers"`
+	// OnlyFailures skips runs without broken links
+	OnlyFailures bool `yaml:"only_failures"`
+
+	client *http.Client
+}
+
+func newWebhookSink(decode func(v any) error) (Sink, error) {
+	sink := &webhookSink{client: &http.Client{Timeout: 30 * time.Second}}
+	if err := decode(sink); err != nil {
+		return nil, err
+	}
+	if sink.URL == "" {
+		return nil, errors.New("no url")
+	}
+	return sink, nil
+}
+
+func (s *webhookSink) Send(findings Findings) error {
+	if s.OnlyFailures && findings.Summary.BrokenLinks == 0 {
+		return nil
+	}
+	body, err := json.Marshal(findings)
+	if err != nil {
+		return fmt.Errorf("failed to encode findings: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook url %s: %v", s.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hugo-link-checker/"+version.Version)
+	for name, value := range s.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post findings to %s: %v", s.URL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s answered %s", s.URL, resp.Status)
+	}
+	return nil
+}